@@ -0,0 +1,74 @@
+package DAO
+
+import (
+	"melody_cure/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AIUsageDAO struct {
+	db *gorm.DB
+}
+
+func NewAIUsageDAO(db *gorm.DB) *AIUsageDAO {
+	return &AIUsageDAO{db: db}
+}
+
+// AIUsageFilter 用量汇总的筛选条件，为空的条件不限制
+type AIUsageFilter struct {
+	UserID        string
+	InstitutionID string
+	Start         *time.Time
+	End           *time.Time
+}
+
+// CreateAIUsageRecord 记录一次大模型调用的用量
+func (dao *AIUsageDAO) CreateAIUsageRecord(record *model.AIUsageRecord) error {
+	return dao.db.Create(record).Error
+}
+
+// SumUserTokens 统计用户自指定时间起使用的 token 数
+func (dao *AIUsageDAO) SumUserTokens(userID string, since time.Time) (int64, error) {
+	return dao.sumTokens("user_id = ?", userID, since)
+}
+
+// SumInstitutionTokens 统计机构及其成员自指定时间起使用的 token 数
+func (dao *AIUsageDAO) SumInstitutionTokens(institutionID string, since time.Time) (int64, error) {
+	return dao.sumTokens("institution_id = ?", institutionID, since)
+}
+
+func (dao *AIUsageDAO) sumTokens(condition, value string, since time.Time) (int64, error) {
+	var total int64
+	err := dao.db.Model(&model.AIUsageRecord{}).
+		Where(condition, value).
+		Where("created_at >= ?", since).
+		Select("COALESCE(SUM(total_tokens), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// SummarizeAIUsageByModel 按提供商和模型汇总用量
+func (dao *AIUsageDAO) SummarizeAIUsageByModel(filter AIUsageFilter) ([]model.AIModelUsage, error) {
+	query := dao.db.Model(&model.AIUsageRecord{})
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.InstitutionID != "" {
+		query = query.Where("institution_id = ?", filter.InstitutionID)
+	}
+	if filter.Start != nil {
+		query = query.Where("created_at >= ?", *filter.Start)
+	}
+	if filter.End != nil {
+		query = query.Where("created_at < ?", *filter.End)
+	}
+
+	var usages []model.AIModelUsage
+	err := query.
+		Select("provider, model, COUNT(*) AS requests, SUM(prompt_tokens) AS prompt_tokens, SUM(completion_tokens) AS completion_tokens, SUM(total_tokens) AS total_tokens").
+		Group("provider, model").
+		Order("total_tokens DESC").
+		Scan(&usages).Error
+	return usages, err
+}
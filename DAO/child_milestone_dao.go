@@ -0,0 +1,27 @@
+package DAO
+
+import (
+	"melody_cure/model"
+
+	"gorm.io/gorm"
+)
+
+type ChildMilestoneDAO struct {
+	db *gorm.DB
+}
+
+func NewChildMilestoneDAO(db *gorm.DB) *ChildMilestoneDAO {
+	return &ChildMilestoneDAO{db: db}
+}
+
+// CreateMilestone 记录达成的里程碑
+func (dao *ChildMilestoneDAO) CreateMilestone(milestone *model.ChildMilestone) error {
+	return dao.db.Create(milestone).Error
+}
+
+// GetMilestonesByChildID 获取儿童已达成的里程碑
+func (dao *ChildMilestoneDAO) GetMilestonesByChildID(childArchiveID string) ([]model.ChildMilestone, error) {
+	var milestones []model.ChildMilestone
+	err := dao.db.Where("child_archive_id = ?", childArchiveID).Order("days asc").Find(&milestones).Error
+	return milestones, err
+}
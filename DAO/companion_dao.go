@@ -0,0 +1,112 @@
+package DAO
+
+import (
+	"melody_cure/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CompanionDAO struct {
+	db *gorm.DB
+}
+
+func NewCompanionDAO(db *gorm.DB) *CompanionDAO {
+	return &CompanionDAO{db: db}
+}
+
+// GetAICompanionByID 根据ID获取AI陪伴
+func (dao *CompanionDAO) GetAICompanionByID(id string) (*AICompanion, error) {
+	var companion AICompanion
+	err := dao.db.Where("id = ?", id).First(&companion).Error
+	return &companion, err
+}
+
+// CreateConversation 创建陪伴对话
+func (dao *CompanionDAO) CreateConversation(conversation *model.CompanionConversation) error {
+	return dao.db.Create(conversation).Error
+}
+
+// GetConversationByID 根据ID获取陪伴对话
+func (dao *CompanionDAO) GetConversationByID(id uint) (*model.CompanionConversation, error) {
+	var conversation model.CompanionConversation
+	err := dao.db.First(&conversation, id).Error
+	return &conversation, err
+}
+
+// ListConversations 获取儿童的陪伴对话，最近有消息的排在前面，companionID 为空时不限陪伴
+func (dao *CompanionDAO) ListConversations(childArchiveID, companionID string) ([]model.CompanionConversation, error) {
+	query := dao.db.Where("child_archive_id = ?", childArchiveID)
+	if companionID != "" {
+		query = query.Where("companion_id = ?", companionID)
+	}
+	var conversations []model.CompanionConversation
+	err := query.Order("COALESCE(last_message_at, created_at) desc").Find(&conversations).Error
+	return conversations, err
+}
+
+// AppendMessages 在一个事务中保存消息并更新对话的消息数、过滤数和最后消息时间
+func (dao *CompanionDAO) AppendMessages(conversation *model.CompanionConversation, messages ...*model.CompanionMessage) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		filtered := 0
+		for _, message := range messages {
+			message.ConversationID = conversation.ID
+			if err := tx.Create(message).Error; err != nil {
+				return err
+			}
+			if message.Filtered {
+				filtered++
+			}
+		}
+		now := time.Now()
+		conversation.MessageCount += len(messages)
+		conversation.FilteredCount += filtered
+		conversation.LastMessageAt = &now
+		return tx.Model(conversation).Updates(map[string]interface{}{
+			"message_count":   gorm.Expr("message_count + ?", len(messages)),
+			"filtered_count":  gorm.Expr("filtered_count + ?", filtered),
+			"last_message_at": now,
+		}).Error
+	})
+}
+
+// GetMessagesAfter 获取对话中ID大于 afterID 的消息，按时间升序
+func (dao *CompanionDAO) GetMessagesAfter(conversationID, afterID uint) ([]model.CompanionMessage, error) {
+	var messages []model.CompanionMessage
+	err := dao.db.Where("conversation_id = ? AND id > ?", conversationID, afterID).Order("id asc").Find(&messages).Error
+	return messages, err
+}
+
+// GetMessages 分页获取对话消息，beforeID 大于0时只返回更早的消息。结果按时间升序
+func (dao *CompanionDAO) GetMessages(conversationID, beforeID uint, limit int) ([]model.CompanionMessage, error) {
+	query := dao.db.Where("conversation_id = ?", conversationID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	var messages []model.CompanionMessage
+	if err := query.Order("id desc").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+// UpdateMemory 更新对话的记忆摘要及已合并的最后一条消息
+func (dao *CompanionDAO) UpdateMemory(conversationID uint, summary string, summarizedUntil uint) error {
+	return dao.db.Model(&model.CompanionConversation{}).Where("id = ?", conversationID).
+		Updates(map[string]interface{}{"memory_summary": summary, "summarized_until": summarizedUntil}).Error
+}
+
+// GetMessageByID 获取对话中的一条消息
+func (dao *CompanionDAO) GetMessageByID(conversationID, messageID uint) (*model.CompanionMessage, error) {
+	var message model.CompanionMessage
+	err := dao.db.Where("conversation_id = ? AND id = ?", conversationID, messageID).First(&message).Error
+	return &message, err
+}
+
+// UpdateMessageAudio 保存消息的合成语音地址
+func (dao *CompanionDAO) UpdateMessageAudio(messageID uint, audioURL string) error {
+	return dao.db.Model(&model.CompanionMessage{}).Where("id = ?", messageID).Update("audio_url", audioURL).Error
+}
@@ -53,6 +53,32 @@ func AutoMigrate(db *gorm.DB) error {
 		&model.LogMedia{},
 		&model.ImageToken{},
 		&model.GeneratedReport{},
+		&model.GeneratedReportVersion{},
+		&model.LogTemplate{},
+		&model.ChildLogTemplate{},
+		&model.LogMetric{},
+		&model.Notification{},
+		&model.ChildMilestone{},
+		&model.TreatmentPlan{},
+		&model.TreatmentGoal{},
+		&model.GoalLink{},
+		&model.GameSession{},
+		&model.ReportJob{},
+		&model.ReportType{},
+		&model.PromptTemplate{},
+		&model.AIUsageRecord{},
+		&model.InstitutionBranding{},
+		&model.ShareLink{},
+		&model.ShareAccessLog{},
+		&model.ReportFeedback{},
+		&model.ReportSafetyReview{},
+		&model.CompanionConversation{},
+		&model.CompanionMessage{},
+		&model.ConsultationThread{},
+		&model.ConsultationMessage{},
+		&model.ConsultationEscalation{},
+		&model.SemanticChunk{},
+		&model.ChildTherapistGrant{},
 	)
 }
 
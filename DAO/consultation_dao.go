@@ -0,0 +1,169 @@
+package DAO
+
+import (
+	"melody_cure/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ConsultationDAO struct {
+	db *gorm.DB
+}
+
+func NewConsultationDAO(db *gorm.DB) *ConsultationDAO {
+	return &ConsultationDAO{db: db}
+}
+
+// GetVirtualTherapistByID 根据ID获取虚拟疗愈导师
+func (dao *ConsultationDAO) GetVirtualTherapistByID(id string) (*VirtualTherapist, error) {
+	var therapist VirtualTherapist
+	err := dao.db.Where("id = ?", id).First(&therapist).Error
+	return &therapist, err
+}
+
+// CreateThread 创建咨询会话
+func (dao *ConsultationDAO) CreateThread(thread *model.ConsultationThread) error {
+	return dao.db.Create(thread).Error
+}
+
+// GetThreadByID 根据ID获取咨询会话
+func (dao *ConsultationDAO) GetThreadByID(id uint) (*model.ConsultationThread, error) {
+	var thread model.ConsultationThread
+	err := dao.db.First(&thread, id).Error
+	return &thread, err
+}
+
+// ListThreads 获取儿童的咨询会话，最近有消息的排在前面
+func (dao *ConsultationDAO) ListThreads(childArchiveID string) ([]model.ConsultationThread, error) {
+	var threads []model.ConsultationThread
+	err := dao.db.Where("child_archive_id = ?", childArchiveID).
+		Order("COALESCE(last_message_at, created_at) desc").Find(&threads).Error
+	return threads, err
+}
+
+// AppendMessages 在一个事务中保存消息并更新会话的消息数和最后消息时间
+func (dao *ConsultationDAO) AppendMessages(thread *model.ConsultationThread, messages ...*model.ConsultationMessage) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		for _, message := range messages {
+			message.ThreadID = thread.ID
+			if err := tx.Create(message).Error; err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		thread.MessageCount += len(messages)
+		thread.LastMessageAt = &now
+		return tx.Model(thread).Updates(map[string]interface{}{
+			"message_count":   gorm.Expr("message_count + ?", len(messages)),
+			"last_message_at": now,
+		}).Error
+	})
+}
+
+// GetMessages 获取会话的全部消息，按时间升序
+func (dao *ConsultationDAO) GetMessages(threadID uint) ([]model.ConsultationMessage, error) {
+	var messages []model.ConsultationMessage
+	err := dao.db.Where("thread_id = ?", threadID).Order("id asc").Find(&messages).Error
+	return messages, err
+}
+
+// GetRecentMessages 获取会话最近的 limit 条消息，按时间升序
+func (dao *ConsultationDAO) GetRecentMessages(threadID uint, limit int) ([]model.ConsultationMessage, error) {
+	var messages []model.ConsultationMessage
+	if err := dao.db.Where("thread_id = ?", threadID).Order("id desc").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+// CreateEscalation 创建转介申请
+func (dao *ConsultationDAO) CreateEscalation(escalation *model.ConsultationEscalation) error {
+	return dao.db.Create(escalation).Error
+}
+
+// GetEscalationByID 根据ID获取转介申请
+func (dao *ConsultationDAO) GetEscalationByID(id uint) (*model.ConsultationEscalation, error) {
+	var escalation model.ConsultationEscalation
+	err := dao.db.First(&escalation, id).Error
+	return &escalation, err
+}
+
+// GetActiveEscalation 获取会话中等待接手或已接手的转介申请
+func (dao *ConsultationDAO) GetActiveEscalation(threadID uint) (*model.ConsultationEscalation, error) {
+	var escalation model.ConsultationEscalation
+	err := dao.db.Where("thread_id = ? AND status IN ?", threadID,
+		[]string{model.EscalationPending, model.EscalationAccepted}).First(&escalation).Error
+	return &escalation, err
+}
+
+// ListThreadEscalations 获取会话的全部转介申请，按时间倒序
+func (dao *ConsultationDAO) ListThreadEscalations(threadID uint) ([]model.ConsultationEscalation, error) {
+	var escalations []model.ConsultationEscalation
+	err := dao.db.Where("thread_id = ?", threadID).Order("created_at desc").Find(&escalations).Error
+	return escalations, err
+}
+
+// ListTherapistEscalations 获取康复师可以看到的转介申请：等待接手的未指定申请、指定给该康复师或由其接手的申请。
+// status 为空时不限状态，未指定康复师的申请只在等待接手时可见
+func (dao *ConsultationDAO) ListTherapistEscalations(therapistUserID, status string) ([]model.ConsultationEscalation, error) {
+	query := dao.db.Where("therapist_user_id = ? OR (therapist_user_id = '' AND status = ?)", therapistUserID, model.EscalationPending)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if status == model.EscalationPending {
+		query = query.Order("created_at asc")
+	} else {
+		query = query.Order("created_at desc")
+	}
+	var escalations []model.ConsultationEscalation
+	err := query.Find(&escalations).Error
+	return escalations, err
+}
+
+// AcceptEscalation 康复师接手转介申请并将会话转为由其负责。申请已被处理或指定给其他康复师时返回 false
+func (dao *ConsultationDAO) AcceptEscalation(escalation *model.ConsultationEscalation, therapistUserID string) (bool, error) {
+	accepted := false
+	err := dao.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.ConsultationEscalation{}).
+			Where("id = ? AND status = ? AND (therapist_user_id = '' OR therapist_user_id = ?)", escalation.ID, model.EscalationPending, therapistUserID).
+			Updates(map[string]interface{}{"status": model.EscalationAccepted, "therapist_user_id": therapistUserID, "accepted_at": now})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Model(&model.ConsultationThread{}).Where("id = ?", escalation.ThreadID).
+			Updates(map[string]interface{}{"status": model.ConsultationEscalated, "therapist_user_id": therapistUserID}).Error; err != nil {
+			return err
+		}
+		escalation.Status, escalation.TherapistUserID, escalation.AcceptedAt = model.EscalationAccepted, therapistUserID, &now
+		accepted = true
+		return nil
+	})
+	return accepted && err == nil, err
+}
+
+// CloseEscalation 结束或撤回转介申请，会话恢复由虚拟疗愈导师回复。申请不处于 fromStatus 状态时返回 false
+func (dao *ConsultationDAO) CloseEscalation(escalation *model.ConsultationEscalation, fromStatus, toStatus, note string) (bool, error) {
+	closed := false
+	err := dao.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.ConsultationEscalation{}).
+			Where("id = ? AND status = ?", escalation.ID, fromStatus).
+			Updates(map[string]interface{}{"status": toStatus, "resolution_note": note, "closed_at": now})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Model(&model.ConsultationThread{}).Where("id = ?", escalation.ThreadID).
+			Updates(map[string]interface{}{"status": model.ConsultationOpen, "therapist_user_id": ""}).Error; err != nil {
+			return err
+		}
+		escalation.Status, escalation.ResolutionNote, escalation.ClosedAt = toStatus, note, &now
+		closed = true
+		return nil
+	})
+	return closed && err == nil, err
+}
@@ -0,0 +1,34 @@
+package DAO
+
+import (
+	"melody_cure/model"
+
+	"gorm.io/gorm"
+)
+
+type GameSessionDAO struct {
+	db *gorm.DB
+}
+
+func NewGameSessionDAO(db *gorm.DB) *GameSessionDAO {
+	return &GameSessionDAO{db: db}
+}
+
+// CreateGameSession 记录一次游戏训练
+func (dao *GameSessionDAO) CreateGameSession(session *model.GameSession) error {
+	return dao.db.Create(session).Error
+}
+
+// GetGameSessionsByChildID 获取儿童的游戏训练记录
+func (dao *GameSessionDAO) GetGameSessionsByChildID(childArchiveID string) ([]model.GameSession, error) {
+	var sessions []model.GameSession
+	err := dao.db.Where("child_archive_id = ?", childArchiveID).Order("played_at desc").Find(&sessions).Error
+	return sessions, err
+}
+
+// GetGameSessionByID 获取游戏训练记录
+func (dao *GameSessionDAO) GetGameSessionByID(sessionID uint) (*model.GameSession, error) {
+	var session model.GameSession
+	err := dao.db.First(&session, sessionID).Error
+	return &session, err
+}
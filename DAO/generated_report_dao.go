@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GeneratedReportDAO struct {
@@ -15,22 +16,115 @@ func NewGeneratedReportDAO(db *gorm.DB) *GeneratedReportDAO {
 	return &GeneratedReportDAO{db: db}
 }
 
-// CreateGeneratedReport 创建AI生成报告
+// CreateGeneratedReport 创建AI生成报告，同时保存AI原始内容作为第一个版本
 func (dao *GeneratedReportDAO) CreateGeneratedReport(report *model.GeneratedReport) error {
-	return dao.db.Create(report).Error
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		return createGeneratedReport(tx, report)
+	})
 }
 
-// GetGeneratedReportByChildIDAndType 根据儿童档案ID和报告类型获取报告
+func createGeneratedReport(tx *gorm.DB, report *model.GeneratedReport) error {
+	report.CurrentVersion = 1
+	if err := tx.Create(report).Error; err != nil {
+		return err
+	}
+	return tx.Create(&model.GeneratedReportVersion{
+		ReportID:          report.ID,
+		Version:           1,
+		Content:           report.Content,
+		StructuredContent: report.StructuredContent,
+		Source:            model.ReportVersionAI,
+	}).Error
+}
+
+// GetGeneratedReportByID 根据ID获取AI生成报告
+func (dao *GeneratedReportDAO) GetGeneratedReportByID(reportID uint) (*model.GeneratedReport, error) {
+	var report model.GeneratedReport
+	err := dao.db.First(&report, reportID).Error
+	return &report, err
+}
+
+// GetGeneratedReportByChildIDAndType 根据儿童档案ID和报告类型获取最新的报告原文
 func (dao *GeneratedReportDAO) GetGeneratedReportByChildIDAndType(childArchiveID, reportType string) (*model.GeneratedReport, error) {
 	var report model.GeneratedReport
-	err := dao.db.Where("child_archive_id = ? AND report_type = ?", childArchiveID, reportType).
+	err := dao.db.Where("child_archive_id = ? AND report_type = ? AND source_report_id IS NULL", childArchiveID, reportType).
 		Order("created_at desc").First(&report).Error
 	return &report, err
 }
 
-// UpdateGeneratedReport 更新AI生成报告
-func (dao *GeneratedReportDAO) UpdateGeneratedReport(report *model.GeneratedReport) error {
-	return dao.db.Save(report).Error
+// GetReportTranslation 获取报告在指定语言下最新的译文
+func (dao *GeneratedReportDAO) GetReportTranslation(sourceReportID uint, language string) (*model.GeneratedReport, error) {
+	var report model.GeneratedReport
+	err := dao.db.Where("source_report_id = ? AND language = ?", sourceReportID, language).
+		Order("source_version desc, id desc").First(&report).Error
+	return &report, err
+}
+
+// GetReportTranslations 获取报告的所有译文，每种语言只返回最新的一份
+func (dao *GeneratedReportDAO) GetReportTranslations(sourceReportID uint) ([]model.GeneratedReport, error) {
+	var reports []model.GeneratedReport
+	err := dao.db.Where("source_report_id = ?", sourceReportID).
+		Order("language asc, source_version desc, id desc").Find(&reports).Error
+	if err != nil {
+		return nil, err
+	}
+	latest := make([]model.GeneratedReport, 0, len(reports))
+	for _, report := range reports {
+		if len(latest) == 0 || latest[len(latest)-1].Language != report.Language {
+			latest = append(latest, report)
+		}
+	}
+	return latest, nil
+}
+
+// AddReportVersion 新增报告版本并将报告内容更新为该版本，版本号在报告行锁内递增
+func (dao *GeneratedReportDAO) AddReportVersion(version *model.GeneratedReportVersion) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		var report model.GeneratedReport
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&report, version.ReportID).Error; err != nil {
+			return err
+		}
+		if report.CurrentVersion == 0 {
+			// 版本功能上线前生成的报告，先保存当前内容作为第一个版本
+			if err := tx.Create(&model.GeneratedReportVersion{
+				ReportID:          report.ID,
+				Version:           1,
+				Content:           report.Content,
+				StructuredContent: report.StructuredContent,
+				Source:            model.ReportVersionAI,
+				CreatedAt:         report.GeneratedAt,
+			}).Error; err != nil {
+				return err
+			}
+			report.CurrentVersion = 1
+		}
+
+		version.Version = report.CurrentVersion + 1
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+		// 结构化内容随版本切换，人工编辑的版本没有结构化内容
+		return tx.Model(&report).Updates(map[string]interface{}{
+			"content":            version.Content,
+			"structured_content": version.StructuredContent,
+			"current_version":    version.Version,
+			"is_edited":          true,
+		}).Error
+	})
+}
+
+// GetReportVersions 获取报告的所有版本，按版本号升序
+func (dao *GeneratedReportDAO) GetReportVersions(reportID uint) ([]model.GeneratedReportVersion, error) {
+	var versions []model.GeneratedReportVersion
+	err := dao.db.Where("report_id = ?", reportID).Order("version asc").Find(&versions).Error
+	return versions, err
+}
+
+// GetReportVersion 获取报告的指定版本
+func (dao *GeneratedReportDAO) GetReportVersion(reportID uint, version int) (*model.GeneratedReportVersion, error) {
+	var v model.GeneratedReportVersion
+	err := dao.db.Where("report_id = ? AND version = ?", reportID, version).First(&v).Error
+	return &v, err
 }
 
 // DeleteGeneratedReport 删除AI生成报告
@@ -46,11 +140,11 @@ func (dao *GeneratedReportDAO) GetGeneratedReportsByChildID(childArchiveID strin
 	return reports, err
 }
 
-// GetGeneratedReportsByChildIDWithDateFilter 获取指定儿童的报告，支持日期筛选
+// GetGeneratedReportsByChildIDWithDateFilter 获取指定儿童的报告原文，支持日期筛选，译文通过原文获取
 func (dao *GeneratedReportDAO) GetGeneratedReportsByChildIDWithDateFilter(childArchiveID string, startDate, endDate *time.Time) ([]model.GeneratedReport, error) {
 	var reports []model.GeneratedReport
-	query := dao.db.Where("child_archive_id = ?", childArchiveID)
-	
+	query := dao.db.Where("child_archive_id = ? AND source_report_id IS NULL", childArchiveID)
+
 	// 添加日期筛选条件
 	if startDate != nil {
 		query = query.Where("created_at >= ?", *startDate)
@@ -58,7 +152,7 @@ func (dao *GeneratedReportDAO) GetGeneratedReportsByChildIDWithDateFilter(childA
 	if endDate != nil {
 		query = query.Where("created_at <= ?", *endDate)
 	}
-	
+
 	err := query.Order("created_at desc").Find(&reports).Error
 	return reports, err
-}
\ No newline at end of file
+}
@@ -23,14 +23,14 @@ func (dao *HealingLogDAO) CreateHealingLog(log *model.HealingLog) error {
 // GetHealingLogsByChildID 获取指定儿童的所有疗愈日志
 func (dao *HealingLogDAO) GetHealingLogsByChildID(childID uint) ([]model.HealingLog, error) {
 	var logs []model.HealingLog
-	err := dao.db.Preload("Media").Where("child_archive_id = ?", childID).Order("created_at desc").Find(&logs).Error
+	err := dao.db.Preload("Media").Preload("Metrics").Where("child_archive_id = ?", childID).Order("created_at desc").Find(&logs).Error
 	return logs, err
 }
 
 // GetHealingLogByID 获取单个疗愈日志详情
 func (dao *HealingLogDAO) GetHealingLogByID(logID uint) (*model.HealingLog, error) {
 	var log model.HealingLog
-	err := dao.db.Preload("Media").First(&log, logID).Error
+	err := dao.db.Preload("Media").Preload("Metrics").First(&log, logID).Error
 	return &log, err
 }
 
@@ -42,6 +42,10 @@ func (dao *HealingLogDAO) DeleteHealingLog(logID uint) error {
 		tx.Rollback()
 		return err
 	}
+	if err := tx.Where("healing_log_id = ?", logID).Delete(&model.LogMetric{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
 	if err := tx.Delete(&model.HealingLog{}, logID).Error; err != nil {
 		tx.Rollback()
 		return err
@@ -52,7 +56,7 @@ func (dao *HealingLogDAO) DeleteHealingLog(logID uint) error {
 // GetHealingLogsByChildIDWithDateFilter 获取指定儿童的疗愈日志，支持日期筛选
 func (dao *HealingLogDAO) GetHealingLogsByChildIDWithDateFilter(childID uint, startDate, endDate *time.Time) ([]model.HealingLog, error) {
 	var logs []model.HealingLog
-	query := dao.db.Preload("Media").Where("child_archive_id = ?", childID)
+	query := dao.db.Preload("Media").Preload("Metrics").Where("child_archive_id = ?", childID)
 	
 	// 添加日期筛选条件
 	if startDate != nil {
@@ -64,4 +68,40 @@ func (dao *HealingLogDAO) GetHealingLogsByChildIDWithDateFilter(childID uint, st
 	
 	err := query.Order("created_at desc").Find(&logs).Error
 	return logs, err
-}
\ No newline at end of file
+}
+
+// GetLogTimesByChildID 获取指定儿童所有日志的记录时间，用于计算疗愈天数和连续记录天数
+func (dao *HealingLogDAO) GetLogTimesByChildID(childID uint, since *time.Time) ([]time.Time, error) {
+	var times []time.Time
+	query := dao.db.Model(&model.HealingLog{}).Where("child_archive_id = ?", childID)
+	if since != nil {
+		query = query.Where("created_at >= ?", *since)
+	}
+	err := query.Order("created_at asc").Pluck("created_at", &times).Error
+	return times, err
+}
+
+// UpdateHealingLogPhase 标记疗愈日志的对比阶段和技能
+func (dao *HealingLogDAO) UpdateHealingLogPhase(logID uint, phase, skill string) error {
+	return dao.db.Model(&model.HealingLog{}).Where("id = ?", logID).
+		Updates(map[string]interface{}{"phase": phase, "skill": skill}).Error
+}
+
+// UpdateHealingLogContent 更新疗愈日志内容
+func (dao *HealingLogDAO) UpdateHealingLogContent(logID uint, content string) error {
+	return dao.db.Model(&model.HealingLog{}).Where("id = ?", logID).Update("content", content).Error
+}
+
+// UpdateLogMediaPhase 标记日志中指定媒体的对比阶段和技能
+func (dao *HealingLogDAO) UpdateLogMediaPhase(logID uint, mediaIDs []uint, phase, skill string) error {
+	return dao.db.Model(&model.LogMedia{}).Where("healing_log_id = ? AND id IN ?", logID, mediaIDs).
+		Updates(map[string]interface{}{"phase": phase, "skill": skill}).Error
+}
+
+// GetMetricsByChildIDAndName 获取儿童某项指标的全部记录，按时间升序
+func (dao *HealingLogDAO) GetMetricsByChildIDAndName(childID uint, name string) ([]model.LogMetric, error) {
+	var metrics []model.LogMetric
+	err := dao.db.Where("child_archive_id = ? AND name = ?", childID, name).
+		Order("recorded_at asc").Find(&metrics).Error
+	return metrics, err
+}
@@ -0,0 +1,22 @@
+package DAO
+
+import (
+	"melody_cure/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ImageTokenDAO struct {
+	db *gorm.DB
+}
+
+func NewImageTokenDAO(db *gorm.DB) *ImageTokenDAO {
+	return &ImageTokenDAO{db: db}
+}
+
+// DeleteExpiredImageTokens 物理删除在指定时间前过期的图床token，返回删除数量
+func (dao *ImageTokenDAO) DeleteExpiredImageTokens(before time.Time) (int64, error) {
+	result := dao.db.Unscoped().Where("expires_at < ?", before).Delete(&model.ImageToken{})
+	return result.RowsAffected, result.Error
+}
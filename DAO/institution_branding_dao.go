@@ -0,0 +1,27 @@
+package DAO
+
+import (
+	"melody_cure/model"
+
+	"gorm.io/gorm"
+)
+
+type InstitutionBrandingDAO struct {
+	db *gorm.DB
+}
+
+func NewInstitutionBrandingDAO(db *gorm.DB) *InstitutionBrandingDAO {
+	return &InstitutionBrandingDAO{db: db}
+}
+
+// GetBrandingByInstitutionID 获取机构的信头设置
+func (dao *InstitutionBrandingDAO) GetBrandingByInstitutionID(institutionID string) (*model.InstitutionBranding, error) {
+	var branding model.InstitutionBranding
+	err := dao.db.Where("institution_id = ?", institutionID).First(&branding).Error
+	return &branding, err
+}
+
+// SaveBranding 创建或更新机构的信头设置
+func (dao *InstitutionBrandingDAO) SaveBranding(branding *model.InstitutionBranding) error {
+	return dao.db.Save(branding).Error
+}
@@ -0,0 +1,93 @@
+package DAO
+
+import (
+	"melody_cure/model"
+
+	"gorm.io/gorm"
+)
+
+type LogTemplateDAO struct {
+	db *gorm.DB
+}
+
+func NewLogTemplateDAO(db *gorm.DB) *LogTemplateDAO {
+	return &LogTemplateDAO{db: db}
+}
+
+// CreateLogTemplate 创建日志模板
+func (dao *LogTemplateDAO) CreateLogTemplate(template *model.LogTemplate) error {
+	return dao.db.Create(template).Error
+}
+
+// GetLogTemplateByID 获取日志模板详情
+func (dao *LogTemplateDAO) GetLogTemplateByID(templateID uint) (*model.LogTemplate, error) {
+	var template model.LogTemplate
+	err := dao.db.First(&template, templateID).Error
+	return &template, err
+}
+
+// UpdateLogTemplate 更新日志模板
+func (dao *LogTemplateDAO) UpdateLogTemplate(template *model.LogTemplate) error {
+	return dao.db.Save(template).Error
+}
+
+// DeleteLogTemplate 删除日志模板及其分配记录
+func (dao *LogTemplateDAO) DeleteLogTemplate(templateID uint) error {
+	tx := dao.db.Begin()
+	if err := tx.Where("template_id = ?", templateID).Delete(&model.ChildLogTemplate{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Delete(&model.LogTemplate{}, templateID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// ListLogTemplates 获取模板库，diagnosis 不为空时只返回匹配该诊断关键词或通用的模板
+func (dao *LogTemplateDAO) ListLogTemplates(diagnosis string, onlyActive bool) ([]model.LogTemplate, error) {
+	var templates []model.LogTemplate
+	query := dao.db.Model(&model.LogTemplate{})
+	if onlyActive {
+		query = query.Where("is_active = ?", true)
+	}
+	if diagnosis != "" {
+		query = query.Where("diagnosis = '' OR diagnosis LIKE ?", "%"+diagnosis+"%")
+	}
+	err := query.Order("created_at desc").Find(&templates).Error
+	return templates, err
+}
+
+// GetActiveDiagnosisTemplates 获取所有设置了诊断关键词的启用模板
+func (dao *LogTemplateDAO) GetActiveDiagnosisTemplates() ([]model.LogTemplate, error) {
+	var templates []model.LogTemplate
+	err := dao.db.Where("is_active = ? AND diagnosis <> ''", true).Find(&templates).Error
+	return templates, err
+}
+
+// AssignTemplate 为儿童分配日志模板
+func (dao *LogTemplateDAO) AssignTemplate(assignment *model.ChildLogTemplate) error {
+	return dao.db.Create(assignment).Error
+}
+
+// GetAssignment 获取儿童与模板的分配记录
+func (dao *LogTemplateDAO) GetAssignment(childArchiveID string, templateID uint) (*model.ChildLogTemplate, error) {
+	var assignment model.ChildLogTemplate
+	err := dao.db.Where("child_archive_id = ? AND template_id = ?", childArchiveID, templateID).First(&assignment).Error
+	return &assignment, err
+}
+
+// GetAssignmentsByChildID 获取儿童手动分配的模板
+func (dao *LogTemplateDAO) GetAssignmentsByChildID(childArchiveID string) ([]model.ChildLogTemplate, error) {
+	var assignments []model.ChildLogTemplate
+	err := dao.db.Preload("Template").Where("child_archive_id = ?", childArchiveID).
+		Order("created_at desc").Find(&assignments).Error
+	return assignments, err
+}
+
+// DeleteAssignment 取消儿童的模板分配
+func (dao *LogTemplateDAO) DeleteAssignment(childArchiveID string, templateID uint) error {
+	return dao.db.Where("child_archive_id = ? AND template_id = ?", childArchiveID, templateID).
+		Delete(&model.ChildLogTemplate{}).Error
+}
@@ -0,0 +1,62 @@
+package DAO
+
+import (
+	"melody_cure/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationDAO struct {
+	db *gorm.DB
+}
+
+func NewNotificationDAO(db *gorm.DB) *NotificationDAO {
+	return &NotificationDAO{db: db}
+}
+
+// CreateNotification 创建通知
+func (dao *NotificationDAO) CreateNotification(notification *model.Notification) error {
+	return dao.db.Create(notification).Error
+}
+
+// GetNotificationsByUserID 获取用户的通知列表
+func (dao *NotificationDAO) GetNotificationsByUserID(userID string, unreadOnly bool) ([]model.Notification, error) {
+	var notifications []model.Notification
+	query := dao.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	err := query.Order("created_at desc").Find(&notifications).Error
+	return notifications, err
+}
+
+// CountUnread 统计用户未读通知数量
+func (dao *NotificationDAO) CountUnread(userID string) (int64, error) {
+	var count int64
+	err := dao.db.Model(&model.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).Count(&count).Error
+	return count, err
+}
+
+// MarkAsRead 将用户的某条通知标记为已读
+func (dao *NotificationDAO) MarkAsRead(userID string, notificationID uint) error {
+	now := time.Now()
+	return dao.db.Model(&model.Notification{}).Where("id = ? AND user_id = ?", notificationID, userID).
+		Updates(map[string]interface{}{"is_read": true, "read_at": now}).Error
+}
+
+// MarkAllAsRead 将用户的全部通知标记为已读
+func (dao *NotificationDAO) MarkAllAsRead(userID string) error {
+	now := time.Now()
+	return dao.db.Model(&model.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]interface{}{"is_read": true, "read_at": now}).Error
+}
+
+// HasNotificationSince 判断指定时间之后是否已给用户发送过同类型、同关联资源的通知
+func (dao *NotificationDAO) HasNotificationSince(userID, notificationType, relatedID string, since time.Time) (bool, error) {
+	var count int64
+	err := dao.db.Model(&model.Notification{}).
+		Where("user_id = ? AND type = ? AND related_id = ? AND created_at >= ?", userID, notificationType, relatedID, since).
+		Count(&count).Error
+	return count > 0, err
+}
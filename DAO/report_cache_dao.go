@@ -0,0 +1,37 @@
+package DAO
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const windowSummaryPrefix = "report:window_summary:"
+
+// ReportCacheDAO 报告生成过程中的中间结果缓存
+type ReportCacheDAO struct {
+	rdb *redis.Client
+}
+
+func NewReportCacheDAO(rdb *redis.Client) *ReportCacheDAO {
+	return &ReportCacheDAO{rdb: rdb}
+}
+
+// GetWindowSummary 获取缓存的分段摘要
+func (dao *ReportCacheDAO) GetWindowSummary(ctx context.Context, key string) (string, bool, error) {
+	summary, err := dao.rdb.Get(ctx, windowSummaryPrefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return summary, true, nil
+}
+
+// SetWindowSummary 缓存分段摘要
+func (dao *ReportCacheDAO) SetWindowSummary(ctx context.Context, key, summary string, ttl time.Duration) error {
+	return dao.rdb.Set(ctx, windowSummaryPrefix+key, summary, ttl).Err()
+}
@@ -0,0 +1,75 @@
+package DAO
+
+import (
+	"errors"
+	"melody_cure/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReportFeedbackDAO struct {
+	db *gorm.DB
+}
+
+func NewReportFeedbackDAO(db *gorm.DB) *ReportFeedbackDAO {
+	return &ReportFeedbackDAO{db: db}
+}
+
+// ReportFeedbackFilter 评价汇总的筛选条件，为空的条件不限制
+type ReportFeedbackFilter struct {
+	ReportType string
+	Model      string
+	Start      *time.Time
+	End        *time.Time
+}
+
+// SaveReportFeedback 保存康复师对报告的评价，已评价过时更新原评价
+func (dao *ReportFeedbackDAO) SaveReportFeedback(feedback *model.ReportFeedback) error {
+	var existing model.ReportFeedback
+	err := dao.db.Where("report_id = ? AND reviewer_id = ?", feedback.ReportID, feedback.ReviewerID).First(&existing).Error
+	if err == nil {
+		feedback.ID, feedback.CreatedAt = existing.ID, existing.CreatedAt
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return dao.db.Save(feedback).Error
+}
+
+// GetFeedbackByReportID 获取报告的全部评价
+func (dao *ReportFeedbackDAO) GetFeedbackByReportID(reportID uint) ([]model.ReportFeedback, error) {
+	var feedback []model.ReportFeedback
+	err := dao.db.Where("report_id = ?", reportID).Order("updated_at desc").Find(&feedback).Error
+	return feedback, err
+}
+
+// SummarizeFeedback 按报告类型、提示词模板版本和模型汇总评价
+func (dao *ReportFeedbackDAO) SummarizeFeedback(filter ReportFeedbackFilter) ([]model.ReportFeedbackStats, error) {
+	query := dao.db.Model(&model.ReportFeedback{})
+	if filter.ReportType != "" {
+		query = query.Where("report_type = ?", filter.ReportType)
+	}
+	if filter.Model != "" {
+		query = query.Where("model = ?", filter.Model)
+	}
+	if filter.Start != nil {
+		query = query.Where("created_at >= ?", *filter.Start)
+	}
+	if filter.End != nil {
+		query = query.Where("created_at < ?", *filter.End)
+	}
+
+	var stats []model.ReportFeedbackStats
+	err := query.
+		Select("report_type, template_id, template_version, provider, model, " +
+			"COUNT(*) AS reviews, COUNT(DISTINCT report_id) AS reports, " +
+			"SUM(CASE WHEN report_edited THEN 1 ELSE 0 END) AS edited_reviews, " +
+			"AVG(accuracy) AS avg_accuracy, AVG(usefulness) AS avg_usefulness, AVG(safety) AS avg_safety, " +
+			"SUM(hallucination_count) AS hallucination_flags, " +
+			"SUM(CASE WHEN hallucination_count > 0 THEN 1 ELSE 0 END) AS hallucinated_reviews, " +
+			"SUM(suggestion_count) AS suggestions, SUM(adopted_count) AS adopted_suggestions").
+		Group("report_type, template_id, template_version, provider, model").
+		Order("report_type, template_version DESC, model").
+		Scan(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,133 @@
+package DAO
+
+import (
+	"context"
+	"fmt"
+	"melody_cure/model"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	reportJobQueueKey     = "report_jobs:queue"
+	reportJobCancelPrefix = "report_jobs:cancel:"
+)
+
+// ReportJobDAO 报告生成任务，状态保存在数据库，待执行队列保存在 Redis 有序集合中（分数为可执行时间）
+type ReportJobDAO struct {
+	db  *gorm.DB
+	rdb *redis.Client
+}
+
+func NewReportJobDAO(db *gorm.DB, rdb *redis.Client) *ReportJobDAO {
+	return &ReportJobDAO{db: db, rdb: rdb}
+}
+
+// CreateReportJob 创建任务
+func (dao *ReportJobDAO) CreateReportJob(job *model.ReportJob) error {
+	return dao.db.Create(job).Error
+}
+
+// GetReportJobByID 获取任务
+func (dao *ReportJobDAO) GetReportJobByID(jobID uint) (*model.ReportJob, error) {
+	var job model.ReportJob
+	err := dao.db.First(&job, jobID).Error
+	return &job, err
+}
+
+// GetReportJobsByChildID 获取儿童最近的报告任务
+func (dao *ReportJobDAO) GetReportJobsByChildID(childArchiveID string, limit int) ([]model.ReportJob, error) {
+	var jobs []model.ReportJob
+	err := dao.db.Where("child_archive_id = ?", childArchiveID).Order("created_at desc").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+// TransitionReportJob 仅当任务处于 fromStatus 时更新，返回是否更新成功，用于避免并发覆盖状态
+func (dao *ReportJobDAO) TransitionReportJob(jobID uint, fromStatus string, updates map[string]interface{}) (bool, error) {
+	result := dao.db.Model(&model.ReportJob{}).Where("id = ? AND status = ?", jobID, fromStatus).Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// TouchReportJob 更新执行中任务的心跳
+func (dao *ReportJobDAO) TouchReportJob(jobID uint) error {
+	return dao.db.Model(&model.ReportJob{}).Where("id = ? AND status = ?", jobID, model.ReportJobRunning).
+		Update("heartbeat_at", time.Now()).Error
+}
+
+// GetStaleRunningReportJobs 获取心跳超时的执行中任务
+func (dao *ReportJobDAO) GetStaleRunningReportJobs(before time.Time) ([]model.ReportJob, error) {
+	var jobs []model.ReportJob
+	err := dao.db.Where("status = ? AND heartbeat_at < ?", model.ReportJobRunning, before).Find(&jobs).Error
+	return jobs, err
+}
+
+// GetOverduePendingReportJobs 获取执行时间早于 before 但仍在等待的任务
+func (dao *ReportJobDAO) GetOverduePendingReportJobs(before time.Time) ([]model.ReportJob, error) {
+	var jobs []model.ReportJob
+	err := dao.db.Where("status = ? AND next_run_at < ?", model.ReportJobPending, before).Find(&jobs).Error
+	return jobs, err
+}
+
+// EnqueueReportJob 将任务放入队列，readyAt 之后才会被取出
+func (dao *ReportJobDAO) EnqueueReportJob(ctx context.Context, jobID uint, readyAt time.Time) error {
+	return dao.rdb.ZAdd(ctx, reportJobQueueKey, redis.Z{
+		Score:  float64(readyAt.UnixMilli()),
+		Member: strconv.FormatUint(uint64(jobID), 10),
+	}).Err()
+}
+
+// RequeueReportJob 任务不在队列中时重新放入，已在队列中时不改变执行时间，返回是否重新放入
+func (dao *ReportJobDAO) RequeueReportJob(ctx context.Context, jobID uint, readyAt time.Time) (bool, error) {
+	added, err := dao.rdb.ZAddNX(ctx, reportJobQueueKey, redis.Z{
+		Score:  float64(readyAt.UnixMilli()),
+		Member: strconv.FormatUint(uint64(jobID), 10),
+	}).Result()
+	return added > 0, err
+}
+
+// ClaimDueReportJob 取出一个已到执行时间的任务，多个副本同时取时只有一个能成功。
+// 取出与更新任务状态不是原子操作，取出后副本退出的任务由 RequeueReportJob 重新放入
+func (dao *ReportJobDAO) ClaimDueReportJob(ctx context.Context, now time.Time) (uint, bool, error) {
+	members, err := dao.rdb.ZRangeByScore(ctx, reportJobQueueKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: 10,
+	}).Result()
+	if err != nil {
+		return 0, false, err
+	}
+	for _, member := range members {
+		removed, err := dao.rdb.ZRem(ctx, reportJobQueueKey, member).Result()
+		if err != nil {
+			return 0, false, err
+		}
+		if removed == 0 {
+			continue
+		}
+		jobID, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("队列中的任务ID无效: %s", member)
+		}
+		return uint(jobID), true, nil
+	}
+	return 0, false, nil
+}
+
+// RemoveQueuedReportJob 从队列中移除任务
+func (dao *ReportJobDAO) RemoveQueuedReportJob(ctx context.Context, jobID uint) error {
+	return dao.rdb.ZRem(ctx, reportJobQueueKey, strconv.FormatUint(uint64(jobID), 10)).Err()
+}
+
+// RequestReportJobCancel 标记执行中的任务需要取消，由执行该任务的副本轮询
+func (dao *ReportJobDAO) RequestReportJobCancel(ctx context.Context, jobID uint) error {
+	return dao.rdb.Set(ctx, reportJobCancelPrefix+strconv.FormatUint(uint64(jobID), 10), 1, time.Hour).Err()
+}
+
+// IsReportJobCancelRequested 判断任务是否已被请求取消
+func (dao *ReportJobDAO) IsReportJobCancelRequested(ctx context.Context, jobID uint) (bool, error) {
+	count, err := dao.rdb.Exists(ctx, reportJobCancelPrefix+strconv.FormatUint(uint64(jobID), 10)).Result()
+	return count > 0, err
+}
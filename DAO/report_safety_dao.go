@@ -0,0 +1,86 @@
+package DAO
+
+import (
+	"melody_cure/model"
+
+	"gorm.io/gorm"
+)
+
+type ReportSafetyDAO struct {
+	db *gorm.DB
+}
+
+func NewReportSafetyDAO(db *gorm.DB) *ReportSafetyDAO {
+	return &ReportSafetyDAO{db: db}
+}
+
+// SafetyReviewFilter 审核队列的查询条件，AllInstitutions 为 false 时只查询 InstitutionID 对应机构的报告
+type SafetyReviewFilter struct {
+	AllInstitutions bool
+	InstitutionID   string
+	Status          string
+	ChildArchiveID  string
+	Limit           int
+}
+
+// CreateSafetyReview 创建待审核报告
+func (dao *ReportSafetyDAO) CreateSafetyReview(review *model.ReportSafetyReview) error {
+	return dao.db.Create(review).Error
+}
+
+// GetSafetyReviewByID 获取审核记录
+func (dao *ReportSafetyDAO) GetSafetyReviewByID(id uint) (*model.ReportSafetyReview, error) {
+	var review model.ReportSafetyReview
+	err := dao.db.First(&review, id).Error
+	return &review, err
+}
+
+// ListSafetyReviews 获取审核队列，待审核的按提交时间先后排列，其余按时间倒序
+func (dao *ReportSafetyDAO) ListSafetyReviews(filter SafetyReviewFilter) ([]model.ReportSafetyReview, error) {
+	var reviews []model.ReportSafetyReview
+	query := dao.db.Omit("structured_content")
+	if !filter.AllInstitutions {
+		query = query.Where("institution_id = ?", filter.InstitutionID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ChildArchiveID != "" {
+		query = query.Where("child_archive_id = ?", filter.ChildArchiveID)
+	}
+	if filter.Status == model.SafetyReviewPending {
+		query = query.Order("created_at asc")
+	} else {
+		query = query.Order("created_at desc")
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	err := query.Find(&reviews).Error
+	return reviews, err
+}
+
+// TransitionSafetyReview 仅当审核记录处于待审核状态时更新，返回是否更新成功
+func (dao *ReportSafetyDAO) TransitionSafetyReview(id uint, updates map[string]interface{}) (bool, error) {
+	result := dao.db.Model(&model.ReportSafetyReview{}).
+		Where("id = ? AND status = ?", id, model.SafetyReviewPending).Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ApproveSafetyReview 审核通过：更新审核状态并保存正式报告，审核记录已被处理时不保存报告
+func (dao *ReportSafetyDAO) ApproveSafetyReview(id uint, updates map[string]interface{}, report *model.GeneratedReport) (bool, error) {
+	approved := false
+	err := dao.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.ReportSafetyReview{}).
+			Where("id = ? AND status = ?", id, model.SafetyReviewPending).Updates(updates)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := createGeneratedReport(tx, report); err != nil {
+			return err
+		}
+		approved = true
+		return tx.Model(&model.ReportSafetyReview{}).Where("id = ?", id).Update("report_id", report.ID).Error
+	})
+	return approved && err == nil, err
+}
@@ -0,0 +1,123 @@
+package DAO
+
+import (
+	"melody_cure/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReportTypeDAO struct {
+	db *gorm.DB
+}
+
+func NewReportTypeDAO(db *gorm.DB) *ReportTypeDAO {
+	return &ReportTypeDAO{db: db}
+}
+
+// CountReportTypes 统计报告类型数量
+func (dao *ReportTypeDAO) CountReportTypes() (int64, error) {
+	var count int64
+	err := dao.db.Model(&model.ReportType{}).Count(&count).Error
+	return count, err
+}
+
+// CreateReportType 创建报告类型及其第一个模板版本，并启用该版本
+func (dao *ReportTypeDAO) CreateReportType(reportType *model.ReportType, tpl *model.PromptTemplate) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		reportType.ActiveVersion = 1
+		if err := tx.Create(reportType).Error; err != nil {
+			return err
+		}
+		tpl.ReportTypeID = reportType.ID
+		tpl.Version = 1
+		return tx.Create(tpl).Error
+	})
+}
+
+// GetReportTypeByKey 根据标识获取报告类型
+func (dao *ReportTypeDAO) GetReportTypeByKey(key string) (*model.ReportType, error) {
+	var reportType model.ReportType
+	err := dao.db.Where("`key` = ?", key).First(&reportType).Error
+	return &reportType, err
+}
+
+// ListReportTypes 获取报告类型列表
+func (dao *ReportTypeDAO) ListReportTypes(onlyEnabled bool) ([]model.ReportType, error) {
+	var reportTypes []model.ReportType
+	query := dao.db.Model(&model.ReportType{})
+	if onlyEnabled {
+		query = query.Where("enabled = ?", true)
+	}
+	err := query.Order("id asc").Find(&reportTypes).Error
+	return reportTypes, err
+}
+
+// UpdateReportType 更新报告类型的基本信息
+func (dao *ReportTypeDAO) UpdateReportType(reportTypeID uint, updates map[string]interface{}) error {
+	return dao.db.Model(&model.ReportType{}).Where("id = ?", reportTypeID).Updates(updates).Error
+}
+
+// CreatePromptTemplate 新增模板版本，版本号在报告类型行锁内递增，activate 为 true 时同时启用该版本
+func (dao *ReportTypeDAO) CreatePromptTemplate(tpl *model.PromptTemplate, activate bool) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		var reportType model.ReportType
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reportType, tpl.ReportTypeID).Error; err != nil {
+			return err
+		}
+		var latest int
+		if err := tx.Model(&model.PromptTemplate{}).Where("report_type_id = ?", reportType.ID).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+
+		tpl.Version = latest + 1
+		if err := tx.Create(tpl).Error; err != nil {
+			return err
+		}
+		if !activate {
+			return nil
+		}
+		return activatePromptTemplate(tx, &reportType, tpl)
+	})
+}
+
+// ActivatePromptTemplate 启用模板版本：默认语言的模板更新当前版本，其他语言的模板更新该语言的当前版本
+func (dao *ReportTypeDAO) ActivatePromptTemplate(tpl *model.PromptTemplate) (*model.ReportType, error) {
+	var reportType model.ReportType
+	err := dao.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reportType, tpl.ReportTypeID).Error; err != nil {
+			return err
+		}
+		return activatePromptTemplate(tx, &reportType, tpl)
+	})
+	return &reportType, err
+}
+
+func activatePromptTemplate(tx *gorm.DB, reportType *model.ReportType, tpl *model.PromptTemplate) error {
+	if tpl.Language == "" || tpl.Language == model.DefaultLanguage {
+		reportType.ActiveVersion = tpl.Version
+		return tx.Model(reportType).Update("active_version", tpl.Version).Error
+	}
+	versions := make(map[string]int, len(reportType.LocalizedVersions)+1)
+	for language, version := range reportType.LocalizedVersions {
+		versions[language] = version
+	}
+	versions[tpl.Language] = tpl.Version
+	reportType.LocalizedVersions = versions
+	return tx.Model(reportType).Select("localized_versions").Updates(&model.ReportType{LocalizedVersions: versions}).Error
+}
+
+// GetPromptTemplate 获取报告类型的指定模板版本
+func (dao *ReportTypeDAO) GetPromptTemplate(reportTypeID uint, version int) (*model.PromptTemplate, error) {
+	var tpl model.PromptTemplate
+	err := dao.db.Where("report_type_id = ? AND version = ?", reportTypeID, version).First(&tpl).Error
+	return &tpl, err
+}
+
+// GetPromptTemplates 获取报告类型的所有模板版本，按版本号倒序
+func (dao *ReportTypeDAO) GetPromptTemplates(reportTypeID uint) ([]model.PromptTemplate, error) {
+	var templates []model.PromptTemplate
+	err := dao.db.Where("report_type_id = ?", reportTypeID).Order("version desc").Find(&templates).Error
+	return templates, err
+}
@@ -0,0 +1,61 @@
+package DAO
+
+import (
+	"melody_cure/model"
+
+	"gorm.io/gorm"
+)
+
+type SemanticIndexDAO struct {
+	db *gorm.DB
+}
+
+func NewSemanticIndexDAO(db *gorm.DB) *SemanticIndexDAO {
+	return &SemanticIndexDAO{db: db}
+}
+
+// SemanticSourceHash 已索引资料的内容哈希和向量化提供商
+type SemanticSourceHash struct {
+	SourceID    uint
+	ContentHash string
+	Provider    string
+}
+
+// ReplaceSource 在一个事务中删除资料已有的分段并保存新的分段，chunks 为空时只删除
+func (dao *SemanticIndexDAO) ReplaceSource(sourceType string, sourceID uint, chunks []model.SemanticChunk) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source_type = ? AND source_id = ?", sourceType, sourceID).Delete(&model.SemanticChunk{}).Error; err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+		return tx.Create(&chunks).Error
+	})
+}
+
+// DeleteSource 删除资料的所有分段
+func (dao *SemanticIndexDAO) DeleteSource(sourceType string, sourceID uint) error {
+	return dao.db.Where("source_type = ? AND source_id = ?", sourceType, sourceID).Delete(&model.SemanticChunk{}).Error
+}
+
+// GetSourceHashes 获取儿童某类已索引资料的内容哈希
+func (dao *SemanticIndexDAO) GetSourceHashes(childArchiveID, sourceType string) ([]SemanticSourceHash, error) {
+	var hashes []SemanticSourceHash
+	err := dao.db.Model(&model.SemanticChunk{}).
+		Where("child_archive_id = ? AND source_type = ? AND chunk_index = 0", childArchiveID, sourceType).
+		Select("source_id, content_hash, provider").Scan(&hashes).Error
+	return hashes, err
+}
+
+// GetChunks 获取儿童某类资料最近的分段，按时间从新到旧，limit 为 0 时不限
+func (dao *SemanticIndexDAO) GetChunks(childArchiveID, sourceType string, limit int) ([]model.SemanticChunk, error) {
+	query := dao.db.Where("child_archive_id = ? AND source_type = ?", childArchiveID, sourceType).
+		Order("source_time desc, id desc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var chunks []model.SemanticChunk
+	err := query.Find(&chunks).Error
+	return chunks, err
+}
@@ -0,0 +1,82 @@
+package DAO
+
+import (
+	"melody_cure/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ShareLinkDAO struct {
+	db *gorm.DB
+}
+
+func NewShareLinkDAO(db *gorm.DB) *ShareLinkDAO {
+	return &ShareLinkDAO{db: db}
+}
+
+// CreateShareLink 创建分享链接
+func (dao *ShareLinkDAO) CreateShareLink(link *model.ShareLink) error {
+	return dao.db.Create(link).Error
+}
+
+// GetShareLinkByID 获取分享链接
+func (dao *ShareLinkDAO) GetShareLinkByID(id uint) (*model.ShareLink, error) {
+	var link model.ShareLink
+	err := dao.db.First(&link, id).Error
+	return &link, err
+}
+
+// GetShareLinkByPublicID 按链接令牌中的随机部分获取分享链接
+func (dao *ShareLinkDAO) GetShareLinkByPublicID(publicID string) (*model.ShareLink, error) {
+	var link model.ShareLink
+	err := dao.db.Where("public_id = ?", publicID).First(&link).Error
+	return &link, err
+}
+
+// GetShareLinksByCreator 获取用户创建的分享链接，activeAt 不为空时只返回该时间仍有效的链接，不加载快照
+func (dao *ShareLinkDAO) GetShareLinksByCreator(userID, childArchiveID string, activeAt *time.Time) ([]model.ShareLink, error) {
+	var links []model.ShareLink
+	query := dao.db.Omit("snapshot").Where("created_by = ?", userID)
+	if childArchiveID != "" {
+		query = query.Where("child_archive_id = ?", childArchiveID)
+	}
+	if activeAt != nil {
+		query = query.Where("revoked_at IS NULL AND expires_at > ?", *activeAt)
+	}
+	err := query.Order("created_at desc").Find(&links).Error
+	return links, err
+}
+
+// RevokeShareLink 撤销分享链接，已撤销的保留原撤销时间
+func (dao *ShareLinkDAO) RevokeShareLink(id uint, revokedAt time.Time) error {
+	return dao.db.Model(&model.ShareLink{}).Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt).Error
+}
+
+// RecordShareView 增加查看次数
+func (dao *ShareLinkDAO) RecordShareView(id uint, viewedAt time.Time) error {
+	return dao.db.Model(&model.ShareLink{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"view_count": gorm.Expr("view_count + 1"), "last_viewed_at": viewedAt}).Error
+}
+
+// CreateShareAccessLog 记录一次访问
+func (dao *ShareLinkDAO) CreateShareAccessLog(entry *model.ShareAccessLog) error {
+	return dao.db.Create(entry).Error
+}
+
+// GetShareAccessLogs 获取分享链接的访问记录，按时间倒序
+func (dao *ShareLinkDAO) GetShareAccessLogs(shareLinkID uint, limit int) ([]model.ShareAccessLog, error) {
+	var logs []model.ShareAccessLog
+	err := dao.db.Where("share_link_id = ?", shareLinkID).Order("created_at desc").Limit(limit).Find(&logs).Error
+	return logs, err
+}
+
+// CountShareAccess 统计分享链接自指定时间起某种结果的访问次数
+func (dao *ShareLinkDAO) CountShareAccess(shareLinkID uint, outcome string, since time.Time) (int64, error) {
+	var count int64
+	err := dao.db.Model(&model.ShareAccessLog{}).
+		Where("share_link_id = ? AND outcome = ? AND created_at >= ?", shareLinkID, outcome, since).
+		Count(&count).Error
+	return count, err
+}
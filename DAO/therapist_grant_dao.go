@@ -0,0 +1,58 @@
+package DAO
+
+import (
+	"melody_cure/model"
+)
+
+// 康复师授权的读写放在 UserDAO 上，与儿童档案的权限校验共用
+
+// CreateTherapistGrant 保存授权
+func (dao *UserDAO) CreateTherapistGrant(grant *model.ChildTherapistGrant) error {
+	return dao.db.Create(grant).Error
+}
+
+// GetTherapistGrant 获取康复师对儿童的授权
+func (dao *UserDAO) GetTherapistGrant(childArchiveID, therapistUserID string) (*model.ChildTherapistGrant, error) {
+	var grant model.ChildTherapistGrant
+	err := dao.db.Where("child_archive_id = ? AND therapist_user_id = ?", childArchiveID, therapistUserID).First(&grant).Error
+	return &grant, err
+}
+
+func (dao *UserDAO) GetTherapistGrantByID(id uint) (*model.ChildTherapistGrant, error) {
+	var grant model.ChildTherapistGrant
+	err := dao.db.First(&grant, id).Error
+	return &grant, err
+}
+
+// ListChildTherapistGrants 获取儿童的全部授权
+func (dao *UserDAO) ListChildTherapistGrants(childArchiveID string) ([]model.ChildTherapistGrant, error) {
+	var grants []model.ChildTherapistGrant
+	err := dao.db.Where("child_archive_id = ?", childArchiveID).Order("created_at desc").Find(&grants).Error
+	return grants, err
+}
+
+// ListTherapistGrants 获取康复师获得的全部授权
+func (dao *UserDAO) ListTherapistGrants(therapistUserID string) ([]model.ChildTherapistGrant, error) {
+	var grants []model.ChildTherapistGrant
+	err := dao.db.Where("therapist_user_id = ?", therapistUserID).Order("created_at desc").Find(&grants).Error
+	return grants, err
+}
+
+func (dao *UserDAO) DeleteTherapistGrant(id uint) error {
+	return dao.db.Delete(&model.ChildTherapistGrant{}, id).Error
+}
+
+// HasTherapistAccess 康复师是否获得儿童的授权，或正在接手该儿童的咨询转介
+func (dao *UserDAO) HasTherapistAccess(childArchiveID, therapistUserID string) (bool, error) {
+	var count int64
+	err := dao.db.Model(&model.ChildTherapistGrant{}).
+		Where("child_archive_id = ? AND therapist_user_id = ?", childArchiveID, therapistUserID).
+		Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+	err = dao.db.Model(&model.ConsultationEscalation{}).
+		Where("child_archive_id = ? AND therapist_user_id = ? AND status = ?", childArchiveID, therapistUserID, model.EscalationAccepted).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,136 @@
+package DAO
+
+import (
+	"melody_cure/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TreatmentPlanDAO struct {
+	db *gorm.DB
+}
+
+func NewTreatmentPlanDAO(db *gorm.DB) *TreatmentPlanDAO {
+	return &TreatmentPlanDAO{db: db}
+}
+
+// CreateTreatmentPlan 创建治疗方案（连同目标）
+func (dao *TreatmentPlanDAO) CreateTreatmentPlan(plan *model.TreatmentPlan) error {
+	return dao.db.Create(plan).Error
+}
+
+// GetTreatmentPlanByID 获取治疗方案详情
+func (dao *TreatmentPlanDAO) GetTreatmentPlanByID(planID uint) (*model.TreatmentPlan, error) {
+	var plan model.TreatmentPlan
+	err := dao.db.Preload("Goals").First(&plan, planID).Error
+	return &plan, err
+}
+
+// GetTreatmentPlansByChildID 获取儿童的所有治疗方案
+func (dao *TreatmentPlanDAO) GetTreatmentPlansByChildID(childArchiveID string) ([]model.TreatmentPlan, error) {
+	var plans []model.TreatmentPlan
+	err := dao.db.Preload("Goals").Where("child_archive_id = ?", childArchiveID).
+		Order("created_at desc").Find(&plans).Error
+	return plans, err
+}
+
+// UpdateTreatmentPlan 更新治疗方案基本信息
+func (dao *TreatmentPlanDAO) UpdateTreatmentPlan(plan *model.TreatmentPlan) error {
+	return dao.db.Omit("Goals").Save(plan).Error
+}
+
+// DeleteTreatmentPlan 删除治疗方案及其目标和关联
+func (dao *TreatmentPlanDAO) DeleteTreatmentPlan(planID uint) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		var goalIDs []uint
+		if err := tx.Model(&model.TreatmentGoal{}).Where("plan_id = ?", planID).Pluck("id", &goalIDs).Error; err != nil {
+			return err
+		}
+		if len(goalIDs) > 0 {
+			if err := tx.Where("goal_id IN ?", goalIDs).Delete(&model.GoalLink{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("plan_id = ?", planID).Delete(&model.TreatmentGoal{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.TreatmentPlan{}, planID).Error
+	})
+}
+
+// CreateGoal 创建目标
+func (dao *TreatmentPlanDAO) CreateGoal(goal *model.TreatmentGoal) error {
+	return dao.db.Create(goal).Error
+}
+
+// GetGoalByID 获取目标详情
+func (dao *TreatmentPlanDAO) GetGoalByID(goalID uint) (*model.TreatmentGoal, error) {
+	var goal model.TreatmentGoal
+	err := dao.db.First(&goal, goalID).Error
+	return &goal, err
+}
+
+// GetGoalsByIDs 批量获取目标
+func (dao *TreatmentPlanDAO) GetGoalsByIDs(goalIDs []uint) ([]model.TreatmentGoal, error) {
+	var goals []model.TreatmentGoal
+	err := dao.db.Where("id IN ?", goalIDs).Find(&goals).Error
+	return goals, err
+}
+
+// GetGoalsByChildID 获取儿童所有方案中的目标
+func (dao *TreatmentPlanDAO) GetGoalsByChildID(childArchiveID string) ([]model.TreatmentGoal, error) {
+	var goals []model.TreatmentGoal
+	err := dao.db.Where("child_archive_id = ?", childArchiveID).Order("plan_id asc, id asc").Find(&goals).Error
+	return goals, err
+}
+
+// UpdateGoal 更新目标
+func (dao *TreatmentPlanDAO) UpdateGoal(goal *model.TreatmentGoal) error {
+	return dao.db.Save(goal).Error
+}
+
+// DeleteGoal 删除目标及其关联
+func (dao *TreatmentPlanDAO) DeleteGoal(goalID uint) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("goal_id = ?", goalID).Delete(&model.GoalLink{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.TreatmentGoal{}, goalID).Error
+	})
+}
+
+// CreateGoalLinks 批量关联目标，已存在的关联会被忽略
+func (dao *TreatmentPlanDAO) CreateGoalLinks(links []model.GoalLink) error {
+	if len(links) == 0 {
+		return nil
+	}
+	return dao.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// GetLinksByGoalID 获取目标的所有关联记录
+func (dao *TreatmentPlanDAO) GetLinksByGoalID(goalID uint) ([]model.GoalLink, error) {
+	var links []model.GoalLink
+	err := dao.db.Where("goal_id = ?", goalID).Order("created_at desc").Find(&links).Error
+	return links, err
+}
+
+// GetLinksByGoalIDs 批量获取多个目标的关联记录
+func (dao *TreatmentPlanDAO) GetLinksByGoalIDs(goalIDs []uint) ([]model.GoalLink, error) {
+	var links []model.GoalLink
+	if len(goalIDs) == 0 {
+		return links, nil
+	}
+	err := dao.db.Where("goal_id IN ?", goalIDs).Find(&links).Error
+	return links, err
+}
+
+// DeleteGoalLink 删除目标的某个关联
+func (dao *TreatmentPlanDAO) DeleteGoalLink(goalID, linkID uint) error {
+	return dao.db.Where("id = ? AND goal_id = ?", linkID, goalID).Delete(&model.GoalLink{}).Error
+}
+
+// DeleteLinksByTarget 删除某条记录的所有目标关联（日志或游戏记录被删除时）
+func (dao *TreatmentPlanDAO) DeleteLinksByTarget(targetType string, targetID uint) error {
+	return dao.db.Where("target_type = ? AND target_id = ?", targetType, targetID).Delete(&model.GoalLink{}).Error
+}
@@ -5,6 +5,14 @@ import (
 	"gorm.io/gorm"
 )
 
+// 用户身份类型
+const (
+	IdentityParent      = "parent"      // 家长
+	IdentityTherapist   = "therapist"   // 康复师
+	IdentityInstitution = "institution" // 机构
+	IdentityAdmin       = "admin"       // 管理员
+)
+
 type User struct {
 	ID            string         `gorm:"primaryKey" json:"id"`
 	Image         string         `json:"image"`
@@ -16,6 +24,7 @@ type User struct {
 	Address       string         `json:"address"`
 	Certificate   string         `json:"certificate"`
 	Certification bool           `json:"certification"`
+	InstitutionID string         `gorm:"index" json:"institution_id"` // 所属机构（身份为机构的用户ID），用于机构AI用量配额
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
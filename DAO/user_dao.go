@@ -1,6 +1,8 @@
 package DAO
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -29,6 +31,11 @@ func (dao *UserDAO) GetUserByID(id string) (*User, error) {
 	return &user, err
 }
 
+// UpdateUserInstitution 设置用户所属的机构
+func (dao *UserDAO) UpdateUserInstitution(userID, institutionID string) error {
+	return dao.db.Model(&User{}).Where("id = ?", userID).Update("institution_id", institutionID).Error
+}
+
 func (dao *UserDAO) UpdateUser(user *User) error {
 	return dao.db.Save(user).Error
 }
@@ -48,6 +55,33 @@ func (dao *UserDAO) UpdateCertification(cert *Certification) error {
 	return dao.db.Save(cert).Error
 }
 
+// GetApprovedCertificationsExpiringBefore 获取在指定时间前到期的已通过认证
+func (dao *UserDAO) GetApprovedCertificationsExpiringBefore(before time.Time) ([]Certification, error) {
+	var certs []Certification
+	err := dao.db.Where("status = ? AND expiry_date IS NOT NULL AND expiry_date < ?", "approved", before).Find(&certs).Error
+	return certs, err
+}
+
+// ExpireCertification 将认证标记为已过期，用户没有其他有效的已通过认证时取消其认证状态，返回是否取消了认证状态
+func (dao *UserDAO) ExpireCertification(cert *Certification) (bool, error) {
+	revoked := false
+	err := dao.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Certification{}).Where("id = ?", cert.ID).Update("status", "expired").Error; err != nil {
+			return err
+		}
+		var valid int64
+		err := tx.Model(&Certification{}).
+			Where("user_id = ? AND id <> ? AND status = ? AND (expiry_date IS NULL OR expiry_date >= ?)", cert.UserID, cert.ID, "approved", time.Now()).
+			Count(&valid).Error
+		if err != nil || valid > 0 {
+			return err
+		}
+		revoked = true
+		return tx.Model(&User{}).Where("id = ?", cert.UserID).Update("certification", false).Error
+	})
+	return revoked && err == nil, err
+}
+
 // AI陪伴相关操作
 func (dao *UserDAO) CreateAICompanion(companion *AICompanion) error {
 	return dao.db.Create(companion).Error
@@ -81,8 +115,26 @@ func (dao *UserDAO) GetChildArchivesByUserID(userID string) ([]ChildArchive, err
 	return archives, err
 }
 
+func (dao *UserDAO) GetChildArchiveByID(archiveID string) (*ChildArchive, error) {
+	var archive ChildArchive
+	err := dao.db.Where("id = ?", archiveID).First(&archive).Error
+	return &archive, err
+}
+
+// GetChildArchivesInTreatment 获取已开始治疗的儿童档案
+func (dao *UserDAO) GetChildArchivesInTreatment() ([]ChildArchive, error) {
+	var archives []ChildArchive
+	err := dao.db.Where("treatment_start_date IS NOT NULL AND treatment_start_date <= ?", time.Now()).Find(&archives).Error
+	return archives, err
+}
+
 func (dao *UserDAO) UpdateChildArchive(archive *ChildArchive) error {
-	return dao.db.Save(archive).Error
+	// 已疗愈天数由疗愈进度统计维护，不随档案编辑覆盖
+	return dao.db.Omit("healed_days", "created_at").Save(archive).Error
+}
+
+func (dao *UserDAO) UpdateChildHealedDays(archiveID string, healedDays int) error {
+	return dao.db.Model(&ChildArchive{}).Where("id = ?", archiveID).Update("healed_days", healedDays).Error
 }
 
 func (dao *UserDAO) DeleteChildArchive(archiveID string) error {
@@ -0,0 +1,14 @@
+package request
+
+// StartCompanionConversationRequest 开始与AI陪伴的对话
+type StartCompanionConversationRequest struct {
+	CompanionID    string `json:"companion_id" binding:"required" example:"c1a2b3"`
+	ChildArchiveID string `json:"child_archive_id" binding:"required" example:"1"`
+	Title          string `json:"title,omitempty" binding:"max=100" example:"睡前聊天"` // 为空时按陪伴名称和日期生成
+}
+
+// CompanionMessageRequest 儿童发送给AI陪伴的消息
+type CompanionMessageRequest struct {
+	Content   string `json:"content" binding:"required" example:"我今天在幼儿园搭了一个很高的积木"`
+	WithAudio bool   `json:"with_audio" example:"true"` // 是否以陪伴的语音类型合成回复语音
+}
@@ -0,0 +1,24 @@
+package request
+
+// StartConsultationRequest 就某个孩子开始与虚拟疗愈导师的咨询
+type StartConsultationRequest struct {
+	VirtualTherapistID string `json:"virtual_therapist_id" binding:"required" example:"t1a2b3"`
+	ChildArchiveID     string `json:"child_archive_id" binding:"required" example:"1"`
+	Title              string `json:"title,omitempty" binding:"max=100" example:"最近睡前情绪波动"` // 为空时按导师名称和日期生成
+}
+
+// ConsultationMessageRequest 咨询会话中的一条消息
+type ConsultationMessageRequest struct {
+	Content string `json:"content" binding:"required" example:"孩子这两周睡前总是哭闹，和训练强度有关系吗？"`
+}
+
+// EscalateConsultationRequest 把咨询转给认证康复师
+type EscalateConsultationRequest struct {
+	Reason          string `json:"reason" binding:"required,max=1000" example:"希望康复师评估是否需要调整训练计划"`
+	TherapistUserID string `json:"therapist_user_id,omitempty" example:"u123"` // 指定的认证康复师，为空时任一认证康复师都可接手
+}
+
+// CloseEscalationRequest 结束转介
+type CloseEscalationRequest struct {
+	Note string `json:"note,omitempty" binding:"max=1000" example:"已建议降低训练强度，两周后复评"`
+}
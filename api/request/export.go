@@ -0,0 +1,15 @@
+package request
+
+// UpdateBrandingRequest 更新机构导出文档信头请求
+type UpdateBrandingRequest struct {
+	InstitutionID string `json:"institution_id,omitempty" example:"inst-001"` // 仅管理员需要指定
+	DisplayName   string `json:"display_name" binding:"max=100" example:"星语儿童康复中心"`
+	Tagline       string `json:"tagline" binding:"max=200" example:"专注孤独症儿童早期干预"`
+	Address       string `json:"address" binding:"max=255" example:"北京市海淀区某某路1号"`
+	Phone         string `json:"phone" binding:"max=50" example:"010-12345678"`
+	Website       string `json:"website" binding:"max=255" example:"https://example.com"`
+	FooterText    string `json:"footer_text" binding:"max=255" example:"本报告仅供机构内部及家长使用"`
+	PrimaryColor  string `json:"primary_color" example:"#3A7BD5"`         // #RRGGBB，为空时使用平台主题色
+	Logo          string `json:"logo,omitempty" example:"iVBORw0KGgo..."` // base64 编码的 PNG 或 JPEG，不超过 512KB，为空时保留原徽标
+	RemoveLogo    bool   `json:"remove_logo,omitempty"`
+}
@@ -2,13 +2,58 @@ package request
 
 // GenerateReportRequest AI生成报告请求
 type GenerateReportRequest struct {
-	ChildArchiveID uint   `json:"child_archive_id" binding:"required" example:"1"`
-	StartDate      string `json:"start_date,omitempty" example:"2024-01-01"`
-	EndDate        string `json:"end_date,omitempty" example:"2024-01-31"`
-	ReportType     string `json:"report_type" binding:"required,oneof=summary suggestion" example:"summary"`
+	ChildArchiveID uint               `json:"child_archive_id" binding:"required" example:"1"`
+	StartDate      string             `json:"start_date,omitempty" example:"2024-01-01"`
+	EndDate        string             `json:"end_date,omitempty" example:"2024-01-31"`
+	ReportType     string             `json:"report_type" binding:"required" example:"summary"` // 报告类型标识，见 /api/report-types
+	Comparison     *ComparisonRequest `json:"comparison,omitempty"`
+	Language       string             `json:"language,omitempty" example:"en"` // 报告语言，如 zh、en，为空时为中文，见 /api/report-types/languages
+}
+
+// TranslateReportRequest 翻译AI报告请求
+type TranslateReportRequest struct {
+	Language string `json:"language" binding:"required" example:"en"` // 目标语言
 }
 
 // UpdateGeneratedContentRequest 更新AI生成内容请求
 type UpdateGeneratedContentRequest struct {
 	Content string `json:"content" binding:"required" example:"更新后的内容"`
-}
\ No newline at end of file
+}
+
+// ComparisonRequest 疗愈前后对比的技能和两个对比时间段
+type ComparisonRequest struct {
+	Skill         string `json:"skill" form:"skill" example:"语言表达"`
+	BaselineStart string `json:"baseline_start,omitempty" form:"baseline_start" example:"2024-01-01"`
+	BaselineEnd   string `json:"baseline_end,omitempty" form:"baseline_end" example:"2024-01-31"`
+	FollowUpStart string `json:"follow_up_start,omitempty" form:"follow_up_start" example:"2024-03-01"`
+	FollowUpEnd   string `json:"follow_up_end,omitempty" form:"follow_up_end" example:"2024-03-31"`
+}
+
+// MarkLogPhaseRequest 标记日志或媒体为基线/跟进记录
+type MarkLogPhaseRequest struct {
+	Phase    string `json:"phase" binding:"omitempty,oneof=baseline follow_up" example:"baseline"`
+	Skill    string `json:"skill" example:"语言表达"`
+	MediaIDs []uint `json:"media_ids,omitempty"`
+}
+
+// CreateVoiceLogRequest 语音疗愈日志的表单字段，录音通过 audio 文件字段上传
+type CreateVoiceLogRequest struct {
+	ChildArchiveID uint   `form:"child_archive_id" binding:"required" example:"1"`
+	Note           string `form:"note" example:"睡前练习发音"` // 补充的文字说明，放在识别出的文字之前
+	Phase          string `form:"phase" binding:"omitempty,oneof=baseline follow_up" example:"baseline"`
+	Skill          string `form:"skill" example:"语言表达"`
+	Language       string `form:"language" example:"zh"` // 录音的语言，为空时为中文
+}
+
+// SimilarEpisodesRequest 查找相似的过往资料，q 和 log_id 至少提供一个
+type SimilarEpisodesRequest struct {
+	Query string `form:"q" example:"午睡后情绪崩溃"`                            // 描述当前情况的文字
+	LogID uint   `form:"log_id" example:"12"`                            // 以该疗愈记录为查询，结果中不含该记录
+	K     int    `form:"k" binding:"omitempty,min=1,max=50" example:"5"` // 返回条数，为空时使用 semantic.similarK
+	Types string `form:"types" example:"log,report"`                     // 资料类型，逗号分隔（log, report, goal），为空时检索全部
+}
+
+// UpdateHealingLogRequest 编辑疗愈日志内容
+type UpdateHealingLogRequest struct {
+	Content string `json:"content" binding:"required" example:"今天孩子主动和小朋友打招呼"`
+}
@@ -0,0 +1,17 @@
+package request
+
+import "melody_cure/model"
+
+// LogTemplateRequest 创建/更新日志模板请求
+type LogTemplateRequest struct {
+	Name        string                  `json:"name" binding:"required" example:"孤独症日常观察"`
+	Description string                  `json:"description" example:"适用于孤独症谱系儿童的每日观察记录"`
+	Diagnosis   string                  `json:"diagnosis" example:"孤独症"`
+	Items       []model.LogTemplateItem `json:"items" binding:"required"`
+	IsActive    *bool                   `json:"is_active,omitempty"`
+}
+
+// AssignLogTemplateRequest 为儿童分配日志模板请求
+type AssignLogTemplateRequest struct {
+	TemplateID uint `json:"template_id" binding:"required" example:"1"`
+}
@@ -0,0 +1,35 @@
+package request
+
+// ReportFeedbackRequest 康复师评价AI报告请求，评分为 1-5
+type ReportFeedbackRequest struct {
+	Accuracy       int                         `json:"accuracy" binding:"required,min=1,max=5" example:"4"`   // 准确性
+	Usefulness     int                         `json:"usefulness" binding:"required,min=1,max=5" example:"4"` // 实用性
+	Safety         int                         `json:"safety" binding:"required,min=1,max=5" example:"5"`     // 安全性
+	Comment        string                      `json:"comment" binding:"max=2000" example:"整体符合日志记录"`
+	Sections       []SectionFeedbackRequest    `json:"sections,omitempty" binding:"dive"`
+	Hallucinations []HallucinationFlagRequest  `json:"hallucinations,omitempty" binding:"dive"`
+	Suggestions    []SuggestionFeedbackRequest `json:"suggestions,omitempty" binding:"dive"`
+}
+
+// SectionFeedbackRequest 章节评分，不评的维度传 0
+type SectionFeedbackRequest struct {
+	Section    string `json:"section" binding:"required" example:"语言发展"`
+	Accuracy   int    `json:"accuracy" binding:"min=0,max=5" example:"3"`
+	Usefulness int    `json:"usefulness" binding:"min=0,max=5" example:"4"`
+	Safety     int    `json:"safety" binding:"min=0,max=5" example:"5"`
+	Comment    string `json:"comment" binding:"max=1000"`
+}
+
+// HallucinationFlagRequest 标记报告中与记录不符或无依据的内容
+type HallucinationFlagRequest struct {
+	Section string `json:"section" example:"语言发展"`
+	Excerpt string `json:"excerpt" binding:"max=1000" example:"本周能说出完整句子"`
+	Comment string `json:"comment" binding:"required,max=1000" example:"日志中没有相关记录"`
+}
+
+// SuggestionFeedbackRequest 报告中的建议是否采纳
+type SuggestionFeedbackRequest struct {
+	Suggestion string `json:"suggestion" binding:"required,max=1000" example:"每天进行15分钟绘本共读"`
+	Adopted    bool   `json:"adopted" example:"true"`
+	Comment    string `json:"comment" binding:"max=1000"`
+}
@@ -0,0 +1,6 @@
+package request
+
+// SafetyReviewRequest 审核安全审查拦截的报告
+type SafetyReviewRequest struct {
+	Note string `json:"note" binding:"max=1000" example:"已与家长电话沟通，报告可以发布"` // 审核意见
+}
@@ -0,0 +1,42 @@
+package request
+
+// CreateReportTypeRequest 创建报告类型请求，同时创建第一个模板版本
+type CreateReportTypeRequest struct {
+	Key          string `json:"key" binding:"required" example:"monthly_review"`
+	Name         string `json:"name" binding:"required" example:"月度回顾"`
+	Description  string `json:"description" example:"按月回顾康复进展"`
+	Enabled      *bool  `json:"enabled,omitempty"`
+	SystemPrompt string `json:"system_prompt" example:"你是一位专业的儿童康复治疗师"`
+	Template     string `json:"template" binding:"required" example:"{{template \"logs\" .}}请总结本月进展。"`
+	OutputSchema string `json:"output_schema,omitempty"`
+	Note         string `json:"note,omitempty" example:"初始版本"`
+}
+
+// UpdateReportTypeRequest 更新报告类型请求
+type UpdateReportTypeRequest struct {
+	Name        string  `json:"name,omitempty" example:"月度回顾"`
+	Description *string `json:"description,omitempty"`
+	Enabled     *bool   `json:"enabled,omitempty"`
+}
+
+// PromptTemplateRequest 新增模板版本请求
+type PromptTemplateRequest struct {
+	SystemPrompt string `json:"system_prompt" example:"你是一位专业的儿童康复治疗师"`
+	Template     string `json:"template" binding:"required"`
+	OutputSchema string `json:"output_schema,omitempty"`
+	Note         string `json:"note,omitempty" example:"增加指标汇总"`
+	Language     string `json:"language,omitempty" example:"en"` // 模板语言，为空时为中文，启用后仅用于该语言的报告
+	Activate     bool   `json:"activate" example:"true"`         // 是否立即启用该版本
+}
+
+// PreviewPromptRequest 预览提示词请求，提供 template 时预览草稿，否则预览指定版本
+type PreviewPromptRequest struct {
+	Version        int    `json:"version,omitempty" example:"2"`   // 为0时使用报告语言的当前版本
+	Language       string `json:"language,omitempty" example:"en"` // 报告语言，为空时为中文
+	SystemPrompt   string `json:"system_prompt,omitempty"`
+	Template       string `json:"template,omitempty"`
+	OutputSchema   string `json:"output_schema,omitempty"`
+	ChildArchiveID string `json:"child_archive_id,omitempty" example:"1"` // 为空时使用示例数据
+	StartDate      string `json:"start_date,omitempty" example:"2024-01-01"`
+	EndDate        string `json:"end_date,omitempty" example:"2024-01-31"`
+}
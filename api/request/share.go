@@ -0,0 +1,16 @@
+package request
+
+// CreateShareLinkRequest 创建分享链接请求
+type CreateShareLinkRequest struct {
+	ResourceType   string `json:"resource_type" binding:"required,oneof=report dossier" example:"report"` // report 分享AI报告，dossier 分享儿童康复档案快照
+	ReportID       uint   `json:"report_id,omitempty" example:"1"`                                        // 分享报告时必填
+	ChildArchiveID string `json:"child_archive_id,omitempty" example:"1"`                                 // 分享档案时必填
+	StartDate      string `json:"start_date,omitempty" example:"2024-01-01"`                              // 档案的开始日期
+	EndDate        string `json:"end_date,omitempty" example:"2024-01-31"`                                // 档案的结束日期，包含当天
+	LogIDs         []uint `json:"log_ids,omitempty"`                                                      // 档案中包含的日志，为空时包含最近的日志
+	LogLimit       int    `json:"log_limit,omitempty" example:"10"`
+	ReportLimit    int    `json:"report_limit,omitempty" example:"3"`
+	ExpiresInHours int    `json:"expires_in_hours,omitempty" example:"72"` // 有效小时数，默认72小时
+	AccessCode     string `json:"access_code,omitempty" example:"2468"`    // 可选的访问码，4-12位
+	Recipient      string `json:"recipient,omitempty" binding:"max=100" example:"班主任王老师"`
+}
@@ -0,0 +1,8 @@
+package request
+
+// GrantTherapistRequest 家长授权认证康复师访问孩子的资料
+type GrantTherapistRequest struct {
+	ChildArchiveID  string `json:"child_archive_id" binding:"required" example:"1"`
+	TherapistUserID string `json:"therapist_user_id" binding:"required" example:"u123"`
+	Note            string `json:"note,omitempty" binding:"max=200" example:"每周康复训练的主治康复师"`
+}
@@ -0,0 +1,46 @@
+package request
+
+import "time"
+
+// TreatmentPlanRequest 创建或更新治疗方案请求
+type TreatmentPlanRequest struct {
+	ChildArchiveID string                 `json:"child_archive_id" example:"1"`
+	Title          string                 `json:"title" binding:"required" example:"第一阶段语言训练方案"`
+	Description    string                 `json:"description" example:"以提升主动表达为主"`
+	Status         string                 `json:"status" binding:"omitempty,oneof=active completed archived" example:"active"`
+	StartDate      *time.Time             `json:"start_date,omitempty"`
+	EndDate        *time.Time             `json:"end_date,omitempty"`
+	Goals          []TreatmentGoalRequest `json:"goals,omitempty" binding:"dive"`
+}
+
+// TreatmentGoalRequest 创建或更新治疗目标请求
+type TreatmentGoalRequest struct {
+	Domain         string     `json:"domain" binding:"required,oneof=language social motor emotion" example:"language"`
+	Title          string     `json:"title" binding:"required" example:"能用完整句子表达需求"`
+	Description    string     `json:"description" example:"在日常场景中主动提出需求"`
+	TargetCriteria string     `json:"target_criteria" example:"连续一周每天至少三次主动表达"`
+	MetricName     string     `json:"metric_name" example:"主动表达次数"`
+	BaselineValue  *float64   `json:"baseline_value,omitempty" example:"1"`
+	TargetValue    *float64   `json:"target_value,omitempty" example:"5"`
+	TargetCount    int        `json:"target_count" binding:"min=0" example:"20"`
+	StartDate      *time.Time `json:"start_date,omitempty"`
+	TargetDate     *time.Time `json:"target_date,omitempty"`
+	Status         string     `json:"status" binding:"omitempty,oneof=not_started in_progress achieved paused dropped" example:"in_progress"`
+}
+
+// GoalLinkRequest 将疗愈日志或游戏记录关联到目标
+type GoalLinkRequest struct {
+	TargetType string `json:"target_type" binding:"required,oneof=healing_log game_session" example:"healing_log"`
+	TargetID   uint   `json:"target_id" binding:"required" example:"1"`
+}
+
+// GameSessionRequest 记录游戏训练请求
+type GameSessionRequest struct {
+	ChildArchiveID  string     `json:"child_archive_id" binding:"required" example:"1"`
+	GameID          string     `json:"game_id" binding:"required" example:"game_001"`
+	Score           *float64   `json:"score,omitempty" example:"85"`
+	DurationSeconds int        `json:"duration_seconds" binding:"min=0" example:"300"`
+	Notes           string     `json:"notes" example:"能够独立完成第二关"`
+	PlayedAt        *time.Time `json:"played_at,omitempty"`
+	GoalIDs         []uint     `json:"goal_ids,omitempty"`
+}
@@ -0,0 +1,6 @@
+package request
+
+// SetUserInstitutionRequest 设置用户所属机构请求
+type SetUserInstitutionRequest struct {
+	InstitutionID string `json:"institution_id" example:"inst-001"` // 为空时解除关联
+}
@@ -0,0 +1,10 @@
+package response
+
+import "melody_cure/service"
+
+// ChildProfileResponse 儿童个人信息、疗愈进度及治疗目标进度
+type ChildProfileResponse struct {
+	ChildArchiveResponse
+	Progress *service.ChildProgress     `json:"progress,omitempty"`
+	Goals    []service.GoalWithProgress `json:"goals"`
+}
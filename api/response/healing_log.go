@@ -1,16 +1,33 @@
 package response
 
-import "time"
+import (
+	"encoding/json"
+	"melody_cure/model"
+	"time"
+)
 
 // GeneratedReportResponse AI生成报告响应
 type GeneratedReportResponse struct {
-	ID             uint      `json:"id" example:"1"`
-	ChildArchiveID uint      `json:"child_archive_id" example:"1"`
-	ReportType     string    `json:"report_type" example:"summary"`
-	Content        string    `json:"content" example:"本月儿童在情绪管理方面有显著进步..."`
-	IsEdited       bool      `json:"is_edited" example:"false"`
-	GeneratedAt    time.Time `json:"generated_at" example:"2024-01-15T10:30:00Z"`
-	UpdatedAt      time.Time `json:"updated_at" example:"2024-01-15T10:30:00Z"`
+	ID                uint                   `json:"id" example:"1"`
+	ChildArchiveID    uint                   `json:"child_archive_id" example:"1"`
+	ReportType        string                 `json:"report_type" example:"summary"`
+	Content           string                 `json:"content" example:"本月儿童在情绪管理方面有显著进步..."`
+	StructuredContent json.RawMessage        `json:"structured_content,omitempty" swaggertype:"object"` // 结构化报告的原始内容，content 为其渲染的 Markdown
+	IsEdited          bool                   `json:"is_edited" example:"false"`
+	CurrentVersion    int                    `json:"current_version" example:"1"`
+	RedactionLog      []model.RedactionEntry `json:"redaction_log,omitempty"` // 发送给模型前替换的敏感信息，原文已掩码
+	SafetyFindings    []model.SafetyFinding  `json:"safety_findings,omitempty"` // 生成后安全审查隐去或标注的内容
+	Language          string                 `json:"language" example:"zh"`
+	SourceReportID    *uint                  `json:"source_report_id,omitempty"` // 译文对应的原文报告
+	SourceVersion     int                    `json:"source_version,omitempty"`   // 翻译时原文的版本号
+	GeneratedAt       time.Time              `json:"generated_at" example:"2024-01-15T10:30:00Z"`
+	UpdatedAt         time.Time              `json:"updated_at" example:"2024-01-15T10:30:00Z"`
+}
+
+// ReportJobResponse 报告生成任务响应，任务成功时附带生成的报告
+type ReportJobResponse struct {
+	*model.ReportJob
+	Report *GeneratedReportResponse `json:"report,omitempty"`
 }
 
 // HealingLogWithReportResponse 包含AI生成报告的疗愈记录响应
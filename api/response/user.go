@@ -144,12 +144,6 @@ func ToChildArchiveResponse(archive *DAO.ChildArchive) ChildArchiveResponse {
 	// 计算年龄
 	age := calculateAge(archive.BirthDate)
 	
-	// 计算已疗愈天数
-	healedDays := archive.HealedDays
-	if archive.TreatmentStartDate != nil {
-		healedDays = int(time.Since(*archive.TreatmentStartDate).Hours() / 24)
-	}
-	
 	return ChildArchiveResponse{
 		ID:                 archive.ID,
 		ChildName:          archive.ChildName,
@@ -163,7 +157,7 @@ func ToChildArchiveResponse(archive *DAO.ChildArchive) ChildArchiveResponse {
 		Progress:           archive.Progress,
 		Notes:              archive.Notes,
 		TreatmentStartDate: archive.TreatmentStartDate,
-		HealedDays:         healedDays,
+		HealedDays:         archive.HealedDays,
 		CreatedAt:          archive.CreatedAt,
 		UpdatedAt:          archive.UpdatedAt,
 	}
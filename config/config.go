@@ -7,12 +7,22 @@ import (
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Email    EmailConfig
-	Qiniu    QiniuConfig
-	AI       AIConfig
+	Database     DatabaseConfig
+	Redis        RedisConfig
+	JWT          JWTConfig
+	Email        EmailConfig
+	Qiniu        QiniuConfig
+	AI           AIConfig
+	Scheduler    SchedulerConfig
+	ReportJob    ReportJobConfig
+	Usage        UsageConfig
+	Share        ShareConfig
+	Safety       SafetyConfig
+	Companion    CompanionConfig
+	Consultation ConsultationConfig
+	Storage      StorageConfig
+	Speech       SpeechConfig
+	Semantic     SemanticConfig
 }
 
 type DatabaseConfig struct {
@@ -53,13 +63,184 @@ type QiniuConfig struct {
 }
 
 type AIConfig struct {
-	Provider    string  `mapstructure:"provider"`
-	APIKey      string  `mapstructure:"apiKey"`
-	BaseURL     string  `mapstructure:"baseURL"`
-	Model       string  `mapstructure:"model"`
-	MaxTokens   int     `mapstructure:"maxTokens"`
-	Temperature float64 `mapstructure:"temperature"`
-	Timeout     int     `mapstructure:"timeout"`
+	Provider       string            `mapstructure:"provider"`
+	APIKey         string            `mapstructure:"apiKey"`
+	BaseURL        string            `mapstructure:"baseURL"`
+	Model          string            `mapstructure:"model"`
+	MaxTokens      int               `mapstructure:"maxTokens"`
+	Temperature    float64           `mapstructure:"temperature"`
+	Timeout        int               `mapstructure:"timeout"`
+	ModelOverrides map[string]string `mapstructure:"modelOverrides"` // 按报告类型覆盖模型
+	Fallback       AIProviderConfig  `mapstructure:"fallback"`       // 主提供商失败时使用的备用提供商
+	Budget         AIBudgetConfig    `mapstructure:"budget"`         // 长时间段报告的提示词预算
+	RedactPII      bool              `mapstructure:"redactPII"`      // 发送给模型前将姓名、电话、证件号、地址等替换为占位符
+	Retry          AIRetryConfig     `mapstructure:"retry"`          // 限流和服务端错误的重试
+	CircuitBreaker AIBreakerConfig   `mapstructure:"circuitBreaker"` // 提供商连续失败时熔断
+	MockSeed       int64             `mapstructure:"mockSeed"`       // 模拟提供商的随机种子，相同种子和记录生成相同的报告
+}
+
+type AIProviderConfig struct {
+	Provider string `mapstructure:"provider"`
+	APIKey   string `mapstructure:"apiKey"`
+	BaseURL  string `mapstructure:"baseURL"`
+	Model    string `mapstructure:"model"`
+}
+
+// AIBudgetConfig 提示词超出模型上下文时，按时间窗口分段摘要后再汇总生成报告
+type AIBudgetConfig struct {
+	ContextTokens       int `mapstructure:"contextTokens"`       // 模型上下文长度(token)
+	WindowDays          int `mapstructure:"windowDays"`          // 分段摘要的时间窗口(天)
+	WindowSummaryTokens int `mapstructure:"windowSummaryTokens"` // 每段摘要的最大输出token数
+	SummaryCacheTTL     int `mapstructure:"summaryCacheTTL"`     // 分段摘要缓存时间(小时)
+}
+
+// AIRetryConfig 提供商返回 429、5xx 或网络错误时按带随机抖动的指数退避重试
+type AIRetryConfig struct {
+	MaxAttempts int `mapstructure:"maxAttempts"` // 单次调用的最多请求次数(含首次)
+	BaseDelay   int `mapstructure:"baseDelay"`   // 重试基础间隔(毫秒)，每次翻倍
+	MaxDelay    int `mapstructure:"maxDelay"`    // 单次等待上限(秒)，Retry-After 超过该值时不再等待
+}
+
+// AIBreakerConfig 提供商连续失败达到阈值后熔断，熔断期间直接切换到备用提供商或由任务队列稍后重试
+type AIBreakerConfig struct {
+	FailureThreshold int `mapstructure:"failureThreshold"` // 连续失败次数阈值
+	OpenSeconds      int `mapstructure:"openSeconds"`      // 熔断持续时间(秒)，之后放行一次试探请求
+}
+
+type SchedulerConfig struct {
+	Enabled              bool   `mapstructure:"enabled"`
+	ReminderTime         string `mapstructure:"reminderTime"`         // 每日记录提醒时间 HH:MM
+	WeeklyReportWeekday  int    `mapstructure:"weeklyReportWeekday"`  // 周报生成日，0为周日
+	WeeklyReportTime     string `mapstructure:"weeklyReportTime"`     // 周报生成时间 HH:MM
+	CertExpiryCheckTime  string `mapstructure:"certExpiryCheckTime"`  // 认证到期检查时间 HH:MM
+	CertExpiryWarnDays   int    `mapstructure:"certExpiryWarnDays"`   // 认证到期前提醒天数
+	TokenCleanupInterval int    `mapstructure:"tokenCleanupInterval"` // 过期图床token清理间隔(分钟)
+}
+
+type ReportJobConfig struct {
+	Workers        int `mapstructure:"workers"`        // 每个副本的报告生成并发数
+	MaxAttempts    int `mapstructure:"maxAttempts"`    // 最大尝试次数
+	RetryBaseDelay int `mapstructure:"retryBaseDelay"` // 重试基础间隔(秒)，按次数指数增长
+}
+
+// UsageConfig AI用量配额和费用估算，配额按 token 数（输入+输出）计算，0 表示不限
+type UsageConfig struct {
+	Enabled           bool                    `mapstructure:"enabled"`
+	RoleQuotas        map[string]QuotaConfig  `mapstructure:"roleQuotas"`        // 按用户身份的每人配额
+	InstitutionQuota  QuotaConfig             `mapstructure:"institutionQuota"`  // 每个机构（含成员）的默认配额
+	InstitutionQuotas map[string]QuotaConfig  `mapstructure:"institutionQuotas"` // 按机构ID覆盖默认配额
+	Pricing           map[string]ModelPricing `mapstructure:"pricing"`           // 按模型的单价，模型名使用小写
+	Currency          string                  `mapstructure:"currency"`
+}
+
+type QuotaConfig struct {
+	Daily   int64 `mapstructure:"daily"`
+	Monthly int64 `mapstructure:"monthly"`
+}
+
+// ModelPricing 每千 token 的价格
+type ModelPricing struct {
+	Prompt     float64 `mapstructure:"prompt"`
+	Completion float64 `mapstructure:"completion"`
+}
+
+// ShareConfig 报告和康复档案的分享链接
+type ShareConfig struct {
+	Secret             string `mapstructure:"secret"`             // 链接令牌的签名密钥，为空时使用 JWT 密钥
+	BaseURL            string `mapstructure:"baseURL"`            // 前端分享页地址，返回的链接为 baseURL + 令牌
+	DefaultExpireHours int    `mapstructure:"defaultExpireHours"` // 未指定有效期时的有效小时数
+	MaxExpireDays      int    `mapstructure:"maxExpireDays"`      // 最长有效天数
+	MaxCodeAttempts    int    `mapstructure:"maxCodeAttempts"`    // 15分钟内访问码错误次数上限，超出后暂时拒绝访问
+}
+
+// SafetyConfig AI报告生成后的安全审查
+type SafetyConfig struct {
+	Enabled     bool                   `mapstructure:"enabled"`
+	Disclaimer  string                 `mapstructure:"disclaimer"`  // 追加在每份中文报告末尾的免责声明，为空时使用内置声明
+	Disclaimers map[string]string      `mapstructure:"disclaimers"` // 按报告语言配置的免责声明，如 en，未配置的语言使用内置声明
+	Rules       []SafetyRuleConfig     `mapstructure:"rules"`       // 关键词和正则规则，为空时使用内置规则
+	Classifier  SafetyClassifierConfig `mapstructure:"classifier"`  // 可选的模型分类器
+}
+
+// SafetyRuleConfig 一条安全规则，命中任一关键词或正则表达式即触发
+type SafetyRuleConfig struct {
+	Name     string   `mapstructure:"name"`
+	Category string   `mapstructure:"category"` // medication, diagnosis, crisis, self_harm, abuse 等
+	Scope    string   `mapstructure:"scope"`    // output 检查模型输出，logs 检查用于生成报告的疗愈记录
+	Action   string   `mapstructure:"action"`   // block 隐去所在句子，annotate 附加安全提示，escalate 转机构人工审核
+	Keywords []string `mapstructure:"keywords"`
+	Patterns []string `mapstructure:"patterns"` // 正则表达式
+	Message  string   `mapstructure:"message"`  // 隐去内容或安全提示中展示的说明
+}
+
+// SafetyClassifierConfig 在规则之外用模型识别风险内容，提供商为空时使用报告生成的提供商
+type SafetyClassifierConfig struct {
+	Enabled    bool    `mapstructure:"enabled"`
+	Provider   string  `mapstructure:"provider"`
+	APIKey     string  `mapstructure:"apiKey"`
+	BaseURL    string  `mapstructure:"baseURL"`
+	Model      string  `mapstructure:"model"`
+	Threshold  float64 `mapstructure:"threshold"`  // 分类得分不低于该值时视为命中
+	FailClosed bool    `mapstructure:"failClosed"` // 分类器调用失败时转人工审核，否则仅使用规则结果
+}
+
+// CompanionConfig AI陪伴对话
+type CompanionConfig struct {
+	HistoryMessages  int                         `mapstructure:"historyMessages"`  // 每次请求携带的最近消息数，更早的消息通过记忆摘要提供
+	SummaryThreshold int                         `mapstructure:"summaryThreshold"` // 未并入记忆摘要的消息超过该数量时更新摘要
+	MaxMessageLength int                         `mapstructure:"maxMessageLength"` // 单条消息最多字数
+	MaxReplyTokens   int                         `mapstructure:"maxReplyTokens"`   // 回复的最大token数
+	Temperature      float64                     `mapstructure:"temperature"`
+	FilterRules      []CompanionFilterRuleConfig `mapstructure:"filterRules"` // 内容过滤规则，为空时使用内置规则
+}
+
+// CompanionFilterRuleConfig 一条陪伴对话的内容过滤规则，儿童消息和陪伴回复命中任一关键词或正则表达式即被过滤
+type CompanionFilterRuleConfig struct {
+	Name         string   `mapstructure:"name"`
+	Category     string   `mapstructure:"category"`     // self_harm, abuse, violence, sexual, substance, scary, personal_info 等
+	MaxAge       int      `mapstructure:"maxAge"`       // 只对不超过该年龄的儿童生效，0 表示所有年龄
+	NotifyParent bool     `mapstructure:"notifyParent"` // 儿童消息命中时通知家长
+	Keywords     []string `mapstructure:"keywords"`
+	Patterns     []string `mapstructure:"patterns"` // 正则表达式
+	Reply        string   `mapstructure:"reply"`    // 代替模型回复儿童的话，为空时使用内置回复
+}
+
+// ConsultationConfig 虚拟疗愈导师咨询
+type ConsultationConfig struct {
+	RecentLogDays    int     `mapstructure:"recentLogDays"`    // 检索最近多少天的疗愈记录
+	MaxLogs          int     `mapstructure:"maxLogs"`          // 参与检索的疗愈记录最多条数
+	ReportLimit      int     `mapstructure:"reportLimit"`      // 参与检索的最新报告数
+	TopK             int     `mapstructure:"topK"`             // 每次回复引用的资料条数上限（不含儿童档案）
+	HistoryMessages  int     `mapstructure:"historyMessages"`  // 每次请求携带的最近消息数
+	MaxMessageLength int     `mapstructure:"maxMessageLength"` // 单条消息最多字数
+	MaxReplyTokens   int     `mapstructure:"maxReplyTokens"`   // 回复的最大token数
+	Temperature      float64 `mapstructure:"temperature"`
+}
+
+// StorageConfig 服务端生成的文件（如合成语音、语音记录）的对象存储
+type StorageConfig struct {
+	Provider string `mapstructure:"provider"` // local 保存到本地目录，qiniu 上传到七牛云（使用 qiniu 配置）
+	LocalDir string `mapstructure:"localDir"` // 本地存储目录
+	BaseURL  string `mapstructure:"baseURL"`  // 本地存储文件的访问地址前缀（路径或完整地址），服务在其路径部分下提供文件
+}
+
+// SpeechConfig 语音合成与识别
+type SpeechConfig struct {
+	Provider          string `mapstructure:"provider"`          // local 为离线的本地替代实现
+	DefaultVoice      string `mapstructure:"defaultVoice"`      // AI陪伴未设置语音类型时使用的音色
+	MaxAudioBytes     int64  `mapstructure:"maxAudioBytes"`     // 上传的语音记录最大字节数
+	MaxSynthesisRunes int    `mapstructure:"maxSynthesisRunes"` // 单次合成的最多字数
+}
+
+// SemanticConfig 按儿童建立的疗愈记录、报告和目标语义索引
+type SemanticConfig struct {
+	Provider       string  `mapstructure:"provider"`       // 向量化提供商，local 为离线的本地实现
+	Dimensions     int     `mapstructure:"dimensions"`     // 本地向量化的维度
+	ChunkRunes     int     `mapstructure:"chunkRunes"`     // 长文本按段落切分后每段的最大字数
+	SimilarK       int     `mapstructure:"similarK"`       // 查找相似记录时默认返回的条数
+	ReportContextK int     `mapstructure:"reportContextK"` // 生成报告时补充的相关历史资料条数，0 表示不补充
+	MinScore       float64 `mapstructure:"minScore"`       // 相似度低于该值的结果不返回
+	MaxCandidates  int     `mapstructure:"maxCandidates"`  // 每类资料参与相似度计算的最多分段数，按时间从新到旧选取，0 表示不限
 }
 
 var GlobalConfig Config
@@ -88,6 +269,7 @@ func InitConfig() {
 	viper.BindEnv("redis.db", "REDIS_DB")
 	
 	viper.BindEnv("jwt.secretKey", "JWT_SECRET")
+	viper.BindEnv("share.secret", "SHARE_SECRET")
 	
 	viper.BindEnv("qiniu.access_key", "QINIU_ACCESS_KEY")
 	viper.BindEnv("qiniu.secret_key", "QINIU_SECRET_KEY")
@@ -104,6 +286,14 @@ func InitConfig() {
 	viper.BindEnv("ai.maxTokens", "AI_MAX_TOKENS")
 	viper.BindEnv("ai.temperature", "AI_TEMPERATURE")
 	viper.BindEnv("ai.timeout", "AI_TIMEOUT")
+	viper.BindEnv("ai.mockSeed", "AI_MOCK_SEED")
+	viper.BindEnv("ai.fallback.provider", "AI_FALLBACK_PROVIDER")
+	viper.BindEnv("ai.fallback.apiKey", "AI_FALLBACK_API_KEY")
+	viper.BindEnv("ai.fallback.baseURL", "AI_FALLBACK_BASE_URL")
+	viper.BindEnv("ai.fallback.model", "AI_FALLBACK_MODEL")
+
+	viper.BindEnv("scheduler.enabled", "SCHEDULER_ENABLED")
+	viper.BindEnv("scheduler.reminderTime", "SCHEDULER_REMINDER_TIME")
 	
 	// 设置默认值
 	setDefaults()
@@ -158,6 +348,81 @@ func setDefaults() {
 	viper.SetDefault("ai.maxTokens", 2000)
 	viper.SetDefault("ai.temperature", 0.7)
 	viper.SetDefault("ai.timeout", 30)
+	viper.SetDefault("ai.budget.contextTokens", 16000)
+	viper.SetDefault("ai.budget.windowDays", 7)
+	viper.SetDefault("ai.budget.windowSummaryTokens", 600)
+	viper.SetDefault("ai.budget.summaryCacheTTL", 720)
+	viper.SetDefault("ai.redactPII", true)
+	viper.SetDefault("ai.retry.maxAttempts", 3)
+	viper.SetDefault("ai.retry.baseDelay", 500)
+	viper.SetDefault("ai.retry.maxDelay", 30)
+	viper.SetDefault("ai.circuitBreaker.failureThreshold", 5)
+	viper.SetDefault("ai.circuitBreaker.openSeconds", 60)
+	viper.SetDefault("ai.mockSeed", 0)
+
+	// 定时任务默认配置
+	viper.SetDefault("scheduler.enabled", true)
+	viper.SetDefault("scheduler.reminderTime", "20:00")
+	viper.SetDefault("scheduler.weeklyReportWeekday", 0)
+	viper.SetDefault("scheduler.weeklyReportTime", "21:00")
+	viper.SetDefault("scheduler.certExpiryCheckTime", "09:00")
+	viper.SetDefault("scheduler.certExpiryWarnDays", 30)
+	viper.SetDefault("scheduler.tokenCleanupInterval", 60)
+
+	// 报告生成任务默认配置
+	viper.SetDefault("reportJob.workers", 2)
+	viper.SetDefault("reportJob.maxAttempts", 3)
+	viper.SetDefault("reportJob.retryBaseDelay", 10)
+
+	// AI用量配额默认配置
+	viper.SetDefault("usage.enabled", true)
+	viper.SetDefault("usage.currency", "USD")
+
+	// 分享链接默认配置
+	viper.SetDefault("share.defaultExpireHours", 72)
+	viper.SetDefault("share.maxExpireDays", 30)
+	viper.SetDefault("share.maxCodeAttempts", 5)
+
+	// 报告安全审查默认配置
+	viper.SetDefault("safety.enabled", true)
+	viper.SetDefault("safety.classifier.threshold", 0.7)
+
+	// AI陪伴对话默认配置
+	viper.SetDefault("companion.historyMessages", 12)
+	viper.SetDefault("companion.summaryThreshold", 24)
+	viper.SetDefault("companion.maxMessageLength", 500)
+	viper.SetDefault("companion.maxReplyTokens", 300)
+	viper.SetDefault("companion.temperature", 0.8)
+
+	// 虚拟疗愈导师咨询默认配置
+	viper.SetDefault("consultation.recentLogDays", 30)
+	viper.SetDefault("consultation.maxLogs", 60)
+	viper.SetDefault("consultation.reportLimit", 3)
+	viper.SetDefault("consultation.topK", 6)
+	viper.SetDefault("consultation.historyMessages", 10)
+	viper.SetDefault("consultation.maxMessageLength", 1000)
+	viper.SetDefault("consultation.maxReplyTokens", 800)
+	viper.SetDefault("consultation.temperature", 0.4)
+
+	// 对象存储默认配置
+	viper.SetDefault("storage.provider", "local")
+	viper.SetDefault("storage.localDir", "./data/media")
+	viper.SetDefault("storage.baseURL", "/media")
+
+	// 语音默认配置
+	viper.SetDefault("speech.provider", "local")
+	viper.SetDefault("speech.defaultVoice", "warm")
+	viper.SetDefault("speech.maxAudioBytes", 10<<20)
+	viper.SetDefault("speech.maxSynthesisRunes", 500)
+
+	// 语义索引默认配置
+	viper.SetDefault("semantic.provider", "local")
+	viper.SetDefault("semantic.dimensions", 256)
+	viper.SetDefault("semantic.chunkRunes", 400)
+	viper.SetDefault("semantic.similarK", 5)
+	viper.SetDefault("semantic.reportContextK", 5)
+	viper.SetDefault("semantic.minScore", 0.1)
+	viper.SetDefault("semantic.maxCandidates", 2000)
 }
 
 // GetConfig 获取全局配置
@@ -194,3 +459,53 @@ func GetQiniuConfig() QiniuConfig {
 func GetAIConfig() AIConfig {
 	return GlobalConfig.AI
 }
+
+// GetSchedulerConfig 获取定时任务配置
+func GetSchedulerConfig() SchedulerConfig {
+	return GlobalConfig.Scheduler
+}
+
+// GetReportJobConfig 获取报告生成任务配置
+func GetReportJobConfig() ReportJobConfig {
+	return GlobalConfig.ReportJob
+}
+
+// GetUsageConfig 获取AI用量配额配置
+func GetUsageConfig() UsageConfig {
+	return GlobalConfig.Usage
+}
+
+// GetShareConfig 获取分享链接配置
+func GetShareConfig() ShareConfig {
+	return GlobalConfig.Share
+}
+
+// GetSafetyConfig 获取报告安全审查配置
+func GetSafetyConfig() SafetyConfig {
+	return GlobalConfig.Safety
+}
+
+// GetCompanionConfig 获取AI陪伴对话配置
+func GetCompanionConfig() CompanionConfig {
+	return GlobalConfig.Companion
+}
+
+// GetConsultationConfig 获取虚拟疗愈导师咨询配置
+func GetConsultationConfig() ConsultationConfig {
+	return GlobalConfig.Consultation
+}
+
+// GetStorageConfig 获取对象存储配置
+func GetStorageConfig() StorageConfig {
+	return GlobalConfig.Storage
+}
+
+// GetSpeechConfig 获取语音配置
+func GetSpeechConfig() SpeechConfig {
+	return GlobalConfig.Speech
+}
+
+// GetSemanticConfig 获取语义索引配置
+func GetSemanticConfig() SemanticConfig {
+	return GlobalConfig.Semantic
+}
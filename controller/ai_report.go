@@ -1,8 +1,10 @@
 package controller
 
 import (
+	"errors"
 	"melody_cure/api/request"
 	"melody_cure/api/response"
+	"melody_cure/model"
 	"melody_cure/service"
 	"net/http"
 	"strconv"
@@ -12,12 +14,16 @@ import (
 )
 
 type AIReportController struct {
-	aiReportService *service.AIReportService
+	aiReportService  *service.AIReportService
+	reportJobService *service.ReportJobService
+	usageService     *service.UsageService
 }
 
-func NewAIReportController(aiReportService *service.AIReportService) *AIReportController {
+func NewAIReportController(aiReportService *service.AIReportService, reportJobService *service.ReportJobService, usageService *service.UsageService) *AIReportController {
 	return &AIReportController{
-		aiReportService: aiReportService,
+		aiReportService:  aiReportService,
+		reportJobService: reportJobService,
+		usageService:     usageService,
 	}
 }
 
@@ -30,48 +36,232 @@ func (c *AIReportController) GenerateReport(ctx *gin.Context) {
 	}
 
 	// 解析日期参数
-	var startDate, endDate *time.Time
-	if req.StartDate != "" {
-		if parsed, err := time.Parse("2006-01-02", req.StartDate); err == nil {
-			startDate = &parsed
-		} else {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "开始日期格式错误"})
+	startDate, endDate, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	language, err := service.NormalizeReportLanguage(req.Language)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 解析疗愈前后对比参数
+	opts := &service.ReportOptions{Language: language}
+	if req.Comparison != nil {
+		comparison, err := service.ParseComparisonRequest(req.Comparison)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		opts.Comparison = comparison
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+	if !checkAIQuota(ctx, c.usageService, userID.(string)) {
+		return
+	}
+
+	// 创建报告生成任务，由后台任务异步生成
+	job, err := c.reportJobService.SubmitJob(ctx.Request.Context(), userID.(string), strconv.FormatUint(uint64(req.ChildArchiveID), 10), req.ReportType, startDate, endDate, opts)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": "创建报告任务失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": "报告任务已创建",
+		"data":    response.ReportJobResponse{ReportJob: job},
+	})
+}
+
+// StreamReport 以 Server-Sent Events 流式生成AI报告
+// 事件：delta 为模型输出的增量内容，done 为保存后的报告（经安全审查，内容以此为准），held 为报告已转人工审核，error 为生成失败原因。
+// 客户端断开连接时中止上游模型请求，不保存报告；输出开始前AI服务暂时不可用时转为后台任务并返回 202
+func (c *AIReportController) StreamReport(ctx *gin.Context) {
+	var req request.GenerateReportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+
+	startDate, endDate, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	language, err := service.NormalizeReportLanguage(req.Language)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts := &service.ReportOptions{Language: language}
+	if req.Comparison != nil {
+		comparison, err := service.ParseComparisonRequest(req.Comparison)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		opts.Comparison = comparison
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+	if !checkAIQuota(ctx, c.usageService, userID.(string)) {
+		return
 	}
-	if req.EndDate != "" {
-		if parsed, err := time.Parse("2006-01-02", req.EndDate); err == nil {
-			endDate = &parsed
-		} else {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "结束日期格式错误"})
+
+	// 收到第一段内容时才写入 SSE 响应头，此前的错误仍以普通 JSON 返回
+	reqCtx := ctx.Request.Context()
+	started := false
+	startStream := func() {
+		if started {
 			return
 		}
+		started = true
+		ctx.Header("Content-Type", "text/event-stream")
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("Connection", "keep-alive")
+		ctx.Header("X-Accel-Buffering", "no")
+		ctx.Status(http.StatusOK)
+	}
+	onDelta := func(delta string) error {
+		if err := reqCtx.Err(); err != nil {
+			return err
+		}
+		startStream()
+		ctx.SSEvent("delta", gin.H{"content": delta})
+		ctx.Writer.Flush()
+		return nil
 	}
 
-	// 生成报告
-	report, err := c.aiReportService.GenerateReport(strconv.FormatUint(uint64(req.ChildArchiveID), 10), req.ReportType, startDate, endDate)
+	report, err := c.aiReportService.StreamReport(reqCtx, userID.(string), strconv.FormatUint(uint64(req.ChildArchiveID), 10), req.ReportType, startDate, endDate, opts, onDelta)
+	if reqCtx.Err() != nil {
+		// 客户端已断开
+		return
+	}
+	var held *service.ReportHeldError
+	if errors.As(err, &held) {
+		// 报告转机构人工审核，不返回报告内容
+		data := gin.H{"safety_review_id": held.Review.ID, "status": held.Review.Status}
+		if !started {
+			ctx.JSON(http.StatusAccepted, gin.H{"message": err.Error(), "data": data})
+			return
+		}
+		ctx.SSEvent("held", gin.H{"message": err.Error(), "data": data})
+		ctx.Writer.Flush()
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "生成报告失败: " + err.Error()})
+		if !started && service.IsTransientLLMError(err) {
+			// AI服务暂时不可用（熔断、限流等），转为后台任务在服务恢复后生成
+			job, submitErr := c.reportJobService.SubmitJob(reqCtx, userID.(string), strconv.FormatUint(uint64(req.ChildArchiveID), 10), req.ReportType, startDate, endDate, opts)
+			if submitErr == nil {
+				ctx.JSON(http.StatusAccepted, gin.H{
+					"message": "AI服务暂时不可用，已转为后台生成，完成后将通知您",
+					"data":    response.ReportJobResponse{ReportJob: job},
+				})
+				return
+			}
+		}
+		if !started {
+			setRetryAfter(ctx, err)
+			ctx.JSON(serviceErrorStatus(err), gin.H{"error": "生成报告失败: " + err.Error()})
+			return
+		}
+		ctx.SSEvent("error", gin.H{"error": "生成报告失败: " + err.Error()})
+		ctx.Writer.Flush()
 		return
 	}
 
-	// 构建响应
-	resp := response.GeneratedReportResponse{
-		ID:              report.ID,
-		ChildArchiveID:  req.ChildArchiveID,
-		ReportType:      report.ReportType,
-		Content:         report.Content,
-		IsEdited:        report.IsEdited,
-		GeneratedAt:     report.GeneratedAt,
-		UpdatedAt:       report.UpdatedAt,
+	startStream()
+	ctx.SSEvent("done", newGeneratedReportResponse(report))
+	ctx.Writer.Flush()
+}
+
+// GetReportJob 查询报告生成任务状态
+func (c *AIReportController) GetReportJob(ctx *gin.Context) {
+	jobID, err := strconv.ParseUint(ctx.Param("job_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "任务ID格式错误"})
+		return
+	}
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	job, report, err := c.reportJobService.GetJob(userID.(string), uint(jobID))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": err.Error()})
+		return
 	}
 
+	resp := response.ReportJobResponse{ReportJob: job}
+	if report != nil {
+		resp.Report = newGeneratedReportResponse(report)
+	}
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "报告生成成功",
+		"message": "获取任务成功",
 		"data":    resp,
 	})
 }
 
+// GetChildReportJobs 获取儿童最近的报告生成任务
+func (c *AIReportController) GetChildReportJobs(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	jobs, err := c.reportJobService.ListJobs(userID.(string), ctx.Param("child_id"))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "获取任务列表成功",
+		"data":    jobs,
+	})
+}
+
+// CancelReportJob 取消报告生成任务
+func (c *AIReportController) CancelReportJob(ctx *gin.Context) {
+	jobID, err := strconv.ParseUint(ctx.Param("job_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "任务ID格式错误"})
+		return
+	}
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	job, err := c.reportJobService.CancelJob(ctx.Request.Context(), userID.(string), uint(jobID))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "任务已取消",
+		"data":    response.ReportJobResponse{ReportJob: job},
+	})
+}
+
 // UpdateReportContent 更新报告内容
 func (c *AIReportController) UpdateReportContent(ctx *gin.Context) {
 	reportIDStr := ctx.Param("id")
@@ -87,19 +277,214 @@ func (c *AIReportController) UpdateReportContent(ctx *gin.Context) {
 		return
 	}
 
-	// 更新报告内容
-	err = c.aiReportService.UpdateReportContent(uint(reportID), req.Content)
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 编辑内容保存为新版本，AI原始内容和历史版本保持不变
+	version, err := c.aiReportService.UpdateReportContent(userID.(string), uint(reportID), req.Content)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "更新报告失败: " + err.Error()})
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": "更新报告失败: " + err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "报告更新成功",
+		"data":    version,
+	})
+}
+
+// ListChildReports 获取儿童的所有报告，支持按生成日期和报告类型筛选
+func (c *AIReportController) ListChildReports(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	startDate, endDate, err := parseReportDateRange(ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if endDate != nil {
+		// 结束日期包含当天
+		end := endDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		endDate = &end
+	}
+
+	reports, err := c.aiReportService.ListReports(userID.(string), ctx.Param("child_id"), ctx.Query("report_type"), startDate, endDate)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]*response.GeneratedReportResponse, 0, len(reports))
+	for i := range reports {
+		resp = append(resp, newGeneratedReportResponse(&reports[i]))
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "获取报告列表成功",
+		"data":    resp,
 	})
 }
 
-// GetReport 获取报告
+// ListReportVersions 获取报告的版本历史
+func (c *AIReportController) ListReportVersions(ctx *gin.Context) {
+	reportID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "报告ID格式错误"})
+		return
+	}
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	versions, err := c.aiReportService.ListReportVersions(userID.(string), uint(reportID))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "获取版本历史成功",
+		"data":    versions,
+	})
+}
+
+// DiffReportVersions 比较报告的两个版本
+func (c *AIReportController) DiffReportVersions(ctx *gin.Context) {
+	reportID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "报告ID格式错误"})
+		return
+	}
+	from, err := strconv.Atoi(ctx.Query("from"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "起始版本号格式错误"})
+		return
+	}
+	to, err := strconv.Atoi(ctx.Query("to"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "目标版本号格式错误"})
+		return
+	}
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	diff, err := c.aiReportService.DiffReportVersions(userID.(string), uint(reportID), from, to)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "获取版本差异成功",
+		"data":    diff,
+	})
+}
+
+// RestoreReportVersion 恢复报告的历史版本
+func (c *AIReportController) RestoreReportVersion(ctx *gin.Context) {
+	reportID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "报告ID格式错误"})
+		return
+	}
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "版本号格式错误"})
+		return
+	}
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	restored, err := c.aiReportService.RestoreReportVersion(userID.(string), uint(reportID), version)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "版本恢复成功",
+		"data":    restored,
+	})
+}
+
+// TranslateReport 将报告翻译为指定语言，译文作为原文的关联版本保存，原文未变化时返回已有译文
+func (c *AIReportController) TranslateReport(ctx *gin.Context) {
+	reportID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "报告ID格式错误"})
+		return
+	}
+	var req request.TranslateReportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误: " + err.Error()})
+		return
+	}
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+	if !checkAIQuota(ctx, c.usageService, userID.(string)) {
+		return
+	}
+
+	report, err := c.aiReportService.TranslateReport(ctx.Request.Context(), userID.(string), uint(reportID), req.Language)
+	if err != nil {
+		setRetryAfter(ctx, err)
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": "翻译报告失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "翻译报告成功",
+		"data":    newGeneratedReportResponse(report),
+	})
+}
+
+// ListReportTranslations 获取报告原文及其各语言的译文
+func (c *AIReportController) ListReportTranslations(ctx *gin.Context) {
+	reportID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "报告ID格式错误"})
+		return
+	}
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	reports, err := c.aiReportService.ListReportTranslations(userID.(string), uint(reportID))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := make([]*response.GeneratedReportResponse, 0, len(reports))
+	for i := range reports {
+		resp = append(resp, newGeneratedReportResponse(&reports[i]))
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "获取译文成功",
+		"data":    resp,
+	})
+}
+
+// GetReport 获取报告，指定 language 时返回该语言的译文
 func (c *AIReportController) GetReport(ctx *gin.Context) {
 	childArchiveID := ctx.Query("child_archive_id")
 	reportType := ctx.Query("report_type")
@@ -108,34 +493,74 @@ func (c *AIReportController) GetReport(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "缺少必要参数"})
 		return
 	}
-
-	// 获取报告
-	report, err := c.aiReportService.GetReportByChildIDAndType(childArchiveID, reportType)
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "报告不存在"})
+	var language string
+	if ctx.Query("language") != "" {
+		normalized, err := service.NormalizeReportLanguage(ctx.Query("language"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		language = normalized
+	}
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
 		return
 	}
 
-	// 将字符串ID转换为uint
-	childArchiveIDUint, err := strconv.ParseUint(childArchiveID, 10, 32)
+	// 获取报告
+	report, err := c.aiReportService.GetReportByChildIDAndType(userID.(string), childArchiveID, reportType, language)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "儿童档案ID格式错误"})
+		ctx.JSON(serviceErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	// 构建响应
-	resp := response.GeneratedReportResponse{
-		ID:              report.ID,
-		ChildArchiveID:  uint(childArchiveIDUint),
-		ReportType:      report.ReportType,
-		Content:         report.Content,
-		IsEdited:        report.IsEdited,
-		GeneratedAt:     report.GeneratedAt,
-		UpdatedAt:       report.UpdatedAt,
-	}
+	resp := newGeneratedReportResponse(report)
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "获取报告成功",
 		"data":    resp,
 	})
-}
\ No newline at end of file
+}
+
+// parseReportDateRange 解析报告的起止日期，为空表示不限制
+func parseReportDateRange(start, end string) (*time.Time, *time.Time, error) {
+	var startDate, endDate *time.Time
+	if start != "" {
+		parsed, err := time.Parse("2006-01-02", start)
+		if err != nil {
+			return nil, nil, errors.New("开始日期格式错误")
+		}
+		startDate = &parsed
+	}
+	if end != "" {
+		parsed, err := time.Parse("2006-01-02", end)
+		if err != nil {
+			return nil, nil, errors.New("结束日期格式错误")
+		}
+		endDate = &parsed
+	}
+	return startDate, endDate, nil
+}
+
+// newGeneratedReportResponse 构建报告响应
+func newGeneratedReportResponse(report *model.GeneratedReport) *response.GeneratedReportResponse {
+	childArchiveID, _ := strconv.ParseUint(report.ChildArchiveID, 10, 32)
+	return &response.GeneratedReportResponse{
+		ID:                report.ID,
+		ChildArchiveID:    uint(childArchiveID),
+		ReportType:        report.ReportType,
+		Content:           report.Content,
+		StructuredContent: report.StructuredContent,
+		IsEdited:          report.IsEdited,
+		CurrentVersion:    report.CurrentVersion,
+		RedactionLog:      report.RedactionLog,
+		SafetyFindings:    report.SafetyFindings,
+		Language:          report.Language,
+		SourceReportID:    report.SourceReportID,
+		SourceVersion:     report.SourceVersion,
+		GeneratedAt:       report.GeneratedAt,
+		UpdatedAt:         report.UpdatedAt,
+	}
+}
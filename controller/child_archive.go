@@ -5,27 +5,34 @@ import (
 	"melody_cure/api/response"
 	"melody_cure/service"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ChildArchiveController struct {
-	userService *service.User
+	userService     *service.User
+	progressService *service.ChildProgressService
+	planService     *service.TreatmentPlanService
 }
 
-func NewChildArchiveController(userService *service.User) *ChildArchiveController {
-	return &ChildArchiveController{userService: userService}
+func NewChildArchiveController(userService *service.User, progressService *service.ChildProgressService, planService *service.TreatmentPlanService) *ChildArchiveController {
+	return &ChildArchiveController{
+		userService:     userService,
+		progressService: progressService,
+		planService:     planService,
+	}
 }
 
 // GetChildProfile 获取儿童个人信息
 // @Summary 获取儿童个人信息
-// @Description 获取儿童的个人信息，包括照片、姓名、年龄、性别、诊断结果、已疗愈天数等
+// @Description 获取儿童的个人信息，包括照片、姓名、年龄、性别、诊断结果、已疗愈天数、连续记录天数、里程碑和治疗目标进度等
 // @Tags 儿童档案
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param archive_id path string true "儿童档案ID"
-// @Success 200 {object} object{code=int,data=response.ChildArchiveResponse} "获取成功"
+// @Success 200 {object} object{code=int,data=response.ChildProfileResponse} "获取成功"
 // @Failure 400 {object} response.ErrorResponse "参数错误"
 // @Failure 401 {object} response.ErrorResponse "未认证"
 // @Failure 404 {object} response.ErrorResponse "档案不存在"
@@ -66,12 +73,99 @@ func (c *ChildArchiveController) GetChildProfile(ctx *gin.Context) {
 		return
 	}
 
+	// 刷新疗愈进度（已疗愈天数、连续记录天数和里程碑）
+	progress, err := c.progressService.RefreshProgress(targetArchive)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: http.StatusInternalServerError, Message: "获取疗愈进度失败: " + err.Error()})
+		return
+	}
+
+	// 计算治疗目标进度
+	goals, err := c.planService.GetGoalsWithProgress(targetArchive.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: http.StatusInternalServerError, Message: "获取治疗目标失败: " + err.Error()})
+		return
+	}
+
 	// 转换为响应格式
-	profileResponse := response.ToChildArchiveResponse(targetArchive)
+	profileResponse := response.ChildProfileResponse{
+		ChildArchiveResponse: response.ToChildArchiveResponse(targetArchive),
+		Progress:             progress,
+		Goals:                goals,
+	}
 
 	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": profileResponse})
 }
 
+// GetChildProgress 获取儿童疗愈进度
+// @Summary 获取儿童疗愈进度
+// @Description 根据治疗开始日期和日志记录计算已疗愈天数、连续记录天数和里程碑
+// @Tags 儿童档案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param archive_id path string true "儿童档案ID"
+// @Success 200 {object} object{code=int,data=service.ChildProgress} "获取成功"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "档案不存在"
+// @Router /api/child-archive/{archive_id}/progress [get]
+func (c *ChildArchiveController) GetChildProgress(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	progress, err := c.progressService.GetProgress(userID.(string), ctx.Param("archive_id"))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": progress})
+}
+
+// GetChildCalendar 获取儿童月度记录日历
+// @Summary 获取儿童月度记录日历
+// @Description 返回指定月份每天的日志、媒体和指标数量
+// @Tags 儿童档案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param archive_id path string true "儿童档案ID"
+// @Param month query string false "月份 (YYYY-MM)，默认为当前月份"
+// @Success 200 {object} object{code=int,data=service.ChildCalendar} "获取成功"
+// @Failure 400 {object} response.ErrorResponse "月份格式错误"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/child-archive/{archive_id}/calendar [get]
+func (c *ChildArchiveController) GetChildCalendar(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	month := time.Now()
+	if monthStr := ctx.Query("month"); monthStr != "" {
+		parsed, err := time.ParseInLocation("2006-01", monthStr, time.Local)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "月份格式错误，请使用 YYYY-MM 格式"})
+			return
+		}
+		month = parsed
+	}
+
+	calendar, err := c.progressService.GetCalendar(userID.(string), ctx.Param("archive_id"), month)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": calendar})
+}
+
 // GetChildArchives 获取用户的所有儿童档案列表
 // @Summary 获取儿童档案列表
 // @Description 获取当前用户的所有儿童档案列表
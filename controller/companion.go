@@ -0,0 +1,216 @@
+package controller
+
+import (
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CompanionController struct {
+	companionService *service.CompanionService
+	usageService     *service.UsageService
+}
+
+func NewCompanionController(companionService *service.CompanionService, usageService *service.UsageService) *CompanionController {
+	return &CompanionController{
+		companionService: companionService,
+		usageService:     usageService,
+	}
+}
+
+// StartConversation 开始与AI陪伴的对话
+// @Summary 开始陪伴对话
+// @Description 家长为自己的孩子开始一段与自己创建的AI陪伴的对话，孩子通过家长的设备聊天
+// @Tags AI陪伴对话
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body request.StartCompanionConversationRequest true "对话信息"
+// @Success 200 {object} object{code=int,data=model.CompanionConversation} "创建成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/companion-conversations [post]
+func (c *CompanionController) StartConversation(ctx *gin.Context) {
+	var req request.StartCompanionConversationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	conversation, err := c.companionService.StartConversation(userID.(string), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": conversation})
+}
+
+// ListConversations 获取孩子的陪伴对话
+// @Summary 陪伴对话列表
+// @Description 家长查看孩子与AI陪伴的对话，最近有消息的排在前面，包含记忆摘要和被过滤的消息数
+// @Tags AI陪伴对话
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_archive_id query string true "儿童档案ID"
+// @Param companion_id query string false "AI陪伴ID"
+// @Success 200 {object} object{code=int,data=[]model.CompanionConversation} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/companion-conversations [get]
+func (c *CompanionController) ListConversations(ctx *gin.Context) {
+	childArchiveID := ctx.Query("child_archive_id")
+	if childArchiveID == "" {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "缺少儿童档案ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	conversations, err := c.companionService.ListConversations(userID.(string), childArchiveID, ctx.Query("companion_id"))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": conversations})
+}
+
+// GetTranscript 获取陪伴对话记录
+// @Summary 陪伴对话记录
+// @Description 家长查看对话记录，包括孩子发送的全部原文和被过滤的消息，按时间升序分页返回
+// @Tags AI陪伴对话
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "对话ID"
+// @Param before_id query int false "只返回该消息之前的消息，用于加载更早的记录"
+// @Param limit query int false "每页消息数，默认50，最多200"
+// @Success 200 {object} object{code=int,data=service.CompanionTranscript} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "对话不存在"
+// @Router /api/companion-conversations/{id} [get]
+func (c *CompanionController) GetTranscript(ctx *gin.Context) {
+	conversationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "对话ID格式错误"})
+		return
+	}
+	beforeID, _ := strconv.ParseUint(ctx.Query("before_id"), 10, 32)
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	transcript, err := c.companionService.GetTranscript(userID.(string), uint(conversationID), uint(beforeID), limit)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": transcript})
+}
+
+// SendMessage 向AI陪伴发送消息
+// @Summary 与AI陪伴聊天
+// @Description 孩子通过家长的设备向AI陪伴发送消息并获得回复。回复按陪伴的性格设定和孩子的年龄、诊断调整说话方式；消息和回复都经过年龄分级的内容过滤，孩子提到自我伤害等需要关注的内容时通知家长。with_audio 为 true 时回复附带以陪伴语音类型合成的语音地址
+// @Tags AI陪伴对话
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "对话ID"
+// @Param request body request.CompanionMessageRequest true "消息内容"
+// @Success 200 {object} object{code=int,data=service.CompanionExchange} "发送成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "对话不存在"
+// @Failure 429 {object} response.ErrorResponse "超出AI用量配额"
+// @Router /api/companion-conversations/{id}/messages [post]
+func (c *CompanionController) SendMessage(ctx *gin.Context) {
+	conversationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "对话ID格式错误"})
+		return
+	}
+
+	var req request.CompanionMessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+	if !checkAIQuota(ctx, c.usageService, userID.(string)) {
+		return
+	}
+
+	exchange, err := c.companionService.SendMessage(ctx.Request.Context(), userID.(string), uint(conversationID), req.Content, req.WithAudio)
+	if err != nil {
+		setRetryAfter(ctx, err)
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": exchange})
+}
+
+// SynthesizeMessage 为陪伴回复合成语音
+// @Summary 合成陪伴回复语音
+// @Description 以AI陪伴的语音类型为对话中已有的一条陪伴回复合成语音，相同内容和音色的语音只合成一次并缓存在对象存储中
+// @Tags AI陪伴对话
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "对话ID"
+// @Param message_id path int true "消息ID"
+// @Success 200 {object} object{code=int,data=model.CompanionMessage} "合成成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "对话不存在"
+// @Router /api/companion-conversations/{id}/messages/{message_id}/audio [post]
+func (c *CompanionController) SynthesizeMessage(ctx *gin.Context) {
+	conversationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "对话ID格式错误"})
+		return
+	}
+	messageID, err := strconv.ParseUint(ctx.Param("message_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "消息ID格式错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	message, err := c.companionService.SynthesizeMessage(ctx.Request.Context(), userID.(string), uint(conversationID), uint(messageID))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": message})
+}
@@ -0,0 +1,320 @@
+package controller
+
+import (
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConsultationController struct {
+	consultationService *service.ConsultationService
+	usageService        *service.UsageService
+}
+
+func NewConsultationController(consultationService *service.ConsultationService, usageService *service.UsageService) *ConsultationController {
+	return &ConsultationController{
+		consultationService: consultationService,
+		usageService:        usageService,
+	}
+}
+
+// StartConsultation 开始咨询
+// @Summary 开始虚拟疗愈导师咨询
+// @Description 家长就自己的孩子开始与自己创建的虚拟疗愈导师的咨询会话
+// @Tags 虚拟疗愈导师咨询
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body request.StartConsultationRequest true "咨询信息"
+// @Success 200 {object} object{code=int,data=model.ConsultationThread} "创建成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/consultations [post]
+func (c *ConsultationController) StartConsultation(ctx *gin.Context) {
+	var req request.StartConsultationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	thread, err := c.consultationService.StartConsultation(userID.(string), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": thread})
+}
+
+// ListConsultations 获取孩子的咨询会话
+// @Summary 咨询会话列表
+// @Description 家长查看孩子的咨询会话，最近有消息的排在前面
+// @Tags 虚拟疗愈导师咨询
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_archive_id query string true "儿童档案ID"
+// @Success 200 {object} object{code=int,data=[]model.ConsultationThread} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/consultations [get]
+func (c *ConsultationController) ListConsultations(ctx *gin.Context) {
+	childArchiveID := ctx.Query("child_archive_id")
+	if childArchiveID == "" {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "缺少儿童档案ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	threads, err := c.consultationService.ListConsultations(userID.(string), childArchiveID)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": threads})
+}
+
+// GetConsultation 获取咨询记录
+// @Summary 咨询记录
+// @Description 查看咨询会话的全部消息（虚拟疗愈导师的回复附带引用的资料）和转介申请。家长、接手的康复师以及可以接手该会话转介申请的认证康复师可以查看
+// @Tags 虚拟疗愈导师咨询
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "咨询会话ID"
+// @Success 200 {object} object{code=int,data=service.ConsultationDetail} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "咨询会话不存在"
+// @Router /api/consultations/{id} [get]
+func (c *ConsultationController) GetConsultation(ctx *gin.Context) {
+	threadID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "咨询会话ID格式错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	detail, err := c.consultationService.GetConsultation(userID.(string), uint(threadID))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": detail})
+}
+
+// SendMessage 在咨询会话中发送消息
+// @Summary 发送咨询消息
+// @Description 家长提问时，虚拟疗愈导师检索孩子的档案、近期疗愈记录和最新报告，生成带 [编号] 引用标注的回复，引用的资料在回复的 sources 中返回。会话已由认证康复师接手时只保存消息并通知对方，接手的康复师也通过该接口回复家长
+// @Tags 虚拟疗愈导师咨询
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "咨询会话ID"
+// @Param request body request.ConsultationMessageRequest true "消息内容"
+// @Success 200 {object} object{code=int,data=service.ConsultationExchange} "发送成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "咨询会话不存在"
+// @Failure 429 {object} response.ErrorResponse "超出AI用量配额"
+// @Router /api/consultations/{id}/messages [post]
+func (c *ConsultationController) SendMessage(ctx *gin.Context) {
+	threadID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "咨询会话ID格式错误"})
+		return
+	}
+
+	var req request.ConsultationMessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+	// 只有由虚拟疗愈导师回复的消息才调用模型，需要检查配额
+	if c.consultationService.AwaitsAIReply(userID.(string), uint(threadID)) && !checkAIQuota(ctx, c.usageService, userID.(string)) {
+		return
+	}
+
+	exchange, err := c.consultationService.SendMessage(ctx.Request.Context(), userID.(string), uint(threadID), req.Content)
+	if err != nil {
+		setRetryAfter(ctx, err)
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": exchange})
+}
+
+// Escalate 转给认证康复师
+// @Summary 申请转介认证康复师
+// @Description 家长把咨询转给认证康复师。指定康复师时只有该康复师可以接手并会收到通知，否则任一认证康复师都可以接手。每个会话同时只能有一个进行中的转介申请
+// @Tags 虚拟疗愈导师咨询
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "咨询会话ID"
+// @Param request body request.EscalateConsultationRequest true "转介信息"
+// @Success 200 {object} object{code=int,data=model.ConsultationEscalation} "申请成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误或已有进行中的转介申请"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "咨询会话不存在"
+// @Router /api/consultations/{id}/escalations [post]
+func (c *ConsultationController) Escalate(ctx *gin.Context) {
+	threadID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "咨询会话ID格式错误"})
+		return
+	}
+
+	var req request.EscalateConsultationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	escalation, err := c.consultationService.Escalate(userID.(string), uint(threadID), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": escalation})
+}
+
+// ListEscalations 获取转介申请
+// @Summary 转介申请列表
+// @Description 认证康复师查看可以接手的申请（未指定康复师且等待接手）、指定给自己的申请和自己接手的申请。等待接手的按申请先后排列，其余按时间倒序
+// @Tags 虚拟疗愈导师咨询
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "状态：pending, accepted, resolved, cancelled"
+// @Success 200 {object} object{code=int,data=[]model.ConsultationEscalation} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/consultation-escalations [get]
+func (c *ConsultationController) ListEscalations(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	escalations, err := c.consultationService.ListEscalations(userID.(string), ctx.Query("status"))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": escalations})
+}
+
+// AcceptEscalation 接手转介申请
+// @Summary 接手转介申请
+// @Description 认证康复师接手转介申请，之后家长在该会话中的消息由康复师回复，不再调用虚拟疗愈导师
+// @Tags 虚拟疗愈导师咨询
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "转介申请ID"
+// @Success 200 {object} object{code=int,data=model.ConsultationEscalation} "接手成功"
+// @Failure 400 {object} response.ErrorResponse "申请已被处理"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "转介申请不存在"
+// @Router /api/consultation-escalations/{id}/accept [post]
+func (c *ConsultationController) AcceptEscalation(ctx *gin.Context) {
+	escalationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "转介申请ID格式错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	escalation, err := c.consultationService.AcceptEscalation(userID.(string), uint(escalationID))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": escalation})
+}
+
+// CloseEscalation 结束转介
+// @Summary 结束转介
+// @Description 家长撤回等待接手的申请，或家长、接手的康复师结束已接手的转介。结束后会话恢复由虚拟疗愈导师回复
+// @Tags 虚拟疗愈导师咨询
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "转介申请ID"
+// @Param request body request.CloseEscalationRequest false "结束说明"
+// @Success 200 {object} object{code=int,data=model.ConsultationEscalation} "操作成功"
+// @Failure 400 {object} response.ErrorResponse "申请已被处理"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "转介申请不存在"
+// @Router /api/consultation-escalations/{id}/close [post]
+func (c *ConsultationController) CloseEscalation(ctx *gin.Context) {
+	escalationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "转介申请ID格式错误"})
+		return
+	}
+
+	var req request.CloseEscalationRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+			return
+		}
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	escalation, err := c.consultationService.CloseEscalation(userID.(string), uint(escalationID), req.Note)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": escalation})
+}
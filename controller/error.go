@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"errors"
+	"math"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serviceErrorStatus 将业务层的错误映射为HTTP状态码，未归类的错误（数据库、外部服务等）视为服务端错误
+func serviceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidRequest), errors.Is(err, service.ErrUnsupportedLanguage):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, service.ErrReportTypeNotFound),
+		errors.Is(err, service.ErrChildNotFound), errors.Is(err, service.ErrShareNotFound),
+		errors.Is(err, service.ErrSafetyReviewNotFound), errors.Is(err, service.ErrConversationNotFound),
+		errors.Is(err, service.ErrConsultationNotFound), errors.Is(err, service.ErrEscalationNotFound),
+		errors.Is(err, service.ErrHealingLogNotFound), errors.Is(err, service.ErrTherapistGrantNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrEscalationClosed), errors.Is(err, service.ErrSafetyReviewClosed),
+		errors.Is(err, service.ErrReportJobFinished):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrShareExpired):
+		return http.StatusGone
+	case errors.Is(err, service.ErrShareCodeRequired):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrShareCodeInvalid):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrQuotaExceeded), errors.Is(err, service.ErrLLMRateLimited), errors.Is(err, service.ErrShareLocked):
+		return http.StatusTooManyRequests
+	case errors.Is(err, service.ErrLLMUnavailable), errors.Is(err, service.ErrLLMCircuitOpen):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, service.ErrLLMTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, service.ErrLLMAuth), errors.Is(err, service.ErrLLMBadRequest), errors.Is(err, service.ErrLLMInvalidResponse):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+// setRetryAfter 大模型调用错误带有建议的重试时间时设置 Retry-After 响应头
+func setRetryAfter(ctx *gin.Context, err error) {
+	if retryAfter := service.LLMRetryAfter(err); retryAfter > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"fmt"
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportController struct {
+	exportService *service.ExportService
+}
+
+func NewExportController(exportService *service.ExportService) *ExportController {
+	return &ExportController{
+		exportService: exportService,
+	}
+}
+
+// ExportReport 导出AI报告
+// @Summary 导出AI报告
+// @Description 将AI报告导出为 PDF 或 DOCX，带导出人所属机构的信头，中文字体内嵌在文件中
+// @Tags 文档导出
+// @Produce application/pdf
+// @Produce application/vnd.openxmlformats-officedocument.wordprocessingml.document
+// @Security BearerAuth
+// @Param id path int true "报告ID"
+// @Param format query string false "导出格式 pdf 或 docx，默认 pdf"
+// @Success 200 {file} file "导出的文件"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/exports/reports/{id} [get]
+func (c *ExportController) ExportReport(ctx *gin.Context) {
+	reportID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "报告ID格式错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	file, err := c.exportService.ExportReport(userID.(string), uint(reportID), ctx.DefaultQuery("format", service.ExportFormatPDF))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	sendExportFile(ctx, file, fmt.Sprintf("report-%d", reportID))
+}
+
+// ExportChildDossier 导出儿童康复档案
+// @Summary 导出儿童康复档案
+// @Description 将儿童基本信息、治疗目标进度、指标趋势图、疗愈日志和最近的AI报告导出为 PDF 或 DOCX
+// @Tags 文档导出
+// @Produce application/pdf
+// @Produce application/vnd.openxmlformats-officedocument.wordprocessingml.document
+// @Security BearerAuth
+// @Param child_id path string true "儿童档案ID"
+// @Param format query string false "导出格式 pdf 或 docx，默认 pdf"
+// @Param start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param end_date query string false "结束日期 (YYYY-MM-DD)，包含当天"
+// @Param log_ids query string false "指定导出的日志ID，逗号分隔，为空时导出最近的日志"
+// @Param log_limit query int false "未指定日志时导出的日志条数，默认10，最多50"
+// @Param report_limit query int false "导出的AI报告份数，默认3，最多10"
+// @Success 200 {file} file "导出的文件"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "儿童档案不存在"
+// @Router /api/exports/children/{child_id} [get]
+func (c *ExportController) ExportChildDossier(ctx *gin.Context) {
+	startDate, endDate, err := parseReportDateRange(ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	if endDate != nil {
+		// 结束日期包含当天
+		end := endDate.AddDate(0, 0, 1)
+		endDate = &end
+	}
+	options := service.DossierOptions{Start: startDate, End: endDate}
+	for _, value := range strings.Split(ctx.Query("log_ids"), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		logID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "日志ID格式错误"})
+			return
+		}
+		options.LogIDs = append(options.LogIDs, uint(logID))
+	}
+	options.LogLimit, _ = strconv.Atoi(ctx.Query("log_limit"))
+	options.ReportLimit, _ = strconv.Atoi(ctx.Query("report_limit"))
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	childID := ctx.Param("child_id")
+	file, err := c.exportService.ExportChildDossier(userID.(string), childID, ctx.DefaultQuery("format", service.ExportFormatPDF), options)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	sendExportFile(ctx, file, "dossier-"+childID)
+}
+
+// GetBranding 获取机构导出信头
+// @Summary 获取机构导出信头
+// @Description 获取当前用户所属机构的导出文档信头设置，管理员需指定机构ID
+// @Tags 文档导出
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param institution_id query string false "机构ID（仅管理员）"
+// @Success 200 {object} object{code=int,data=model.InstitutionBranding} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/exports/branding [get]
+func (c *ExportController) GetBranding(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	branding, err := c.exportService.GetBranding(userID.(string), ctx.Query("institution_id"))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": branding})
+}
+
+// UpdateBranding 更新机构导出信头
+// @Summary 更新机构导出信头
+// @Description 设置导出文档的机构名称、联系方式、页脚、主题色和徽标（机构账号或管理员）
+// @Tags 文档导出
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body request.UpdateBrandingRequest true "信头设置"
+// @Success 200 {object} object{code=int,data=model.InstitutionBranding} "更新成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/exports/branding [put]
+func (c *ExportController) UpdateBranding(ctx *gin.Context) {
+	var req request.UpdateBrandingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	branding, err := c.exportService.UpdateBranding(userID.(string), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": branding})
+}
+
+// sendExportFile 以附件形式返回导出的文件，中文文件名通过 filename* 传递，fallback 为 ASCII 文件名
+func sendExportFile(ctx *gin.Context, file *service.ExportFile, fallbackName string) {
+	extension := file.FileName[strings.LastIndex(file.FileName, ".")+1:]
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"; filename*=UTF-8''%s`,
+		fallbackName, extension, url.PathEscape(file.FileName)))
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, file.ContentType, file.Data)
+}
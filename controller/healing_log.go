@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"melody_cure/api/request"
 	"melody_cure/api/response"
 	"melody_cure/model"
 	"melody_cure/service"
@@ -13,10 +14,14 @@ import (
 
 type HealingLogController struct {
 	healingLogService *service.HealingLogService
+	comparisonService *service.HealingComparisonService
 }
 
-func NewHealingLogController(healingLogService *service.HealingLogService) *HealingLogController {
-	return &HealingLogController{healingLogService: healingLogService}
+func NewHealingLogController(healingLogService *service.HealingLogService, comparisonService *service.HealingComparisonService) *HealingLogController {
+	return &HealingLogController{
+		healingLogService: healingLogService,
+		comparisonService: comparisonService,
+	}
 }
 
 // CreateHealingLog 创建疗愈日志
@@ -30,7 +35,8 @@ func NewHealingLogController(healingLogService *service.HealingLogService) *Heal
 // @Success 200 {object} response.SuccessResponse "创建成功"
 // @Failure 400 {object} response.ErrorResponse "参数错误"
 // @Failure 401 {object} response.ErrorResponse "未认证"
-// @Failure 500 {object} response.ErrorResponse "创建失败"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "儿童档案不存在"
 // @Router /api/healing-log [post]
 func (c *HealingLogController) CreateHealingLog(ctx *gin.Context) {
 	var log model.HealingLog
@@ -45,10 +51,9 @@ func (c *HealingLogController) CreateHealingLog(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
 		return
 	}
-	log.UserID = userID.(uint)
 
-	if err := c.healingLogService.CreateHealingLog(&log); err != nil {
-		ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: http.StatusInternalServerError, Message: "创建失败: " + err.Error()})
+	if err := c.healingLogService.CreateHealingLog(userID.(string), &log); err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
 		return
 	}
 
@@ -156,6 +161,8 @@ func (c *HealingLogController) GetHealingLogByID(ctx *gin.Context) {
 // @Success 200 {object} response.SuccessResponse "删除成功"
 // @Failure 400 {object} response.ErrorResponse "无效的日志ID"
 // @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "日志不存在"
 // @Failure 500 {object} response.ErrorResponse "删除失败"
 // @Router /api/healing-log/{log_id} [delete]
 func (c *HealingLogController) DeleteHealingLog(ctx *gin.Context) {
@@ -166,10 +173,146 @@ func (c *HealingLogController) DeleteHealingLog(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.healingLogService.DeleteHealingLog(uint(logID)); err != nil {
-		ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: http.StatusInternalServerError, Message: "删除失败: " + err.Error()})
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.healingLogService.DeleteHealingLog(userID.(string), uint(logID)); err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, response.SuccessResponse{Code: http.StatusOK, Message: "删除成功"})
-}
\ No newline at end of file
+}
+
+// UpdateHealingLog 编辑疗愈日志
+// @Summary 编辑疗愈日志
+// @Description 修改疗愈日志的文字内容，同时更新该日志在语义索引中的向量
+// @Tags 疗愈日志
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param log_id path int true "日志ID"
+// @Param request body request.UpdateHealingLogRequest true "日志内容"
+// @Success 200 {object} object{code=int,data=model.HealingLog} "更新成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "日志不存在"
+// @Router /api/healing-log/{log_id} [put]
+func (c *HealingLogController) UpdateHealingLog(ctx *gin.Context) {
+	logID, err := strconv.ParseUint(ctx.Param("log_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的日志ID"})
+		return
+	}
+
+	var req request.UpdateHealingLogRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	log, err := c.healingLogService.UpdateHealingLog(userID.(string), uint(logID), req.Content)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": log})
+}
+
+// MarkPhase 标记疗愈日志的对比阶段
+// @Summary 标记疗愈日志的对比阶段
+// @Description 将日志或其中指定的媒体标记为某项技能的基线（疗愈前）或跟进（疗愈后）记录，阶段为空表示取消标记
+// @Tags 疗愈日志
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param log_id path int true "日志ID"
+// @Param request body request.MarkLogPhaseRequest true "阶段标记"
+// @Success 200 {object} response.SuccessResponse "标记成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "日志不存在"
+// @Router /api/healing-log/{log_id}/phase [put]
+func (c *HealingLogController) MarkPhase(ctx *gin.Context) {
+	logID, err := strconv.ParseUint(ctx.Param("log_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的日志ID"})
+		return
+	}
+
+	var req request.MarkLogPhaseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.comparisonService.MarkPhase(userID.(string), uint(logID), &req); err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.SuccessResponse{Code: http.StatusOK, Message: "标记成功"})
+}
+
+// GetComparison 获取疗愈前后对比
+// @Summary 获取疗愈前后对比
+// @Description 返回某项技能在基线和跟进两个阶段的配对记录、指标变化和并排媒体
+// @Tags 疗愈日志
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_id path string true "儿童档案ID"
+// @Param skill query string false "对比的技能"
+// @Param baseline_start query string false "基线开始日期 (YYYY-MM-DD)"
+// @Param baseline_end query string false "基线结束日期 (YYYY-MM-DD)"
+// @Param follow_up_start query string false "跟进开始日期 (YYYY-MM-DD)"
+// @Param follow_up_end query string false "跟进结束日期 (YYYY-MM-DD)"
+// @Success 200 {object} object{code=int,data=service.HealingComparison} "获取成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/healing-log/child/{child_id}/comparison [get]
+func (c *HealingLogController) GetComparison(ctx *gin.Context) {
+	var req request.ComparisonRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+	query, err := service.ParseComparisonRequest(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	comparison, err := c.comparisonService.Compare(userID.(string), ctx.Param("child_id"), query)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": comparison})
+}
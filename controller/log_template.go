@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LogTemplateController struct {
+	logTemplateService *service.LogTemplateService
+}
+
+func NewLogTemplateController(logTemplateService *service.LogTemplateService) *LogTemplateController {
+	return &LogTemplateController{logTemplateService: logTemplateService}
+}
+
+// ListTemplates 获取日志模板库
+// @Summary 获取日志模板库
+// @Description 获取康复师编写的日志模板列表，可按诊断关键词筛选
+// @Tags 日志模板
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param diagnosis query string false "诊断关键词"
+// @Param include_inactive query bool false "是否包含已停用模板"
+// @Success 200 {object} object{code=int,data=[]model.LogTemplate} "获取成功"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 500 {object} response.ErrorResponse "获取失败"
+// @Router /api/log-templates [get]
+func (c *LogTemplateController) ListTemplates(ctx *gin.Context) {
+	onlyActive := ctx.Query("include_inactive") != "true"
+	templates, err := c.logTemplateService.ListTemplates(ctx.Query("diagnosis"), onlyActive)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: http.StatusInternalServerError, Message: "获取失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": templates})
+}
+
+// GetTemplate 获取日志模板详情
+// @Summary 获取日志模板详情
+// @Description 获取单个日志模板的问题、清单和量表定义
+// @Tags 日志模板
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "模板ID"
+// @Success 200 {object} object{code=int,data=model.LogTemplate} "获取成功"
+// @Failure 400 {object} response.ErrorResponse "无效的模板ID"
+// @Failure 404 {object} response.ErrorResponse "模板不存在"
+// @Router /api/log-templates/{id} [get]
+func (c *LogTemplateController) GetTemplate(ctx *gin.Context) {
+	templateID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的模板ID"})
+		return
+	}
+
+	template, err := c.logTemplateService.GetTemplate(uint(templateID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, response.ErrorResponse{Code: http.StatusNotFound, Message: "模板不存在"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": template})
+}
+
+// CreateTemplate 创建日志模板
+// @Summary 创建日志模板
+// @Description 管理员或已认证康复师创建日志模板，可设置适用的诊断关键词
+// @Tags 日志模板
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body request.LogTemplateRequest true "模板信息"
+// @Success 200 {object} object{code=int,data=model.LogTemplate} "创建成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/log-templates [post]
+func (c *LogTemplateController) CreateTemplate(ctx *gin.Context) {
+	var req request.LogTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	template, err := c.logTemplateService.CreateTemplate(userID.(string), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": template})
+}
+
+// UpdateTemplate 更新日志模板
+// @Summary 更新日志模板
+// @Description 管理员或已认证康复师更新日志模板
+// @Tags 日志模板
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "模板ID"
+// @Param request body request.LogTemplateRequest true "模板信息"
+// @Success 200 {object} object{code=int,data=model.LogTemplate} "更新成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/log-templates/{id} [put]
+func (c *LogTemplateController) UpdateTemplate(ctx *gin.Context) {
+	templateID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的模板ID"})
+		return
+	}
+
+	var req request.LogTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	template, err := c.logTemplateService.UpdateTemplate(userID.(string), uint(templateID), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": template})
+}
+
+// DeleteTemplate 删除日志模板
+// @Summary 删除日志模板
+// @Description 管理员或已认证康复师删除日志模板，同时移除相关的分配记录
+// @Tags 日志模板
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "模板ID"
+// @Success 200 {object} response.SuccessResponse "删除成功"
+// @Failure 400 {object} response.ErrorResponse "无效的模板ID"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/log-templates/{id} [delete]
+func (c *LogTemplateController) DeleteTemplate(ctx *gin.Context) {
+	templateID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的模板ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.logTemplateService.DeleteTemplate(userID.(string), uint(templateID)); err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: "删除失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.SuccessResponse{Code: http.StatusOK, Message: "删除成功"})
+}
+
+// GetChildTemplates 获取儿童可用的日志模板
+// @Summary 获取儿童可用的日志模板
+// @Description 返回手动分配给儿童的模板以及根据诊断结果自动匹配的模板
+// @Tags 日志模板
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_id path string true "儿童档案ID"
+// @Success 200 {object} object{code=int,data=[]model.ChildLogTemplate} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "档案不存在"
+// @Router /api/log-templates/child/{child_id} [get]
+func (c *LogTemplateController) GetChildTemplates(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	templates, err := c.logTemplateService.GetTemplatesForChild(userID.(string), ctx.Param("child_id"))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": templates})
+}
+
+// AssignTemplate 为儿童分配日志模板
+// @Summary 为儿童分配日志模板
+// @Description 手动为儿童分配一个日志模板
+// @Tags 日志模板
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_id path string true "儿童档案ID"
+// @Param request body request.AssignLogTemplateRequest true "模板ID"
+// @Success 200 {object} object{code=int,data=model.ChildLogTemplate} "分配成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/log-templates/child/{child_id} [post]
+func (c *LogTemplateController) AssignTemplate(ctx *gin.Context) {
+	var req request.AssignLogTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	assignment, err := c.logTemplateService.AssignTemplate(userID.(string), ctx.Param("child_id"), req.TemplateID)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": assignment})
+}
+
+// UnassignTemplate 取消儿童的日志模板分配
+// @Summary 取消儿童的日志模板分配
+// @Description 移除手动分配给儿童的日志模板
+// @Tags 日志模板
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_id path string true "儿童档案ID"
+// @Param template_id path int true "模板ID"
+// @Success 200 {object} response.SuccessResponse "取消成功"
+// @Failure 400 {object} response.ErrorResponse "无效的模板ID"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/log-templates/child/{child_id}/{template_id} [delete]
+func (c *LogTemplateController) UnassignTemplate(ctx *gin.Context) {
+	templateID, err := strconv.ParseUint(ctx.Param("template_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的模板ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.logTemplateService.UnassignTemplate(userID.(string), ctx.Param("child_id"), uint(templateID)); err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.SuccessResponse{Code: http.StatusOK, Message: "取消成功"})
+}
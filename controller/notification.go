@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationController(notificationService *service.NotificationService) *NotificationController {
+	return &NotificationController{notificationService: notificationService}
+}
+
+// GetNotifications 获取通知列表
+// @Summary 获取通知列表
+// @Description 获取当前用户的站内通知（里程碑、提醒等）及未读数量
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param unread_only query bool false "是否只返回未读通知"
+// @Success 200 {object} object{code=int,data=object{notifications=[]model.Notification,unread=int}} "获取成功"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 500 {object} response.ErrorResponse "获取失败"
+// @Router /api/notifications [get]
+func (c *NotificationController) GetNotifications(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	notifications, unread, err := c.notificationService.GetNotifications(userID.(string), ctx.Query("unread_only") == "true")
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: http.StatusInternalServerError, Message: "获取失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": gin.H{"notifications": notifications, "unread": unread}})
+}
+
+// MarkAsRead 标记通知为已读
+// @Summary 标记通知为已读
+// @Description 将指定通知标记为已读
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "通知ID"
+// @Success 200 {object} response.SuccessResponse "操作成功"
+// @Failure 400 {object} response.ErrorResponse "无效的通知ID"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Router /api/notifications/{id}/read [put]
+func (c *NotificationController) MarkAsRead(ctx *gin.Context) {
+	notificationID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的通知ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.notificationService.MarkAsRead(userID.(string), uint(notificationID)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: http.StatusInternalServerError, Message: "操作失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.SuccessResponse{Code: http.StatusOK, Message: "操作成功"})
+}
+
+// MarkAllAsRead 标记全部通知为已读
+// @Summary 标记全部通知为已读
+// @Description 将当前用户的全部未读通知标记为已读
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.SuccessResponse "操作成功"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Router /api/notifications/read-all [put]
+func (c *NotificationController) MarkAllAsRead(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.notificationService.MarkAllAsRead(userID.(string)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, response.ErrorResponse{Code: http.StatusInternalServerError, Message: "操作失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.SuccessResponse{Code: http.StatusOK, Message: "操作成功"})
+}
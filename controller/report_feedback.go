@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportFeedbackController struct {
+	feedbackService *service.ReportFeedbackService
+}
+
+func NewReportFeedbackController(feedbackService *service.ReportFeedbackService) *ReportFeedbackController {
+	return &ReportFeedbackController{
+		feedbackService: feedbackService,
+	}
+}
+
+// SubmitFeedback 评价AI报告
+// @Summary 评价AI报告
+// @Description 获得儿童授权（家长授权或接手咨询转介）的认证康复师对报告当前版本的准确性、实用性和安全性评分（1-5），可对章节评分、标记幻觉内容、标记建议是否采纳。重复提交时更新自己的评价
+// @Tags 报告评价
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "报告ID"
+// @Param request body request.ReportFeedbackRequest true "评价内容"
+// @Success 200 {object} object{code=int,data=model.ReportFeedback} "评价成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "仅获得儿童授权的认证康复师可以评价"
+// @Failure 404 {object} response.ErrorResponse "报告不存在"
+// @Router /api/ai-reports/{id}/feedback [put]
+func (c *ReportFeedbackController) SubmitFeedback(ctx *gin.Context) {
+	reportID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "报告ID格式错误"})
+		return
+	}
+
+	var req request.ReportFeedbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	feedback, err := c.feedbackService.SubmitFeedback(userID.(string), uint(reportID), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": feedback})
+}
+
+// GetReportFeedback 获取AI报告的评价
+// @Summary 获取报告评价
+// @Description 获取报告的全部评价，以及报告中可评价的章节和建议（管理员和获得儿童授权的认证康复师）
+// @Tags 报告评价
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "报告ID"
+// @Success 200 {object} object{code=int,data=service.ReportFeedbackDetail} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "报告不存在"
+// @Router /api/ai-reports/{id}/feedback [get]
+func (c *ReportFeedbackController) GetReportFeedback(ctx *gin.Context) {
+	reportID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "报告ID格式错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	detail, err := c.feedbackService.GetReportFeedback(userID.(string), uint(reportID))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": detail})
+}
+
+// GetFeedbackAnalytics 按提示词模板版本和模型汇总报告评价
+// @Summary 报告评价汇总
+// @Description 按报告类型、提示词模板版本和模型汇总平均评分、幻觉标记率和建议采纳率，用于比较提示词修改前后的效果（仅管理员）
+// @Tags 报告评价
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "开始日期 (YYYY-MM-DD)"
+// @Param end_date query string false "结束日期 (YYYY-MM-DD)，包含当天"
+// @Param report_type query string false "报告类型"
+// @Param model query string false "模型"
+// @Success 200 {object} object{code=int,data=service.FeedbackAnalytics} "获取成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/report-feedback/analytics [get]
+func (c *ReportFeedbackController) GetFeedbackAnalytics(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	startDate, endDate, err := parseReportDateRange(ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	if endDate != nil {
+		// 结束日期包含当天
+		end := endDate.AddDate(0, 0, 1)
+		endDate = &end
+	}
+
+	analytics, err := c.feedbackService.GetFeedbackAnalytics(userID.(string), DAO.ReportFeedbackFilter{
+		ReportType: ctx.Query("report_type"),
+		Model:      ctx.Query("model"),
+		Start:      startDate,
+		End:        endDate,
+	})
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": analytics})
+}
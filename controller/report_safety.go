@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/model"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportSafetyController struct {
+	safetyService *service.SafetyService
+}
+
+func NewReportSafetyController(safetyService *service.SafetyService) *ReportSafetyController {
+	return &ReportSafetyController{
+		safetyService: safetyService,
+	}
+}
+
+// ListReviews 获取报告安全审核队列
+// @Summary 获取安全审核队列
+// @Description 获取触发安全审查、等待人工审核的AI报告。机构账号和所属的认证康复师查看本机构的报告，管理员可查看全部或指定机构
+// @Tags 报告安全审核
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "审核状态 pending, approved, rejected，默认 pending"
+// @Param child_archive_id query string false "儿童档案ID"
+// @Param institution_id query string false "机构ID（仅管理员）"
+// @Success 200 {object} object{code=int,data=[]model.ReportSafetyReview} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/safety-reviews [get]
+func (c *ReportSafetyController) ListReviews(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	reviews, err := c.safetyService.ListReviews(userID.(string), ctx.Query("institution_id"), ctx.DefaultQuery("status", "pending"), ctx.Query("child_archive_id"))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": reviews})
+}
+
+// GetReview 获取待审核报告
+// @Summary 获取待审核报告
+// @Description 获取待审核报告的完整内容和安全审查的命中记录
+// @Tags 报告安全审核
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "审核记录ID"
+// @Success 200 {object} object{code=int,data=model.ReportSafetyReview} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "审核记录不存在"
+// @Router /api/safety-reviews/{id} [get]
+func (c *ReportSafetyController) GetReview(ctx *gin.Context) {
+	reviewID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "审核记录ID格式错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	review, err := c.safetyService.GetReview(userID.(string), uint(reviewID))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": review})
+}
+
+// ApproveReview 审核通过
+// @Summary 审核通过
+// @Description 审核通过后报告保存为正式报告并通知发起人和家长，后台任务同时标记为成功
+// @Tags 报告安全审核
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "审核记录ID"
+// @Param request body request.SafetyReviewRequest false "审核意见"
+// @Success 200 {object} object{code=int,data=model.ReportSafetyReview} "审核成功"
+// @Failure 400 {object} response.ErrorResponse "该报告已审核"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "审核记录不存在"
+// @Router /api/safety-reviews/{id}/approve [post]
+func (c *ReportSafetyController) ApproveReview(ctx *gin.Context) {
+	c.review(ctx, c.safetyService.ApproveReview)
+}
+
+// RejectReview 审核不通过
+// @Summary 审核不通过
+// @Description 审核不通过时报告不保存，后台任务标记为失败并通知发起人和家长
+// @Tags 报告安全审核
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "审核记录ID"
+// @Param request body request.SafetyReviewRequest false "审核意见"
+// @Success 200 {object} object{code=int,data=model.ReportSafetyReview} "审核成功"
+// @Failure 400 {object} response.ErrorResponse "该报告已审核"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "审核记录不存在"
+// @Router /api/safety-reviews/{id}/reject [post]
+func (c *ReportSafetyController) RejectReview(ctx *gin.Context) {
+	c.review(ctx, c.safetyService.RejectReview)
+}
+
+// review 解析审核请求并执行审核操作，请求体可以为空
+func (c *ReportSafetyController) review(ctx *gin.Context, decide func(userID string, reviewID uint, note string) (*model.ReportSafetyReview, error)) {
+	reviewID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "审核记录ID格式错误"})
+		return
+	}
+
+	var req request.SafetyReviewRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+			return
+		}
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	review, err := decide(userID.(string), uint(reviewID), req.Note)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": review})
+}
@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportTypeController struct {
+	templateService *service.ReportTemplateService
+	aiReportService *service.AIReportService
+}
+
+func NewReportTypeController(templateService *service.ReportTemplateService, aiReportService *service.AIReportService) *ReportTypeController {
+	return &ReportTypeController{
+		templateService: templateService,
+		aiReportService: aiReportService,
+	}
+}
+
+// ListReportTypes 获取报告类型列表
+// @Summary 获取报告类型列表
+// @Description 获取可用于生成AI报告的报告类型，管理员传 all=true 时包含已停用的类型
+// @Tags 报告类型
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param all query bool false "是否包含已停用的类型（仅管理员）"
+// @Success 200 {object} object{code=int,data=[]model.ReportType} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/report-types [get]
+func (c *ReportTypeController) ListReportTypes(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	reportTypes, err := c.templateService.ListReportTypes(userID.(string), ctx.Query("all") == "true")
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": reportTypes})
+}
+
+// ListReportLanguages 获取支持的报告语言
+// @Summary 获取支持的报告语言
+// @Description 获取生成和翻译报告时可选的语言，没有专用模板的语言使用中文模板并要求模型以该语言输出
+// @Tags 报告类型
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{code=int,data=[]service.ReportLanguage} "获取成功"
+// @Router /api/report-types/languages [get]
+func (c *ReportTypeController) ListReportLanguages(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": service.ReportLanguages()})
+}
+
+// GetReportType 获取报告类型详情
+// @Summary 获取报告类型详情
+// @Description 获取报告类型及其所有提示词模板版本（仅管理员）
+// @Tags 报告类型
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "报告类型标识"
+// @Success 200 {object} object{code=int,data=service.ReportTypeDetail} "获取成功"
+// @Failure 400 {object} response.ErrorResponse "报告类型不存在"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/report-types/{key} [get]
+func (c *ReportTypeController) GetReportType(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	detail, err := c.templateService.GetReportType(userID.(string), ctx.Param("key"))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": detail})
+}
+
+// CreateReportType 创建报告类型
+// @Summary 创建报告类型
+// @Description 创建报告类型及其第一个提示词模板版本，模板为 Go text/template，可引用 system_role、child_profile、logs、metrics、comparison 等共用片段（仅管理员）
+// @Tags 报告类型
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body request.CreateReportTypeRequest true "报告类型信息"
+// @Success 200 {object} object{code=int,data=service.ReportTypeDetail} "创建成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误或模板无法渲染"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/report-types [post]
+func (c *ReportTypeController) CreateReportType(ctx *gin.Context) {
+	var req request.CreateReportTypeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	detail, err := c.templateService.CreateReportType(userID.(string), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": detail})
+}
+
+// UpdateReportType 更新报告类型
+// @Summary 更新报告类型
+// @Description 更新报告类型的名称、描述和启用状态，模板内容通过新增版本修改（仅管理员）
+// @Tags 报告类型
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "报告类型标识"
+// @Param request body request.UpdateReportTypeRequest true "报告类型信息"
+// @Success 200 {object} object{code=int,data=model.ReportType} "更新成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/report-types/{key} [put]
+func (c *ReportTypeController) UpdateReportType(ctx *gin.Context) {
+	var req request.UpdateReportTypeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	reportType, err := c.templateService.UpdateReportType(userID.(string), ctx.Param("key"), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": reportType})
+}
+
+// CreateTemplateVersion 新增提示词模板版本
+// @Summary 新增提示词模板版本
+// @Description 保存新的模板版本，已有版本不会被修改；language 为模板语言，为空时为中文；activate 为 true 时立即启用为该语言的当前版本（仅管理员）
+// @Tags 报告类型
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "报告类型标识"
+// @Param request body request.PromptTemplateRequest true "模板内容"
+// @Success 200 {object} object{code=int,data=model.PromptTemplate} "保存成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误或模板无法渲染"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/report-types/{key}/versions [post]
+func (c *ReportTypeController) CreateTemplateVersion(ctx *gin.Context) {
+	var req request.PromptTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	tpl, err := c.templateService.CreateTemplateVersion(userID.(string), ctx.Param("key"), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": tpl})
+}
+
+// ActivateTemplateVersion 启用提示词模板版本
+// @Summary 启用提示词模板版本
+// @Description 将指定版本设为报告类型在该版本语言下的当前版本，可用于回滚（仅管理员）
+// @Tags 报告类型
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "报告类型标识"
+// @Param version path int true "模板版本号"
+// @Success 200 {object} object{code=int,data=model.ReportType} "启用成功"
+// @Failure 400 {object} response.ErrorResponse "版本不存在"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/report-types/{key}/versions/{version}/activate [post]
+func (c *ReportTypeController) ActivateTemplateVersion(ctx *gin.Context) {
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "版本号格式错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	reportType, err := c.templateService.ActivateTemplateVersion(userID.(string), ctx.Param("key"), version)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": reportType})
+}
+
+// PreviewPrompt 预览提示词
+// @Summary 预览提示词
+// @Description 渲染草稿或指定版本的提示词模板，指定儿童档案时使用其真实日志和指标，否则使用示例数据（仅管理员）
+// @Tags 报告类型
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "报告类型标识"
+// @Param request body request.PreviewPromptRequest true "预览参数"
+// @Success 200 {object} object{code=int,data=service.PromptPreview} "渲染成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误或模板无法渲染"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/report-types/{key}/preview [post]
+func (c *ReportTypeController) PreviewPrompt(ctx *gin.Context) {
+	var req request.PreviewPromptRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+	startDate, endDate, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	preview, err := c.aiReportService.PreviewPrompt(ctx.Request.Context(), userID.(string), ctx.Param("key"), &req, startDate, endDate)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": preview})
+}
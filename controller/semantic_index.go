@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SemanticIndexController struct {
+	semanticIndexService *service.SemanticIndexService
+}
+
+func NewSemanticIndexController(semanticIndexService *service.SemanticIndexService) *SemanticIndexController {
+	return &SemanticIndexController{
+		semanticIndexService: semanticIndexService,
+	}
+}
+
+// FindSimilar 查找相似的过往资料
+// @Summary 查找相似的过往经历
+// @Description 在孩子的疗愈记录、报告和治疗目标中查找与一段描述或一条疗愈记录语义相近的资料，按相似度降序，同一资料只返回最相关的一段
+// @Tags 语义索引
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_id path string true "儿童档案ID"
+// @Param q query string false "描述当前情况的文字，与 log_id 至少提供一个"
+// @Param log_id query int false "以该疗愈记录为查询，结果中不含该记录"
+// @Param k query int false "返回条数 (1-50)，为空时使用配置的默认值"
+// @Param types query string false "资料类型，逗号分隔 (log, report, goal)，为空时检索全部"
+// @Success 200 {object} object{code=int,data=[]service.SemanticMatch} "获取成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "儿童档案或疗愈记录不存在"
+// @Router /api/semantic-index/child/{child_id}/similar [get]
+func (c *SemanticIndexController) FindSimilar(ctx *gin.Context) {
+	var req request.SimilarEpisodesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	matches, err := c.semanticIndexService.FindSimilar(ctx.Request.Context(), userID.(string), ctx.Param("child_id"), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": matches})
+}
+
+// Rebuild 同步孩子的语义索引
+// @Summary 同步语义索引
+// @Description 按内容哈希同步孩子全部疗愈记录、报告和治疗目标的索引，只重新向量化新增或变化的资料并移除已删除资料的索引。用于补建功能上线前的记录或更换向量化提供商后重建
+// @Tags 语义索引
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_id path string true "儿童档案ID"
+// @Success 200 {object} object{code=int,data=service.SemanticSyncResult} "同步成功"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "儿童档案不存在"
+// @Router /api/semantic-index/child/{child_id}/rebuild [post]
+func (c *SemanticIndexController) Rebuild(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	result, err := c.semanticIndexService.Rebuild(ctx.Request.Context(), userID.(string), ctx.Param("child_id"))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": result})
+}
@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShareController struct {
+	shareService *service.ShareService
+}
+
+func NewShareController(shareService *service.ShareService) *ShareController {
+	return &ShareController{
+		shareService: shareService,
+	}
+}
+
+// CreateShareLink 创建分享链接
+// @Summary 创建分享链接
+// @Description 为AI报告或儿童康复档案创建有过期时间、可撤销的只读分享链接，可设置访问码。报告分享当前版本，档案在创建时生成快照
+// @Tags 分享链接
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body request.CreateShareLinkRequest true "分享内容"
+// @Success 200 {object} object{code=int,data=service.ShareLinkView} "创建成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/shares [post]
+func (c *ShareController) CreateShareLink(ctx *gin.Context) {
+	var req request.CreateShareLinkRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+	startDate, endDate, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	if endDate != nil {
+		// 结束日期包含当天
+		end := endDate.AddDate(0, 0, 1)
+		endDate = &end
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	link, err := c.shareService.CreateShareLink(userID.(string), &req, service.DossierOptions{
+		Start:       startDate,
+		End:         endDate,
+		LogIDs:      req.LogIDs,
+		LogLimit:    req.LogLimit,
+		ReportLimit: req.ReportLimit,
+	})
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": link})
+}
+
+// GetShareLinks 获取我创建的分享链接
+// @Summary 获取分享链接列表
+// @Description 获取当前用户创建的分享链接及查看次数，默认只返回有效的链接
+// @Tags 分享链接
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_archive_id query string false "儿童档案ID"
+// @Param include_inactive query bool false "是否包含已过期和已撤销的链接"
+// @Success 200 {object} object{code=int,data=[]service.ShareLinkView} "获取成功"
+// @Router /api/shares [get]
+func (c *ShareController) GetShareLinks(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	includeInactive, _ := strconv.ParseBool(ctx.Query("include_inactive"))
+	links, err := c.shareService.GetShareLinks(userID.(string), ctx.Query("child_archive_id"), includeInactive)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": links})
+}
+
+// RevokeShareLink 撤销分享链接
+// @Summary 撤销分享链接
+// @Description 撤销分享链接，撤销后立即无法访问（创建人或管理员）
+// @Tags 分享链接
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "分享链接ID"
+// @Success 200 {object} object{code=int,data=service.ShareLinkView} "撤销成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "分享链接不存在"
+// @Router /api/shares/{id} [delete]
+func (c *ShareController) RevokeShareLink(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "分享链接ID格式错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	link, err := c.shareService.RevokeShareLink(userID.(string), uint(id))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": link})
+}
+
+// GetShareAccessLogs 获取分享链接的访问记录
+// @Summary 获取分享链接访问记录
+// @Description 获取分享链接最近200次访问，包括查看、下载、访问码错误和过期后的访问（创建人或管理员）
+// @Tags 分享链接
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "分享链接ID"
+// @Success 200 {object} object{code=int,data=[]model.ShareAccessLog} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "分享链接不存在"
+// @Router /api/shares/{id}/access-logs [get]
+func (c *ShareController) GetShareAccessLogs(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "分享链接ID格式错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	logs, err := c.shareService.GetShareAccessLogs(userID.(string), uint(id))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": logs})
+}
+
+// ViewSharedDocument 通过分享链接查看文档
+// @Summary 查看分享的文档
+// @Description 无需登录，通过分享令牌查看只读文档。设置了访问码的链接需在 X-Share-Code 请求头或 code 参数中提供访问码
+// @Tags 分享链接
+// @Produce json
+// @Param token path string true "分享令牌"
+// @Param X-Share-Code header string false "访问码"
+// @Param code query string false "访问码"
+// @Success 200 {object} object{code=int,data=service.SharedDocument} "获取成功"
+// @Failure 401 {object} response.ErrorResponse "需要访问码"
+// @Failure 403 {object} response.ErrorResponse "访问码错误"
+// @Failure 404 {object} response.ErrorResponse "分享链接不存在"
+// @Failure 410 {object} response.ErrorResponse "分享链接已过期或已被撤销"
+// @Failure 429 {object} response.ErrorResponse "访问码错误次数过多"
+// @Router /api/public/shares/{token} [get]
+func (c *ShareController) ViewSharedDocument(ctx *gin.Context) {
+	doc, err := c.shareService.ViewSharedDocument(ctx.Param("token"), shareAccess(ctx))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": doc})
+}
+
+// DownloadSharedDocument 通过分享链接下载文档
+// @Summary 下载分享的文档
+// @Description 无需登录，通过分享令牌下载 PDF 或 DOCX，信头使用分享人所属机构的设置
+// @Tags 分享链接
+// @Produce application/pdf
+// @Produce application/vnd.openxmlformats-officedocument.wordprocessingml.document
+// @Param token path string true "分享令牌"
+// @Param format query string false "导出格式 pdf 或 docx，默认 pdf"
+// @Param X-Share-Code header string false "访问码"
+// @Param code query string false "访问码"
+// @Success 200 {file} file "导出的文件"
+// @Failure 401 {object} response.ErrorResponse "需要访问码"
+// @Failure 403 {object} response.ErrorResponse "访问码错误"
+// @Failure 404 {object} response.ErrorResponse "分享链接不存在"
+// @Failure 410 {object} response.ErrorResponse "分享链接已过期或已被撤销"
+// @Router /api/public/shares/{token}/download [get]
+func (c *ShareController) DownloadSharedDocument(ctx *gin.Context) {
+	file, err := c.shareService.DownloadSharedDocument(ctx.Param("token"), ctx.DefaultQuery("format", service.ExportFormatPDF), shareAccess(ctx))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	sendExportFile(ctx, file, "shared-document")
+}
+
+// shareAccess 访问码优先从请求头读取，避免出现在地址栏和访问日志中
+func shareAccess(ctx *gin.Context) service.ShareAccess {
+	code := ctx.GetHeader("X-Share-Code")
+	if code == "" {
+		code = ctx.Query("code")
+	}
+	return service.ShareAccess{Code: code, IP: ctx.ClientIP(), UserAgent: ctx.Request.UserAgent()}
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TherapistGrantController struct {
+	therapistGrantService *service.TherapistGrantService
+}
+
+func NewTherapistGrantController(therapistGrantService *service.TherapistGrantService) *TherapistGrantController {
+	return &TherapistGrantController{
+		therapistGrantService: therapistGrantService,
+	}
+}
+
+// GrantTherapist 授权康复师
+// @Summary 授权康复师访问孩子的资料
+// @Description 家长或管理员授权一位认证康复师访问孩子的疗愈记录、报告、导出和评价等资料，康复师会收到通知。已授权时返回已有的授权
+// @Tags 康复师授权
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body request.GrantTherapistRequest true "授权信息"
+// @Success 200 {object} object{code=int,data=model.ChildTherapistGrant} "授权成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误或康复师未认证"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "儿童档案不存在"
+// @Router /api/therapist-grants [post]
+func (c *TherapistGrantController) GrantTherapist(ctx *gin.Context) {
+	var req request.GrantTherapistRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	grant, err := c.therapistGrantService.GrantTherapist(userID.(string), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": grant})
+}
+
+// ListChildGrants 获取孩子的授权
+// @Summary 孩子的康复师授权列表
+// @Description 家长或管理员查看已授权访问孩子资料的康复师
+// @Tags 康复师授权
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_archive_id query string true "儿童档案ID"
+// @Success 200 {object} object{code=int,data=[]model.ChildTherapistGrant} "获取成功"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "儿童档案不存在"
+// @Router /api/therapist-grants [get]
+func (c *TherapistGrantController) ListChildGrants(ctx *gin.Context) {
+	childArchiveID := ctx.Query("child_archive_id")
+	if childArchiveID == "" {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "缺少儿童档案ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	grants, err := c.therapistGrantService.ListChildGrants(userID.(string), childArchiveID)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": grants})
+}
+
+// ListMyGrants 获取自己获得的授权
+// @Summary 康复师获得的授权
+// @Description 康复师查看授权自己访问资料的孩子。正在接手的咨询转介同样可以访问对应孩子的资料，不在此列出
+// @Tags 康复师授权
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{code=int,data=[]model.ChildTherapistGrant} "获取成功"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Router /api/therapist-grants/mine [get]
+func (c *TherapistGrantController) ListMyGrants(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	grants, err := c.therapistGrantService.ListMyGrants(userID.(string))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": grants})
+}
+
+// RevokeGrant 撤销授权
+// @Summary 撤销康复师授权
+// @Description 家长或管理员撤销授权，康复师也可以放弃自己获得的授权
+// @Tags 康复师授权
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "授权ID"
+// @Success 200 {object} response.SuccessResponse "撤销成功"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "授权不存在"
+// @Router /api/therapist-grants/{id} [delete]
+func (c *TherapistGrantController) RevokeGrant(ctx *gin.Context) {
+	grantID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "授权ID格式错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.therapistGrantService.RevokeGrant(userID.(string), uint(grantID)); err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.SuccessResponse{Code: http.StatusOK, Message: "撤销成功"})
+}
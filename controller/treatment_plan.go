@@ -0,0 +1,480 @@
+package controller
+
+import (
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TreatmentPlanController struct {
+	treatmentPlanService *service.TreatmentPlanService
+}
+
+func NewTreatmentPlanController(treatmentPlanService *service.TreatmentPlanService) *TreatmentPlanController {
+	return &TreatmentPlanController{treatmentPlanService: treatmentPlanService}
+}
+
+// CreatePlan 创建治疗方案
+// @Summary 创建治疗方案
+// @Description 为儿童创建结构化治疗方案，可同时创建语言、社交、运动、情绪等领域的目标
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body request.TreatmentPlanRequest true "方案信息"
+// @Success 200 {object} object{code=int,data=service.TreatmentPlanDetail} "创建成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/treatment-plans [post]
+func (c *TreatmentPlanController) CreatePlan(ctx *gin.Context) {
+	var req request.TreatmentPlanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	plan, err := c.treatmentPlanService.CreatePlan(userID.(string), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": plan})
+}
+
+// GetChildPlans 获取儿童的治疗方案
+// @Summary 获取儿童的治疗方案
+// @Description 获取儿童的所有治疗方案及各目标的进度
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_id path string true "儿童档案ID"
+// @Success 200 {object} object{code=int,data=[]service.TreatmentPlanDetail} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "档案不存在"
+// @Router /api/treatment-plans/child/{child_id} [get]
+func (c *TreatmentPlanController) GetChildPlans(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	plans, err := c.treatmentPlanService.ListPlansForChild(userID.(string), ctx.Param("child_id"))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": plans})
+}
+
+// GetPlan 获取治疗方案详情
+// @Summary 获取治疗方案详情
+// @Description 获取治疗方案及各目标的进度
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param plan_id path int true "方案ID"
+// @Success 200 {object} object{code=int,data=service.TreatmentPlanDetail} "获取成功"
+// @Failure 400 {object} response.ErrorResponse "无效的方案ID"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/treatment-plans/{plan_id} [get]
+func (c *TreatmentPlanController) GetPlan(ctx *gin.Context) {
+	planID, err := strconv.ParseUint(ctx.Param("plan_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的方案ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	plan, err := c.treatmentPlanService.GetPlan(userID.(string), uint(planID))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": plan})
+}
+
+// UpdatePlan 更新治疗方案
+// @Summary 更新治疗方案
+// @Description 更新治疗方案的标题、说明、状态和日期，目标请使用目标接口维护
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param plan_id path int true "方案ID"
+// @Param request body request.TreatmentPlanRequest true "方案信息"
+// @Success 200 {object} object{code=int,data=service.TreatmentPlanDetail} "更新成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/treatment-plans/{plan_id} [put]
+func (c *TreatmentPlanController) UpdatePlan(ctx *gin.Context) {
+	planID, err := strconv.ParseUint(ctx.Param("plan_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的方案ID"})
+		return
+	}
+
+	var req request.TreatmentPlanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	plan, err := c.treatmentPlanService.UpdatePlan(userID.(string), uint(planID), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": plan})
+}
+
+// DeletePlan 删除治疗方案
+// @Summary 删除治疗方案
+// @Description 删除治疗方案及其全部目标和关联记录
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param plan_id path int true "方案ID"
+// @Success 200 {object} response.SuccessResponse "删除成功"
+// @Failure 400 {object} response.ErrorResponse "无效的方案ID"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/treatment-plans/{plan_id} [delete]
+func (c *TreatmentPlanController) DeletePlan(ctx *gin.Context) {
+	planID, err := strconv.ParseUint(ctx.Param("plan_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的方案ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.treatmentPlanService.DeletePlan(userID.(string), uint(planID)); err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: "删除失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.SuccessResponse{Code: http.StatusOK, Message: "删除成功"})
+}
+
+// AddGoal 添加治疗目标
+// @Summary 添加治疗目标
+// @Description 为治疗方案添加目标，可设置达成标准、指标基线与目标值、目标练习次数和目标日期
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param plan_id path int true "方案ID"
+// @Param request body request.TreatmentGoalRequest true "目标信息"
+// @Success 200 {object} object{code=int,data=service.GoalWithProgress} "创建成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/treatment-plans/{plan_id}/goals [post]
+func (c *TreatmentPlanController) AddGoal(ctx *gin.Context) {
+	planID, err := strconv.ParseUint(ctx.Param("plan_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的方案ID"})
+		return
+	}
+
+	var req request.TreatmentGoalRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	goal, err := c.treatmentPlanService.AddGoal(userID.(string), uint(planID), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": goal})
+}
+
+// GetGoal 获取治疗目标详情
+// @Summary 获取治疗目标详情
+// @Description 获取目标进度以及关联的疗愈日志和游戏记录
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param goal_id path int true "目标ID"
+// @Success 200 {object} object{code=int,data=service.GoalDetail} "获取成功"
+// @Failure 400 {object} response.ErrorResponse "无效的目标ID"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/treatment-plans/goals/{goal_id} [get]
+func (c *TreatmentPlanController) GetGoal(ctx *gin.Context) {
+	goalID, err := strconv.ParseUint(ctx.Param("goal_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的目标ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	goal, err := c.treatmentPlanService.GetGoal(userID.(string), uint(goalID))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": goal})
+}
+
+// UpdateGoal 更新治疗目标
+// @Summary 更新治疗目标
+// @Description 更新目标的达成标准、日期和状态，标记为已达成时记录达成时间
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param goal_id path int true "目标ID"
+// @Param request body request.TreatmentGoalRequest true "目标信息"
+// @Success 200 {object} object{code=int,data=service.GoalWithProgress} "更新成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/treatment-plans/goals/{goal_id} [put]
+func (c *TreatmentPlanController) UpdateGoal(ctx *gin.Context) {
+	goalID, err := strconv.ParseUint(ctx.Param("goal_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的目标ID"})
+		return
+	}
+
+	var req request.TreatmentGoalRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	goal, err := c.treatmentPlanService.UpdateGoal(userID.(string), uint(goalID), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": goal})
+}
+
+// DeleteGoal 删除治疗目标
+// @Summary 删除治疗目标
+// @Description 删除目标及其关联记录
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param goal_id path int true "目标ID"
+// @Success 200 {object} response.SuccessResponse "删除成功"
+// @Failure 400 {object} response.ErrorResponse "无效的目标ID"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/treatment-plans/goals/{goal_id} [delete]
+func (c *TreatmentPlanController) DeleteGoal(ctx *gin.Context) {
+	goalID, err := strconv.ParseUint(ctx.Param("goal_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的目标ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.treatmentPlanService.DeleteGoal(userID.(string), uint(goalID)); err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: "删除失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.SuccessResponse{Code: http.StatusOK, Message: "删除成功"})
+}
+
+// LinkGoal 关联记录到目标
+// @Summary 关联记录到目标
+// @Description 将同一儿童的疗愈日志或游戏训练记录关联到目标
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param goal_id path int true "目标ID"
+// @Param request body request.GoalLinkRequest true "关联记录"
+// @Success 200 {object} response.SuccessResponse "关联成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/treatment-plans/goals/{goal_id}/links [post]
+func (c *TreatmentPlanController) LinkGoal(ctx *gin.Context) {
+	goalID, err := strconv.ParseUint(ctx.Param("goal_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的目标ID"})
+		return
+	}
+
+	var req request.GoalLinkRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.treatmentPlanService.LinkGoal(userID.(string), uint(goalID), &req); err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.SuccessResponse{Code: http.StatusOK, Message: "关联成功"})
+}
+
+// UnlinkGoal 取消目标关联
+// @Summary 取消目标关联
+// @Description 移除目标与疗愈日志或游戏记录的关联
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param goal_id path int true "目标ID"
+// @Param link_id path int true "关联ID"
+// @Success 200 {object} response.SuccessResponse "取消成功"
+// @Failure 400 {object} response.ErrorResponse "无效的ID"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/treatment-plans/goals/{goal_id}/links/{link_id} [delete]
+func (c *TreatmentPlanController) UnlinkGoal(ctx *gin.Context) {
+	goalID, err := strconv.ParseUint(ctx.Param("goal_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的目标ID"})
+		return
+	}
+	linkID, err := strconv.ParseUint(ctx.Param("link_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "无效的关联ID"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.treatmentPlanService.UnlinkGoal(userID.(string), uint(goalID), uint(linkID)); err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response.SuccessResponse{Code: http.StatusOK, Message: "取消成功"})
+}
+
+// CreateGameSession 记录游戏训练
+// @Summary 记录游戏训练
+// @Description 记录儿童的一次游戏训练，可同时关联治疗目标
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body request.GameSessionRequest true "游戏训练信息"
+// @Success 200 {object} object{code=int,data=model.GameSession} "记录成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/game-sessions [post]
+func (c *TreatmentPlanController) CreateGameSession(ctx *gin.Context) {
+	var req request.GameSessionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	session, err := c.treatmentPlanService.CreateGameSession(userID.(string), &req)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": session})
+}
+
+// GetChildGameSessions 获取儿童的游戏训练记录
+// @Summary 获取儿童的游戏训练记录
+// @Description 按时间倒序获取儿童的游戏训练记录
+// @Tags 治疗方案
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param child_id path string true "儿童档案ID"
+// @Success 200 {object} object{code=int,data=[]model.GameSession} "获取成功"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "档案不存在"
+// @Router /api/game-sessions/child/{child_id} [get]
+func (c *TreatmentPlanController) GetChildGameSessions(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	sessions, err := c.treatmentPlanService.GetGameSessions(userID.(string), ctx.Param("child_id"))
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": sessions})
+}
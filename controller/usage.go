@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"errors"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsageController struct {
+	usageService *service.UsageService
+}
+
+func NewUsageController(usageService *service.UsageService) *UsageController {
+	return &UsageController{
+		usageService: usageService,
+	}
+}
+
+// GetQuota 获取当前用户的AI用量配额
+// @Summary 获取AI用量配额
+// @Description 获取当前用户今日和本月的AI用量配额，同时受个人和机构配额限制时返回剩余较少的一项，不限额的周期不返回
+// @Tags AI用量
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{code=int,data=service.QuotaStatus} "获取成功"
+// @Router /api/usage/quota [get]
+func (c *UsageController) GetQuota(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	status, err := c.usageService.CheckQuota(userID.(string))
+	if err != nil && !errors.Is(err, service.ErrQuotaExceeded) {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	setQuotaHeaders(ctx, status)
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": status})
+}
+
+// GetUsageSummary 按模型汇总AI用量和估算费用
+// @Summary AI用量汇总
+// @Description 按提供商和模型汇总 token 用量，并按配置的单价估算费用（仅管理员）
+// @Tags AI用量
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "开始日期 (YYYY-MM-DD)，默认本月第一天"
+// @Param end_date query string false "结束日期 (YYYY-MM-DD)，包含当天"
+// @Param user_id query string false "用户ID"
+// @Param institution_id query string false "机构ID"
+// @Success 200 {object} object{code=int,data=service.UsageSummary} "获取成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/usage/summary [get]
+func (c *UsageController) GetUsageSummary(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	startDate, endDate, err := parseReportDateRange(ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	if startDate == nil {
+		now := time.Now()
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+		startDate = &monthStart
+	}
+	if endDate != nil {
+		// 结束日期包含当天
+		end := endDate.AddDate(0, 0, 1)
+		endDate = &end
+	}
+
+	summary, err := c.usageService.GetUsageSummary(userID.(string), DAO.AIUsageFilter{
+		UserID:        ctx.Query("user_id"),
+		InstitutionID: ctx.Query("institution_id"),
+		Start:         startDate,
+		End:           endDate,
+	})
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": summary})
+}
+
+// SetUserInstitution 设置用户所属机构
+// @Summary 设置用户所属机构
+// @Description 设置用户所属的机构，机构成员的AI用量计入机构配额（仅管理员）
+// @Tags AI用量
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path string true "用户ID"
+// @Param request body request.SetUserInstitutionRequest true "机构信息"
+// @Success 200 {object} object{code=int,message=string} "设置成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Router /api/usage/users/{user_id}/institution [put]
+func (c *UsageController) SetUserInstitution(ctx *gin.Context) {
+	var req request.SetUserInstitutionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	if err := c.usageService.SetUserInstitution(userID.(string), ctx.Param("user_id"), req.InstitutionID); err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "message": "设置成功"})
+}
+
+// checkAIQuota 调用大模型前校验用量配额，在响应头中返回剩余配额，超出时返回 429
+func checkAIQuota(ctx *gin.Context, usageService *service.UsageService, userID string) bool {
+	status, err := usageService.CheckQuota(userID)
+	if status != nil {
+		setQuotaHeaders(ctx, status)
+	}
+	if err == nil {
+		return true
+	}
+	if exceeded := status.Exceeded(); errors.Is(err, service.ErrQuotaExceeded) && exceeded != nil {
+		retryAfter := int(time.Until(exceeded.ResetAt).Seconds()) + 1
+		ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "quota": status})
+		return false
+	}
+	ctx.JSON(serviceErrorStatus(err), gin.H{"error": err.Error()})
+	return false
+}
+
+// setQuotaHeaders 在响应头中返回各周期的配额、剩余量和重置时间（Unix 秒）
+func setQuotaHeaders(ctx *gin.Context, status *service.QuotaStatus) {
+	windows := []struct {
+		prefix string
+		window *service.QuotaWindow
+	}{
+		{"X-Quota-Daily-", status.Daily},
+		{"X-Quota-Monthly-", status.Monthly},
+	}
+	for _, w := range windows {
+		if w.window == nil {
+			continue
+		}
+		ctx.Header(w.prefix+"Limit", strconv.FormatInt(w.window.Limit, 10))
+		ctx.Header(w.prefix+"Remaining", strconv.FormatInt(w.window.Remaining, 10))
+		ctx.Header(w.prefix+"Reset", strconv.FormatInt(w.window.ResetAt.Unix(), 10))
+	}
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"io"
+	"melody_cure/api/request"
+	"melody_cure/api/response"
+	"melody_cure/config"
+	"melody_cure/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VoiceLogController struct {
+	voiceLogService *service.VoiceLogService
+}
+
+func NewVoiceLogController(voiceLogService *service.VoiceLogService) *VoiceLogController {
+	return &VoiceLogController{
+		voiceLogService: voiceLogService,
+	}
+}
+
+// CreateVoiceLog 上传录音创建疗愈日志
+// @Summary 创建语音疗愈日志
+// @Description 上传一段录音创建疗愈日志：录音识别出的文字保存为日志内容（有补充说明时放在说明之后），录音作为 audio 类型的日志媒体保留
+// @Tags 疗愈日志
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param audio formData file true "录音文件 (wav, mp3, m4a, aac, ogg, webm, amr)"
+// @Param child_archive_id formData int true "儿童档案ID"
+// @Param note formData string false "补充的文字说明"
+// @Param phase formData string false "对比阶段 (baseline, follow_up)"
+// @Param skill formData string false "对比的技能"
+// @Param language formData string false "录音的语言，为空时为中文"
+// @Success 200 {object} object{code=int,data=service.VoiceLogResult} "创建成功"
+// @Failure 400 {object} response.ErrorResponse "参数错误"
+// @Failure 401 {object} response.ErrorResponse "未认证"
+// @Failure 403 {object} response.ErrorResponse "没有操作权限"
+// @Failure 404 {object} response.ErrorResponse "儿童档案不存在"
+// @Router /api/healing-log/voice [post]
+func (c *VoiceLogController) CreateVoiceLog(ctx *gin.Context) {
+	// 限制请求体大小，为表单的其他字段预留 1MB
+	if max := config.GetSpeechConfig().MaxAudioBytes; max > 0 {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, max+1<<20)
+	}
+
+	var req request.CreateVoiceLogRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "参数错误"})
+		return
+	}
+	header, err := ctx.FormFile("audio")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "请上传录音文件"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "读取录音失败"})
+		return
+	}
+	defer file.Close()
+	audio, err := io.ReadAll(file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.ErrorResponse{Code: http.StatusBadRequest, Message: "读取录音失败"})
+		return
+	}
+
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, response.ErrorResponse{Code: http.StatusUnauthorized, Message: "未认证"})
+		return
+	}
+
+	result, err := c.voiceLogService.CreateVoiceLog(ctx.Request.Context(), userID.(string), &req, header.Filename, audio)
+	if err != nil {
+		ctx.JSON(serviceErrorStatus(err), response.ErrorResponse{Code: serviceErrorStatus(err), Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"code": http.StatusOK, "data": result})
+}
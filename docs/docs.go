@@ -15,14 +15,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/child-archive": {
+        "/api/ai-reports/{id}/feedback": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取当前用户的所有儿童档案列表",
+                "description": "获取报告的全部评价，以及报告中可评价的章节和建议（管理员和获得儿童授权的认证康复师）",
                 "consumes": [
                     "application/json"
                 ],
@@ -30,9 +30,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "儿童档案"
+                    "报告评价"
+                ],
+                "summary": "获取报告评价",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "报告ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "获取儿童档案列表",
                 "responses": {
                     "200": {
                         "description": "获取成功",
@@ -43,37 +52,32 @@ const docTemplate = `{
                                     "type": "integer"
                                 },
                                 "data": {
-                                    "type": "array",
-                                    "items": {
-                                        "$ref": "#/definitions/response.ChildArchiveResponse"
-                                    }
+                                    "$ref": "#/definitions/service.ReportFeedbackDetail"
                                 }
                             }
                         }
                     },
-                    "401": {
-                        "description": "未认证",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "获取失败",
+                    "404": {
+                        "description": "报告不存在",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/child-archive/{archive_id}/profile": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取儿童的个人信息，包括照片、姓名、年龄、性别、诊断结果、已疗愈天数等",
+                "description": "获得儿童授权（家长授权或接手咨询转介）的认证康复师对报告当前版本的准确性、实用性和安全性评分（1-5），可对章节评分、标记幻觉内容、标记建议是否采纳。重复提交时更新自己的评价",
                 "consumes": [
                     "application/json"
                 ],
@@ -81,21 +85,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "儿童档案"
+                    "报告评价"
                 ],
-                "summary": "获取儿童个人信息",
+                "summary": "评价AI报告",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "儿童档案ID",
-                        "name": "archive_id",
+                        "type": "integer",
+                        "description": "报告ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "评价内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ReportFeedbackRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "评价成功",
                         "schema": {
                             "type": "object",
                             "properties": {
@@ -103,7 +116,7 @@ const docTemplate = `{
                                     "type": "integer"
                                 },
                                 "data": {
-                                    "$ref": "#/definitions/response.ChildArchiveResponse"
+                                    "$ref": "#/definitions/model.ReportFeedback"
                                 }
                             }
                         }
@@ -114,20 +127,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "未认证",
+                    "403": {
+                        "description": "仅获得儿童授权的认证康复师可以评价",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "档案不存在",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "获取失败",
+                        "description": "报告不存在",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -135,9 +142,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/course/{id}": {
+        "/api/child-archive": {
             "get": {
-                "description": "根据ID获取课程详情",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取当前用户的所有儿童档案列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -145,45 +157,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "内容管理"
-                ],
-                "summary": "获取课程详情",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "课程ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "儿童档案"
                 ],
+                "summary": "获取儿童档案列表",
                 "responses": {
                     "200": {
                         "description": "获取成功",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
                                 },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/DAO.Course"
-                                        }
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/response.ChildArchiveResponse"
                                     }
                                 }
-                            ]
+                            }
                         }
                     },
-                    "400": {
-                        "description": "参数错误",
+                    "401": {
+                        "description": "未认证",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "服务器错误",
+                        "description": "获取失败",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -191,9 +193,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/courses": {
+        "/api/child-archive/{archive_id}/calendar": {
             "get": {
-                "description": "获取所有课程列表",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "返回指定月份每天的日志、媒体和指标数量",
                 "consumes": [
                     "application/json"
                 ],
@@ -201,33 +208,53 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "内容管理"
+                    "儿童档案"
+                ],
+                "summary": "获取儿童月度记录日历",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "archive_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "月份 (YYYY-MM)，默认为当前月份",
+                        "name": "month",
+                        "in": "query"
+                    }
                 ],
-                "summary": "获取课程列表",
                 "responses": {
                     "200": {
                         "description": "获取成功",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
                                 },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/DAO.Course"
-                                            }
-                                        }
-                                    }
+                                "data": {
+                                    "$ref": "#/definitions/service.ChildCalendar"
                                 }
-                            ]
+                            }
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "400": {
+                        "description": "月份格式错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -235,9 +262,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/game/{id}": {
+        "/api/child-archive/{archive_id}/profile": {
             "get": {
-                "description": "根据ID获取游戏详情",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取儿童的个人信息，包括照片、姓名、年龄、性别、诊断结果、已疗愈天数、连续记录天数、里程碑和治疗目标进度等",
                 "consumes": [
                     "application/json"
                 ],
@@ -245,14 +277,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "内容管理"
+                    "儿童档案"
                 ],
-                "summary": "获取游戏详情",
+                "summary": "获取儿童个人信息",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "游戏ID",
-                        "name": "id",
+                        "description": "儿童档案ID",
+                        "name": "archive_id",
                         "in": "path",
                         "required": true
                     }
@@ -261,19 +293,15 @@ const docTemplate = `{
                     "200": {
                         "description": "获取成功",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
                                 },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/DAO.Game"
-                                        }
-                                    }
+                                "data": {
+                                    "$ref": "#/definitions/response.ChildProfileResponse"
                                 }
-                            ]
+                            }
                         }
                     },
                     "400": {
@@ -282,52 +310,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "401": {
+                        "description": "未认证",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/api/games": {
-            "get": {
-                "description": "获取所有游戏列表",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "内容管理"
-                ],
-                "summary": "获取游戏列表",
-                "responses": {
-                    "200": {
-                        "description": "获取成功",
+                    },
+                    "404": {
+                        "description": "档案不存在",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/DAO.Game"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "服务器错误",
+                        "description": "获取失败",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -335,14 +331,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/healing-log": {
-            "post": {
+        "/api/child-archive/{archive_id}/progress": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "创建一条新的疗愈日志，记录儿童成长进步和疗愈前后对比",
+                "description": "根据治疗开始日期和日志记录计算已疗愈天数、连续记录天数和里程碑",
                 "consumes": [
                     "application/json"
                 ],
@@ -350,41 +346,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "疗愈日志"
+                    "儿童档案"
                 ],
-                "summary": "创建疗愈日志",
+                "summary": "获取儿童疗愈进度",
                 "parameters": [
                     {
-                        "description": "疗愈日志信息",
-                        "name": "healing_log",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.HealingLog"
-                        }
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "archive_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功",
+                        "description": "获取成功",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
-                        }
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.ChildProgress"
+                                }
+                            }
+                        }
                     },
-                    "400": {
-                        "description": "参数错误",
+                    "401": {
+                        "description": "未认证",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "未认证",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "创建失败",
+                    "404": {
+                        "description": "档案不存在",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -392,14 +394,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/healing-log/child/{child_id}": {
+        "/api/companion-conversations": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取指定儿童的所有疗愈日志，支持按日期筛选",
+                "description": "家长查看孩子与AI陪伴的对话，最近有消息的排在前面，包含记忆摘要和被过滤的消息数",
                 "consumes": [
                     "application/json"
                 ],
@@ -407,27 +409,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "疗愈日志"
+                    "AI陪伴对话"
                 ],
-                "summary": "根据儿童ID获取疗愈日志",
+                "summary": "陪伴对话列表",
                 "parameters": [
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "儿童档案ID",
-                        "name": "child_id",
-                        "in": "path",
+                        "name": "child_archive_id",
+                        "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "开始日期 (YYYY-MM-DD)",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "结束日期 (YYYY-MM-DD)",
-                        "name": "end_date",
+                        "description": "AI陪伴ID",
+                        "name": "companion_id",
                         "in": "query"
                     }
                 ],
@@ -443,41 +439,27 @@ const docTemplate = `{
                                 "data": {
                                     "type": "array",
                                     "items": {
-                                        "$ref": "#/definitions/model.HealingLog"
+                                        "$ref": "#/definitions/model.CompanionConversation"
                                     }
                                 }
                             }
                         }
                     },
-                    "400": {
-                        "description": "无效的儿童ID或日期格式",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "未认证",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "获取失败",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/healing-log/{log_id}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取单个疗愈日志的详细信息，包括文字内容和媒体文件",
+                "description": "家长为自己的孩子开始一段与自己创建的AI陪伴的对话，孩子通过家长的设备聊天",
                 "consumes": [
                     "application/json"
                 ],
@@ -485,21 +467,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "疗愈日志"
+                    "AI陪伴对话"
                 ],
-                "summary": "获取疗愈日志详情",
+                "summary": "开始陪伴对话",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "日志ID",
-                        "name": "log_id",
-                        "in": "path",
-                        "required": true
+                        "description": "对话信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.StartCompanionConversationRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "创建成功",
                         "schema": {
                             "type": "object",
                             "properties": {
@@ -507,38 +491,34 @@ const docTemplate = `{
                                     "type": "integer"
                                 },
                                 "data": {
-                                    "$ref": "#/definitions/model.HealingLog"
+                                    "$ref": "#/definitions/model.CompanionConversation"
                                 }
                             }
                         }
                     },
                     "400": {
-                        "description": "无效的日志ID",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "未认证",
+                        "description": "参数错误",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "获取失败",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/companion-conversations/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "删除指定的疗愈日志及其关联的媒体文件",
+                "description": "家长查看对话记录，包括孩子发送的全部原文和被过滤的消息，按时间升序分页返回",
                 "consumes": [
                     "application/json"
                 ],
@@ -546,39 +526,53 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "疗愈日志"
+                    "AI陪伴对话"
                 ],
-                "summary": "删除疗愈日志",
+                "summary": "陪伴对话记录",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "日志ID",
-                        "name": "log_id",
+                        "description": "对话ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "只返回该消息之前的消息，用于加载更早的记录",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页消息数，默认50，最多200",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功",
-                        "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "无效的日志ID",
+                        "description": "获取成功",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.CompanionTranscript"
+                                }
+                            }
                         }
                     },
-                    "401": {
-                        "description": "未认证",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "删除失败",
+                    "404": {
+                        "description": "对话不存在",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -586,14 +580,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/image/qiniu/token": {
-            "get": {
+        "/api/companion-conversations/{id}/messages": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取七牛云图床上传凭证，用于前端直接上传文件到七牛云",
+                "description": "孩子通过家长的设备向AI陪伴发送消息并获得回复。回复按陪伴的性格设定和孩子的年龄、诊断调整说话方式；消息和回复都经过年龄分级的内容过滤，孩子提到自我伤害等需要关注的内容时通知家长。with_audio 为 true 时回复附带以陪伴语音类型合成的语音地址",
                 "consumes": [
                     "application/json"
                 ],
@@ -601,12 +595,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "图床管理"
+                    "AI陪伴对话"
+                ],
+                "summary": "与AI陪伴聊天",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "对话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "消息内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.CompanionMessageRequest"
+                        }
+                    }
                 ],
-                "summary": "获取七牛云上传token",
                 "responses": {
                     "200": {
-                        "description": "获取上传token成功",
+                        "description": "发送成功",
                         "schema": {
                             "type": "object",
                             "properties": {
@@ -614,34 +626,31 @@ const docTemplate = `{
                                     "type": "integer"
                                 },
                                 "data": {
-                                    "type": "object",
-                                    "properties": {
-                                        "bucket": {
-                                            "type": "string"
-                                        },
-                                        "domain": {
-                                            "type": "string"
-                                        },
-                                        "expires_at": {
-                                            "type": "integer",
-                                            "format": "int64"
-                                        },
-                                        "token": {
-                                            "type": "string"
-                                        },
-                                        "use_https": {
-                                            "type": "boolean"
-                                        }
-                                    }
-                                },
-                                "message": {
-                                    "type": "string"
+                                    "$ref": "#/definitions/service.CompanionExchange"
                                 }
                             }
                         }
                     },
-                    "500": {
-                        "description": "生成token失败",
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "对话不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "超出AI用量配额",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -649,52 +658,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/ai-companion": {
+        "/api/companion-conversations/{id}/messages/{message_id}/audio": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "创建AI陪伴角色",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "以AI陪伴的语音类型为对话中已有的一条陪伴回复合成语音，相同内容和音色的语音只合成一次并缓存在对象存储中",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "AI陪伴"
+                    "AI陪伴对话"
                 ],
-                "summary": "创建AI陪伴",
+                "summary": "合成陪伴回复语音",
                 "parameters": [
                     {
-                        "description": "AI陪伴创建请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.AICompanionRequest"
-                        }
+                        "type": "integer",
+                        "description": "对话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "消息ID",
+                        "name": "message_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功",
+                        "description": "合成成功",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
                                 },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/DAO.AICompanion"
-                                        }
-                                    }
+                                "data": {
+                                    "$ref": "#/definitions/model.CompanionMessage"
                                 }
-                            ]
+                            }
                         }
                     },
                     "400": {
@@ -703,14 +710,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "未授权",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "404": {
+                        "description": "对话不存在",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -718,14 +725,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/ai-companions": {
+        "/api/consultation-escalations": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取用户的AI陪伴列表",
+                "description": "认证康复师查看可以接手的申请（未指定康复师且等待接手）、指定给自己的申请和自己接手的申请。等待接手的按申请先后排列，其余按时间倒序",
                 "consumes": [
                     "application/json"
                 ],
@@ -733,39 +740,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "AI陪伴"
+                    "虚拟疗愈导师咨询"
+                ],
+                "summary": "转介申请列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "状态：pending, accepted, resolved, cancelled",
+                        "name": "status",
+                        "in": "query"
+                    }
                 ],
-                "summary": "获取AI陪伴列表",
                 "responses": {
                     "200": {
                         "description": "获取成功",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
                                 },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/DAO.AICompanion"
-                                            }
-                                        }
-                                    }
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.ConsultationEscalation"
+                                    }
                                 }
-                            ]
-                        }
-                    },
-                    "401": {
-                        "description": "未授权",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -773,14 +778,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/certification": {
-            "get": {
+        "/api/consultation-escalations/{id}/accept": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取用户认证状态",
+                "description": "认证康复师接手转介申请，之后家长在该会话中的消息由康复师回复，不再调用虚拟疗愈导师",
                 "consumes": [
                     "application/json"
                 ],
@@ -788,49 +793,62 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "认证管理"
+                    "虚拟疗愈导师咨询"
+                ],
+                "summary": "接手转介申请",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "转介申请ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "获取认证状态",
                 "responses": {
                     "200": {
-                        "description": "获取成功",
+                        "description": "接手成功",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
                                 },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/DAO.Certification"
-                                        }
-                                    }
+                                "data": {
+                                    "$ref": "#/definitions/model.ConsultationEscalation"
                                 }
-                            ]
+                            }
                         }
                     },
-                    "401": {
-                        "description": "未授权",
+                    "400": {
+                        "description": "申请已被处理",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "转介申请不存在",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/consultation-escalations/{id}/close": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "用户申请专业认证",
+                "description": "家长撤回等待接手的申请，或家长、接手的康复师结束已接手的转介。结束后会话恢复由虚拟疗愈导师回复",
                 "consumes": [
                     "application/json"
                 ],
@@ -838,41 +856,55 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "认证管理"
+                    "虚拟疗愈导师咨询"
                 ],
-                "summary": "申请认证",
+                "summary": "结束转介",
                 "parameters": [
                     {
-                        "description": "认证申请请求",
+                        "type": "integer",
+                        "description": "转介申请ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "结束说明",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.CertificationRequest"
+                            "$ref": "#/definitions/request.CloseEscalationRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "申请成功",
+                        "description": "操作成功",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.ConsultationEscalation"
+                                }
+                            }
                         }
                     },
                     "400": {
-                        "description": "参数错误",
+                        "description": "申请已被处理",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "未授权",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "404": {
+                        "description": "转介申请不存在",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -880,14 +912,66 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/child-archive": {
+        "/api/consultations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "家长查看孩子的咨询会话，最近有消息的排在前面",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "虚拟疗愈导师咨询"
+                ],
+                "summary": "咨询会话列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_archive_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.ConsultationThread"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "创建儿童档案",
+                "description": "家长就自己的孩子开始与自己创建的虚拟疗愈导师的咨询会话",
                 "consumes": [
                     "application/json"
                 ],
@@ -895,17 +979,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "儿童档案管理"
+                    "虚拟疗愈导师咨询"
                 ],
-                "summary": "创建儿童档案",
+                "summary": "开始虚拟疗愈导师咨询",
                 "parameters": [
                     {
-                        "description": "儿童档案创建请求",
+                        "description": "咨询信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.ChildArchiveRequest"
+                            "$ref": "#/definitions/request.StartConsultationRequest"
                         }
                     }
                 ],
@@ -913,19 +997,15 @@ const docTemplate = `{
                     "200": {
                         "description": "创建成功",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
                                 },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/DAO.ChildArchive"
-                                        }
-                                    }
+                                "data": {
+                                    "$ref": "#/definitions/model.ConsultationThread"
                                 }
-                            ]
+                            }
                         }
                     },
                     "400": {
@@ -934,14 +1014,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "未授权",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "服务器错误",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -949,14 +1023,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/child-archive/{id}": {
-            "put": {
+        "/api/consultations/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "更新儿童档案信息",
+                "description": "查看咨询会话的全部消息（虚拟疗愈导师的回复附带引用的资料）和转介申请。家长、接手的康复师以及可以接手该会话转介申请的认证康复师可以查看",
                 "consumes": [
                     "application/json"
                 ],
@@ -964,61 +1038,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "儿童档案管理"
+                    "虚拟疗愈导师咨询"
                 ],
-                "summary": "更新儿童档案",
+                "summary": "咨询记录",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "档案ID",
+                        "type": "integer",
+                        "description": "咨询会话ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "儿童档案更新请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.ChildArchiveRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
-                        "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "参数错误",
+                        "description": "获取成功",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.ConsultationDetail"
+                                }
+                            }
                         }
                     },
-                    "401": {
-                        "description": "未授权",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "404": {
+                        "description": "咨询会话不存在",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/consultations/{id}/escalations": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "删除儿童档案",
+                "description": "家长把咨询转给认证康复师。指定康复师时只有该康复师可以接手并会收到通知，否则任一认证康复师都可以接手。每个会话同时只能有一个进行中的转介申请",
                 "consumes": [
                     "application/json"
                 ],
@@ -1026,39 +1095,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "儿童档案管理"
+                    "虚拟疗愈导师咨询"
                 ],
-                "summary": "删除儿童档案",
+                "summary": "申请转介认证康复师",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "档案ID",
+                        "type": "integer",
+                        "description": "咨询会话ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "转介信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.EscalateConsultationRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "删除成功",
+                        "description": "申请成功",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.ConsultationEscalation"
+                                }
+                            }
                         }
                     },
                     "400": {
-                        "description": "参数错误",
+                        "description": "参数错误或已有进行中的转介申请",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "未授权",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "404": {
+                        "description": "咨询会话不存在",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1066,14 +1152,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/child-archives": {
-            "get": {
+        "/api/consultations/{id}/messages": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取用户的儿童档案列表",
+                "description": "家长提问时，虚拟疗愈导师检索孩子的档案、近期疗愈记录和最新报告，生成带 [编号] 引用标注的回复，引用的资料在回复的 sources 中返回。会话已由认证康复师接手时只保存消息并通知对方，接手的康复师也通过该接口回复家长",
                 "consumes": [
                     "application/json"
                 ],
@@ -1081,80 +1167,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "儿童档案管理"
+                    "虚拟疗愈导师咨询"
                 ],
-                "summary": "获取儿童档案列表",
-                "responses": {
-                    "200": {
-                        "description": "获取成功",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/DAO.ChildArchive"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "401": {
-                        "description": "未授权",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "服务器错误",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/user/favorite": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "添加内容到收藏夹",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "收藏管理"
-                ],
-                "summary": "添加收藏",
+                "summary": "发送咨询消息",
                 "parameters": [
                     {
-                        "description": "收藏请求",
+                        "type": "integer",
+                        "description": "咨询会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "消息内容",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.FavoriteRequest"
+                            "$ref": "#/definitions/request.ConsultationMessageRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "添加成功",
+                        "description": "发送成功",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.ConsultationExchange"
+                                }
+                            }
                         }
                     },
                     "400": {
@@ -1163,69 +1209,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "未授权",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "服务器错误",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "从收藏夹移除内容",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "收藏管理"
-                ],
-                "summary": "移除收藏",
-                "parameters": [
-                    {
-                        "description": "收藏移除请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.FavoriteRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "移除成功",
-                        "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "参数错误",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "未授权",
+                    "404": {
+                        "description": "咨询会话不存在",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "429": {
+                        "description": "超出AI用量配额",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1233,14 +1230,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/favorites": {
+        "/api/course/{id}": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "获取用户的收藏列表",
+                "description": "根据ID获取课程详情",
                 "consumes": [
                     "application/json"
                 ],
@@ -1248,9 +1240,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "收藏管理"
+                    "内容管理"
+                ],
+                "summary": "获取课程详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "课程ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "获取收藏列表",
                 "responses": {
                     "200": {
                         "description": "获取成功",
@@ -1263,18 +1264,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/DAO.UserFavorite"
-                                            }
+                                            "$ref": "#/definitions/DAO.Course"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "401": {
-                        "description": "未授权",
+                    "400": {
+                        "description": "参数错误",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1288,9 +1286,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/login": {
-            "post": {
-                "description": "用户登录接口",
+        "/api/courses": {
+            "get": {
+                "description": "获取所有课程列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -1298,23 +1296,12 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
-                ],
-                "summary": "用户登录",
-                "parameters": [
-                    {
-                        "description": "登录请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.LoginRequest"
-                        }
-                    }
+                    "内容管理"
                 ],
+                "summary": "获取课程列表",
                 "responses": {
                     "200": {
-                        "description": "登录成功，返回token",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1324,21 +1311,18 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/DAO.Course"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "用户名或密码错误",
+                    "500": {
+                        "description": "服务器错误",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1346,14 +1330,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/logout": {
-            "post": {
+        "/api/exports/branding": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "用户登出，使token失效",
+                "description": "获取当前用户所属机构的导出文档信头设置，管理员需指定机构ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1361,33 +1345,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "文档导出"
+                ],
+                "summary": "获取机构导出信头",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "机构ID（仅管理员）",
+                        "name": "institution_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "用户登出",
                 "responses": {
                     "200": {
-                        "description": "登出成功",
+                        "description": "获取成功",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.InstitutionBranding"
+                                }
+                            }
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/user/password": {
+            },
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "用户修改登录密码",
+                "description": "设置导出文档的机构名称、联系方式、页脚、主题色和徽标（机构账号或管理员）",
                 "consumes": [
                     "application/json"
                 ],
@@ -1395,33 +1393,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "文档导出"
                 ],
-                "summary": "修改密码",
+                "summary": "更新机构导出信头",
                 "parameters": [
                     {
-                        "description": "修改密码请求",
+                        "description": "信头设置",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "new_password": {
-                                    "type": "string"
-                                },
-                                "old_password": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/request.UpdateBrandingRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "密码修改成功",
+                        "description": "更新成功",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.InstitutionBranding"
+                                }
+                            }
                         }
                     },
                     "400": {
@@ -1430,14 +1428,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "未认证",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "服务器错误",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1445,90 +1437,131 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/profile": {
+        "/api/exports/children/{child_id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取用户个人信息",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "将儿童基本信息、治疗目标进度、指标趋势图、疗愈日志和最近的AI报告导出为 PDF 或 DOCX",
                 "produces": [
-                    "application/json"
+                    "application/pdf",
+                    "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
                 ],
                 "tags": [
-                    "用户管理"
+                    "文档导出"
                 ],
-                "summary": "获取个人信息",
-                "responses": {
-                    "200": {
-                        "description": "获取成功",
+                "summary": "导出儿童康复档案",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "导出格式 pdf 或 docx，默认 pdf",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始日期 (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束日期 (YYYY-MM-DD)，包含当天",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "指定导出的日志ID，逗号分隔，为空时导出最近的日志",
+                        "name": "log_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "未指定日志时导出的日志条数，默认10，最多50",
+                        "name": "log_limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "导出的AI报告份数，默认3，最多10",
+                        "name": "report_limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导出的文件",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/DAO.User"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
                         }
                     },
-                    "401": {
-                        "description": "未授权",
+                    "400": {
+                        "description": "参数错误",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "儿童档案不存在",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/exports/reports/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "更新用户个人信息",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "将AI报告导出为 PDF 或 DOCX，带导出人所属机构的信头，中文字体内嵌在文件中",
                 "produces": [
-                    "application/json"
+                    "application/pdf",
+                    "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
                 ],
                 "tags": [
-                    "用户管理"
+                    "文档导出"
                 ],
-                "summary": "更新个人信息",
+                "summary": "导出AI报告",
                 "parameters": [
                     {
-                        "description": "更新信息请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.UpdateProfileRequest"
-                        }
+                        "type": "integer",
+                        "description": "报告ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "导出格式 pdf 或 docx，默认 pdf",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "导出的文件",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -1537,14 +1570,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "未授权",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "服务器错误",
+                    "403": {
+                        "description": "没有操作权限",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -1552,9 +1579,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/register": {
+        "/api/game-sessions": {
             "post": {
-                "description": "用户注册接口",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "记录儿童的一次游戏训练，可同时关联治疗目标",
                 "consumes": [
                     "application/json"
                 ],
@@ -1562,25 +1594,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "治疗方案"
                 ],
-                "summary": "用户注册",
+                "summary": "记录游戏训练",
                 "parameters": [
                     {
-                        "description": "注册请求",
+                        "description": "游戏训练信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.Register"
+                            "$ref": "#/definitions/request.GameSessionRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "注册成功",
+                        "description": "记录成功",
                         "schema": {
-                            "$ref": "#/definitions/response.SuccessResponse"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.GameSession"
+                                }
+                            }
                         }
                     },
                     "400": {
@@ -1588,18 +1628,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/user/virtual-therapist": {
-            "post": {
+        "/api/game-sessions/child/{child_id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "创建虚拟疗愈导师",
+                "description": "按时间倒序获取儿童的游戏训练记录",
                 "consumes": [
                     "application/json"
                 ],
@@ -1607,23 +1653,76 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "虚拟疗愈导师"
+                    "治疗方案"
                 ],
-                "summary": "创建虚拟疗愈导师",
+                "summary": "获取儿童的游戏训练记录",
                 "parameters": [
                     {
-                        "description": "虚拟疗愈导师创建请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
                         "schema": {
-                            "$ref": "#/definitions/request.VirtualTherapistRequest"
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.GameSession"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "档案不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
+                }
+            }
+        },
+        "/api/game/{id}": {
+            "get": {
+                "description": "根据ID获取游戏详情",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "内容管理"
+                ],
+                "summary": "获取游戏详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "游戏ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "创建成功",
+                        "description": "获取成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1633,7 +1732,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/DAO.VirtualTherapist"
+                                            "$ref": "#/definitions/DAO.Game"
                                         }
                                     }
                                 }
@@ -1646,12 +1745,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "未授权",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "服务器错误",
                         "schema": {
@@ -1661,14 +1754,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/virtual-therapists": {
+        "/api/games": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "获取用户的虚拟疗愈导师列表",
+                "description": "获取所有游戏列表",
                 "consumes": [
                     "application/json"
                 ],
@@ -1676,9 +1764,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "虚拟疗愈导师"
+                    "内容管理"
                 ],
-                "summary": "获取虚拟疗愈导师列表",
+                "summary": "获取游戏列表",
                 "responses": {
                     "200": {
                         "description": "获取成功",
@@ -1693,7 +1781,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/DAO.VirtualTherapist"
+                                                "$ref": "#/definitions/DAO.Game"
                                             }
                                         }
                                     }
@@ -1701,12 +1789,6 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "401": {
-                        "description": "未授权",
-                        "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "服务器错误",
                         "schema": {
@@ -1715,167 +1797,7707 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "DAO.AICompanion": {
-            "type": "object",
-            "properties": {
-                "avatar": {
-                    "type": "string"
-                },
-                "companion_type": {
-                    "description": "类似抖音小火人/王者灵主",
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "is_active": {
-                    "type": "boolean"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "personality": {
-                    "description": "性格设定",
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
-                },
-                "user": {
-                    "$ref": "#/definitions/DAO.User"
+        },
+        "/api/healing-log": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "创建一条新的疗愈日志，记录儿童成长进步和疗愈前后对比",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "疗愈日志"
+                ],
+                "summary": "创建疗愈日志",
+                "parameters": [
+                    {
+                        "description": "疗愈日志信息",
+                        "name": "healing_log",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.HealingLog"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "儿童档案不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/healing-log/child/{child_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取指定儿童的所有疗愈日志，支持按日期筛选",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "疗愈日志"
+                ],
+                "summary": "根据儿童ID获取疗愈日志",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "儿童档案ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始日期 (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束日期 (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.HealingLog"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "无效的儿童ID或日期格式",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "获取失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/healing-log/child/{child_id}/comparison": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "返回某项技能在基线和跟进两个阶段的配对记录、指标变化和并排媒体",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "疗愈日志"
+                ],
+                "summary": "获取疗愈前后对比",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "对比的技能",
+                        "name": "skill",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "基线开始日期 (YYYY-MM-DD)",
+                        "name": "baseline_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "基线结束日期 (YYYY-MM-DD)",
+                        "name": "baseline_end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "跟进开始日期 (YYYY-MM-DD)",
+                        "name": "follow_up_start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "跟进结束日期 (YYYY-MM-DD)",
+                        "name": "follow_up_end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.HealingComparison"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/healing-log/voice": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "上传一段录音创建疗愈日志：录音识别出的文字保存为日志内容（有补充说明时放在说明之后），录音作为 audio 类型的日志媒体保留",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "疗愈日志"
+                ],
+                "summary": "创建语音疗愈日志",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "录音文件 (wav, mp3, m4a, aac, ogg, webm, amr)",
+                        "name": "audio",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "儿童档案ID",
+                        "name": "child_archive_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "补充的文字说明",
+                        "name": "note",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "对比阶段 (baseline, follow_up)",
+                        "name": "phase",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "对比的技能",
+                        "name": "skill",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "录音的语言，为空时为中文",
+                        "name": "language",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.VoiceLogResult"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "儿童档案不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/healing-log/{log_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取单个疗愈日志的详细信息，包括文字内容和媒体文件",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "疗愈日志"
+                ],
+                "summary": "获取疗愈日志详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "日志ID",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.HealingLog"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "无效的日志ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "获取失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "修改疗愈日志的文字内容，同时更新该日志在语义索引中的向量",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "疗愈日志"
+                ],
+                "summary": "编辑疗愈日志",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "日志ID",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "日志内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.UpdateHealingLogRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.HealingLog"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "日志不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "删除指定的疗愈日志及其关联的媒体文件",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "疗愈日志"
+                ],
+                "summary": "删除疗愈日志",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "日志ID",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "无效的日志ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "日志不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "删除失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/healing-log/{log_id}/phase": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "将日志或其中指定的媒体标记为某项技能的基线（疗愈前）或跟进（疗愈后）记录，阶段为空表示取消标记",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "疗愈日志"
+                ],
+                "summary": "标记疗愈日志的对比阶段",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "日志ID",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "阶段标记",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.MarkLogPhaseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "标记成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "日志不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/image/qiniu/token": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取七牛云图床上传凭证，用于前端直接上传文件到七牛云",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "图床管理"
+                ],
+                "summary": "获取七牛云上传token",
+                "responses": {
+                    "200": {
+                        "description": "获取上传token成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "object",
+                                    "properties": {
+                                        "bucket": {
+                                            "type": "string"
+                                        },
+                                        "domain": {
+                                            "type": "string"
+                                        },
+                                        "expires_at": {
+                                            "type": "integer",
+                                            "format": "int64"
+                                        },
+                                        "token": {
+                                            "type": "string"
+                                        },
+                                        "use_https": {
+                                            "type": "boolean"
+                                        }
+                                    }
+                                },
+                                "message": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "生成token失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/log-templates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取康复师编写的日志模板列表，可按诊断关键词筛选",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "日志模板"
+                ],
+                "summary": "获取日志模板库",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "诊断关键词",
+                        "name": "diagnosis",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否包含已停用模板",
+                        "name": "include_inactive",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.LogTemplate"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "获取失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "管理员或已认证康复师创建日志模板，可设置适用的诊断关键词",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "日志模板"
+                ],
+                "summary": "创建日志模板",
+                "parameters": [
+                    {
+                        "description": "模板信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.LogTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.LogTemplate"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/log-templates/child/{child_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "返回手动分配给儿童的模板以及根据诊断结果自动匹配的模板",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "日志模板"
+                ],
+                "summary": "获取儿童可用的日志模板",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.ChildLogTemplate"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "档案不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "手动为儿童分配一个日志模板",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "日志模板"
+                ],
+                "summary": "为儿童分配日志模板",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "模板ID",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.AssignLogTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "分配成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.ChildLogTemplate"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/log-templates/child/{child_id}/{template_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "移除手动分配给儿童的日志模板",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "日志模板"
+                ],
+                "summary": "取消儿童的日志模板分配",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "模板ID",
+                        "name": "template_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "取消成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "无效的模板ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/log-templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取单个日志模板的问题、清单和量表定义",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "日志模板"
+                ],
+                "summary": "获取日志模板详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.LogTemplate"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "无效的模板ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "模板不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "管理员或已认证康复师更新日志模板",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "日志模板"
+                ],
+                "summary": "更新日志模板",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "模板信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.LogTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.LogTemplate"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "管理员或已认证康复师删除日志模板，同时移除相关的分配记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "日志模板"
+                ],
+                "summary": "删除日志模板",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "模板ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "无效的模板ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取当前用户的站内通知（里程碑、提醒等）及未读数量",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "通知"
+                ],
+                "summary": "获取通知列表",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "是否只返回未读通知",
+                        "name": "unread_only",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "object",
+                                    "properties": {
+                                        "notifications": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Notification"
+                                            }
+                                        },
+                                        "unread": {
+                                            "type": "integer"
+                                        }
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "获取失败",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/notifications/read-all": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "将当前用户的全部未读通知标记为已读",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "通知"
+                ],
+                "summary": "标记全部通知为已读",
+                "responses": {
+                    "200": {
+                        "description": "操作成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/notifications/{id}/read": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "将指定通知标记为已读",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "通知"
+                ],
+                "summary": "标记通知为已读",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "通知ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "操作成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "无效的通知ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/public/shares/{token}": {
+            "get": {
+                "description": "无需登录，通过分享令牌查看只读文档。设置了访问码的链接需在 X-Share-Code 请求头或 code 参数中提供访问码",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "分享链接"
+                ],
+                "summary": "查看分享的文档",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "分享令牌",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "访问码",
+                        "name": "X-Share-Code",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "访问码",
+                        "name": "code",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.SharedDocument"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "需要访问码",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "访问码错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "分享链接不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "分享链接已过期或已被撤销",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "访问码错误次数过多",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/public/shares/{token}/download": {
+            "get": {
+                "description": "无需登录，通过分享令牌下载 PDF 或 DOCX，信头使用分享人所属机构的设置",
+                "produces": [
+                    "application/pdf",
+                    "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+                ],
+                "tags": [
+                    "分享链接"
+                ],
+                "summary": "下载分享的文档",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "分享令牌",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "导出格式 pdf 或 docx，默认 pdf",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "访问码",
+                        "name": "X-Share-Code",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "访问码",
+                        "name": "code",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导出的文件",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "需要访问码",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "访问码错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "分享链接不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "分享链接已过期或已被撤销",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/report-feedback/analytics": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "按报告类型、提示词模板版本和模型汇总平均评分、幻觉标记率和建议采纳率，用于比较提示词修改前后的效果（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告评价"
+                ],
+                "summary": "报告评价汇总",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "开始日期 (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束日期 (YYYY-MM-DD)，包含当天",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "报告类型",
+                        "name": "report_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "模型",
+                        "name": "model",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.FeedbackAnalytics"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/report-types": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取可用于生成AI报告的报告类型，管理员传 all=true 时包含已停用的类型",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告类型"
+                ],
+                "summary": "获取报告类型列表",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "是否包含已停用的类型（仅管理员）",
+                        "name": "all",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.ReportType"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "创建报告类型及其第一个提示词模板版本，模板为 Go text/template，可引用 system_role、child_profile、logs、metrics、comparison 等共用片段（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告类型"
+                ],
+                "summary": "创建报告类型",
+                "parameters": [
+                    {
+                        "description": "报告类型信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.CreateReportTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.ReportTypeDetail"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或模板无法渲染",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/report-types/languages": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取生成和翻译报告时可选的语言，没有专用模板的语言使用中文模板并要求模型以该语言输出",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告类型"
+                ],
+                "summary": "获取支持的报告语言",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/service.ReportLanguage"
+                                    }
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/report-types/{key}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取报告类型及其所有提示词模板版本（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告类型"
+                ],
+                "summary": "获取报告类型详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "报告类型标识",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.ReportTypeDetail"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "报告类型不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "更新报告类型的名称、描述和启用状态，模板内容通过新增版本修改（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告类型"
+                ],
+                "summary": "更新报告类型",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "报告类型标识",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "报告类型信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.UpdateReportTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.ReportType"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/report-types/{key}/preview": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "渲染草稿或指定版本的提示词模板，指定儿童档案时使用其真实日志和指标，否则使用示例数据（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告类型"
+                ],
+                "summary": "预览提示词",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "报告类型标识",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "预览参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.PreviewPromptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "渲染成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.PromptPreview"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或模板无法渲染",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/report-types/{key}/versions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "保存新的模板版本，已有版本不会被修改；language 为模板语言，为空时为中文；activate 为 true 时立即启用为该语言的当前版本（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告类型"
+                ],
+                "summary": "新增提示词模板版本",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "报告类型标识",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "模板内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.PromptTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "保存成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.PromptTemplate"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或模板无法渲染",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/report-types/{key}/versions/{version}/activate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "将指定版本设为报告类型在该版本语言下的当前版本，可用于回滚（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告类型"
+                ],
+                "summary": "启用提示词模板版本",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "报告类型标识",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "模板版本号",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "启用成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.ReportType"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "版本不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/safety-reviews": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取触发安全审查、等待人工审核的AI报告。机构账号和所属的认证康复师查看本机构的报告，管理员可查看全部或指定机构",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告安全审核"
+                ],
+                "summary": "获取安全审核队列",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "审核状态 pending, approved, rejected，默认 pending",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_archive_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "机构ID（仅管理员）",
+                        "name": "institution_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.ReportSafetyReview"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/safety-reviews/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取待审核报告的完整内容和安全审查的命中记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告安全审核"
+                ],
+                "summary": "获取待审核报告",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "审核记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.ReportSafetyReview"
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "审核记录不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/safety-reviews/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "审核通过后报告保存为正式报告并通知发起人和家长，后台任务同时标记为成功",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告安全审核"
+                ],
+                "summary": "审核通过",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "审核记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "审核意见",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/request.SafetyReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "审核成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.ReportSafetyReview"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "该报告已审核",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "审核记录不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/safety-reviews/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "审核不通过时报告不保存，后台任务标记为失败并通知发起人和家长",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "报告安全审核"
+                ],
+                "summary": "审核不通过",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "审核记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "审核意见",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/request.SafetyReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "审核成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.ReportSafetyReview"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "该报告已审核",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "审核记录不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/semantic-index/child/{child_id}/rebuild": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "按内容哈希同步孩子全部疗愈记录、报告和治疗目标的索引，只重新向量化新增或变化的资料并移除已删除资料的索引。用于补建功能上线前的记录或更换向量化提供商后重建",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "语义索引"
+                ],
+                "summary": "同步语义索引",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "同步成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.SemanticSyncResult"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "儿童档案不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/semantic-index/child/{child_id}/similar": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "在孩子的疗愈记录、报告和治疗目标中查找与一段描述或一条疗愈记录语义相近的资料，按相似度降序，同一资料只返回最相关的一段",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "语义索引"
+                ],
+                "summary": "查找相似的过往经历",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "描述当前情况的文字，与 log_id 至少提供一个",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "以该疗愈记录为查询，结果中不含该记录",
+                        "name": "log_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "返回条数 (1-50)，为空时使用配置的默认值",
+                        "name": "k",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "资料类型，逗号分隔 (log, report, goal)，为空时检索全部",
+                        "name": "types",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/service.SemanticMatch"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "儿童档案或疗愈记录不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shares": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取当前用户创建的分享链接及查看次数，默认只返回有效的链接",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "分享链接"
+                ],
+                "summary": "获取分享链接列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_archive_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否包含已过期和已撤销的链接",
+                        "name": "include_inactive",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/service.ShareLinkView"
+                                    }
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "为AI报告或儿童康复档案创建有过期时间、可撤销的只读分享链接，可设置访问码。报告分享当前版本，档案在创建时生成快照",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "分享链接"
+                ],
+                "summary": "创建分享链接",
+                "parameters": [
+                    {
+                        "description": "分享内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.CreateShareLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.ShareLinkView"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shares/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "撤销分享链接，撤销后立即无法访问（创建人或管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "分享链接"
+                ],
+                "summary": "撤销分享链接",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "分享链接ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "撤销成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.ShareLinkView"
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "分享链接不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shares/{id}/access-logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取分享链接最近200次访问，包括查看、下载、访问码错误和过期后的访问（创建人或管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "分享链接"
+                ],
+                "summary": "获取分享链接访问记录",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "分享链接ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.ShareAccessLog"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "分享链接不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/therapist-grants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "家长或管理员查看已授权访问孩子资料的康复师",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "康复师授权"
+                ],
+                "summary": "孩子的康复师授权列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_archive_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.ChildTherapistGrant"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "儿童档案不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "家长或管理员授权一位认证康复师访问孩子的疗愈记录、报告、导出和评价等资料，康复师会收到通知。已授权时返回已有的授权",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "康复师授权"
+                ],
+                "summary": "授权康复师访问孩子的资料",
+                "parameters": [
+                    {
+                        "description": "授权信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.GrantTherapistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "授权成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/model.ChildTherapistGrant"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误或康复师未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "儿童档案不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/therapist-grants/mine": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "康复师查看授权自己访问资料的孩子。正在接手的咨询转介同样可以访问对应孩子的资料，不在此列出",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "康复师授权"
+                ],
+                "summary": "康复师获得的授权",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.ChildTherapistGrant"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/therapist-grants/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "家长或管理员撤销授权，康复师也可以放弃自己获得的授权",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "康复师授权"
+                ],
+                "summary": "撤销康复师授权",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "授权ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "撤销成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "授权不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/treatment-plans": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "为儿童创建结构化治疗方案，可同时创建语言、社交、运动、情绪等领域的目标",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "治疗方案"
+                ],
+                "summary": "创建治疗方案",
+                "parameters": [
+                    {
+                        "description": "方案信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.TreatmentPlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.TreatmentPlanDetail"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/treatment-plans/child/{child_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取儿童的所有治疗方案及各目标的进度",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "治疗方案"
+                ],
+                "summary": "获取儿童的治疗方案",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "儿童档案ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/service.TreatmentPlanDetail"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "档案不存在",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/treatment-plans/goals/{goal_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取目标进度以及关联的疗愈日志和游戏记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "治疗方案"
+                ],
+                "summary": "获取治疗目标详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "目标ID",
+                        "name": "goal_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.GoalDetail"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "无效的目标ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "更新目标的达成标准、日期和状态，标记为已达成时记录达成时间",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "治疗方案"
+                ],
+                "summary": "更新治疗目标",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "目标ID",
+                        "name": "goal_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "目标信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.TreatmentGoalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.GoalWithProgress"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "删除目标及其关联记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "治疗方案"
+                ],
+                "summary": "删除治疗目标",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "目标ID",
+                        "name": "goal_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "无效的目标ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/treatment-plans/goals/{goal_id}/links": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "将同一儿童的疗愈日志或游戏训练记录关联到目标",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "治疗方案"
+                ],
+                "summary": "关联记录到目标",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "目标ID",
+                        "name": "goal_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "关联记录",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.GoalLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "关联成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/treatment-plans/goals/{goal_id}/links/{link_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "移除目标与疗愈日志或游戏记录的关联",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "治疗方案"
+                ],
+                "summary": "取消目标关联",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "目标ID",
+                        "name": "goal_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "关联ID",
+                        "name": "link_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "取消成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "无效的ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/treatment-plans/{plan_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取治疗方案及各目标的进度",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "治疗方案"
+                ],
+                "summary": "获取治疗方案详情",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "方案ID",
+                        "name": "plan_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.TreatmentPlanDetail"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "无效的方案ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "更新治疗方案的标题、说明、状态和日期，目标请使用目标接口维护",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "治疗方案"
+                ],
+                "summary": "更新治疗方案",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "方案ID",
+                        "name": "plan_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "方案信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.TreatmentPlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.TreatmentPlanDetail"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "删除治疗方案及其全部目标和关联记录",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "治疗方案"
+                ],
+                "summary": "删除治疗方案",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "方案ID",
+                        "name": "plan_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "无效的方案ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/treatment-plans/{plan_id}/goals": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "为治疗方案添加目标，可设置达成标准、指标基线与目标值、目标练习次数和目标日期",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "治疗方案"
+                ],
+                "summary": "添加治疗目标",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "方案ID",
+                        "name": "plan_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "目标信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.TreatmentGoalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.GoalWithProgress"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/usage/quota": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取当前用户今日和本月的AI用量配额，同时受个人和机构配额限制时返回剩余较少的一项，不限额的周期不返回",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI用量"
+                ],
+                "summary": "获取AI用量配额",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.QuotaStatus"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/usage/summary": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "按提供商和模型汇总 token 用量，并按配置的单价估算费用（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI用量"
+                ],
+                "summary": "AI用量汇总",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "开始日期 (YYYY-MM-DD)，默认本月第一天",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束日期 (YYYY-MM-DD)，包含当天",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "机构ID",
+                        "name": "institution_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "data": {
+                                    "$ref": "#/definitions/service.UsageSummary"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/usage/users/{user_id}/institution": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "设置用户所属的机构，机构成员的AI用量计入机构配额（仅管理员）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI用量"
+                ],
+                "summary": "设置用户所属机构",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "机构信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetUserInstitutionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "设置成功",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": {
+                                    "type": "integer"
+                                },
+                                "message": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "没有操作权限",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/ai-companion": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "创建AI陪伴角色",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI陪伴"
+                ],
+                "summary": "创建AI陪伴",
+                "parameters": [
+                    {
+                        "description": "AI陪伴创建请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.AICompanionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/DAO.AICompanion"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/ai-companions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取用户的AI陪伴列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI陪伴"
+                ],
+                "summary": "获取AI陪伴列表",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/DAO.AICompanion"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/certification": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取用户认证状态",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证管理"
+                ],
+                "summary": "获取认证状态",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/DAO.Certification"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "用户申请专业认证",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证管理"
+                ],
+                "summary": "申请认证",
+                "parameters": [
+                    {
+                        "description": "认证申请请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.CertificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "申请成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/child-archive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "创建儿童档案",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "儿童档案管理"
+                ],
+                "summary": "创建儿童档案",
+                "parameters": [
+                    {
+                        "description": "儿童档案创建请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ChildArchiveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/DAO.ChildArchive"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/child-archive/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "更新儿童档案信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "儿童档案管理"
+                ],
+                "summary": "更新儿童档案",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "档案ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "儿童档案更新请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ChildArchiveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "删除儿童档案",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "儿童档案管理"
+                ],
+                "summary": "删除儿童档案",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "档案ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/child-archives": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取用户的儿童档案列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "儿童档案管理"
+                ],
+                "summary": "获取儿童档案列表",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/DAO.ChildArchive"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/favorite": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "添加内容到收藏夹",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "收藏管理"
+                ],
+                "summary": "添加收藏",
+                "parameters": [
+                    {
+                        "description": "收藏请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.FavoriteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "添加成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "从收藏夹移除内容",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "收藏管理"
+                ],
+                "summary": "移除收藏",
+                "parameters": [
+                    {
+                        "description": "收藏移除请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.FavoriteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "移除成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/favorites": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取用户的收藏列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "收藏管理"
+                ],
+                "summary": "获取收藏列表",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/DAO.UserFavorite"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/login": {
+            "post": {
+                "description": "用户登录接口",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "用户登录",
+                "parameters": [
+                    {
+                        "description": "登录请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登录成功，返回token",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "用户名或密码错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "用户登出，使token失效",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "用户登出",
+                "responses": {
+                    "200": {
+                        "description": "登出成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "用户修改登录密码",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "修改密码",
+                "parameters": [
+                    {
+                        "description": "修改密码请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "new_password": {
+                                    "type": "string"
+                                },
+                                "old_password": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "密码修改成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未认证",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取用户个人信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "获取个人信息",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/DAO.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "更新用户个人信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "更新个人信息",
+                "parameters": [
+                    {
+                        "description": "更新信息请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/register": {
+            "post": {
+                "description": "用户注册接口",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "用户注册",
+                "parameters": [
+                    {
+                        "description": "注册请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.Register"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "注册成功",
+                        "schema": {
+                            "$ref": "#/definitions/response.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/virtual-therapist": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "创建虚拟疗愈导师",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "虚拟疗愈导师"
+                ],
+                "summary": "创建虚拟疗愈导师",
+                "parameters": [
+                    {
+                        "description": "虚拟疗愈导师创建请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.VirtualTherapistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/DAO.VirtualTherapist"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/virtual-therapists": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取用户的虚拟疗愈导师列表",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "虚拟疗愈导师"
+                ],
+                "summary": "获取虚拟疗愈导师列表",
+                "responses": {
+                    "200": {
+                        "description": "获取成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/DAO.VirtualTherapist"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "DAO.AICompanion": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "companion_type": {
+                    "description": "类似抖音小火人/王者灵主",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "personality": {
+                    "description": "性格设定",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/DAO.User"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "voice_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "DAO.Certification": {
+            "type": "object",
+            "properties": {
+                "certificate_name": {
+                    "type": "string"
+                },
+                "certificate_no": {
+                    "type": "string"
+                },
+                "certificate_type": {
+                    "description": "机构认证/康复师认证",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "issue_date": {
+                    "type": "string"
+                },
+                "issuing_authority": {
+                    "type": "string"
+                },
+                "review_notes": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending, approved, rejected",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/DAO.User"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "DAO.ChildArchive": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "birth_date": {
+                    "type": "string"
+                },
+                "child_name": {
+                    "type": "string"
+                },
+                "condition": {
+                    "description": "病情描述",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "diagnosis": {
+                    "description": "诊断结果",
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "healed_days": {
+                    "description": "已疗愈天数",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "notes": {
+                    "description": "备注",
+                    "type": "string"
+                },
+                "progress": {
+                    "description": "康复进展",
+                    "type": "string"
+                },
+                "treatment": {
+                    "description": "治疗方案",
+                    "type": "string"
+                },
+                "treatment_start_date": {
+                    "description": "治疗开始日期",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/DAO.User"
+                },
+                "user_id": {
+                    "description": "家长ID",
+                    "type": "string"
+                }
+            }
+        },
+        "DAO.Course": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "content": {
+                    "description": "课程内容",
+                    "type": "string"
+                },
+                "cover_image": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "课程时长(分钟)",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_free": {
+                    "type": "boolean"
+                },
+                "level": {
+                    "description": "初级/中级/高级",
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "status": {
+                    "description": "published, draft",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "video_url": {
+                    "type": "string"
+                },
+                "view_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "DAO.Game": {
+            "type": "object",
+            "properties": {
+                "age_range": {
+                    "description": "适用年龄段",
+                    "type": "string"
+                },
+                "benefits": {
+                    "description": "训练效果",
+                    "type": "string"
+                },
+                "category": {
+                    "description": "认知训练/感统训练等",
+                    "type": "string"
+                },
+                "cover_image": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "difficulty": {
+                    "description": "难度等级",
+                    "type": "string"
+                },
+                "game_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "instructions": {
+                    "description": "游戏说明",
+                    "type": "string"
+                },
+                "play_count": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "status": {
+                    "description": "published, draft",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "DAO.User": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "certificate": {
+                    "type": "string"
+                },
+                "certification": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "identity": {
+                    "description": "身份类型",
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "institution_id": {
+                    "description": "所属机构（身份为机构的用户ID），用于机构AI用量配额",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "DAO.UserFavorite": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "description": "course, game, article",
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/DAO.User"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "DAO.VirtualTherapist": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "experience": {
+                    "description": "经验年限",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "specialization": {
+                    "description": "专业特长",
+                    "type": "string"
+                },
+                "therapist_type": {
+                    "description": "专业领域",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/DAO.User"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "gorm.DeletedAt": {
+            "type": "object",
+            "properties": {
+                "time": {
+                    "type": "string"
+                },
+                "valid": {
+                    "description": "Valid is true if Time is not NULL",
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.ChildLogTemplate": {
+            "type": "object",
+            "properties": {
+                "assigned_by": {
+                    "type": "string"
+                },
+                "child_archive_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "diagnosis, manual",
+                    "type": "string"
+                },
+                "template": {
+                    "$ref": "#/definitions/model.LogTemplate"
+                },
+                "template_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ChildMilestone": {
+            "type": "object",
+            "properties": {
+                "badge": {
+                    "type": "string"
+                },
+                "child_archive_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "days": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reached_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ChildTherapistGrant": {
+            "type": "object",
+            "properties": {
+                "child_archive_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "granted_by": {
+                    "description": "授权的家长或管理员",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "therapist_user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CompanionConversation": {
+            "type": "object",
+            "properties": {
+                "child_archive_id": {
+                    "type": "string"
+                },
+                "companion_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "filtered_count": {
+                    "description": "触发内容过滤的消息数",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_message_at": {
+                    "type": "string"
+                },
+                "memory_summary": {
+                    "description": "已合并消息的记忆摘要",
+                    "type": "string"
+                },
+                "message_count": {
+                    "type": "integer"
+                },
+                "summarized_until": {
+                    "description": "已合并进记忆摘要的最后一条消息ID",
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "发起对话的家长",
+                    "type": "string"
+                }
+            }
+        },
+        "model.CompanionMessage": {
+            "type": "object",
+            "properties": {
+                "audio_url": {
+                    "description": "陪伴回复的合成语音",
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "conversation_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "filter_category": {
+                    "description": "命中规则的类别",
+                    "type": "string"
+                },
+                "filtered": {
+                    "description": "是否触发内容过滤",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "child, companion",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ConsultationEscalation": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "child_archive_id": {
+                    "type": "string"
+                },
+                "closed_at": {
+                    "description": "结束或撤回时间",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "requester_id": {
+                    "type": "string"
+                },
+                "resolution_note": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "therapist_user_id": {
+                    "description": "指定或接手的康复师，为空时任一认证康复师都可接手",
+                    "type": "string"
+                },
+                "thread_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ConsultationMessage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "parent, assistant, therapist",
+                    "type": "string"
+                },
+                "sender_id": {
+                    "description": "家长或康复师的用户ID，虚拟导师为空",
+                    "type": "string"
+                },
+                "sources": {
+                    "description": "回复引用的资料",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ConsultationSource"
+                    }
+                },
+                "thread_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ConsultationSource": {
+            "type": "object",
+            "properties": {
+                "excerpt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "archive, healing_log, report",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ConsultationThread": {
+            "type": "object",
+            "properties": {
+                "child_archive_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_message_at": {
+                    "type": "string"
+                },
+                "message_count": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "open, escalated",
+                    "type": "string"
+                },
+                "therapist_user_id": {
+                    "description": "接手咨询的认证康复师",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "发起咨询的家长",
+                    "type": "string"
+                },
+                "virtual_therapist_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GameSession": {
+            "type": "object",
+            "properties": {
+                "child_archive_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duration_seconds": {
+                    "type": "integer"
+                },
+                "game_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "played_at": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GoalLink": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "goal_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "target_id": {
+                    "type": "integer"
+                },
+                "target_type": {
+                    "description": "healing_log, game_session",
+                    "type": "string"
+                }
+            }
+        },
+        "model.HallucinationFlag": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "description": "问题说明",
+                    "type": "string"
+                },
+                "excerpt": {
+                    "description": "报告中的原文",
+                    "type": "string"
+                },
+                "section": {
+                    "description": "所在章节",
+                    "type": "string"
+                }
+            }
+        },
+        "model.HealingLog": {
+            "type": "object",
+            "properties": {
+                "childArchiveID": {
+                    "type": "integer"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "goalIDs": {
+                    "description": "创建时关联的治疗目标ID",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "media": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LogMedia"
+                    }
+                },
+                "metrics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LogMetric"
+                    }
+                },
+                "phase": {
+                    "type": "string"
+                },
+                "skill": {
+                    "type": "string"
+                },
+                "templateAnswers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LogTemplateAnswer"
+                    }
+                },
+                "templateID": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.InstitutionBranding": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "description": "信头显示的机构名称",
+                    "type": "string"
+                },
+                "footer_text": {
+                    "description": "页脚文字，如保密声明",
+                    "type": "string"
+                },
+                "has_logo": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "institution_id": {
+                    "description": "身份为机构的用户ID",
+                    "type": "string"
+                },
+                "logo_type": {
+                    "description": "png, jpeg",
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "primary_color": {
+                    "description": "主题色 #RRGGBB",
+                    "type": "string"
+                },
+                "tagline": {
+                    "description": "名称下方的副标题",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "string"
+                },
+                "website": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LogMedia": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "healingLogID": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "mediaType": {
+                    "type": "string"
+                },
+                "phase": {
+                    "type": "string"
+                },
+                "skill": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LogMetric": {
+            "type": "object",
+            "properties": {
+                "child_archive_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "healing_log_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "recorded_at": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.LogTemplate": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "diagnosis": {
+                    "description": "适用的诊断关键词，为空表示通用模板",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LogTemplateItem"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.LogTemplateAnswer": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {}
+            }
+        },
+        "model.LogTemplateItem": {
+            "type": "object",
+            "properties": {
+                "hint": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "max": {
+                    "description": "scale 的最大值",
+                    "type": "integer"
+                },
+                "min": {
+                    "description": "scale 的最小值",
+                    "type": "integer"
+                },
+                "options": {
+                    "description": "checklist 的可选项",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "description": "question, checklist, scale",
+                    "type": "string"
+                }
+            }
+        },
+        "model.Notification": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_read": {
+                    "type": "boolean"
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "related_id": {
+                    "type": "string"
+                },
+                "related_type": {
+                    "description": "关联资源类型，如 child_archive",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PromptTemplate": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "language": {
+                    "description": "模板语言，其他语言的模板只能在该语言下启用",
+                    "type": "string"
+                },
+                "note": {
+                    "description": "版本说明",
+                    "type": "string"
+                },
+                "output_schema": {
+                    "description": "报告输出结构的 JSON Schema",
+                    "type": "string"
+                },
+                "report_type_id": {
+                    "type": "integer"
+                },
+                "system_prompt": {
+                    "description": "系统消息，为空时使用默认系统消息",
+                    "type": "string"
+                },
+                "template": {
+                    "description": "Go text/template 模板，数据为日志、指标和儿童档案",
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RedactionEntry": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "child_name, child_given_name, name, phone, id_number, email, address, school",
+                    "type": "string"
+                },
+                "count": {
+                    "description": "替换次数",
+                    "type": "integer"
+                },
+                "masked": {
+                    "description": "掩码后的原文",
+                    "type": "string"
+                },
+                "placeholder": {
+                    "description": "发送给模型的占位符",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ReportFeedback": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "description": "准确性 1-5",
+                    "type": "integer"
+                },
+                "adopted_count": {
+                    "description": "采纳的建议数",
+                    "type": "integer"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "hallucination_count": {
+                    "description": "标记的幻觉数",
+                    "type": "integer"
+                },
+                "hallucinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.HallucinationFlag"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "report_edited": {
+                    "description": "评价时报告是否经过人工编辑",
+                    "type": "boolean"
+                },
+                "report_id": {
+                    "type": "integer"
+                },
+                "report_type": {
+                    "description": "报告类型标识",
+                    "type": "string"
+                },
+                "report_version": {
+                    "description": "评价时报告的版本",
+                    "type": "integer"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                },
+                "safety": {
+                    "description": "安全性 1-5",
+                    "type": "integer"
+                },
+                "sections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SectionFeedback"
+                    }
+                },
+                "suggestion_count": {
+                    "description": "评价的建议数",
+                    "type": "integer"
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SuggestionFeedback"
+                    }
+                },
+                "template_id": {
+                    "type": "integer"
+                },
+                "template_version": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "usefulness": {
+                    "description": "实用性 1-5",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ReportSafetyReview": {
+            "type": "object",
+            "properties": {
+                "child_archive_id": {
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "findings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SafetyFinding"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "institution_id": {
+                    "description": "负责审核的机构，为空时由管理员审核",
+                    "type": "string"
+                },
+                "job_id": {
+                    "description": "后台任务生成时对应的任务",
+                    "type": "integer"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "redaction_log": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RedactionEntry"
+                    }
+                },
+                "report_id": {
+                    "description": "审核通过后保存的报告",
+                    "type": "integer"
+                },
+                "report_type": {
+                    "type": "string"
+                },
+                "requested_by": {
+                    "description": "报告发起人",
+                    "type": "string"
+                },
+                "review_note": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewer_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "structured_content": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "template_id": {
+                    "type": "integer"
+                },
+                "template_version": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ReportType": {
+            "type": "object",
+            "properties": {
+                "active_version": {
+                    "description": "当前启用的中文模板版本号，其他语言没有专用模板时也使用该版本",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "报告类型标识，如 summary",
+                    "type": "string"
+                },
+                "localized_versions": {
+                    "description": "其他语言当前启用的模板版本号，键为语言代码",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SafetyFinding": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "block, annotate, escalate",
+                    "type": "string"
+                },
+                "category": {
+                    "description": "medication, diagnosis, crisis, self_harm, abuse 等",
+                    "type": "string"
+                },
+                "excerpt": {
+                    "description": "命中的原文片段",
+                    "type": "string"
+                },
+                "log_time": {
+                    "description": "命中疗愈记录时为记录时间",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "展示给用户的说明",
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "命中的规则名，分类器命中时为 classifier",
+                    "type": "string"
+                },
+                "scope": {
+                    "description": "output, logs",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "分类器得分",
+                    "type": "number"
+                }
+            }
+        },
+        "model.SectionFeedback": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "type": "integer"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "safety": {
+                    "type": "integer"
+                },
+                "section": {
+                    "description": "章节标题",
+                    "type": "string"
+                },
+                "usefulness": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ShareAccessLog": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "description": "下载的文件格式",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "description": "viewed, downloaded, bad_code, needs_code, expired, revoked",
+                    "type": "string"
+                },
+                "share_link_id": {
+                    "type": "integer"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SuggestionFeedback": {
+            "type": "object",
+            "properties": {
+                "adopted": {
+                    "type": "boolean"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "suggestion": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.TreatmentGoal": {
+            "type": "object",
+            "properties": {
+                "achieved_at": {
+                    "type": "string"
+                },
+                "baseline_value": {
+                    "description": "指标基线值，为空时取首次记录",
+                    "type": "number"
+                },
+                "child_archive_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "domain": {
+                    "description": "language, social, motor, emotion",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "metric_name": {
+                    "description": "用于计算进度的日志指标",
+                    "type": "string"
+                },
+                "plan_id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "target_count": {
+                    "description": "目标练习次数（关联的日志和游戏记录）",
+                    "type": "integer"
+                },
+                "target_criteria": {
+                    "description": "达成标准描述",
+                    "type": "string"
+                },
+                "target_date": {
+                    "type": "string"
+                },
+                "target_value": {
+                    "description": "指标目标值",
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.AICompanionRequest": {
+            "type": "object",
+            "required": [
+                "companion_type",
+                "name"
+            ],
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "companion_type": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "personality": {
+                    "type": "string"
+                },
+                "voice_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.AssignLogTemplateRequest": {
+            "type": "object",
+            "required": [
+                "template_id"
+            ],
+            "properties": {
+                "template_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "request.CertificationRequest": {
+            "type": "object",
+            "required": [
+                "certificate_name",
+                "certificate_no",
+                "certificate_type",
+                "issue_date",
+                "issuing_authority"
+            ],
+            "properties": {
+                "certificate_name": {
+                    "type": "string"
+                },
+                "certificate_no": {
+                    "type": "string"
+                },
+                "certificate_type": {
+                    "description": "机构认证/康复师认证",
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "issue_date": {
+                    "type": "string"
+                },
+                "issuing_authority": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.ChildArchiveRequest": {
+            "type": "object",
+            "required": [
+                "birth_date",
+                "child_name",
+                "gender"
+            ],
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "birth_date": {
+                    "type": "string"
+                },
+                "child_name": {
+                    "type": "string"
+                },
+                "condition": {
+                    "type": "string"
+                },
+                "diagnosis": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "progress": {
+                    "type": "string"
+                },
+                "treatment": {
+                    "type": "string"
+                },
+                "treatment_start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.CloseEscalationRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "已建议降低训练强度，两周后复评"
+                }
+            }
+        },
+        "request.CompanionMessageRequest": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "我今天在幼儿园搭了一个很高的积木"
+                },
+                "with_audio": {
+                    "description": "是否以陪伴的语音类型合成回复语音",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "request.ConsultationMessageRequest": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "孩子这两周睡前总是哭闹，和训练强度有关系吗？"
+                }
+            }
+        },
+        "request.CreateReportTypeRequest": {
+            "type": "object",
+            "required": [
+                "key",
+                "name",
+                "template"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "按月回顾康复进展"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "monthly_review"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "月度回顾"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "初始版本"
+                },
+                "output_schema": {
+                    "type": "string"
+                },
+                "system_prompt": {
+                    "type": "string",
+                    "example": "你是一位专业的儿童康复治疗师"
+                },
+                "template": {
+                    "type": "string",
+                    "example": "{{template \"logs\" .}}请总结本月进展。"
+                }
+            }
+        },
+        "request.CreateShareLinkRequest": {
+            "type": "object",
+            "required": [
+                "resource_type"
+            ],
+            "properties": {
+                "access_code": {
+                    "description": "可选的访问码，4-12位",
+                    "type": "string",
+                    "example": "2468"
+                },
+                "child_archive_id": {
+                    "description": "分享档案时必填",
+                    "type": "string",
+                    "example": "1"
+                },
+                "end_date": {
+                    "description": "档案的结束日期，包含当天",
+                    "type": "string",
+                    "example": "2024-01-31"
+                },
+                "expires_in_hours": {
+                    "description": "有效小时数，默认72小时",
+                    "type": "integer",
+                    "example": 72
+                },
+                "log_ids": {
+                    "description": "档案中包含的日志，为空时包含最近的日志",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "log_limit": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "recipient": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "班主任王老师"
+                },
+                "report_id": {
+                    "description": "分享报告时必填",
+                    "type": "integer",
+                    "example": 1
+                },
+                "report_limit": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "resource_type": {
+                    "description": "report 分享AI报告，dossier 分享儿童康复档案快照",
+                    "type": "string",
+                    "enum": [
+                        "report",
+                        "dossier"
+                    ],
+                    "example": "report"
+                },
+                "start_date": {
+                    "description": "档案的开始日期",
+                    "type": "string",
+                    "example": "2024-01-01"
+                }
+            }
+        },
+        "request.EscalateConsultationRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "希望康复师评估是否需要调整训练计划"
+                },
+                "therapist_user_id": {
+                    "description": "指定的认证康复师，为空时任一认证康复师都可接手",
+                    "type": "string",
+                    "example": "u123"
+                }
+            }
+        },
+        "request.FavoriteRequest": {
+            "type": "object",
+            "required": [
+                "resource_id",
+                "resource_type"
+            ],
+            "properties": {
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "description": "course, game, article",
+                    "type": "string"
+                }
+            }
+        },
+        "request.GameSessionRequest": {
+            "type": "object",
+            "required": [
+                "child_archive_id",
+                "game_id"
+            ],
+            "properties": {
+                "child_archive_id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "duration_seconds": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 300
+                },
+                "game_id": {
+                    "type": "string",
+                    "example": "game_001"
+                },
+                "goal_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "能够独立完成第二关"
+                },
+                "played_at": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number",
+                    "example": 85
+                }
+            }
+        },
+        "request.GoalLinkRequest": {
+            "type": "object",
+            "required": [
+                "target_id",
+                "target_type"
+            ],
+            "properties": {
+                "target_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "target_type": {
+                    "type": "string",
+                    "enum": [
+                        "healing_log",
+                        "game_session"
+                    ],
+                    "example": "healing_log"
+                }
+            }
+        },
+        "request.GrantTherapistRequest": {
+            "type": "object",
+            "required": [
+                "child_archive_id",
+                "therapist_user_id"
+            ],
+            "properties": {
+                "child_archive_id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "每周康复训练的主治康复师"
+                },
+                "therapist_user_id": {
+                    "type": "string",
+                    "example": "u123"
+                }
+            }
+        },
+        "request.HallucinationFlagRequest": {
+            "type": "object",
+            "required": [
+                "comment"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "日志中没有相关记录"
+                },
+                "excerpt": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "本周能说出完整句子"
+                },
+                "section": {
+                    "type": "string",
+                    "example": "语言发展"
+                }
+            }
+        },
+        "request.LogTemplateRequest": {
+            "type": "object",
+            "required": [
+                "items",
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "适用于孤独症谱系儿童的每日观察记录"
+                },
+                "diagnosis": {
+                    "type": "string",
+                    "example": "孤独症"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LogTemplateItem"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "孤独症日常观察"
+                }
+            }
+        },
+        "request.LoginRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "password"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.MarkLogPhaseRequest": {
+            "type": "object",
+            "properties": {
+                "media_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "phase": {
+                    "type": "string",
+                    "enum": [
+                        "baseline",
+                        "follow_up"
+                    ],
+                    "example": "baseline"
+                },
+                "skill": {
+                    "type": "string",
+                    "example": "语言表达"
+                }
+            }
+        },
+        "request.PreviewPromptRequest": {
+            "type": "object",
+            "properties": {
+                "child_archive_id": {
+                    "description": "为空时使用示例数据",
+                    "type": "string",
+                    "example": "1"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2024-01-31"
+                },
+                "language": {
+                    "description": "报告语言，为空时为中文",
+                    "type": "string",
+                    "example": "en"
+                },
+                "output_schema": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "system_prompt": {
+                    "type": "string"
+                },
+                "template": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "为0时使用报告语言的当前版本",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "request.PromptTemplateRequest": {
+            "type": "object",
+            "required": [
+                "template"
+            ],
+            "properties": {
+                "activate": {
+                    "description": "是否立即启用该版本",
+                    "type": "boolean",
+                    "example": true
+                },
+                "language": {
+                    "description": "模板语言，为空时为中文，启用后仅用于该语言的报告",
+                    "type": "string",
+                    "example": "en"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "增加指标汇总"
+                },
+                "output_schema": {
+                    "type": "string"
+                },
+                "system_prompt": {
+                    "type": "string",
+                    "example": "你是一位专业的儿童康复治疗师"
+                },
+                "template": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.Register": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "identity": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.ReportFeedbackRequest": {
+            "type": "object",
+            "required": [
+                "accuracy",
+                "safety",
+                "usefulness"
+            ],
+            "properties": {
+                "accuracy": {
+                    "description": "准确性",
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1,
+                    "example": 4
+                },
+                "comment": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "整体符合日志记录"
+                },
+                "hallucinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/request.HallucinationFlagRequest"
+                    }
+                },
+                "safety": {
+                    "description": "安全性",
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1,
+                    "example": 5
+                },
+                "sections": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/request.SectionFeedbackRequest"
+                    }
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/request.SuggestionFeedbackRequest"
+                    }
+                },
+                "usefulness": {
+                    "description": "实用性",
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1,
+                    "example": 4
+                }
+            }
+        },
+        "request.SafetyReviewRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "description": "审核意见",
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "已与家长电话沟通，报告可以发布"
+                }
+            }
+        },
+        "request.SectionFeedbackRequest": {
+            "type": "object",
+            "required": [
+                "section"
+            ],
+            "properties": {
+                "accuracy": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 0,
+                    "example": 3
+                },
+                "comment": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "safety": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 0,
+                    "example": 5
+                },
+                "section": {
+                    "type": "string",
+                    "example": "语言发展"
+                },
+                "usefulness": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 0,
+                    "example": 4
+                }
+            }
+        },
+        "request.SetUserInstitutionRequest": {
+            "type": "object",
+            "properties": {
+                "institution_id": {
+                    "description": "为空时解除关联",
+                    "type": "string",
+                    "example": "inst-001"
+                }
+            }
+        },
+        "request.StartCompanionConversationRequest": {
+            "type": "object",
+            "required": [
+                "child_archive_id",
+                "companion_id"
+            ],
+            "properties": {
+                "child_archive_id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "companion_id": {
+                    "type": "string",
+                    "example": "c1a2b3"
+                },
+                "title": {
+                    "description": "为空时按陪伴名称和日期生成",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "睡前聊天"
+                }
+            }
+        },
+        "request.StartConsultationRequest": {
+            "type": "object",
+            "required": [
+                "child_archive_id",
+                "virtual_therapist_id"
+            ],
+            "properties": {
+                "child_archive_id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "title": {
+                    "description": "为空时按导师名称和日期生成",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "最近睡前情绪波动"
+                },
+                "virtual_therapist_id": {
+                    "type": "string",
+                    "example": "t1a2b3"
+                }
+            }
+        },
+        "request.SuggestionFeedbackRequest": {
+            "type": "object",
+            "required": [
+                "suggestion"
+            ],
+            "properties": {
+                "adopted": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "comment": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "suggestion": {
+                    "type": "string",
+                    "maxLength": 1000,
+                    "example": "每天进行15分钟绘本共读"
+                }
+            }
+        },
+        "request.TreatmentGoalRequest": {
+            "type": "object",
+            "required": [
+                "domain",
+                "title"
+            ],
+            "properties": {
+                "baseline_value": {
+                    "type": "number",
+                    "example": 1
+                },
+                "description": {
+                    "type": "string",
+                    "example": "在日常场景中主动提出需求"
+                },
+                "domain": {
+                    "type": "string",
+                    "enum": [
+                        "language",
+                        "social",
+                        "motor",
+                        "emotion"
+                    ],
+                    "example": "language"
+                },
+                "metric_name": {
+                    "type": "string",
+                    "example": "主动表达次数"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "not_started",
+                        "in_progress",
+                        "achieved",
+                        "paused",
+                        "dropped"
+                    ],
+                    "example": "in_progress"
+                },
+                "target_count": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 20
+                },
+                "target_criteria": {
+                    "type": "string",
+                    "example": "连续一周每天至少三次主动表达"
+                },
+                "target_date": {
+                    "type": "string"
+                },
+                "target_value": {
+                    "type": "number",
+                    "example": 5
+                },
+                "title": {
+                    "type": "string",
+                    "example": "能用完整句子表达需求"
+                }
+            }
+        },
+        "request.TreatmentPlanRequest": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "child_archive_id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "以提升主动表达为主"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "goals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/request.TreatmentGoalRequest"
+                    }
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "completed",
+                        "archived"
+                    ],
+                    "example": "active"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "第一阶段语言训练方案"
+                }
+            }
+        },
+        "request.UpdateBrandingRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "北京市海淀区某某路1号"
+                },
+                "display_name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "星语儿童康复中心"
+                },
+                "footer_text": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "本报告仅供机构内部及家长使用"
+                },
+                "institution_id": {
+                    "description": "仅管理员需要指定",
+                    "type": "string",
+                    "example": "inst-001"
+                },
+                "logo": {
+                    "description": "base64 编码的 PNG 或 JPEG，不超过 512KB，为空时保留原徽标",
+                    "type": "string",
+                    "example": "iVBORw0KGgo..."
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "010-12345678"
+                },
+                "primary_color": {
+                    "description": "#RRGGBB，为空时使用平台主题色",
+                    "type": "string",
+                    "example": "#3A7BD5"
+                },
+                "remove_logo": {
+                    "type": "boolean"
+                },
+                "tagline": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "专注孤独症儿童早期干预"
+                },
+                "website": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "https://example.com"
+                }
+            }
+        },
+        "request.UpdateHealingLogRequest": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "今天孩子主动和小朋友打招呼"
+                }
+            }
+        },
+        "request.UpdateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.UpdateReportTypeRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "月度回顾"
+                }
+            }
+        },
+        "request.VirtualTherapistRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "therapist_type"
+            ],
+            "properties": {
+                "avatar": {
+                    "type": "string"
                 },
-                "user_id": {
+                "experience": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
-                "voice_type": {
+                "specialization": {
+                    "type": "string"
+                },
+                "therapist_type": {
                     "type": "string"
                 }
             }
         },
-        "DAO.Certification": {
+        "response.ChildArchiveResponse": {
             "type": "object",
             "properties": {
-                "certificate_name": {
+                "age": {
+                    "description": "计算得出的年龄",
+                    "type": "integer"
+                },
+                "avatar": {
+                    "type": "string"
+                },
+                "birth_date": {
+                    "type": "string"
+                },
+                "child_name": {
+                    "type": "string"
+                },
+                "condition": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "diagnosis": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "healed_days": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "progress": {
+                    "type": "string"
+                },
+                "treatment": {
+                    "type": "string"
+                },
+                "treatment_start_date": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.ChildProfileResponse": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "description": "计算得出的年龄",
+                    "type": "integer"
+                },
+                "avatar": {
+                    "type": "string"
+                },
+                "birth_date": {
+                    "type": "string"
+                },
+                "child_name": {
+                    "type": "string"
+                },
+                "condition": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "diagnosis": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "goals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.GoalWithProgress"
+                    }
+                },
+                "healed_days": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "progress": {
+                    "$ref": "#/definitions/service.ChildProgress"
+                },
+                "treatment": {
+                    "type": "string"
+                },
+                "treatment_start_date": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {},
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.CalendarDay": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "log_count": {
+                    "type": "integer"
+                },
+                "media_count": {
+                    "type": "integer"
+                },
+                "metric_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ChildCalendar": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.CalendarDay"
+                    }
+                },
+                "month": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.ChildProgress": {
+            "type": "object",
+            "properties": {
+                "current_streak": {
+                    "description": "当前连续记录天数",
+                    "type": "integer"
+                },
+                "healed_days": {
+                    "description": "治疗开始后有记录的天数",
+                    "type": "integer"
+                },
+                "last_log_date": {
+                    "type": "string"
+                },
+                "longest_streak": {
+                    "description": "最长连续记录天数",
+                    "type": "integer"
+                },
+                "milestones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ChildMilestone"
+                    }
+                },
+                "next_milestone": {
+                    "description": "下一个里程碑天数，全部达成时为0",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.CompanionExchange": {
+            "type": "object",
+            "properties": {
+                "conversation": {
+                    "$ref": "#/definitions/model.CompanionConversation"
+                },
+                "message": {
+                    "$ref": "#/definitions/model.CompanionMessage"
+                },
+                "reply": {
+                    "$ref": "#/definitions/model.CompanionMessage"
+                }
+            }
+        },
+        "service.CompanionTranscript": {
+            "type": "object",
+            "properties": {
+                "companion_name": {
+                    "type": "string"
+                },
+                "conversation": {
+                    "$ref": "#/definitions/model.CompanionConversation"
+                },
+                "has_more": {
+                    "description": "是否还有更早的消息",
+                    "type": "boolean"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CompanionMessage"
+                    }
+                }
+            }
+        },
+        "service.ComparisonEntry": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "log_id": {
+                    "type": "integer"
+                },
+                "metrics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LogMetric"
+                    }
+                }
+            }
+        },
+        "service.ComparisonPair": {
+            "type": "object",
+            "properties": {
+                "baseline": {
+                    "$ref": "#/definitions/service.ComparisonEntry"
+                },
+                "follow_up": {
+                    "$ref": "#/definitions/service.ComparisonEntry"
+                }
+            }
+        },
+        "service.ComparisonPeriod": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.ConsultationDetail": {
+            "type": "object",
+            "properties": {
+                "escalations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ConsultationEscalation"
+                    }
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ConsultationMessage"
+                    }
+                },
+                "therapist_name": {
+                    "description": "虚拟疗愈导师的名称",
+                    "type": "string"
+                },
+                "thread": {
+                    "$ref": "#/definitions/model.ConsultationThread"
+                }
+            }
+        },
+        "service.ConsultationExchange": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "$ref": "#/definitions/model.ConsultationMessage"
+                },
+                "reply": {
+                    "$ref": "#/definitions/model.ConsultationMessage"
+                },
+                "thread": {
+                    "$ref": "#/definitions/model.ConsultationThread"
+                }
+            }
+        },
+        "service.FeedbackAnalytics": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.FeedbackGroupStats"
+                    }
+                },
+                "reviews": {
+                    "type": "integer"
+                },
+                "start": {
                     "type": "string"
+                }
+            }
+        },
+        "service.FeedbackGroupStats": {
+            "type": "object",
+            "properties": {
+                "adopted_suggestions": {
+                    "type": "integer"
+                },
+                "adoption_rate": {
+                    "description": "建议采纳率",
+                    "type": "number"
                 },
-                "certificate_no": {
-                    "type": "string"
+                "avg_accuracy": {
+                    "type": "number"
                 },
-                "certificate_type": {
-                    "description": "机构认证/康复师认证",
-                    "type": "string"
+                "avg_safety": {
+                    "type": "number"
                 },
-                "created_at": {
-                    "type": "string"
+                "avg_usefulness": {
+                    "type": "number"
                 },
-                "expiry_date": {
-                    "type": "string"
+                "edited_reviews": {
+                    "description": "评价时报告已被人工编辑的评价数",
+                    "type": "integer"
                 },
-                "id": {
-                    "type": "string"
+                "hallucinated_reviews": {
+                    "description": "至少标记了一处幻觉的评价数",
+                    "type": "integer"
                 },
-                "issue_date": {
-                    "type": "string"
+                "hallucination_flags": {
+                    "type": "integer"
                 },
-                "issuing_authority": {
+                "hallucination_rate": {
+                    "description": "标记了幻觉的评价占比",
+                    "type": "number"
+                },
+                "model": {
                     "type": "string"
                 },
-                "review_notes": {
+                "provider": {
                     "type": "string"
                 },
-                "status": {
-                    "description": "pending, approved, rejected",
+                "report_type": {
                     "type": "string"
                 },
-                "updated_at": {
+                "report_type_name": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/DAO.User"
+                "reports": {
+                    "type": "integer"
                 },
-                "user_id": {
-                    "type": "string"
+                "reviews": {
+                    "type": "integer"
+                },
+                "suggestions": {
+                    "type": "integer"
+                },
+                "template_id": {
+                    "type": "integer"
+                },
+                "template_version": {
+                    "type": "integer"
                 }
             }
         },
-        "DAO.ChildArchive": {
+        "service.GoalDetail": {
             "type": "object",
             "properties": {
-                "avatar": {
+                "achieved_at": {
                     "type": "string"
                 },
-                "birth_date": {
-                    "type": "string"
+                "baseline_value": {
+                    "description": "指标基线值，为空时取首次记录",
+                    "type": "number"
                 },
-                "child_name": {
+                "child_archive_id": {
                     "type": "string"
                 },
-                "condition": {
-                    "description": "病情描述",
+                "created_at": {
                     "type": "string"
                 },
-                "created_at": {
+                "description": {
                     "type": "string"
                 },
-                "diagnosis": {
-                    "description": "诊断结果",
+                "domain": {
+                    "description": "language, social, motor, emotion",
                     "type": "string"
                 },
-                "gender": {
+                "id": {
+                    "type": "integer"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.GoalLink"
+                    }
+                },
+                "metric_name": {
+                    "description": "用于计算进度的日志指标",
                     "type": "string"
                 },
-                "healed_days": {
-                    "description": "已疗愈天数",
+                "plan_id": {
                     "type": "integer"
                 },
-                "id": {
+                "progress": {
+                    "$ref": "#/definitions/service.GoalProgress"
+                },
+                "start_date": {
                     "type": "string"
                 },
-                "notes": {
-                    "description": "备注",
+                "status": {
                     "type": "string"
                 },
-                "progress": {
-                    "description": "康复进展",
+                "target_count": {
+                    "description": "目标练习次数（关联的日志和游戏记录）",
+                    "type": "integer"
+                },
+                "target_criteria": {
+                    "description": "达成标准描述",
                     "type": "string"
                 },
-                "treatment": {
-                    "description": "治疗方案",
+                "target_date": {
                     "type": "string"
                 },
-                "treatment_start_date": {
-                    "description": "治疗开始日期",
+                "target_value": {
+                    "description": "指标目标值",
+                    "type": "number"
+                },
+                "title": {
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
+                }
+            }
+        },
+        "service.GoalProgress": {
+            "type": "object",
+            "properties": {
+                "baseline_value": {
+                    "type": "number"
                 },
-                "user": {
-                    "$ref": "#/definitions/DAO.User"
+                "current_value": {
+                    "type": "number"
                 },
-                "user_id": {
-                    "description": "家长ID",
+                "days_remaining": {
+                    "description": "距目标日期的天数，已过期为负数",
+                    "type": "integer"
+                },
+                "linked_logs": {
+                    "type": "integer"
+                },
+                "linked_sessions": {
+                    "type": "integer"
+                },
+                "method": {
                     "type": "string"
+                },
+                "percent": {
+                    "description": "0-100",
+                    "type": "number"
                 }
             }
         },
-        "DAO.Course": {
+        "service.GoalWithProgress": {
             "type": "object",
             "properties": {
-                "category": {
+                "achieved_at": {
                     "type": "string"
                 },
-                "content": {
-                    "description": "课程内容",
-                    "type": "string"
+                "baseline_value": {
+                    "description": "指标基线值，为空时取首次记录",
+                    "type": "number"
                 },
-                "cover_image": {
+                "child_archive_id": {
                     "type": "string"
                 },
                 "created_at": {
@@ -1884,466 +9506,643 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
-                "duration": {
-                    "description": "课程时长(分钟)",
-                    "type": "integer"
+                "domain": {
+                    "description": "language, social, motor, emotion",
+                    "type": "string"
                 },
                 "id": {
+                    "type": "integer"
+                },
+                "metric_name": {
+                    "description": "用于计算进度的日志指标",
                     "type": "string"
                 },
-                "is_free": {
-                    "type": "boolean"
+                "plan_id": {
+                    "type": "integer"
                 },
-                "level": {
-                    "description": "初级/中级/高级",
-                    "type": "string"
+                "progress": {
+                    "$ref": "#/definitions/service.GoalProgress"
                 },
-                "price": {
-                    "type": "number"
+                "start_date": {
+                    "type": "string"
                 },
                 "status": {
-                    "description": "published, draft",
                     "type": "string"
                 },
-                "title": {
+                "target_count": {
+                    "description": "目标练习次数（关联的日志和游戏记录）",
+                    "type": "integer"
+                },
+                "target_criteria": {
+                    "description": "达成标准描述",
                     "type": "string"
                 },
-                "updated_at": {
+                "target_date": {
                     "type": "string"
                 },
-                "video_url": {
+                "target_value": {
+                    "description": "指标目标值",
+                    "type": "number"
+                },
+                "title": {
                     "type": "string"
                 },
-                "view_count": {
-                    "type": "integer"
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "DAO.Game": {
+        "service.HealingComparison": {
             "type": "object",
             "properties": {
-                "age_range": {
-                    "description": "适用年龄段",
-                    "type": "string"
+                "baseline_media": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LogMedia"
+                    }
                 },
-                "benefits": {
-                    "description": "训练效果",
-                    "type": "string"
+                "baseline_period": {
+                    "$ref": "#/definitions/service.ComparisonPeriod"
                 },
-                "category": {
-                    "description": "认知训练/感统训练等",
-                    "type": "string"
+                "follow_up_media": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LogMedia"
+                    }
                 },
-                "cover_image": {
-                    "type": "string"
+                "follow_up_period": {
+                    "$ref": "#/definitions/service.ComparisonPeriod"
                 },
-                "created_at": {
-                    "type": "string"
+                "metric_deltas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.MetricDelta"
+                    }
                 },
-                "description": {
-                    "type": "string"
+                "pairs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ComparisonPair"
+                    }
                 },
-                "difficulty": {
-                    "description": "难度等级",
+                "skill": {
                     "type": "string"
+                }
+            }
+        },
+        "service.MetricDelta": {
+            "type": "object",
+            "properties": {
+                "baseline_avg": {
+                    "type": "number"
                 },
-                "game_url": {
-                    "type": "string"
+                "baseline_count": {
+                    "type": "integer"
                 },
-                "id": {
-                    "type": "string"
+                "change_rate": {
+                    "description": "变化百分比，基线均值为0时为空",
+                    "type": "number"
                 },
-                "instructions": {
-                    "description": "游戏说明",
+                "delta": {
+                    "type": "number"
+                },
+                "follow_up_avg": {
+                    "type": "number"
+                },
+                "follow_up_count": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
-                "play_count": {
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.ModelUsageCost": {
+            "type": "object",
+            "properties": {
+                "completion_tokens": {
                     "type": "integer"
                 },
-                "rating": {
+                "estimated_cost": {
                     "type": "number"
                 },
-                "status": {
-                    "description": "published, draft",
+                "model": {
                     "type": "string"
                 },
-                "title": {
-                    "type": "string"
+                "priced": {
+                    "description": "是否配置了该模型的单价",
+                    "type": "boolean"
                 },
-                "updated_at": {
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "provider": {
                     "type": "string"
+                },
+                "requests": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
                 }
             }
         },
-        "DAO.User": {
+        "service.PromptPreview": {
             "type": "object",
             "properties": {
-                "address": {
+                "prompt": {
                     "type": "string"
                 },
-                "certificate": {
+                "report_type": {
                     "type": "string"
                 },
-                "certification": {
+                "sample_data": {
+                    "description": "是否使用示例数据渲染",
                     "type": "boolean"
                 },
-                "created_at": {
+                "system_prompt": {
                     "type": "string"
                 },
-                "email": {
-                    "type": "string"
+                "template_version": {
+                    "description": "预览草稿时为0",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.QuotaStatus": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "$ref": "#/definitions/service.QuotaWindow"
                 },
-                "id": {
-                    "type": "string"
+                "monthly": {
+                    "$ref": "#/definitions/service.QuotaWindow"
+                }
+            }
+        },
+        "service.QuotaWindow": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
                 },
-                "identity": {
-                    "description": "身份类型",
+                "period": {
+                    "description": "daily, monthly",
                     "type": "string"
                 },
-                "image": {
-                    "type": "string"
+                "remaining": {
+                    "type": "integer"
                 },
-                "name": {
+                "reset_at": {
                     "type": "string"
                 },
-                "phone": {
+                "scope": {
+                    "description": "user, institution",
                     "type": "string"
                 },
-                "updated_at": {
-                    "type": "string"
+                "used": {
+                    "type": "integer"
                 }
             }
         },
-        "DAO.UserFavorite": {
+        "service.ReportFeedbackDetail": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "feedback": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReportFeedback"
+                    }
+                },
+                "form": {
+                    "$ref": "#/definitions/service.ReportReviewForm"
+                },
+                "model": {
                     "type": "string"
                 },
-                "id": {
+                "provider": {
                     "type": "string"
                 },
-                "resource_id": {
+                "report_id": {
+                    "type": "integer"
+                },
+                "report_version": {
+                    "type": "integer"
+                },
+                "template_version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ReportLanguage": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "ISO 639-1 语言代码",
                     "type": "string"
                 },
-                "resource_type": {
-                    "description": "course, game, article",
+                "name": {
+                    "description": "中文名称",
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/DAO.User"
+                "native_name": {
+                    "description": "该语言的自称",
+                    "type": "string"
+                }
+            }
+        },
+        "service.ReportReviewForm": {
+            "type": "object",
+            "properties": {
+                "sections": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "user_id": {
-                    "type": "string"
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "DAO.VirtualTherapist": {
+        "service.ReportTypeDetail": {
             "type": "object",
             "properties": {
-                "avatar": {
-                    "type": "string"
+                "active_version": {
+                    "description": "当前启用的中文模板版本号，其他语言没有专用模板时也使用该版本",
+                    "type": "integer"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "experience": {
-                    "description": "经验年限",
-                    "type": "integer"
-                },
-                "id": {
+                "description": {
                     "type": "string"
                 },
-                "is_active": {
+                "enabled": {
                     "type": "boolean"
                 },
-                "name": {
-                    "type": "string"
+                "id": {
+                    "type": "integer"
                 },
-                "specialization": {
-                    "description": "专业特长",
+                "key": {
+                    "description": "报告类型标识，如 summary",
                     "type": "string"
                 },
-                "therapist_type": {
-                    "description": "专业领域",
+                "localized_versions": {
+                    "description": "其他语言当前启用的模板版本号，键为语言代码",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "name": {
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/DAO.User"
-                },
-                "user_id": {
-                    "type": "string"
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PromptTemplate"
+                    }
                 }
             }
         },
-        "model.HealingLog": {
-            "type": "object"
-        },
-        "request.AICompanionRequest": {
+        "service.SemanticMatch": {
             "type": "object",
-            "required": [
-                "companion_type",
-                "name"
-            ],
             "properties": {
-                "avatar": {
+                "excerpt": {
                     "type": "string"
                 },
-                "companion_type": {
-                    "type": "string"
+                "score": {
+                    "description": "余弦相似度",
+                    "type": "number"
                 },
-                "name": {
+                "source_id": {
+                    "type": "integer"
+                },
+                "source_type": {
+                    "description": "log, report, goal",
                     "type": "string"
                 },
-                "personality": {
+                "time": {
                     "type": "string"
                 },
-                "voice_type": {
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "request.CertificationRequest": {
+        "service.SemanticSyncResult": {
             "type": "object",
-            "required": [
-                "certificate_name",
-                "certificate_no",
-                "certificate_type",
-                "issue_date",
-                "issuing_authority"
-            ],
             "properties": {
-                "certificate_name": {
-                    "type": "string"
+                "indexed": {
+                    "description": "新增或内容变化后重新索引的资料数",
+                    "type": "integer"
                 },
-                "certificate_no": {
-                    "type": "string"
+                "removed": {
+                    "description": "资料已删除而移除的索引数",
+                    "type": "integer"
                 },
-                "certificate_type": {
-                    "description": "机构认证/康复师认证",
+                "unchanged": {
+                    "description": "内容未变化的资料数",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ShareLinkView": {
+            "type": "object",
+            "properties": {
+                "child_archive_id": {
                     "type": "string"
                 },
-                "expiry_date": {
+                "created_at": {
                     "type": "string"
                 },
-                "issue_date": {
+                "created_by": {
                     "type": "string"
                 },
-                "issuing_authority": {
-                    "type": "string"
-                }
-            }
-        },
-        "request.ChildArchiveRequest": {
-            "type": "object",
-            "required": [
-                "birth_date",
-                "child_name",
-                "gender"
-            ],
-            "properties": {
-                "avatar": {
+                "expires_at": {
                     "type": "string"
                 },
-                "birth_date": {
+                "has_access_code": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_viewed_at": {
                     "type": "string"
                 },
-                "child_name": {
+                "recipient": {
+                    "description": "分享对象备注，如\"班主任王老师\"",
                     "type": "string"
                 },
-                "condition": {
+                "report_id": {
+                    "type": "integer"
+                },
+                "report_version": {
+                    "description": "分享的报告版本",
+                    "type": "integer"
+                },
+                "resource_type": {
+                    "description": "report, dossier",
                     "type": "string"
                 },
-                "diagnosis": {
+                "revoked_at": {
                     "type": "string"
                 },
-                "gender": {
+                "status": {
+                    "description": "active, expired, revoked",
                     "type": "string"
                 },
-                "notes": {
+                "title": {
+                    "description": "分享的文档名称，也用作下载的文件名",
                     "type": "string"
                 },
-                "progress": {
+                "token": {
                     "type": "string"
                 },
-                "treatment": {
+                "updated_at": {
                     "type": "string"
                 },
-                "treatment_start_date": {
+                "url": {
                     "type": "string"
+                },
+                "view_count": {
+                    "type": "integer"
                 }
             }
         },
-        "request.FavoriteRequest": {
+        "service.SharedDocument": {
             "type": "object",
-            "required": [
-                "resource_id",
-                "resource_type"
-            ],
             "properties": {
-                "resource_id": {
+                "content": {
+                    "description": "报告的 Markdown 内容",
                     "type": "string"
                 },
-                "resource_type": {
-                    "description": "course, game, article",
+                "document": {
+                    "description": "与导出文件相同的文档结构",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.exportDocument"
+                        }
+                    ]
+                },
+                "expires_at": {
                     "type": "string"
-                }
-            }
-        },
-        "request.LoginRequest": {
-            "type": "object",
-            "required": [
-                "name",
-                "password"
-            ],
-            "properties": {
-                "name": {
+                },
+                "resource_type": {
                     "type": "string"
                 },
-                "password": {
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "request.Register": {
+        "service.SpeechTranscript": {
             "type": "object",
-            "required": [
-                "email",
-                "name",
-                "password"
-            ],
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "identity": {
-                    "type": "string"
+                "duration": {
+                    "description": "音频时长（纳秒），无法解析时为0",
+                    "type": "integer"
                 },
-                "name": {
+                "language": {
                     "type": "string"
                 },
-                "password": {
+                "provider": {
                     "type": "string"
                 },
-                "phone": {
+                "text": {
                     "type": "string"
                 }
             }
         },
-        "request.UpdateProfileRequest": {
+        "service.TreatmentPlanDetail": {
             "type": "object",
             "properties": {
-                "address": {
+                "child_archive_id": {
                     "type": "string"
                 },
-                "image": {
+                "created_at": {
                     "type": "string"
                 },
-                "name": {
+                "created_by": {
                     "type": "string"
                 },
-                "phone": {
+                "description": {
                     "type": "string"
-                }
-            }
-        },
-        "request.VirtualTherapistRequest": {
-            "type": "object",
-            "required": [
-                "name",
-                "therapist_type"
-            ],
-            "properties": {
-                "avatar": {
+                },
+                "end_date": {
                     "type": "string"
                 },
-                "experience": {
+                "goals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.GoalWithProgress"
+                    }
+                },
+                "id": {
                     "type": "integer"
                 },
-                "name": {
+                "progress": {
+                    "description": "未放弃目标的平均进度",
+                    "type": "number"
+                },
+                "start_date": {
                     "type": "string"
                 },
-                "specialization": {
+                "status": {
+                    "description": "active, completed, archived",
                     "type": "string"
                 },
-                "therapist_type": {
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "response.ChildArchiveResponse": {
+        "service.UsageSummary": {
             "type": "object",
             "properties": {
-                "age": {
-                    "description": "计算得出的年龄",
+                "completion_tokens": {
                     "type": "integer"
                 },
-                "avatar": {
+                "currency": {
                     "type": "string"
                 },
-                "birth_date": {
+                "end": {
                     "type": "string"
                 },
-                "child_name": {
-                    "type": "string"
+                "estimated_cost": {
+                    "type": "number"
                 },
-                "condition": {
-                    "type": "string"
+                "models": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ModelUsageCost"
+                    }
                 },
-                "created_at": {
-                    "type": "string"
+                "prompt_tokens": {
+                    "type": "integer"
                 },
-                "diagnosis": {
-                    "type": "string"
+                "requests": {
+                    "type": "integer"
                 },
-                "gender": {
+                "start": {
                     "type": "string"
                 },
-                "healed_days": {
+                "total_tokens": {
                     "type": "integer"
+                }
+            }
+        },
+        "service.VoiceLogResult": {
+            "type": "object",
+            "properties": {
+                "log": {
+                    "$ref": "#/definitions/model.HealingLog"
                 },
-                "id": {
-                    "type": "string"
+                "transcript": {
+                    "$ref": "#/definitions/service.SpeechTranscript"
+                }
+            }
+        },
+        "service.exportBlock": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "description": "图片像素高度",
+                    "type": "integer"
                 },
-                "notes": {
-                    "type": "string"
+                "image": {
+                    "description": "PNG 图片，JSON 中为 base64",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "progress": {
-                    "type": "string"
+                "items": {
+                    "description": "列表项",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "treatment": {
+                "kind": {
                     "type": "string"
                 },
-                "treatment_start_date": {
-                    "type": "string"
+                "level": {
+                    "description": "标题级别 1-3",
+                    "type": "integer"
                 },
-                "updated_at": {
+                "rows": {
+                    "description": "表格，第一行为表头",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "text": {
+                    "description": "标题、段落和说明的文字",
                     "type": "string"
+                },
+                "width": {
+                    "description": "图片像素宽度",
+                    "type": "integer"
+                },
+                "widths": {
+                    "description": "表格各列的相对宽度，为空时平均分配",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
                 }
             }
         },
-        "response.ErrorResponse": {
+        "service.exportDocument": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer"
+                "blocks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.exportBlock"
+                    }
                 },
-                "message": {
+                "fields": {
+                    "description": "标题下方的基本信息",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.exportField"
+                    }
+                },
+                "subtitle": {
+                    "type": "string"
+                },
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "response.SuccessResponse": {
+        "service.exportField": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer"
+                "label": {
+                    "type": "string"
                 },
-                "data": {},
-                "message": {
+                "value": {
                     "type": "string"
                 }
             }
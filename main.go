@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"melody_cure/DAO"
 	"melody_cure/config"
@@ -29,6 +30,17 @@ func main() {
 		panic(fmt.Sprintf("初始化应用失败: %v", err))
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 启动报告生成任务
+	app.ReportJobs.StartWorkers(ctx, config.GetReportJobConfig().Workers)
+
+	// 启动定时任务
+	if config.GetSchedulerConfig().Enabled {
+		app.Scheduler.Start(ctx)
+	}
+
 	if err := app.Engine.Run(":8080"); err != nil {
 		panic(fmt.Sprintf("服务启动失败: %v", err))
 	}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+)
+
+// 大模型调用来源
+const (
+	AIUsageSourceJob          = "job"          // 报告生成任务
+	AIUsageSourceStream       = "stream"       // 流式生成报告
+	AIUsageSourceTranslate    = "translate"    // 翻译报告
+	AIUsageSourceCompanion    = "companion"    // AI陪伴对话
+	AIUsageSourceConsultation = "consultation" // 虚拟疗愈导师咨询
+)
+
+// AIUsageRecord 一次大模型调用的 token 用量
+type AIUsageRecord struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	UserID           string    `gorm:"type:varchar(64);index:idx_ai_usage_user" json:"user_id"`
+	InstitutionID    string    `gorm:"type:varchar(64);index:idx_ai_usage_institution" json:"institution_id"` // 调用时用户所属的机构
+	Identity         string    `gorm:"type:varchar(20)" json:"identity"`                                      // 调用时用户的身份
+	Source           string    `gorm:"type:varchar(20)" json:"source"`                                        // job, stream, translate, companion, consultation
+	ReportType       string    `gorm:"type:varchar(50)" json:"report_type"`
+	Provider         string    `gorm:"type:varchar(50)" json:"provider"`
+	Model            string    `gorm:"type:varchar(100);index" json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Estimated        bool      `json:"estimated"` // 提供商未返回用量时按文本长度估算
+	CreatedAt        time.Time `gorm:"index:idx_ai_usage_user;index:idx_ai_usage_institution;index" json:"created_at"`
+}
+
+func (AIUsageRecord) TableName() string {
+	return "ai_usage_records"
+}
+
+// AIModelUsage 按模型汇总的用量
+type AIModelUsage struct {
+	Provider         string `json:"provider"`
+	Model            string `json:"model"`
+	Requests         int64  `json:"requests"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	TotalTokens      int64  `json:"total_tokens"`
+}
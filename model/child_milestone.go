@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+// ChildMilestone 儿童疗愈里程碑（坚持疗愈7天、30天、100天等），同时作为家庭获得的徽章
+type ChildMilestone struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ChildArchiveID string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_child_milestone" json:"child_archive_id"`
+	Days           int       `gorm:"not null;uniqueIndex:idx_child_milestone" json:"days"`
+	Badge          string    `gorm:"type:varchar(50)" json:"badge"`
+	ReachedAt      time.Time `json:"reached_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (ChildMilestone) TableName() string {
+	return "child_milestones"
+}
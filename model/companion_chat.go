@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+)
+
+// 陪伴对话中的消息角色
+const (
+	CompanionRoleChild     = "child"     // 儿童通过家长的设备发送的消息
+	CompanionRoleCompanion = "companion" // AI陪伴的回复
+)
+
+// CompanionConversation 儿童与AI陪伴的一段对话。较早的消息滚动合并为记忆摘要，
+// 每次请求只携带记忆摘要和最近的消息
+type CompanionConversation struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	CompanionID     string     `gorm:"type:varchar(64);not null;index" json:"companion_id"`
+	ChildArchiveID  string     `gorm:"type:varchar(64);not null;index" json:"child_archive_id"`
+	UserID          string     `gorm:"type:varchar(64);not null;index" json:"user_id"` // 发起对话的家长
+	Title           string     `gorm:"type:varchar(100)" json:"title"`
+	MemorySummary   string     `gorm:"type:text" json:"memory_summary"` // 已合并消息的记忆摘要
+	SummarizedUntil uint       `json:"summarized_until"`                // 已合并进记忆摘要的最后一条消息ID
+	MessageCount    int        `json:"message_count"`
+	FilteredCount   int        `json:"filtered_count"` // 触发内容过滤的消息数
+	LastMessageAt   *time.Time `gorm:"index" json:"last_message_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (CompanionConversation) TableName() string {
+	return "companion_conversations"
+}
+
+// CompanionMessage 陪伴对话中的一条消息。儿童消息保存原文供家长查看，
+// 被过滤的陪伴回复保存实际展示给儿童的替代回复
+type CompanionMessage struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ConversationID uint      `gorm:"not null;index" json:"conversation_id"`
+	Role           string    `gorm:"type:varchar(20);not null" json:"role"` // child, companion
+	Content        string    `gorm:"type:text" json:"content"`
+	Filtered       bool      `json:"filtered"`                                          // 是否触发内容过滤
+	FilterCategory string    `gorm:"type:varchar(50)" json:"filter_category,omitempty"` // 命中规则的类别
+	Provider       string    `gorm:"type:varchar(50)" json:"provider,omitempty"`
+	Model          string    `gorm:"type:varchar(100)" json:"model,omitempty"`
+	AudioURL       string    `gorm:"type:varchar(255)" json:"audio_url,omitempty"` // 陪伴回复的合成语音
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+}
+
+func (CompanionMessage) TableName() string {
+	return "companion_messages"
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"time"
+)
+
+// 咨询会话状态
+const (
+	ConsultationOpen      = "open"      // 由虚拟疗愈导师回复
+	ConsultationEscalated = "escalated" // 已由认证康复师接手，家长的消息不再由虚拟导师回复
+)
+
+// 咨询消息的发送方
+const (
+	ConsultationRoleParent    = "parent"    // 家长
+	ConsultationRoleAssistant = "assistant" // 虚拟疗愈导师
+	ConsultationRoleTherapist = "therapist" // 接手咨询的认证康复师
+)
+
+// 咨询引用的资料类型
+const (
+	ConsultationSourceArchive = "archive"     // 儿童档案
+	ConsultationSourceLog     = "healing_log" // 疗愈记录
+	ConsultationSourceReport  = "report"      // AI报告
+)
+
+// 转介申请状态
+const (
+	EscalationPending   = "pending"   // 等待认证康复师接手
+	EscalationAccepted  = "accepted"  // 康复师已接手
+	EscalationResolved  = "resolved"  // 已结束
+	EscalationCancelled = "cancelled" // 家长在接手前撤回
+)
+
+// ConsultationThread 家长就某个孩子与虚拟疗愈导师的一个咨询会话
+type ConsultationThread struct {
+	ID                 uint       `gorm:"primaryKey" json:"id"`
+	VirtualTherapistID string     `gorm:"type:varchar(64);not null;index" json:"virtual_therapist_id"`
+	ChildArchiveID     string     `gorm:"type:varchar(64);not null;index" json:"child_archive_id"`
+	UserID             string     `gorm:"type:varchar(64);not null;index" json:"user_id"` // 发起咨询的家长
+	Title              string     `gorm:"type:varchar(100)" json:"title"`
+	Status             string     `gorm:"type:varchar(20);not null;index" json:"status"`             // open, escalated
+	TherapistUserID    string     `gorm:"type:varchar(64);index" json:"therapist_user_id,omitempty"` // 接手咨询的认证康复师
+	MessageCount       int        `json:"message_count"`
+	LastMessageAt      *time.Time `gorm:"index" json:"last_message_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+func (ConsultationThread) TableName() string {
+	return "consultation_threads"
+}
+
+// ConsultationSource 虚拟疗愈导师回复中引用的一条资料，Index 对应回复中的 [n] 标注
+type ConsultationSource struct {
+	Index   int        `json:"index"`
+	Type    string     `json:"type"` // archive, healing_log, report
+	ID      string     `json:"id"`
+	Title   string     `json:"title"`
+	Time    *time.Time `json:"time,omitempty"`
+	Excerpt string     `json:"excerpt"`
+}
+
+// ConsultationMessage 咨询会话中的一条消息
+type ConsultationMessage struct {
+	ID        uint                 `gorm:"primaryKey" json:"id"`
+	ThreadID  uint                 `gorm:"not null;index" json:"thread_id"`
+	Role      string               `gorm:"type:varchar(20);not null" json:"role"`       // parent, assistant, therapist
+	SenderID  string               `gorm:"type:varchar(64)" json:"sender_id,omitempty"` // 家长或康复师的用户ID，虚拟导师为空
+	Content   string               `gorm:"type:text" json:"content"`
+	Sources   []ConsultationSource `gorm:"serializer:json;type:text" json:"sources,omitempty"` // 回复引用的资料
+	Provider  string               `gorm:"type:varchar(50)" json:"provider,omitempty"`
+	Model     string               `gorm:"type:varchar(100)" json:"model,omitempty"`
+	CreatedAt time.Time            `gorm:"index" json:"created_at"`
+}
+
+func (ConsultationMessage) TableName() string {
+	return "consultation_messages"
+}
+
+// ConsultationEscalation 家长把咨询转给认证康复师的申请，指定康复师时只有该康复师可以接手
+type ConsultationEscalation struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	ThreadID        uint       `gorm:"not null;index" json:"thread_id"`
+	ChildArchiveID  string     `gorm:"type:varchar(64);not null;index" json:"child_archive_id"`
+	RequesterID     string     `gorm:"type:varchar(64);not null;index" json:"requester_id"`
+	TherapistUserID string     `gorm:"type:varchar(64);index" json:"therapist_user_id"` // 指定或接手的康复师，为空时任一认证康复师都可接手
+	Reason          string     `gorm:"type:text" json:"reason"`
+	Status          string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	ResolutionNote  string     `gorm:"type:text" json:"resolution_note"`
+	AcceptedAt      *time.Time `json:"accepted_at"`
+	ClosedAt        *time.Time `json:"closed_at"` // 结束或撤回时间
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (ConsultationEscalation) TableName() string {
+	return "consultation_escalations"
+}
@@ -1,24 +1,111 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 	"gorm.io/gorm"
 )
 
 // GeneratedReport AI生成的报告模型
 type GeneratedReport struct {
-	ID             uint           `gorm:"primaryKey" json:"id"`
-	ChildArchiveID string         `gorm:"not null;index" json:"child_archive_id"`
-	ReportType     string         `gorm:"type:varchar(50);not null" json:"report_type"` // summary, suggestion
-	Content        string         `gorm:"type:text;not null" json:"content"`
-	IsEdited       bool           `gorm:"default:false" json:"is_edited"`
-	GeneratedAt    time.Time      `gorm:"not null" json:"generated_at"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                uint             `gorm:"primaryKey" json:"id"`
+	ChildArchiveID    string           `gorm:"not null;index" json:"child_archive_id"`
+	ReportType        string           `gorm:"type:varchar(50);not null" json:"report_type"`      // 报告类型标识，见 report_types
+	Content           string           `gorm:"type:text;not null" json:"content"`                 // Markdown 内容，结构化报告由结构化内容渲染
+	StructuredContent json.RawMessage  `gorm:"type:longtext" json:"structured_content,omitempty"` // 模型按报告类型输出结构生成的原始结构化内容
+	Provider          string           `gorm:"type:varchar(50)" json:"provider"`                  // 生成报告的AI提供商
+	Model             string           `gorm:"type:varchar(100)" json:"model"`                    // 生成报告的模型
+	TemplateID        uint             `gorm:"index" json:"template_id"`                          // 使用的提示词模板
+	TemplateVersion   int              `json:"template_version"`                                  // 使用的提示词模板版本号
+	IsEdited          bool             `gorm:"default:false" json:"is_edited"`
+	CurrentVersion    int              `gorm:"default:0" json:"current_version"`                           // 当前内容对应的版本号
+	RedactionLog      []RedactionEntry `gorm:"serializer:json;type:text" json:"redaction_log,omitempty"`   // 发送给模型前的脱敏记录
+	SafetyFindings    []SafetyFinding  `gorm:"serializer:json;type:text" json:"safety_findings,omitempty"` // 生成后安全审查的命中记录
+	Language          string           `gorm:"type:varchar(10);default:'zh'" json:"language"`              // 报告语言
+	SourceReportID    *uint            `gorm:"index" json:"source_report_id,omitempty"`                    // 译文对应的原文报告，原文为空
+	SourceVersion     int              `json:"source_version,omitempty"`                                   // 翻译时原文的版本号
+	GeneratedAt       time.Time        `gorm:"not null" json:"generated_at"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt   `gorm:"index" json:"-"`
 }
 
 // TableName 指定表名
 func (GeneratedReport) TableName() string {
 	return "generated_reports"
-}
\ No newline at end of file
+}
+
+// 报告版本来源
+const (
+	ReportVersionAI      = "ai"      // AI生成的原始内容
+	ReportVersionEdit    = "edit"    // 人工编辑
+	ReportVersionRestore = "restore" // 恢复历史版本
+)
+
+// GeneratedReportVersion 报告的不可变版本，报告内容每次变化都会新增一个版本
+type GeneratedReportVersion struct {
+	ID                uint            `gorm:"primaryKey" json:"id"`
+	ReportID          uint            `gorm:"not null;uniqueIndex:idx_report_version" json:"report_id"`
+	Version           int             `gorm:"not null;uniqueIndex:idx_report_version" json:"version"`
+	Content           string          `gorm:"type:text;not null" json:"content"`
+	StructuredContent json.RawMessage `gorm:"type:longtext" json:"structured_content,omitempty"` // 该版本的结构化内容，人工编辑的版本为空
+	Source            string          `gorm:"type:varchar(20);not null" json:"source"`           // ai, edit, restore
+	EditorID          string          `gorm:"type:varchar(64)" json:"editor_id"`                 // AI生成的版本为空
+	RestoredFrom      *int            `json:"restored_from,omitempty"`                           // 恢复自哪个版本
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
+func (GeneratedReportVersion) TableName() string {
+	return "generated_report_versions"
+}
+
+// 风险提示等级
+const (
+	RiskLevelLow    = "low"
+	RiskLevelMedium = "medium"
+	RiskLevelHigh   = "high"
+)
+
+// 推荐活动类型
+const (
+	ActivityTypeGame   = "game"
+	ActivityTypeCourse = "course"
+)
+
+// StructuredReport 结构化报告中可渲染为 Markdown 的字段，报告类型的输出结构可以包含更多字段
+type StructuredReport struct {
+	Title                 string                `json:"title"`
+	Summary               string                `json:"summary"`
+	Sections              []ReportSection       `json:"sections"`
+	RiskFlags             []ReportRiskFlag      `json:"risk_flags"`
+	RecommendedActivities []RecommendedActivity `json:"recommended_activities"`
+}
+
+// ReportSection 报告章节，内容为 Markdown
+type ReportSection struct {
+	Heading string `json:"heading"`
+	Content string `json:"content"`
+}
+
+// ReportRiskFlag 报告中需要关注的风险
+type ReportRiskFlag struct {
+	Level       string `json:"level"` // low, medium, high
+	Category    string `json:"category,omitempty"`
+	Description string `json:"description"`
+}
+
+// RecommendedActivity 推荐的游戏或课程，ID 对应 games / courses 表
+type RecommendedActivity struct {
+	Type   string `json:"type"` // game, course
+	ID     string `json:"id"`
+	Title  string `json:"title,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// RedactionEntry 报告生成时发送给模型前的一项脱敏记录，不保存原文
+type RedactionEntry struct {
+	Placeholder string `json:"placeholder"` // 发送给模型的占位符
+	Category    string `json:"category"`    // child_name, child_given_name, name, phone, id_number, email, address, school
+	Masked      string `json:"masked"`      // 掩码后的原文
+	Count       int    `json:"count"`       // 替换次数
+}
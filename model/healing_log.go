@@ -4,21 +4,42 @@ import (
 	"gorm.io/gorm"
 )
 
+// 疗愈前后对比阶段
+const (
+	PhaseBaseline = "baseline"  // 基线（疗愈前）
+	PhaseFollowUp = "follow_up" // 跟进（疗愈后）
+)
+
+// 日志媒体类型
+const (
+	LogMediaImage = "image"
+	LogMediaVideo = "video"
+	LogMediaAudio = "audio" // 语音记录，识别出的文字保存在日志内容中
+)
+
 // HealingLog 疗愈日志模型
 type HealingLog struct {
 	gorm.Model
-	UserID         uint       `gorm:"not null;comment:用户ID"`
-	ChildArchiveID uint       `gorm:"not null;comment:儿童档案ID"`
-	Content        string     `gorm:"type:text;comment:日志内容"`
-	Media          []LogMedia `gorm:"foreignKey:HealingLogID;comment:日志媒体"`
+	UserID          uint                `gorm:"not null;comment:用户ID"`
+	ChildArchiveID  uint                `gorm:"not null;comment:儿童档案ID"`
+	Content         string              `gorm:"type:text;comment:日志内容"`
+	Phase           string              `gorm:"type:varchar(20);index;comment:对比阶段(baseline, follow_up)"`
+	Skill           string              `gorm:"type:varchar(50);index;comment:对比的技能"`
+	TemplateID      *uint               `gorm:"index;comment:使用的日志模板ID"`
+	TemplateAnswers []LogTemplateAnswer `gorm:"serializer:json;type:text;comment:模板答案"`
+	Media           []LogMedia          `gorm:"foreignKey:HealingLogID;comment:日志媒体"`
+	Metrics         []LogMetric         `gorm:"foreignKey:HealingLogID;comment:日志指标"`
+	GoalIDs         []uint              `gorm:"-"` // 创建时关联的治疗目标ID
 }
 
 // LogMedia 日志媒体模型
 type LogMedia struct {
 	gorm.Model
 	HealingLogID uint   `gorm:"not null;comment:疗愈日志ID"`
-	MediaType    string `gorm:"type:varchar(20);not null;comment:媒体类型(image, video)"`
+	MediaType    string `gorm:"type:varchar(20);not null;comment:媒体类型(image, video, audio)"`
 	URL          string `gorm:"type:varchar(255);not null;comment:媒体URL"`
+	Phase        string `gorm:"type:varchar(20);comment:对比阶段(baseline, follow_up)，为空时沿用日志的阶段"`
+	Skill        string `gorm:"type:varchar(50);comment:对比的技能，为空时沿用日志的技能"`
 }
 
 func (HealingLog) TableName() string {
@@ -27,4 +48,4 @@ func (HealingLog) TableName() string {
 
 func (LogMedia) TableName() string {
 	return "log_media"
-}
\ No newline at end of file
+}
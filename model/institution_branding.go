@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// InstitutionBranding 机构导出文档的信头和品牌设置
+type InstitutionBranding struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	InstitutionID string    `gorm:"type:varchar(64);not null;uniqueIndex" json:"institution_id"` // 身份为机构的用户ID
+	DisplayName   string    `gorm:"type:varchar(100)" json:"display_name"`                       // 信头显示的机构名称
+	Tagline       string    `gorm:"type:varchar(200)" json:"tagline"`                            // 名称下方的副标题
+	Address       string    `gorm:"type:varchar(255)" json:"address"`
+	Phone         string    `gorm:"type:varchar(50)" json:"phone"`
+	Website       string    `gorm:"type:varchar(255)" json:"website"`
+	FooterText    string    `gorm:"type:varchar(255)" json:"footer_text"`        // 页脚文字，如保密声明
+	PrimaryColor  string    `gorm:"type:varchar(7)" json:"primary_color"`        // 主题色 #RRGGBB
+	Logo          []byte    `gorm:"type:mediumblob" json:"-"`                    // PNG 或 JPEG
+	LogoType      string    `gorm:"type:varchar(10)" json:"logo_type,omitempty"` // png, jpeg
+	HasLogo       bool      `gorm:"-" json:"has_logo"`
+	UpdatedBy     string    `gorm:"type:varchar(64)" json:"updated_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (InstitutionBranding) TableName() string {
+	return "institution_brandings"
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// LogMetric 疗愈日志中的量化指标，例如量表评分、专注时长等
+type LogMetric struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	HealingLogID   uint      `gorm:"not null;index;comment:疗愈日志ID" json:"healing_log_id"`
+	ChildArchiveID uint      `gorm:"not null;index;comment:儿童档案ID" json:"child_archive_id"`
+	Name           string    `gorm:"type:varchar(100);not null;comment:指标名称" json:"name"`
+	Value          float64   `gorm:"not null;comment:指标数值" json:"value"`
+	Unit           string    `gorm:"type:varchar(20);comment:单位" json:"unit"`
+	RecordedAt     time.Time `gorm:"index;comment:记录时间" json:"recorded_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (LogMetric) TableName() string {
+	return "log_metrics"
+}
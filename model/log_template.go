@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 模板条目类型
+const (
+	TemplateItemQuestion  = "question"  // 问答题
+	TemplateItemChecklist = "checklist" // 清单（多选）
+	TemplateItemScale     = "scale"     // 评分量表
+)
+
+// 模板分配来源
+const (
+	TemplateSourceDiagnosis = "diagnosis" // 根据诊断自动匹配
+	TemplateSourceManual    = "manual"    // 手动分配
+)
+
+// LogTemplate 疗愈日志模板，由康复师编写的问题、清单和量表
+type LogTemplate struct {
+	ID          uint              `gorm:"primaryKey" json:"id"`
+	Name        string            `gorm:"type:varchar(100);not null" json:"name"`
+	Description string            `gorm:"type:text" json:"description"`
+	Diagnosis   string            `gorm:"type:varchar(100);index" json:"diagnosis"` // 适用的诊断关键词，为空表示通用模板
+	Items       []LogTemplateItem `gorm:"serializer:json;type:text" json:"items"`
+	CreatedBy   string            `gorm:"type:varchar(64);index" json:"created_by"`
+	IsActive    bool              `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt    `gorm:"index" json:"-"`
+}
+
+// LogTemplateItem 模板中的单个条目
+type LogTemplateItem struct {
+	Key      string   `json:"key"`
+	Type     string   `json:"type"` // question, checklist, scale
+	Label    string   `json:"label"`
+	Hint     string   `json:"hint,omitempty"`
+	Options  []string `json:"options,omitempty"` // checklist 的可选项
+	Min      int      `json:"min,omitempty"`     // scale 的最小值
+	Max      int      `json:"max,omitempty"`     // scale 的最大值
+	Required bool     `json:"required"`
+}
+
+// LogTemplateAnswer 使用模板记录日志时的单条答案
+type LogTemplateAnswer struct {
+	Key   string      `json:"key"`
+	Label string      `json:"label"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// ChildLogTemplate 儿童与日志模板的关联
+type ChildLogTemplate struct {
+	ID             uint        `gorm:"primaryKey" json:"id"`
+	ChildArchiveID string      `gorm:"type:varchar(64);not null;index" json:"child_archive_id"`
+	TemplateID     uint        `gorm:"not null;index" json:"template_id"`
+	Source         string      `gorm:"type:varchar(20);not null" json:"source"` // diagnosis, manual
+	AssignedBy     string      `gorm:"type:varchar(64)" json:"assigned_by"`
+	CreatedAt      time.Time   `json:"created_at"`
+	Template       LogTemplate `gorm:"foreignKey:TemplateID" json:"template"`
+}
+
+func (LogTemplate) TableName() string {
+	return "log_templates"
+}
+
+func (ChildLogTemplate) TableName() string {
+	return "child_log_templates"
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 通知类型
+const (
+	NotificationMilestone       = "milestone"        // 疗愈里程碑
+	NotificationLoggingReminder = "logging_reminder" // 每日记录提醒
+	NotificationReportReady     = "report_ready"     // 报告已生成
+	NotificationReportFailed    = "report_failed"    // 报告生成失败
+	NotificationCertification   = "certification"    // 认证到期提醒
+	NotificationReportInReview  = "report_in_review" // 报告等待安全审核
+	NotificationSafetyReview    = "safety_review"    // 有待审核的报告（审核人）
+	NotificationCompanionAlert  = "companion_alert"  // 陪伴对话中出现需要家长关注的内容
+	NotificationConsultation    = "consultation"     // 咨询转介的申请、接手、回复和结束
+	NotificationTherapistGrant  = "therapist_grant"  // 家长授权康复师访问孩子的资料
+)
+
+// Notification 站内通知
+type Notification struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	UserID      string         `gorm:"type:varchar(64);not null;index" json:"user_id"`
+	Type        string         `gorm:"type:varchar(50);not null" json:"type"`
+	Title       string         `gorm:"type:varchar(200);not null" json:"title"`
+	Content     string         `gorm:"type:text" json:"content"`
+	RelatedType string         `gorm:"type:varchar(50)" json:"related_type"` // 关联资源类型，如 child_archive
+	RelatedID   string         `gorm:"type:varchar(64)" json:"related_id"`
+	IsRead      bool           `gorm:"default:false;index" json:"is_read"`
+	ReadAt      *time.Time     `json:"read_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
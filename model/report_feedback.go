@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+)
+
+// ReportFeedback 认证康复师对一份AI报告的评价，每位康复师对每份报告保留一条，重复提交时更新。
+// 报告的提示词模板版本和模型冗余保存，便于按版本和模型汇总
+type ReportFeedback struct {
+	ID                 uint                 `gorm:"primaryKey" json:"id"`
+	ReportID           uint                 `gorm:"not null;uniqueIndex:idx_report_feedback_reviewer" json:"report_id"`
+	ReviewerID         string               `gorm:"type:varchar(64);not null;uniqueIndex:idx_report_feedback_reviewer;index" json:"reviewer_id"`
+	ReportVersion      int                  `json:"report_version"`                            // 评价时报告的版本
+	ReportEdited       bool                 `json:"report_edited"`                             // 评价时报告是否经过人工编辑
+	ReportType         string               `gorm:"type:varchar(50);index" json:"report_type"` // 报告类型标识
+	TemplateID         uint                 `gorm:"index:idx_report_feedback_template" json:"template_id"`
+	TemplateVersion    int                  `gorm:"index:idx_report_feedback_template" json:"template_version"`
+	Provider           string               `gorm:"type:varchar(50)" json:"provider"`
+	Model              string               `gorm:"type:varchar(100);index" json:"model"`
+	Accuracy           int                  `gorm:"not null" json:"accuracy"`   // 准确性 1-5
+	Usefulness         int                  `gorm:"not null" json:"usefulness"` // 实用性 1-5
+	Safety             int                  `gorm:"not null" json:"safety"`     // 安全性 1-5
+	Comment            string               `gorm:"type:text" json:"comment"`
+	Sections           []SectionFeedback    `gorm:"serializer:json;type:text" json:"sections"`
+	Hallucinations     []HallucinationFlag  `gorm:"serializer:json;type:text" json:"hallucinations"`
+	Suggestions        []SuggestionFeedback `gorm:"serializer:json;type:text" json:"suggestions"`
+	HallucinationCount int                  `json:"hallucination_count"` // 标记的幻觉数
+	SuggestionCount    int                  `json:"suggestion_count"`    // 评价的建议数
+	AdoptedCount       int                  `json:"adopted_count"`       // 采纳的建议数
+	CreatedAt          time.Time            `gorm:"index" json:"created_at"`
+	UpdatedAt          time.Time            `json:"updated_at"`
+}
+
+func (ReportFeedback) TableName() string {
+	return "report_feedbacks"
+}
+
+// SectionFeedback 对报告某一章节的评分，0 表示未评分
+type SectionFeedback struct {
+	Section    string `json:"section"` // 章节标题
+	Accuracy   int    `json:"accuracy,omitempty"`
+	Usefulness int    `json:"usefulness,omitempty"`
+	Safety     int    `json:"safety,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// HallucinationFlag 报告中与记录不符或无依据的内容
+type HallucinationFlag struct {
+	Section string `json:"section,omitempty"` // 所在章节
+	Excerpt string `json:"excerpt,omitempty"` // 报告中的原文
+	Comment string `json:"comment"`           // 问题说明
+}
+
+// SuggestionFeedback 报告中的一条建议是否被采纳
+type SuggestionFeedback struct {
+	Suggestion string `json:"suggestion"`
+	Adopted    bool   `json:"adopted"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// ReportFeedbackStats 按报告类型、提示词模板版本和模型汇总的评价
+type ReportFeedbackStats struct {
+	ReportType          string  `json:"report_type"`
+	TemplateID          uint    `json:"template_id"`
+	TemplateVersion     int     `json:"template_version"`
+	Provider            string  `json:"provider"`
+	Model               string  `json:"model"`
+	Reviews             int64   `json:"reviews"`
+	Reports             int64   `json:"reports"`
+	EditedReviews       int64   `json:"edited_reviews"` // 评价时报告已被人工编辑的评价数
+	AvgAccuracy         float64 `json:"avg_accuracy"`
+	AvgUsefulness       float64 `json:"avg_usefulness"`
+	AvgSafety           float64 `json:"avg_safety"`
+	HallucinationFlags  int64   `json:"hallucination_flags"`
+	HallucinatedReviews int64   `json:"hallucinated_reviews"` // 至少标记了一处幻觉的评价数
+	Suggestions         int64   `json:"suggestions"`
+	AdoptedSuggestions  int64   `json:"adopted_suggestions"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+)
+
+// 报告生成任务状态
+const (
+	ReportJobPending   = "pending"
+	ReportJobRunning   = "running"
+	ReportJobSucceeded = "succeeded"
+	ReportJobFailed    = "failed"
+	ReportJobCancelled = "cancelled"
+	ReportJobInReview  = "in_review" // 报告触发安全审查，等待机构人工审核
+)
+
+// ReportJobComparison 任务中保存的疗愈前后对比参数
+type ReportJobComparison struct {
+	Skill         string     `json:"skill"`
+	BaselineStart *time.Time `json:"baseline_start"`
+	BaselineEnd   *time.Time `json:"baseline_end"`
+	FollowUpStart *time.Time `json:"follow_up_start"`
+	FollowUpEnd   *time.Time `json:"follow_up_end"`
+}
+
+// ReportJob 异步生成AI报告的任务
+type ReportJob struct {
+	ID             uint                 `gorm:"primaryKey" json:"id"`
+	UserID         string               `gorm:"type:varchar(64);not null;index" json:"user_id"` // 发起人
+	ChildArchiveID string               `gorm:"type:varchar(64);not null;index" json:"child_archive_id"`
+	ReportType     string               `gorm:"type:varchar(50);not null" json:"report_type"`
+	StartDate      *time.Time           `json:"start_date"`
+	EndDate        *time.Time           `json:"end_date"`
+	Comparison     *ReportJobComparison `gorm:"serializer:json;type:text" json:"comparison,omitempty"`
+	Language       string               `gorm:"type:varchar(10)" json:"language"` // 报告语言，为空时为中文
+	Status         string               `gorm:"type:varchar(20);not null;index" json:"status"`
+	Attempts       int                  `json:"attempts"`
+	MaxAttempts    int                  `json:"max_attempts"`
+	LastError      string               `gorm:"type:text" json:"last_error"`
+	ReportID       *uint                `json:"report_id"`
+	SafetyReviewID *uint                `json:"safety_review_id,omitempty"` // 转人工审核时对应的审核记录
+	NextRunAt      *time.Time           `json:"next_run_at"`
+	StartedAt      *time.Time           `json:"started_at"`
+	HeartbeatAt    *time.Time           `json:"-"` // 执行中的心跳，用于回收异常中断的任务
+	FinishedAt     *time.Time           `json:"finished_at"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+func (ReportJob) TableName() string {
+	return "report_jobs"
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 安全规则检查的内容
+const (
+	SafetyScopeOutput = "output" // 模型生成的报告
+	SafetyScopeLogs   = "logs"   // 用于生成报告的疗愈记录
+)
+
+// 安全规则命中后的处理方式
+const (
+	SafetyActionBlock    = "block"    // 隐去所在句子
+	SafetyActionAnnotate = "annotate" // 在报告末尾附加安全提示
+	SafetyActionEscalate = "escalate" // 报告不直接保存，转机构人工审核
+)
+
+// 安全审核状态
+const (
+	SafetyReviewPending  = "pending"
+	SafetyReviewApproved = "approved"
+	SafetyReviewRejected = "rejected"
+)
+
+// SafetyFinding 安全审查的一项命中
+type SafetyFinding struct {
+	Category string     `json:"category"`           // medication, diagnosis, crisis, self_harm, abuse 等
+	Rule     string     `json:"rule"`               // 命中的规则名，分类器命中时为 classifier
+	Scope    string     `json:"scope"`              // output, logs
+	Action   string     `json:"action"`             // block, annotate, escalate
+	Excerpt  string     `json:"excerpt"`            // 命中的原文片段
+	Message  string     `json:"message,omitempty"`  // 展示给用户的说明
+	Score    float64    `json:"score,omitempty"`    // 分类器得分
+	LogTime  *time.Time `json:"log_time,omitempty"` // 命中疗愈记录时为记录时间
+}
+
+// ReportSafetyReview 安全审查要求人工审核的报告，审核通过后才保存为正式报告
+type ReportSafetyReview struct {
+	ID                uint             `gorm:"primaryKey" json:"id"`
+	InstitutionID     string           `gorm:"type:varchar(64);index" json:"institution_id"` // 负责审核的机构，为空时由管理员审核
+	ChildArchiveID    string           `gorm:"type:varchar(64);not null;index" json:"child_archive_id"`
+	RequestedBy       string           `gorm:"type:varchar(64)" json:"requested_by"` // 报告发起人
+	JobID             *uint            `gorm:"index" json:"job_id,omitempty"`        // 后台任务生成时对应的任务
+	ReportType        string           `gorm:"type:varchar(50);not null" json:"report_type"`
+	Language          string           `gorm:"type:varchar(10)" json:"language"`
+	Content           string           `gorm:"type:text;not null" json:"content"`
+	StructuredContent json.RawMessage  `gorm:"type:longtext" json:"structured_content,omitempty"`
+	Provider          string           `gorm:"type:varchar(50)" json:"provider"`
+	Model             string           `gorm:"type:varchar(100)" json:"model"`
+	TemplateID        uint             `json:"template_id"`
+	TemplateVersion   int              `json:"template_version"`
+	RedactionLog      []RedactionEntry `gorm:"serializer:json;type:text" json:"redaction_log,omitempty"`
+	Findings          []SafetyFinding  `gorm:"serializer:json;type:text" json:"findings"`
+	Status            string           `gorm:"type:varchar(20);not null;index" json:"status"`
+	ReviewerID        string           `gorm:"type:varchar(64)" json:"reviewer_id"`
+	ReviewNote        string           `gorm:"type:text" json:"review_note"`
+	ReportID          *uint            `json:"report_id,omitempty"` // 审核通过后保存的报告
+	GeneratedAt       time.Time        `json:"generated_at"`
+	ReviewedAt        *time.Time       `json:"reviewed_at"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+}
+
+func (ReportSafetyReview) TableName() string {
+	return "report_safety_reviews"
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+)
+
+// DefaultLanguage 报告和提示词模板的默认语言
+const DefaultLanguage = "zh"
+
+// ReportType 报告类型，生成报告时使用当前启用的提示词模板版本
+type ReportType struct {
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	Key               string         `gorm:"type:varchar(50);not null;uniqueIndex" json:"key"` // 报告类型标识，如 summary
+	Name              string         `gorm:"type:varchar(100);not null" json:"name"`
+	Description       string         `gorm:"type:text" json:"description"`
+	Enabled           bool           `json:"enabled"`
+	ActiveVersion     int            `json:"active_version"`                                                // 当前启用的中文模板版本号，其他语言没有专用模板时也使用该版本
+	LocalizedVersions map[string]int `gorm:"serializer:json;type:text" json:"localized_versions,omitempty"` // 其他语言当前启用的模板版本号，键为语言代码
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
+// PromptTemplate 报告类型的提示词模板版本，保存后不再修改，编辑时新增版本
+type PromptTemplate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ReportTypeID uint      `gorm:"not null;uniqueIndex:idx_prompt_template_version" json:"report_type_id"`
+	Version      int       `gorm:"not null;uniqueIndex:idx_prompt_template_version" json:"version"`
+	Language     string    `gorm:"type:varchar(10);default:'zh'" json:"language"` // 模板语言，其他语言的模板只能在该语言下启用
+	SystemPrompt string    `gorm:"type:text" json:"system_prompt"`                // 系统消息，为空时使用默认系统消息
+	Template     string    `gorm:"type:longtext;not null" json:"template"`        // Go text/template 模板，数据为日志、指标和儿童档案
+	OutputSchema string    `gorm:"type:text" json:"output_schema"`                // 报告输出结构的 JSON Schema
+	Note         string    `gorm:"type:varchar(255)" json:"note"`                 // 版本说明
+	CreatedBy    string    `gorm:"type:varchar(64)" json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (ReportType) TableName() string {
+	return "report_types"
+}
+
+func (PromptTemplate) TableName() string {
+	return "prompt_templates"
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+// 语义索引的资料类型
+const (
+	SemanticSourceLog    = "log"    // 疗愈记录
+	SemanticSourceReport = "report" // AI报告（不含译文）
+	SemanticSourceGoal   = "goal"   // 治疗目标
+)
+
+// SemanticChunk 语义索引中的一段资料及其向量。长资料按段落切分为多段，同一资料的各段共用内容哈希，
+// 内容或向量化提供商变化时整条资料重新索引
+type SemanticChunk struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ChildArchiveID string    `gorm:"type:varchar(64);not null;index:idx_semantic_child,priority:1" json:"child_archive_id"`
+	SourceType     string    `gorm:"type:varchar(20);not null;index:idx_semantic_source;index:idx_semantic_child,priority:2" json:"source_type"` // log, report, goal
+	SourceID       uint      `gorm:"not null;index:idx_semantic_source" json:"source_id"`
+	ChunkIndex     int       `json:"chunk_index"`
+	Title          string    `gorm:"type:varchar(200)" json:"title"`
+	Name           string    `gorm:"type:varchar(200)" json:"name"` // 报告类型名称或目标标题，疗愈记录为空
+	Content        string    `gorm:"type:text" json:"content"`
+	ContentHash    string    `gorm:"type:varchar(64)" json:"-"` // 向量化提供商、维度和资料全文的哈希
+	Provider       string    `gorm:"type:varchar(50)" json:"provider"`
+	Vector         []float32 `gorm:"serializer:json;type:mediumtext" json:"-"`               // 已归一化的向量
+	SourceTime     time.Time `gorm:"index:idx_semantic_child,priority:3" json:"source_time"` // 资料的记录、生成或创建时间
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (SemanticChunk) TableName() string {
+	return "semantic_chunks"
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+)
+
+// 分享的资源类型
+const (
+	ShareResourceReport  = "report"  // AI报告，分享创建时的版本
+	ShareResourceDossier = "dossier" // 儿童康复档案快照
+)
+
+// 分享链接访问结果
+const (
+	ShareAccessViewed    = "viewed"     // 在线查看
+	ShareAccessDownload  = "downloaded" // 下载文件
+	ShareAccessBadCode   = "bad_code"   // 访问码错误
+	ShareAccessNeedsCode = "needs_code" // 未提供访问码
+	ShareAccessExpired   = "expired"    // 链接已过期
+	ShareAccessRevoked   = "revoked"    // 链接已撤销
+)
+
+// ShareLink 报告或康复档案的只读分享链接，无需账号即可访问，过期或撤销后失效
+type ShareLink struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	PublicID       string     `gorm:"type:varchar(32);not null;uniqueIndex" json:"-"` // 链接令牌中的随机部分，令牌另带签名，不保存签名
+	CreatedBy      string     `gorm:"type:varchar(64);not null;index" json:"created_by"`
+	ChildArchiveID string     `gorm:"type:varchar(64);not null;index" json:"child_archive_id"`
+	ResourceType   string     `gorm:"type:varchar(20);not null" json:"resource_type"` // report, dossier
+	ReportID       uint       `json:"report_id,omitempty"`
+	ReportVersion  int        `json:"report_version,omitempty"`           // 分享的报告版本
+	Snapshot       []byte     `gorm:"type:longblob" json:"-"`             // 档案快照（导出文档结构的 JSON）
+	Title          string     `gorm:"type:varchar(200)" json:"title"`     // 分享的文档名称，也用作下载的文件名
+	Recipient      string     `gorm:"type:varchar(100)" json:"recipient"` // 分享对象备注，如"班主任王老师"
+	AccessCodeHash string     `gorm:"type:varchar(100)" json:"-"`
+	HasAccessCode  bool       `gorm:"-" json:"has_access_code"`
+	ExpiresAt      time.Time  `gorm:"not null;index" json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	ViewCount      int        `gorm:"default:0" json:"view_count"`
+	LastViewedAt   *time.Time `json:"last_viewed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (ShareLink) TableName() string {
+	return "share_links"
+}
+
+// ShareAccessLog 分享链接的一次访问
+type ShareAccessLog struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ShareLinkID uint      `gorm:"not null;index:idx_share_access_link" json:"share_link_id"`
+	Outcome     string    `gorm:"type:varchar(20);not null" json:"outcome"` // viewed, downloaded, bad_code, needs_code, expired, revoked
+	Format      string    `gorm:"type:varchar(10)" json:"format,omitempty"` // 下载的文件格式
+	IP          string    `gorm:"type:varchar(64)" json:"ip"`
+	UserAgent   string    `gorm:"type:varchar(255)" json:"user_agent"`
+	CreatedAt   time.Time `gorm:"index:idx_share_access_link" json:"created_at"`
+}
+
+func (ShareAccessLog) TableName() string {
+	return "share_access_logs"
+}
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+)
+
+// ChildTherapistGrant 家长授权认证康复师访问孩子的资料（疗愈记录、报告、导出和评价等）。
+// 接手该孩子咨询转介的康复师在转介进行中同样可以访问，不需要单独授权
+type ChildTherapistGrant struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ChildArchiveID  string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_child_therapist" json:"child_archive_id"`
+	TherapistUserID string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_child_therapist;index" json:"therapist_user_id"`
+	GrantedBy       string    `gorm:"type:varchar(64);not null" json:"granted_by"` // 授权的家长或管理员
+	Note            string    `gorm:"type:varchar(200)" json:"note"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (ChildTherapistGrant) TableName() string {
+	return "child_therapist_grants"
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 目标领域
+const (
+	GoalDomainLanguage = "language" // 语言
+	GoalDomainSocial   = "social"   // 社交
+	GoalDomainMotor    = "motor"    // 运动
+	GoalDomainEmotion  = "emotion"  // 情绪
+)
+
+// 目标状态
+const (
+	GoalStatusNotStarted = "not_started"
+	GoalStatusInProgress = "in_progress"
+	GoalStatusAchieved   = "achieved"
+	GoalStatusPaused     = "paused"
+	GoalStatusDropped    = "dropped"
+)
+
+// 目标关联的记录类型
+const (
+	GoalLinkHealingLog  = "healing_log"
+	GoalLinkGameSession = "game_session"
+)
+
+// TreatmentPlan 结构化治疗方案
+type TreatmentPlan struct {
+	ID             uint            `gorm:"primaryKey" json:"id"`
+	ChildArchiveID string          `gorm:"type:varchar(64);not null;index" json:"child_archive_id"`
+	Title          string          `gorm:"type:varchar(200);not null" json:"title"`
+	Description    string          `gorm:"type:text" json:"description"`
+	Status         string          `gorm:"type:varchar(20);default:active" json:"status"` // active, completed, archived
+	StartDate      *time.Time      `json:"start_date"`
+	EndDate        *time.Time      `json:"end_date"`
+	CreatedBy      string          `gorm:"type:varchar(64)" json:"created_by"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt  `gorm:"index" json:"-"`
+	Goals          []TreatmentGoal `gorm:"foreignKey:PlanID" json:"goals"`
+}
+
+// TreatmentGoal 治疗方案中的具体目标
+type TreatmentGoal struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	PlanID         uint           `gorm:"not null;index" json:"plan_id"`
+	ChildArchiveID string         `gorm:"type:varchar(64);not null;index" json:"child_archive_id"`
+	Domain         string         `gorm:"type:varchar(20);not null" json:"domain"` // language, social, motor, emotion
+	Title          string         `gorm:"type:varchar(200);not null" json:"title"`
+	Description    string         `gorm:"type:text" json:"description"`
+	TargetCriteria string         `gorm:"type:text" json:"target_criteria"`     // 达成标准描述
+	MetricName     string         `gorm:"type:varchar(100)" json:"metric_name"` // 用于计算进度的日志指标
+	BaselineValue  *float64       `json:"baseline_value"`                       // 指标基线值，为空时取首次记录
+	TargetValue    *float64       `json:"target_value"`                         // 指标目标值
+	TargetCount    int            `json:"target_count"`                         // 目标练习次数（关联的日志和游戏记录）
+	StartDate      *time.Time     `json:"start_date"`
+	TargetDate     *time.Time     `json:"target_date"`
+	Status         string         `gorm:"type:varchar(20);default:not_started" json:"status"`
+	AchievedAt     *time.Time     `json:"achieved_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// GoalLink 目标与疗愈日志、游戏记录的关联
+type GoalLink struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	GoalID     uint      `gorm:"not null;uniqueIndex:idx_goal_link" json:"goal_id"`
+	TargetType string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_goal_link" json:"target_type"` // healing_log, game_session
+	TargetID   uint      `gorm:"not null;uniqueIndex:idx_goal_link" json:"target_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// GameSession 儿童的一次游戏训练记录
+type GameSession struct {
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	ChildArchiveID  string         `gorm:"type:varchar(64);not null;index" json:"child_archive_id"`
+	UserID          string         `gorm:"type:varchar(64);index" json:"user_id"`
+	GameID          string         `gorm:"type:varchar(64);not null;index" json:"game_id"`
+	Score           *float64       `json:"score"`
+	DurationSeconds int            `json:"duration_seconds"`
+	Notes           string         `gorm:"type:text" json:"notes"`
+	PlayedAt        time.Time      `gorm:"index" json:"played_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (TreatmentPlan) TableName() string {
+	return "treatment_plans"
+}
+
+func (TreatmentGoal) TableName() string {
+	return "treatment_goals"
+}
+
+func (GoalLink) TableName() string {
+	return "goal_links"
+}
+
+func (GameSession) TableName() string {
+	return "game_sessions"
+}
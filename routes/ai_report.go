@@ -15,11 +15,31 @@ func SetupAIReportRoutes(router *gin.Engine, aiReportController *controller.AIRe
 	{
 		// 生成AI报告
 		aiReportGroup.POST("/generate", aiReportController.GenerateReport)
+
+		// 流式生成AI报告（Server-Sent Events）
+		aiReportGroup.POST("/stream", aiReportController.StreamReport)
 		
 		// 获取AI报告
 		aiReportGroup.GET("", aiReportController.GetReport)
 		
 		// 更新AI报告内容
 		aiReportGroup.PUT("/:id", aiReportController.UpdateReportContent)
+
+		// 儿童的报告列表
+		aiReportGroup.GET("/child/:child_id", aiReportController.ListChildReports)
+
+		// 报告版本历史
+		aiReportGroup.GET("/:id/versions", aiReportController.ListReportVersions)
+		aiReportGroup.GET("/:id/versions/diff", aiReportController.DiffReportVersions)
+		aiReportGroup.POST("/:id/versions/:version/restore", aiReportController.RestoreReportVersion)
+
+		// 报告译文
+		aiReportGroup.POST("/:id/translations", aiReportController.TranslateReport)
+		aiReportGroup.GET("/:id/translations", aiReportController.ListReportTranslations)
+
+		// 报告生成任务
+		aiReportGroup.GET("/jobs/:job_id", aiReportController.GetReportJob)
+		aiReportGroup.POST("/jobs/:job_id/cancel", aiReportController.CancelReportJob)
+		aiReportGroup.GET("/jobs/child/:child_id", aiReportController.GetChildReportJobs)
 	}
 }
\ No newline at end of file
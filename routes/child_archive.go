@@ -14,7 +14,11 @@ func SetupChildArchiveRoutes(router *gin.Engine, childArchiveController *control
 	childArchiveGroup.Use(jwtMiddleware.AuthMiddleware())
 	{
 		// 获取儿童个人信息
-		childArchiveGroup.GET("/:archiveId/profile", childArchiveController.GetChildProfile)
+		childArchiveGroup.GET("/:archive_id/profile", childArchiveController.GetChildProfile)
+		
+		// 疗愈进度和月度记录日历
+		childArchiveGroup.GET("/:archive_id/progress", childArchiveController.GetChildProgress)
+		childArchiveGroup.GET("/:archive_id/calendar", childArchiveController.GetChildCalendar)
 		
 		// 获取用户的所有儿童档案列表
 		childArchiveGroup.GET("/list", childArchiveController.GetChildArchives)
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCompanionRoutes 设置AI陪伴对话相关路由
+func SetupCompanionRoutes(router *gin.Engine, companionController *controller.CompanionController, jwtMiddleware *middleware.JwtClient) {
+	conversationGroup := router.Group("/api/companion-conversations")
+	conversationGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		conversationGroup.POST("", companionController.StartConversation)
+		conversationGroup.GET("", companionController.ListConversations)
+		conversationGroup.GET("/:id", companionController.GetTranscript)
+		conversationGroup.POST("/:id/messages", companionController.SendMessage)
+		conversationGroup.POST("/:id/messages/:message_id/audio", companionController.SynthesizeMessage)
+	}
+}
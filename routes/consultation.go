@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupConsultationRoutes 设置虚拟疗愈导师咨询和转介相关路由
+func SetupConsultationRoutes(router *gin.Engine, consultationController *controller.ConsultationController, jwtMiddleware *middleware.JwtClient) {
+	consultationGroup := router.Group("/api/consultations")
+	consultationGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		consultationGroup.POST("", consultationController.StartConsultation)
+		consultationGroup.GET("", consultationController.ListConsultations)
+		consultationGroup.GET("/:id", consultationController.GetConsultation)
+		consultationGroup.POST("/:id/messages", consultationController.SendMessage)
+		consultationGroup.POST("/:id/escalations", consultationController.Escalate)
+	}
+
+	escalationGroup := router.Group("/api/consultation-escalations")
+	escalationGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		escalationGroup.GET("", consultationController.ListEscalations)
+		escalationGroup.POST("/:id/accept", consultationController.AcceptEscalation)
+		escalationGroup.POST("/:id/close", consultationController.CloseEscalation)
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupExportRoutes 设置报告和康复档案导出相关路由
+func SetupExportRoutes(router *gin.Engine, exportController *controller.ExportController, jwtMiddleware *middleware.JwtClient) {
+	exportGroup := router.Group("/api/exports")
+	exportGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		// 文档下载
+		exportGroup.GET("/reports/:id", exportController.ExportReport)
+		exportGroup.GET("/children/:child_id", exportController.ExportChildDossier)
+
+		// 机构信头
+		exportGroup.GET("/branding", exportController.GetBranding)
+		exportGroup.PUT("/branding", exportController.UpdateBranding)
+	}
+}
@@ -17,7 +17,10 @@ func SetupHealingLogRoutes(router *gin.Engine, healingLogController *controller.
 	{
 		protected.POST("", healingLogController.CreateHealingLog)
 		protected.GET("/child/:child_id", healingLogController.GetHealingLogsByChildID)
+		protected.GET("/child/:child_id/comparison", healingLogController.GetComparison)
 		protected.GET("/:log_id", healingLogController.GetHealingLogByID)
+		protected.PUT("/:log_id", healingLogController.UpdateHealingLog)
 		protected.DELETE("/:log_id", healingLogController.DeleteHealingLog)
+		protected.PUT("/:log_id/phase", healingLogController.MarkPhase)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupLogTemplateRoutes 设置日志模板相关路由
+func SetupLogTemplateRoutes(router *gin.Engine, logTemplateController *controller.LogTemplateController, jwtMiddleware *middleware.JwtClient) {
+	logTemplateGroup := router.Group("/api/log-templates")
+	logTemplateGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		// 模板库管理
+		logTemplateGroup.GET("", logTemplateController.ListTemplates)
+		logTemplateGroup.GET("/:id", logTemplateController.GetTemplate)
+		logTemplateGroup.POST("", logTemplateController.CreateTemplate)
+		logTemplateGroup.PUT("/:id", logTemplateController.UpdateTemplate)
+		logTemplateGroup.DELETE("/:id", logTemplateController.DeleteTemplate)
+
+		// 儿童模板分配
+		logTemplateGroup.GET("/child/:child_id", logTemplateController.GetChildTemplates)
+		logTemplateGroup.POST("/child/:child_id", logTemplateController.AssignTemplate)
+		logTemplateGroup.DELETE("/child/:child_id/:template_id", logTemplateController.UnassignTemplate)
+	}
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"melody_cure/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupMediaRoutes 使用本地对象存储时，在 storage.baseURL 的路径下提供已保存的文件（合成语音、语音记录等）
+func SetupMediaRoutes(router *gin.Engine, objectStorage service.ObjectStorage) {
+	if local, ok := objectStorage.(*service.LocalObjectStorage); ok {
+		router.Static(local.ServePath(), local.Dir())
+	}
+}
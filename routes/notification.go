@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupNotificationRoutes 设置站内通知相关路由
+func SetupNotificationRoutes(router *gin.Engine, notificationController *controller.NotificationController, jwtMiddleware *middleware.JwtClient) {
+	notificationGroup := router.Group("/api/notifications")
+	notificationGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		notificationGroup.GET("", notificationController.GetNotifications)
+		notificationGroup.PUT("/read-all", notificationController.MarkAllAsRead)
+		notificationGroup.PUT("/:id/read", notificationController.MarkAsRead)
+	}
+}
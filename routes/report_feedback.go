@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupReportFeedbackRoutes 设置康复师报告评价相关路由
+func SetupReportFeedbackRoutes(router *gin.Engine, feedbackController *controller.ReportFeedbackController, jwtMiddleware *middleware.JwtClient) {
+	// 单份报告的评价
+	reportGroup := router.Group("/api/ai-reports")
+	reportGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		reportGroup.PUT("/:id/feedback", feedbackController.SubmitFeedback)
+		reportGroup.GET("/:id/feedback", feedbackController.GetReportFeedback)
+	}
+
+	// 管理员统计
+	feedbackGroup := router.Group("/api/report-feedback")
+	feedbackGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		feedbackGroup.GET("/analytics", feedbackController.GetFeedbackAnalytics)
+	}
+}
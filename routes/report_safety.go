@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupReportSafetyRoutes 设置报告安全审核队列相关路由
+func SetupReportSafetyRoutes(router *gin.Engine, safetyController *controller.ReportSafetyController, jwtMiddleware *middleware.JwtClient) {
+	reviewGroup := router.Group("/api/safety-reviews")
+	reviewGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		reviewGroup.GET("", safetyController.ListReviews)
+		reviewGroup.GET("/:id", safetyController.GetReview)
+		reviewGroup.POST("/:id/approve", safetyController.ApproveReview)
+		reviewGroup.POST("/:id/reject", safetyController.RejectReview)
+	}
+}
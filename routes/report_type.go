@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupReportTypeRoutes 设置报告类型和提示词模板相关路由
+func SetupReportTypeRoutes(router *gin.Engine, reportTypeController *controller.ReportTypeController, jwtMiddleware *middleware.JwtClient) {
+	reportTypeGroup := router.Group("/api/report-types")
+	reportTypeGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		// 报告类型
+		reportTypeGroup.GET("", reportTypeController.ListReportTypes)
+		reportTypeGroup.GET("/languages", reportTypeController.ListReportLanguages)
+		reportTypeGroup.POST("", reportTypeController.CreateReportType)
+		reportTypeGroup.GET("/:key", reportTypeController.GetReportType)
+		reportTypeGroup.PUT("/:key", reportTypeController.UpdateReportType)
+
+		// 提示词模板版本
+		reportTypeGroup.POST("/:key/versions", reportTypeController.CreateTemplateVersion)
+		reportTypeGroup.POST("/:key/versions/:version/activate", reportTypeController.ActivateTemplateVersion)
+		reportTypeGroup.POST("/:key/preview", reportTypeController.PreviewPrompt)
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSemanticIndexRoutes 设置语义索引路由
+func SetupSemanticIndexRoutes(router *gin.Engine, semanticIndexController *controller.SemanticIndexController, jwtMiddleware *middleware.JwtClient) {
+	semanticGroup := router.Group("/api/semantic-index")
+	semanticGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		semanticGroup.GET("/child/:child_id/similar", semanticIndexController.FindSimilar)
+		semanticGroup.POST("/child/:child_id/rebuild", semanticIndexController.Rebuild)
+	}
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupShareRoutes 设置分享链接相关路由
+func SetupShareRoutes(router *gin.Engine, shareController *controller.ShareController, jwtMiddleware *middleware.JwtClient) {
+	// 公开路由（凭分享令牌访问，无需认证）
+	public := router.Group("/api/public/shares")
+	{
+		public.GET("/:token", shareController.ViewSharedDocument)
+		public.GET("/:token/download", shareController.DownloadSharedDocument)
+	}
+
+	// 分享人管理自己的链接
+	shareGroup := router.Group("/api/shares")
+	shareGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		shareGroup.POST("", shareController.CreateShareLink)
+		shareGroup.GET("", shareController.GetShareLinks)
+		shareGroup.DELETE("/:id", shareController.RevokeShareLink)
+		shareGroup.GET("/:id/access-logs", shareController.GetShareAccessLogs)
+	}
+}
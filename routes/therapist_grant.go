@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTherapistGrantRoutes 设置康复师授权路由
+func SetupTherapistGrantRoutes(router *gin.Engine, therapistGrantController *controller.TherapistGrantController, jwtMiddleware *middleware.JwtClient) {
+	grantGroup := router.Group("/api/therapist-grants")
+	grantGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		grantGroup.POST("", therapistGrantController.GrantTherapist)
+		grantGroup.GET("", therapistGrantController.ListChildGrants)
+		grantGroup.GET("/mine", therapistGrantController.ListMyGrants)
+		grantGroup.DELETE("/:id", therapistGrantController.RevokeGrant)
+	}
+}
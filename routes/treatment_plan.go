@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTreatmentPlanRoutes 设置治疗方案、目标和游戏训练记录相关路由
+func SetupTreatmentPlanRoutes(router *gin.Engine, treatmentPlanController *controller.TreatmentPlanController, jwtMiddleware *middleware.JwtClient) {
+	planGroup := router.Group("/api/treatment-plans")
+	planGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		// 治疗方案
+		planGroup.POST("", treatmentPlanController.CreatePlan)
+		planGroup.GET("/child/:child_id", treatmentPlanController.GetChildPlans)
+		planGroup.GET("/:plan_id", treatmentPlanController.GetPlan)
+		planGroup.PUT("/:plan_id", treatmentPlanController.UpdatePlan)
+		planGroup.DELETE("/:plan_id", treatmentPlanController.DeletePlan)
+
+		// 治疗目标
+		planGroup.POST("/:plan_id/goals", treatmentPlanController.AddGoal)
+		planGroup.GET("/goals/:goal_id", treatmentPlanController.GetGoal)
+		planGroup.PUT("/goals/:goal_id", treatmentPlanController.UpdateGoal)
+		planGroup.DELETE("/goals/:goal_id", treatmentPlanController.DeleteGoal)
+		planGroup.POST("/goals/:goal_id/links", treatmentPlanController.LinkGoal)
+		planGroup.DELETE("/goals/:goal_id/links/:link_id", treatmentPlanController.UnlinkGoal)
+	}
+
+	gameSessionGroup := router.Group("/api/game-sessions")
+	gameSessionGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		gameSessionGroup.POST("", treatmentPlanController.CreateGameSession)
+		gameSessionGroup.GET("/child/:child_id", treatmentPlanController.GetChildGameSessions)
+	}
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupUsageRoutes 设置AI用量配额和统计相关路由
+func SetupUsageRoutes(router *gin.Engine, usageController *controller.UsageController, jwtMiddleware *middleware.JwtClient) {
+	usageGroup := router.Group("/api/usage")
+	usageGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		// 当前用户的配额
+		usageGroup.GET("/quota", usageController.GetQuota)
+
+		// 管理员统计和机构设置
+		usageGroup.GET("/summary", usageController.GetUsageSummary)
+		usageGroup.PUT("/users/:user_id/institution", usageController.SetUserInstitution)
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"melody_cure/controller"
+	"melody_cure/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupVoiceLogRoutes 设置语音疗愈日志路由
+func SetupVoiceLogRoutes(router *gin.Engine, voiceLogController *controller.VoiceLogController, jwtMiddleware *middleware.JwtClient) {
+	voiceLogGroup := router.Group("/api/healing-log")
+	voiceLogGroup.Use(jwtMiddleware.AuthMiddleware())
+	{
+		voiceLogGroup.POST("/voice", voiceLogController.CreateVoiceLog)
+	}
+}
@@ -0,0 +1,178 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultLockTTL 每次执行的锁保留时间，需大于各副本之间可能的时钟偏差
+const defaultLockTTL = 30 * time.Minute
+
+// Schedule 计算任务的下一次执行时间
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// Job 定时任务
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+	LockTTL  time.Duration
+}
+
+// Scheduler 进程内定时任务调度器，多副本部署时通过 Redis 锁保证同一时刻的任务只执行一次
+type Scheduler struct {
+	rdb   *redis.Client
+	jobs  []*Job
+	owner string
+	wg    sync.WaitGroup
+}
+
+func NewScheduler(rdb *redis.Client) *Scheduler {
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		rdb:   rdb,
+		owner: fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+	}
+}
+
+// Register 注册定时任务，需在 Start 之前调用
+func (s *Scheduler) Register(job *Job) {
+	if job.LockTTL <= 0 {
+		job.LockTTL = defaultLockTTL
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start 启动所有任务，ctx 取消后停止调度
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+	log.Printf("定时任务调度器已启动，共 %d 个任务", len(s.jobs))
+}
+
+// Wait 等待所有任务循环退出
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	defer s.wg.Done()
+	for {
+		next := job.Schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.runOnce(ctx, job, next)
+		}
+	}
+}
+
+// runOnce 以任务名和计划执行时间作为锁，抢到锁的副本执行任务
+func (s *Scheduler) runOnce(ctx context.Context, job *Job, scheduledAt time.Time) {
+	key := fmt.Sprintf("scheduler:lock:%s:%d", job.Name, scheduledAt.Unix())
+	acquired, err := s.rdb.SetNX(ctx, key, s.owner, job.LockTTL).Result()
+	if err != nil {
+		log.Printf("定时任务 %s 获取锁失败: %v", job.Name, err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("定时任务 %s 异常: %v", job.Name, r)
+		}
+	}()
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		log.Printf("定时任务 %s 执行失败: %v", job.Name, err)
+		return
+	}
+	log.Printf("定时任务 %s 执行完成，耗时 %s", job.Name, time.Since(start))
+}
+
+// dailySchedule 每天固定时间执行
+type dailySchedule struct {
+	hour, minute int
+}
+
+// Daily 每天 hour:minute（本地时区）执行
+func Daily(hour, minute int) Schedule {
+	return dailySchedule{hour: hour, minute: minute}
+}
+
+func (d dailySchedule) Next(after time.Time) time.Time {
+	next := time.Date(after.Year(), after.Month(), after.Day(), d.hour, d.minute, 0, 0, time.Local)
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// weeklySchedule 每周固定时间执行
+type weeklySchedule struct {
+	weekday      time.Weekday
+	hour, minute int
+}
+
+// Weekly 每周 weekday 的 hour:minute（本地时区）执行
+func Weekly(weekday time.Weekday, hour, minute int) Schedule {
+	return weeklySchedule{weekday: weekday, hour: hour, minute: minute}
+}
+
+func (w weeklySchedule) Next(after time.Time) time.Time {
+	next := time.Date(after.Year(), after.Month(), after.Day(), w.hour, w.minute, 0, 0, time.Local)
+	next = next.AddDate(0, 0, (int(w.weekday)-int(next.Weekday())+7)%7)
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// intervalSchedule 固定间隔执行，执行时间按间隔对齐，保证各副本计算出相同的执行时间
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Every 每隔 interval 执行一次
+func Every(interval time.Duration) Schedule {
+	return intervalSchedule{interval: interval}
+}
+
+func (i intervalSchedule) Next(after time.Time) time.Time {
+	return after.Truncate(i.interval).Add(i.interval)
+}
+
+// ParseClock 解析 "HH:MM" 格式的时间
+func ParseClock(value string) (hour, minute int, err error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("时间格式错误，请使用 HH:MM 格式: %s", value)
+	}
+	hour, err = strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, fmt.Errorf("小时无效: %s", value)
+	}
+	minute, err = strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("分钟无效: %s", value)
+	}
+	return hour, minute, nil
+}
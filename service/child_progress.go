@@ -0,0 +1,231 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"melody_cure/DAO"
+	"melody_cure/model"
+	"strconv"
+	"time"
+)
+
+// healingMilestones 疗愈里程碑（坚持疗愈的天数）
+var healingMilestones = []int{7, 30, 100}
+
+type ChildProgressService struct {
+	userDAO             *DAO.UserDAO
+	healingLogDAO       *DAO.HealingLogDAO
+	milestoneDAO        *DAO.ChildMilestoneDAO
+	notificationService *NotificationService
+}
+
+func NewChildProgressService(userDAO *DAO.UserDAO, healingLogDAO *DAO.HealingLogDAO, milestoneDAO *DAO.ChildMilestoneDAO, notificationService *NotificationService) *ChildProgressService {
+	return &ChildProgressService{
+		userDAO:             userDAO,
+		healingLogDAO:       healingLogDAO,
+		milestoneDAO:        milestoneDAO,
+		notificationService: notificationService,
+	}
+}
+
+// ChildProgress 儿童疗愈进度统计
+type ChildProgress struct {
+	HealedDays    int                    `json:"healed_days"`    // 治疗开始后有记录的天数
+	CurrentStreak int                    `json:"current_streak"` // 当前连续记录天数
+	LongestStreak int                    `json:"longest_streak"` // 最长连续记录天数
+	LastLogDate   *time.Time             `json:"last_log_date"`
+	Milestones    []model.ChildMilestone `json:"milestones"`
+	NextMilestone int                    `json:"next_milestone"` // 下一个里程碑天数，全部达成时为0
+}
+
+// CalendarDay 日历中某一天的记录情况
+type CalendarDay struct {
+	Date        string `json:"date"`
+	LogCount    int    `json:"log_count"`
+	MediaCount  int    `json:"media_count"`
+	MetricCount int    `json:"metric_count"`
+}
+
+// ChildCalendar 儿童的月度记录日历
+type ChildCalendar struct {
+	Month string        `json:"month"`
+	Days  []CalendarDay `json:"days"`
+}
+
+// GetProgress 获取儿童的疗愈进度，同时刷新已疗愈天数和里程碑
+func (s *ChildProgressService) GetProgress(userID, childArchiveID string) (*ChildProgress, error) {
+	archive, err := checkChildAccess(s.userDAO, userID, childArchiveID)
+	if err != nil {
+		return nil, err
+	}
+	return s.RefreshProgress(archive)
+}
+
+// RefreshProgress 根据治疗开始日期和日志记录重新计算疗愈天数、连续记录天数并检查里程碑
+func (s *ChildProgressService) RefreshProgress(archive *DAO.ChildArchive) (*ChildProgress, error) {
+	childID, err := strconv.ParseUint(archive.ID, 10, 64)
+	if err != nil {
+		return nil, invalidRequest("儿童档案ID格式错误: %v", err)
+	}
+
+	logTimes, err := s.healingLogDAO.GetLogTimesByChildID(uint(childID), archive.TreatmentStartDate)
+	if err != nil {
+		return nil, fmt.Errorf("获取日志记录失败: %v", err)
+	}
+
+	days := distinctDays(logTimes)
+	progress := &ChildProgress{HealedDays: len(days)}
+	progress.CurrentStreak, progress.LongestStreak = computeStreaks(days, time.Now())
+	if len(days) > 0 {
+		last := days[len(days)-1]
+		progress.LastLogDate = &last
+	}
+
+	if progress.HealedDays != archive.HealedDays {
+		if err := s.userDAO.UpdateChildHealedDays(archive.ID, progress.HealedDays); err != nil {
+			return nil, fmt.Errorf("更新疗愈天数失败: %v", err)
+		}
+		archive.HealedDays = progress.HealedDays
+	}
+
+	milestones, err := s.checkMilestones(archive, progress.HealedDays)
+	if err != nil {
+		return nil, err
+	}
+	progress.Milestones = milestones
+	for _, threshold := range healingMilestones {
+		if threshold > progress.HealedDays {
+			progress.NextMilestone = threshold
+			break
+		}
+	}
+	return progress, nil
+}
+
+// RefreshByChildID 日志变更后刷新进度，失败只记录日志，不影响日志本身的保存
+func (s *ChildProgressService) RefreshByChildID(childID uint) {
+	archive, err := s.userDAO.GetChildArchiveByID(strconv.FormatUint(uint64(childID), 10))
+	if err != nil {
+		return
+	}
+	if _, err := s.RefreshProgress(archive); err != nil {
+		log.Printf("刷新儿童 %s 疗愈进度失败: %v", archive.ID, err)
+	}
+}
+
+// GetCalendar 获取指定月份每天的日志、媒体和指标数量
+func (s *ChildProgressService) GetCalendar(userID, childArchiveID string, month time.Time) (*ChildCalendar, error) {
+	if _, err := checkChildAccess(s.userDAO, userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	childID, err := strconv.ParseUint(childArchiveID, 10, 64)
+	if err != nil {
+		return nil, invalidRequest("儿童档案ID格式错误: %v", err)
+	}
+
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+	logs, err := s.healingLogDAO.GetHealingLogsByChildIDWithDateFilter(uint(childID), &start, &end)
+	if err != nil {
+		return nil, fmt.Errorf("获取日志记录失败: %v", err)
+	}
+
+	dayIndex := make(map[string]*CalendarDay)
+	for _, healingLog := range logs {
+		key := healingLog.CreatedAt.In(time.Local).Format("2006-01-02")
+		day, ok := dayIndex[key]
+		if !ok {
+			day = &CalendarDay{Date: key}
+			dayIndex[key] = day
+		}
+		day.LogCount++
+		day.MediaCount += len(healingLog.Media)
+		day.MetricCount += len(healingLog.Metrics)
+	}
+
+	calendar := &ChildCalendar{Month: start.Format("2006-01")}
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		if day, ok := dayIndex[key]; ok {
+			calendar.Days = append(calendar.Days, *day)
+		} else {
+			calendar.Days = append(calendar.Days, CalendarDay{Date: key})
+		}
+	}
+	return calendar, nil
+}
+
+// checkMilestones 记录新达成的里程碑并通知家长
+func (s *ChildProgressService) checkMilestones(archive *DAO.ChildArchive, healedDays int) ([]model.ChildMilestone, error) {
+	milestones, err := s.milestoneDAO.GetMilestonesByChildID(archive.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取里程碑失败: %v", err)
+	}
+	reached := make(map[int]bool, len(milestones))
+	for _, milestone := range milestones {
+		reached[milestone.Days] = true
+	}
+
+	for _, days := range healingMilestones {
+		if days > healedDays || reached[days] {
+			continue
+		}
+		milestone := model.ChildMilestone{
+			ChildArchiveID: archive.ID,
+			Days:           days,
+			Badge:          fmt.Sprintf("坚持疗愈%d天", days),
+			ReachedAt:      time.Now(),
+		}
+		if err := s.milestoneDAO.CreateMilestone(&milestone); err != nil {
+			return nil, fmt.Errorf("记录里程碑失败: %v", err)
+		}
+		milestones = append(milestones, milestone)
+
+		content := fmt.Sprintf("%s 已累计疗愈记录 %d 天，获得「%s」徽章，继续加油！", archive.ChildName, days, milestone.Badge)
+		if err := s.notificationService.Notify(archive.UserID, model.NotificationMilestone, "疗愈里程碑达成", content, "child_archive", archive.ID); err != nil {
+			log.Printf("发送里程碑通知失败: %v", err)
+		}
+	}
+	return milestones, nil
+}
+
+// distinctDays 将记录时间去重为按天排序的日期（本地时区零点）
+func distinctDays(times []time.Time) []time.Time {
+	var days []time.Time
+	seen := make(map[string]bool)
+	for _, t := range times {
+		local := t.In(time.Local)
+		key := local.Format("2006-01-02")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		days = append(days, time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local))
+	}
+	return days
+}
+
+// computeStreaks 计算当前连续记录天数（截止今天或昨天）和历史最长连续天数，days 需按时间升序
+func computeStreaks(days []time.Time, now time.Time) (current int, longest int) {
+	run := 0
+	for i, day := range days {
+		if i > 0 && days[i-1].AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	if len(days) == 0 {
+		return 0, longest
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	last := days[len(days)-1]
+	if last.Equal(today) || last.Equal(today.AddDate(0, 0, -1)) {
+		current = run
+	}
+	return current, longest
+}
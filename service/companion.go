@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/config"
+	"melody_cure/model"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 陪伴对话请求的报告类型，用于按类型覆盖模型、记录用量和模拟提供商识别
+const (
+	companionChatReportType   = "companion_chat"
+	companionMemoryReportType = "companion_memory"
+)
+
+// companionTranscriptLimit 查看对话记录时每页的默认消息数
+const companionTranscriptLimit = 50
+
+var ErrConversationNotFound = errors.New("对话不存在")
+
+// companionMemorySystemMessage 更新记忆摘要的系统消息
+const companionMemorySystemMessage = "你负责为儿童陪伴应用整理对话记忆。请把已有记忆和新的对话合并为一段不超过200字的中文摘要，记录孩子提到的兴趣爱好、重要的人和事、情绪变化以及约定过的事情，供陪伴角色在之后的对话中参考。只输出摘要本身，方括号占位符（如 [儿童姓名]）原样保留。"
+
+// companionDiagnosisGuidance 按诊断调整陪伴角色说话方式的提示，诊断中包含任一关键词时追加
+var companionDiagnosisGuidance = []struct {
+	keywords []string
+	guidance string
+}{
+	{[]string{"孤独症", "自闭症", "谱系", "ASD"}, "使用直接、具体的表达，避免比喻、反语和模糊的说法，一次只问一个问题"},
+	{[]string{"多动", "注意缺陷", "ADHD"}, "回复要简短，及时给予具体的表扬，孩子跑题时温和地把话题拉回来"},
+	{[]string{"语言发育", "语言障碍", "构音"}, "使用短句和常见的词，耐心等待孩子表达，不纠正孩子的说法，而是自然地示范正确的说法"},
+	{[]string{"焦虑", "抑郁", "情绪障碍"}, "语气平稳温和，多给孩子安全感，不催促、不追问"},
+}
+
+type CompanionService struct {
+	companionDAO        *DAO.CompanionDAO
+	userDAO             *DAO.UserDAO
+	notificationService *NotificationService
+	llmProvider         LLMProvider
+	speechService       *SpeechService
+	filterRules         []companionFilterRule
+}
+
+func NewCompanionService(companionDAO *DAO.CompanionDAO, userDAO *DAO.UserDAO, notificationService *NotificationService, llmProvider LLMProvider, speechService *SpeechService) (*CompanionService, error) {
+	configs := config.GetCompanionConfig().FilterRules
+	if len(configs) == 0 {
+		configs = defaultCompanionFilterRules()
+	}
+	rules, err := compileCompanionFilterRules(configs)
+	if err != nil {
+		return nil, err
+	}
+	return &CompanionService{
+		companionDAO:        companionDAO,
+		userDAO:             userDAO,
+		notificationService: notificationService,
+		llmProvider:         llmProvider,
+		speechService:       speechService,
+		filterRules:         rules,
+	}, nil
+}
+
+// CompanionTranscript 家长查看的对话记录
+type CompanionTranscript struct {
+	Conversation  *model.CompanionConversation `json:"conversation"`
+	CompanionName string                       `json:"companion_name"`
+	Messages      []model.CompanionMessage     `json:"messages"`
+	HasMore       bool                         `json:"has_more"` // 是否还有更早的消息
+}
+
+// CompanionExchange 一轮对话：儿童的消息和陪伴的回复
+type CompanionExchange struct {
+	Message      *model.CompanionMessage      `json:"message"`
+	Reply        *model.CompanionMessage      `json:"reply"`
+	Conversation *model.CompanionConversation `json:"conversation"`
+}
+
+// StartConversation 家长为自己的孩子开始与自己创建的AI陪伴的对话
+func (s *CompanionService) StartConversation(userID string, req *request.StartCompanionConversationRequest) (*model.CompanionConversation, error) {
+	companion, err := s.getOwnCompanion(userID, req.CompanionID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getOwnChild(userID, req.ChildArchiveID); err != nil {
+		return nil, err
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = fmt.Sprintf("和%s的对话 %s", companion.Name, time.Now().Format("2006-01-02"))
+	}
+	conversation := &model.CompanionConversation{
+		CompanionID:    companion.ID,
+		ChildArchiveID: req.ChildArchiveID,
+		UserID:         userID,
+		Title:          title,
+	}
+	if err := s.companionDAO.CreateConversation(conversation); err != nil {
+		return nil, fmt.Errorf("创建对话失败: %v", err)
+	}
+	return conversation, nil
+}
+
+// ListConversations 家长查看孩子的陪伴对话，companionID 为空时不限陪伴
+func (s *CompanionService) ListConversations(userID, childArchiveID, companionID string) ([]model.CompanionConversation, error) {
+	if _, err := s.getOwnChild(userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	conversations, err := s.companionDAO.ListConversations(childArchiveID, companionID)
+	if err != nil {
+		return nil, fmt.Errorf("获取对话失败: %v", err)
+	}
+	return conversations, nil
+}
+
+// GetTranscript 家长查看对话记录，包括被过滤的消息。beforeID 大于0时返回该消息之前的一页
+func (s *CompanionService) GetTranscript(userID string, conversationID, beforeID uint, limit int) (*CompanionTranscript, error) {
+	conversation, err := s.getOwnConversation(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 200 {
+		limit = companionTranscriptLimit
+	}
+	// 多取一条判断是否还有更早的消息
+	messages, err := s.companionDAO.GetMessages(conversation.ID, beforeID, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("获取对话记录失败: %v", err)
+	}
+	transcript := &CompanionTranscript{Conversation: conversation, Messages: messages}
+	if len(messages) > limit {
+		transcript.Messages, transcript.HasMore = messages[1:], true
+	}
+	if companion, err := s.companionDAO.GetAICompanionByID(conversation.CompanionID); err == nil {
+		transcript.CompanionName = companion.Name
+	}
+	return transcript, nil
+}
+
+// SendMessage 儿童通过家长的设备向陪伴发送消息并获得回复。儿童消息命中过滤规则时不发送给模型，
+// 直接以规则的回复作答，需要关注的类别通知家长；模型回复命中规则时替换为规则的回复。withAudio 为 true 时以陪伴的语音类型合成回复语音
+func (s *CompanionService) SendMessage(ctx context.Context, userID string, conversationID uint, content string, withAudio bool) (*CompanionExchange, error) {
+	cfg := config.GetCompanionConfig()
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, invalidRequest("消息不能为空")
+	}
+	if cfg.MaxMessageLength > 0 && utf8.RuneCountInString(content) > cfg.MaxMessageLength {
+		return nil, invalidRequest("消息不能超过%d字", cfg.MaxMessageLength)
+	}
+	conversation, err := s.getOwnConversation(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	companion, err := s.companionDAO.GetAICompanionByID(conversation.CompanionID)
+	if err != nil {
+		return nil, notFound("AI陪伴不存在")
+	}
+	archive, err := s.userDAO.GetChildArchiveByID(conversation.ChildArchiveID)
+	if err != nil {
+		return nil, ErrChildNotFound
+	}
+	age := ageInYears(archive.BirthDate, time.Now())
+
+	message := &model.CompanionMessage{Role: model.CompanionRoleChild, Content: content}
+	if rule := matchCompanionFilter(s.filterRules, content, age); rule != nil {
+		message.Filtered, message.FilterCategory = true, rule.category
+		reply := &model.CompanionMessage{Role: model.CompanionRoleCompanion, Content: rule.reply}
+		if withAudio {
+			s.attachAudio(ctx, companion, reply)
+		}
+		if err := s.companionDAO.AppendMessages(conversation, message, reply); err != nil {
+			return nil, fmt.Errorf("保存对话失败: %v", err)
+		}
+		if rule.notifyParent {
+			s.notifyParent(conversation, archive, companion, rule.category)
+		}
+		return &CompanionExchange{Message: message, Reply: reply, Conversation: conversation}, nil
+	}
+
+	var redactor *piiRedactor
+	if config.GetAIConfig().RedactPII {
+		var parent *DAO.User
+		if user, err := s.userDAO.GetUserByID(archive.UserID); err == nil {
+			parent = user
+		}
+		redactor = newReportRedactor(archive, parent)
+	}
+	history, err := s.companionDAO.GetMessagesAfter(conversation.ID, conversation.SummarizedUntil)
+	if err != nil {
+		return nil, fmt.Errorf("获取对话记录失败: %v", err)
+	}
+	messages := []LLMMessage{{Role: "system", Content: redactor.Redact(companionSystemPrompt(companion, archive, age, conversation.MemorySummary))}}
+	for _, entry := range recentCompanionHistory(history, cfg.HistoryMessages) {
+		role := "user"
+		if entry.Role == model.CompanionRoleCompanion {
+			role = "assistant"
+		}
+		messages = append(messages, LLMMessage{Role: role, Content: redactor.Redact(entry.Content)})
+	}
+	messages = append(messages, LLMMessage{Role: "user", Content: redactor.Redact(content)})
+
+	ctx = WithUsageScope(ctx, userID, model.AIUsageSourceCompanion)
+	resp, err := s.llmProvider.Chat(ctx, &LLMRequest{
+		Model:       modelForReportType(companionChatReportType),
+		Messages:    messages,
+		MaxTokens:   cfg.MaxReplyTokens,
+		Temperature: cfg.Temperature,
+		ReportType:  companionChatReportType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AI陪伴回复失败: %w", err)
+	}
+
+	reply := &model.CompanionMessage{
+		Role:     model.CompanionRoleCompanion,
+		Content:  strings.TrimSpace(redactor.Restore(resp.Content)),
+		Provider: resp.Provider,
+		Model:    resp.Model,
+	}
+	if rule := matchCompanionFilter(s.filterRules, reply.Content, age); rule != nil {
+		log.Printf("陪伴对话 %d 的回复命中过滤规则 %s，已替换", conversation.ID, rule.name)
+		reply.Content, reply.Filtered, reply.FilterCategory = rule.reply, true, rule.category
+	}
+	if withAudio {
+		s.attachAudio(ctx, companion, reply)
+	}
+	if err := s.companionDAO.AppendMessages(conversation, message, reply); err != nil {
+		return nil, fmt.Errorf("保存对话失败: %v", err)
+	}
+	if err := s.refreshMemory(ctx, conversation, companion, redactor); err != nil {
+		log.Printf("更新陪伴对话 %d 的记忆摘要失败: %v", conversation.ID, err)
+	}
+	return &CompanionExchange{Message: message, Reply: reply, Conversation: conversation}, nil
+}
+
+// SynthesizeMessage 为对话中已有的陪伴回复合成语音，已合成过时直接返回
+func (s *CompanionService) SynthesizeMessage(ctx context.Context, userID string, conversationID, messageID uint) (*model.CompanionMessage, error) {
+	conversation, err := s.getOwnConversation(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	message, err := s.companionDAO.GetMessageByID(conversation.ID, messageID)
+	if err != nil {
+		return nil, notFound("消息不存在")
+	}
+	if message.Role != model.CompanionRoleCompanion {
+		return nil, invalidRequest("只能为陪伴的回复合成语音")
+	}
+	if message.AudioURL != "" {
+		return message, nil
+	}
+	companion, err := s.companionDAO.GetAICompanionByID(conversation.CompanionID)
+	if err != nil {
+		return nil, notFound("AI陪伴不存在")
+	}
+	speech, err := s.speechService.Synthesize(ctx, message.Content, companion.VoiceType)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.companionDAO.UpdateMessageAudio(message.ID, speech.URL); err != nil {
+		return nil, fmt.Errorf("保存语音地址失败: %v", err)
+	}
+	message.AudioURL = speech.URL
+	return message, nil
+}
+
+// attachAudio 以陪伴的语音类型合成回复语音，失败时只记录日志，回复仍以文字返回
+func (s *CompanionService) attachAudio(ctx context.Context, companion *DAO.AICompanion, reply *model.CompanionMessage) {
+	speech, err := s.speechService.Synthesize(ctx, reply.Content, companion.VoiceType)
+	if err != nil {
+		log.Printf("合成陪伴 %s 的回复语音失败: %v", companion.ID, err)
+		return
+	}
+	reply.AudioURL = speech.URL
+}
+
+// refreshMemory 未合并进记忆摘要的消息超过阈值时，将最近 historyMessages 条之前的消息合并进摘要
+func (s *CompanionService) refreshMemory(ctx context.Context, conversation *model.CompanionConversation, companion *DAO.AICompanion, redactor *piiRedactor) error {
+	cfg := config.GetCompanionConfig()
+	pending, err := s.companionDAO.GetMessagesAfter(conversation.ID, conversation.SummarizedUntil)
+	if err != nil {
+		return err
+	}
+	if len(pending) <= cfg.SummaryThreshold || len(pending) <= cfg.HistoryMessages {
+		return nil
+	}
+	folded := pending[:len(pending)-cfg.HistoryMessages]
+
+	memory := conversation.MemorySummary
+	if memory == "" {
+		memory = "无"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "已有记忆：%s\n\n新的对话：\n", memory)
+	for _, entry := range companionTranscriptEntries(folded) {
+		speaker := "儿童"
+		if entry.Role == model.CompanionRoleCompanion {
+			speaker = companion.Name
+		}
+		fmt.Fprintf(&b, "%s：%s\n", speaker, entry.Content)
+	}
+	resp, err := s.llmProvider.Chat(ctx, &LLMRequest{
+		Model: modelForReportType(companionMemoryReportType),
+		Messages: []LLMMessage{
+			{Role: "system", Content: companionMemorySystemMessage},
+			{Role: "user", Content: redactor.Redact(b.String())},
+		},
+		MaxTokens:   400,
+		Temperature: 0.3,
+		ReportType:  companionMemoryReportType,
+	})
+	if err != nil {
+		return err
+	}
+	summary := strings.TrimSpace(redactor.Restore(resp.Content))
+	until := folded[len(folded)-1].ID
+	if err := s.companionDAO.UpdateMemory(conversation.ID, summary, until); err != nil {
+		return err
+	}
+	conversation.MemorySummary, conversation.SummarizedUntil = summary, until
+	return nil
+}
+
+// companionSystemPrompt 根据陪伴的性格设定、儿童的年龄和诊断以及记忆摘要构建系统消息。
+// 诊断只用于调整说话方式，不向儿童提起
+func companionSystemPrompt(companion *DAO.AICompanion, archive *DAO.ChildArchive, age int, memory string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "你是儿童康复陪伴应用中的AI陪伴角色「%s」", companion.Name)
+	if companion.CompanionType != "" {
+		fmt.Fprintf(&b, "（%s）", companion.CompanionType)
+	}
+	b.WriteString("。\n")
+	personality := strings.TrimSpace(companion.Personality)
+	if personality == "" {
+		personality = "温暖、耐心、爱鼓励人"
+	}
+	fmt.Fprintf(&b, "性格设定：%s\n", personality)
+
+	b.WriteString("你正在和")
+	if age > 0 {
+		fmt.Fprintf(&b, "一位%d岁的", age)
+	} else {
+		b.WriteString("一个")
+	}
+	fmt.Fprintf(&b, "孩子聊天，孩子叫%s。", archive.ChildName)
+	if diagnosis := strings.TrimSpace(archive.Diagnosis); diagnosis != "" {
+		fmt.Fprintf(&b, "孩子的诊断：%s。诊断只用于调整你说话的方式，不要向孩子提起诊断或病情。", diagnosis)
+	}
+	b.WriteString("\n\n说话方式：\n")
+	switch {
+	case age > 0 && age <= 6:
+		b.WriteString("- 孩子年龄较小，使用非常简短的句子和常见的字词，每次回复不超过2句话，可以多用拟声词和简单的小游戏\n")
+	case age > 0 && age <= 12:
+		b.WriteString("- 使用简单清楚的句子，每次回复不超过3句话，可以聊学校、朋友和兴趣爱好，鼓励孩子多说一点\n")
+	case age > 12:
+		b.WriteString("- 孩子已是青少年，语气平等、尊重，不说教，每次回复不超过4句话\n")
+	default:
+		b.WriteString("- 使用简短、简单、温暖的句子，每次回复不超过3句话\n")
+	}
+	for _, item := range companionDiagnosisGuidance {
+		for _, keyword := range item.keywords {
+			if strings.Contains(strings.ToUpper(archive.Diagnosis), keyword) {
+				fmt.Fprintf(&b, "- %s\n", item.guidance)
+				break
+			}
+		}
+	}
+
+	b.WriteString(`
+对话规则：
+- 始终保持角色，多倾听、多鼓励，适当用简单的问题引导孩子说出自己的感受
+- 只聊适合这个年龄的话题，不涉及暴力、恐怖、色情、烟酒毒品、危险行为等内容，孩子提到时温和地转移话题
+- 不询问孩子和家人的住址、电话、学校、密码等隐私
+- 不提供医疗诊断或用药建议；孩子说身体不舒服、害怕或被人伤害时，鼓励孩子马上告诉爸爸妈妈或信任的大人
+`)
+	if memory = strings.TrimSpace(memory); memory != "" {
+		fmt.Fprintf(&b, "\n之前对话的记忆：%s\n", memory)
+	}
+	return b.String()
+}
+
+// recentCompanionHistory 取最近的消息作为模型上下文，被过滤的儿童消息及其替代回复不发送给模型
+func recentCompanionHistory(history []model.CompanionMessage, limit int) []model.CompanionMessage {
+	entries := companionTranscriptEntries(history)
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries
+}
+
+// companionTranscriptEntries 去掉被过滤的儿童消息及紧随其后的替代回复
+func companionTranscriptEntries(messages []model.CompanionMessage) []model.CompanionMessage {
+	entries := make([]model.CompanionMessage, 0, len(messages))
+	skipReply := false
+	for _, message := range messages {
+		if message.Role == model.CompanionRoleChild {
+			skipReply = message.Filtered
+			if message.Filtered {
+				continue
+			}
+		} else if skipReply {
+			skipReply = false
+			continue
+		}
+		entries = append(entries, message)
+	}
+	return entries
+}
+
+// notifyParent 儿童在对话中提到需要关注的内容时通知家长，通知中不包含消息原文
+func (s *CompanionService) notifyParent(conversation *model.CompanionConversation, archive *DAO.ChildArchive, companion *DAO.AICompanion, category string) {
+	content := fmt.Sprintf("%s在与「%s」的对话中提到了需要关注的内容（%s），请查看对话记录并及时与孩子沟通，必要时寻求专业帮助。",
+		archive.ChildName, companion.Name, companionFilterCategoryName(category))
+	if err := s.notificationService.Notify(archive.UserID, model.NotificationCompanionAlert, "陪伴对话提醒", content,
+		"companion_conversation", strconv.FormatUint(uint64(conversation.ID), 10)); err != nil {
+		log.Printf("发送陪伴对话提醒失败: %v", err)
+	}
+}
+
+// getOwnCompanion 获取用户自己创建的AI陪伴
+func (s *CompanionService) getOwnCompanion(userID, companionID string) (*DAO.AICompanion, error) {
+	companion, err := s.companionDAO.GetAICompanionByID(companionID)
+	if err != nil {
+		return nil, notFound("AI陪伴不存在")
+	}
+	if companion.UserID != userID {
+		return nil, ErrPermissionDenied
+	}
+	return companion, nil
+}
+
+// getOwnChild 陪伴对话和对话记录只对孩子的家长开放
+func (s *CompanionService) getOwnChild(userID, childArchiveID string) (*DAO.ChildArchive, error) {
+	archive, err := s.userDAO.GetChildArchiveByID(childArchiveID)
+	if err != nil {
+		return nil, ErrChildNotFound
+	}
+	if archive.UserID != userID {
+		return nil, ErrPermissionDenied
+	}
+	return archive, nil
+}
+
+// getOwnConversation 获取家长自己孩子的对话
+func (s *CompanionService) getOwnConversation(userID string, conversationID uint) (*model.CompanionConversation, error) {
+	conversation, err := s.companionDAO.GetConversationByID(conversationID)
+	if err != nil {
+		return nil, ErrConversationNotFound
+	}
+	if _, err := s.getOwnChild(userID, conversation.ChildArchiveID); err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
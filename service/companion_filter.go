@@ -0,0 +1,163 @@
+package service
+
+import (
+	"fmt"
+	"melody_cure/config"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 陪伴对话内容过滤的类别
+const (
+	CompanionFilterSelfHarm     = "self_harm"
+	CompanionFilterAbuse        = "abuse"
+	CompanionFilterViolence     = "violence"
+	CompanionFilterSexual       = "sexual"
+	CompanionFilterSubstance    = "substance"
+	CompanionFilterScary        = "scary"
+	CompanionFilterPersonalInfo = "personal_info"
+)
+
+// companionFilterCategoryNames 过滤类别的中文名称，用于家长通知
+var companionFilterCategoryNames = map[string]string{
+	CompanionFilterSelfHarm:     "自我伤害",
+	CompanionFilterAbuse:        "可能受到伤害",
+	CompanionFilterViolence:     "暴力",
+	CompanionFilterSexual:       "不适宜的性内容",
+	CompanionFilterSubstance:    "烟酒毒品和赌博",
+	CompanionFilterScary:        "恐怖内容",
+	CompanionFilterPersonalInfo: "个人隐私",
+}
+
+// companionFilterReplies 命中过滤规则时代替模型回复儿童的话
+var companionFilterReplies = map[string]string{
+	CompanionFilterSelfHarm:     "谢谢你愿意告诉我这些，这很重要。你现在能去找爸爸妈妈或者你信任的大人，和他们说说你的感受吗？他们会陪着你的。",
+	CompanionFilterAbuse:        "你说的事情很重要，这不是你的错。请马上告诉爸爸妈妈或者你信任的老师，他们会保护你。",
+	CompanionFilterPersonalInfo: "这些是你和家人的小秘密，不要告诉别人哦，包括我。我们聊点别的吧！",
+}
+
+// defaultCompanionFilterReply 未单独设置回复的类别使用的回复
+const defaultCompanionFilterReply = "这个话题我们先不聊啦。和我说说今天让你开心的一件事好不好？"
+
+// defaultCompanionFilterRules 内置的年龄分级过滤规则，英文按整词匹配
+func defaultCompanionFilterRules() []config.CompanionFilterRuleConfig {
+	return []config.CompanionFilterRuleConfig{
+		{
+			Name:         "self_harm",
+			Category:     CompanionFilterSelfHarm,
+			NotifyParent: true,
+			Keywords:     []string{"自杀", "想死", "不想活", "伤害自己", "割腕", "跳楼", "kill myself", "suicide", "hurt myself", "want to die"},
+		},
+		{
+			Name:         "abuse",
+			Category:     CompanionFilterAbuse,
+			NotifyParent: true,
+			Keywords:     []string{"打我", "被打", "不让我告诉", "不许告诉别人", "摸我", "欺负我", "hits me", "touched me"},
+		},
+		{
+			Name:     "violence",
+			Category: CompanionFilterViolence,
+			Keywords: []string{"杀人", "砍人", "开枪", "枪杀", "血腥", "打死"},
+			Patterns: []string{`(?i)\b(kill|shoot|blood)\b`},
+		},
+		{
+			Name:     "sexual",
+			Category: CompanionFilterSexual,
+			Keywords: []string{"色情", "性行为", "裸体", "做爱"},
+			Patterns: []string{`(?i)\b(porn|sex|nude)\b`},
+		},
+		{
+			Name:     "substance",
+			Category: CompanionFilterSubstance,
+			Keywords: []string{"毒品", "吸毒", "抽烟", "喝酒", "赌博"},
+			Patterns: []string{`(?i)\b(drugs|cigarettes?|alcohol|gambling)\b`},
+		},
+		{
+			Name:     "scary_young",
+			Category: CompanionFilterScary,
+			MaxAge:   8,
+			Keywords: []string{"闹鬼", "鬼怪", "恐怖", "僵尸", "丧尸", "吓死"},
+			Patterns: []string{`(?i)\b(ghosts?|zombies?|horror)\b`},
+		},
+		{
+			Name:     "personal_info",
+			Category: CompanionFilterPersonalInfo,
+			Keywords: []string{"家庭住址", "住在哪", "密码", "身份证"},
+			Patterns: []string{`1[3-9]\d{9}`, `(?i)\b(password|home address)\b`},
+		},
+	}
+}
+
+// companionFilterRule 编译后的陪伴对话过滤规则，匹配方式与报告安全规则相同
+type companionFilterRule struct {
+	safetyRule
+	maxAge       int
+	notifyParent bool
+	reply        string
+}
+
+// compileCompanionFilterRules 校验并编译过滤规则，未设置回复时使用类别的内置回复
+func compileCompanionFilterRules(configs []config.CompanionFilterRuleConfig) ([]companionFilterRule, error) {
+	rules := make([]companionFilterRule, 0, len(configs))
+	for i, cfg := range configs {
+		rule := companionFilterRule{
+			safetyRule:   safetyRule{name: cfg.Name, category: cfg.Category},
+			maxAge:       cfg.MaxAge,
+			notifyParent: cfg.NotifyParent,
+			reply:        strings.TrimSpace(cfg.Reply),
+		}
+		if rule.name == "" {
+			rule.name = "companion_rule_" + strconv.Itoa(i+1)
+		}
+		if rule.reply == "" {
+			rule.reply = companionFilterReply(rule.category)
+		}
+		for _, keyword := range cfg.Keywords {
+			if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+				rule.keywords = append(rule.keywords, keyword)
+			}
+		}
+		for _, pattern := range cfg.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("陪伴过滤规则 %s 的正则表达式无效: %v", rule.name, err)
+			}
+			rule.patterns = append(rule.patterns, re)
+		}
+		if len(rule.keywords) == 0 && len(rule.patterns) == 0 {
+			return nil, fmt.Errorf("陪伴过滤规则 %s 没有关键词或正则表达式", rule.name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func companionFilterReply(category string) string {
+	if reply, ok := companionFilterReplies[category]; ok {
+		return reply
+	}
+	return defaultCompanionFilterReply
+}
+
+func companionFilterCategoryName(category string) string {
+	if name, ok := companionFilterCategoryNames[category]; ok {
+		return name
+	}
+	return category
+}
+
+// appliesTo 规则是否适用于该年龄的儿童，年龄未知时所有规则都适用
+func (r *companionFilterRule) appliesTo(age int) bool {
+	return r.maxAge <= 0 || age <= 0 || age <= r.maxAge
+}
+
+// matchCompanionFilter 返回文本命中的第一条适用规则，未命中时返回空
+func matchCompanionFilter(rules []companionFilterRule, text string, age int) *companionFilterRule {
+	for i := range rules {
+		if rules[i].appliesTo(age) && rules[i].matches(text) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
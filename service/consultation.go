@@ -0,0 +1,487 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/config"
+	"melody_cure/model"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// consultationReportType 咨询回复请求的报告类型，用于按类型覆盖模型、记录用量和模拟提供商识别
+const consultationReportType = "consultation"
+
+var (
+	ErrConsultationNotFound = errors.New("咨询会话不存在")
+	ErrEscalationNotFound   = errors.New("转介申请不存在")
+	ErrEscalationClosed     = errors.New("该转介申请已被处理")
+)
+
+type ConsultationService struct {
+	consultationDAO     *DAO.ConsultationDAO
+	userDAO             *DAO.UserDAO
+	healingLogDAO       *DAO.HealingLogDAO
+	generatedReportDAO  *DAO.GeneratedReportDAO
+	reportTypeDAO       *DAO.ReportTypeDAO
+	notificationService *NotificationService
+	llmProvider         LLMProvider
+}
+
+func NewConsultationService(consultationDAO *DAO.ConsultationDAO, userDAO *DAO.UserDAO, healingLogDAO *DAO.HealingLogDAO, generatedReportDAO *DAO.GeneratedReportDAO, reportTypeDAO *DAO.ReportTypeDAO, notificationService *NotificationService, llmProvider LLMProvider) *ConsultationService {
+	return &ConsultationService{
+		consultationDAO:     consultationDAO,
+		userDAO:             userDAO,
+		healingLogDAO:       healingLogDAO,
+		generatedReportDAO:  generatedReportDAO,
+		reportTypeDAO:       reportTypeDAO,
+		notificationService: notificationService,
+		llmProvider:         llmProvider,
+	}
+}
+
+// ConsultationDetail 咨询会话的完整记录
+type ConsultationDetail struct {
+	Thread        *model.ConsultationThread      `json:"thread"`
+	TherapistName string                         `json:"therapist_name"` // 虚拟疗愈导师的名称
+	Messages      []model.ConsultationMessage    `json:"messages"`
+	Escalations   []model.ConsultationEscalation `json:"escalations"`
+}
+
+// ConsultationExchange 一次发送的消息，以及虚拟疗愈导师的回复（会话由康复师接手时为空）
+type ConsultationExchange struct {
+	Message *model.ConsultationMessage `json:"message"`
+	Reply   *model.ConsultationMessage `json:"reply,omitempty"`
+	Thread  *model.ConsultationThread  `json:"thread"`
+}
+
+// StartConsultation 家长就自己的孩子开始与自己创建的虚拟疗愈导师的咨询
+func (s *ConsultationService) StartConsultation(userID string, req *request.StartConsultationRequest) (*model.ConsultationThread, error) {
+	therapist, err := s.consultationDAO.GetVirtualTherapistByID(req.VirtualTherapistID)
+	if err != nil {
+		return nil, notFound("虚拟疗愈导师不存在")
+	}
+	if therapist.UserID != userID {
+		return nil, ErrPermissionDenied
+	}
+	if _, err := s.getOwnChild(userID, req.ChildArchiveID); err != nil {
+		return nil, err
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = fmt.Sprintf("向%s咨询 %s", therapist.Name, time.Now().Format("2006-01-02"))
+	}
+	thread := &model.ConsultationThread{
+		VirtualTherapistID: therapist.ID,
+		ChildArchiveID:     req.ChildArchiveID,
+		UserID:             userID,
+		Title:              title,
+		Status:             model.ConsultationOpen,
+	}
+	if err := s.consultationDAO.CreateThread(thread); err != nil {
+		return nil, fmt.Errorf("创建咨询失败: %v", err)
+	}
+	return thread, nil
+}
+
+// ListConsultations 家长查看孩子的咨询会话
+func (s *ConsultationService) ListConsultations(userID, childArchiveID string) ([]model.ConsultationThread, error) {
+	if _, err := s.getOwnChild(userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	threads, err := s.consultationDAO.ListThreads(childArchiveID)
+	if err != nil {
+		return nil, fmt.Errorf("获取咨询失败: %v", err)
+	}
+	return threads, nil
+}
+
+// GetConsultation 查看咨询记录，家长、接手的康复师以及可以接手该会话转介申请的康复师可见
+func (s *ConsultationService) GetConsultation(userID string, threadID uint) (*ConsultationDetail, error) {
+	thread, err := s.getReadableThread(userID, threadID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.consultationDAO.GetMessages(thread.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取咨询记录失败: %v", err)
+	}
+	escalations, err := s.consultationDAO.ListThreadEscalations(thread.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取转介申请失败: %v", err)
+	}
+	detail := &ConsultationDetail{Thread: thread, Messages: messages, Escalations: escalations}
+	if therapist, err := s.consultationDAO.GetVirtualTherapistByID(thread.VirtualTherapistID); err == nil {
+		detail.TherapistName = therapist.Name
+	}
+	return detail, nil
+}
+
+// AwaitsAIReply 该用户在会话中发送的消息是否由虚拟疗愈导师回复，用于在调用模型前检查AI用量配额
+func (s *ConsultationService) AwaitsAIReply(userID string, threadID uint) bool {
+	thread, err := s.consultationDAO.GetThreadByID(threadID)
+	return err == nil && thread.UserID == userID && thread.Status == model.ConsultationOpen
+}
+
+// SendMessage 在咨询会话中发送消息。会话由虚拟疗愈导师回复时，检索孩子的档案、近期疗愈记录和最新报告作为依据生成带引用的回复；
+// 会话已由认证康复师接手时只保存消息并通知对方
+func (s *ConsultationService) SendMessage(ctx context.Context, userID string, threadID uint, content string) (*ConsultationExchange, error) {
+	cfg := config.GetConsultationConfig()
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, invalidRequest("消息不能为空")
+	}
+	if cfg.MaxMessageLength > 0 && utf8.RuneCountInString(content) > cfg.MaxMessageLength {
+		return nil, invalidRequest("消息不能超过%d字", cfg.MaxMessageLength)
+	}
+	thread, err := s.consultationDAO.GetThreadByID(threadID)
+	if err != nil {
+		return nil, ErrConsultationNotFound
+	}
+
+	switch {
+	case thread.Status == model.ConsultationEscalated && userID == thread.TherapistUserID:
+		message := &model.ConsultationMessage{Role: model.ConsultationRoleTherapist, SenderID: userID, Content: content}
+		if err := s.consultationDAO.AppendMessages(thread, message); err != nil {
+			return nil, fmt.Errorf("保存消息失败: %v", err)
+		}
+		s.notify(thread.UserID, "康复师回复了咨询", fmt.Sprintf("认证康复师在咨询「%s」中回复了您。", thread.Title), thread)
+		return &ConsultationExchange{Message: message, Thread: thread}, nil
+	case userID != thread.UserID:
+		return nil, ErrPermissionDenied
+	case thread.Status == model.ConsultationEscalated:
+		message := &model.ConsultationMessage{Role: model.ConsultationRoleParent, SenderID: userID, Content: content}
+		if err := s.consultationDAO.AppendMessages(thread, message); err != nil {
+			return nil, fmt.Errorf("保存消息失败: %v", err)
+		}
+		s.notify(thread.TherapistUserID, "家长在咨询中发来新消息", fmt.Sprintf("您接手的咨询「%s」有新的家长消息。", thread.Title), thread)
+		return &ConsultationExchange{Message: message, Thread: thread}, nil
+	}
+
+	therapist, err := s.consultationDAO.GetVirtualTherapistByID(thread.VirtualTherapistID)
+	if err != nil {
+		return nil, notFound("虚拟疗愈导师不存在")
+	}
+	archive, err := s.getOwnChild(userID, thread.ChildArchiveID)
+	if err != nil {
+		return nil, err
+	}
+	age := ageInYears(archive.BirthDate, time.Now())
+
+	sources, documents, err := s.retrieveSources(archive, age, content, cfg)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.consultationDAO.GetRecentMessages(thread.ID, cfg.HistoryMessages)
+	if err != nil {
+		return nil, fmt.Errorf("获取咨询记录失败: %v", err)
+	}
+
+	var redactor *piiRedactor
+	if config.GetAIConfig().RedactPII {
+		var parent *DAO.User
+		if user, err := s.userDAO.GetUserByID(archive.UserID); err == nil {
+			parent = user
+		}
+		redactor = newReportRedactor(archive, parent)
+	}
+	messages := []LLMMessage{{Role: "system", Content: redactor.Redact(consultationSystemPrompt(therapist, archive, age))}}
+	for _, entry := range history {
+		switch entry.Role {
+		case model.ConsultationRoleAssistant:
+			messages = append(messages, LLMMessage{Role: "assistant", Content: redactor.Redact(entry.Content)})
+		case model.ConsultationRoleTherapist:
+			messages = append(messages, LLMMessage{Role: "assistant", Content: redactor.Redact("（认证康复师的回复）" + entry.Content)})
+		default:
+			messages = append(messages, LLMMessage{Role: "user", Content: redactor.Redact(entry.Content)})
+		}
+	}
+	messages = append(messages, LLMMessage{Role: "user", Content: redactor.Redact(consultationQuestionPrompt(documents, content))})
+
+	ctx = WithUsageScope(ctx, userID, model.AIUsageSourceConsultation)
+	resp, err := s.llmProvider.Chat(ctx, &LLMRequest{
+		Model:       modelForReportType(consultationReportType),
+		Messages:    messages,
+		MaxTokens:   cfg.MaxReplyTokens,
+		Temperature: cfg.Temperature,
+		ReportType:  consultationReportType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("虚拟疗愈导师回复失败: %w", err)
+	}
+
+	replyContent := strings.TrimSpace(redactor.Restore(resp.Content))
+	message := &model.ConsultationMessage{Role: model.ConsultationRoleParent, SenderID: userID, Content: content}
+	reply := &model.ConsultationMessage{
+		Role:     model.ConsultationRoleAssistant,
+		Content:  replyContent,
+		Sources:  consultationCitations(replyContent, sources),
+		Provider: resp.Provider,
+		Model:    resp.Model,
+	}
+	if err := s.consultationDAO.AppendMessages(thread, message, reply); err != nil {
+		return nil, fmt.Errorf("保存咨询记录失败: %v", err)
+	}
+	return &ConsultationExchange{Message: message, Reply: reply, Thread: thread}, nil
+}
+
+// retrieveSources 构建检索资料并选出与问题最相关的资料。儿童档案始终作为第1条资料，其余按相关度最多取 topK 条，
+// 返回的资料已按提示词中的编号设置 Index
+func (s *ConsultationService) retrieveSources(archive *DAO.ChildArchive, age int, question string, cfg config.ConsultationConfig) ([]model.ConsultationSource, []consultationDocument, error) {
+	var candidates []consultationDocument
+
+	childID, err := strconv.ParseUint(archive.ID, 10, 64)
+	if err != nil {
+		return nil, nil, invalidRequest("儿童档案ID格式错误: %v", err)
+	}
+	var since *time.Time
+	if cfg.RecentLogDays > 0 {
+		start := time.Now().AddDate(0, 0, -cfg.RecentLogDays)
+		since = &start
+	}
+	logs, err := s.healingLogDAO.GetHealingLogsByChildIDWithDateFilter(uint(childID), since, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取疗愈记录失败: %v", err)
+	}
+	if cfg.MaxLogs > 0 && len(logs) > cfg.MaxLogs {
+		logs = logs[:cfg.MaxLogs]
+	}
+	for i := range logs {
+		candidates = append(candidates, healingLogDocument(&logs[i]))
+	}
+
+	reports, err := s.generatedReportDAO.GetGeneratedReportsByChildIDWithDateFilter(archive.ID, nil, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取报告失败: %v", err)
+	}
+	if len(reports) > cfg.ReportLimit {
+		reports = reports[:cfg.ReportLimit]
+	}
+	for i := range reports {
+		name := reports[i].ReportType
+		if reportType, err := s.reportTypeDAO.GetReportTypeByKey(name); err == nil {
+			name = reportType.Name
+		}
+		candidates = append(candidates, reportDocuments(&reports[i], name)...)
+	}
+
+	documents := append([]consultationDocument{archiveDocument(archive, age)}, rankConsultationDocuments(question, candidates, cfg.TopK)...)
+	sources := make([]model.ConsultationSource, len(documents))
+	for i := range documents {
+		documents[i].source.Index = i + 1
+		sources[i] = documents[i].source
+	}
+	return sources, documents, nil
+}
+
+// consultationSystemPrompt 根据虚拟疗愈导师的专业设定和孩子的基本情况构建系统消息
+func consultationSystemPrompt(therapist *DAO.VirtualTherapist, archive *DAO.ChildArchive, age int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "你是儿童康复平台上的虚拟疗愈导师「%s」", therapist.Name)
+	if therapist.TherapistType != "" {
+		fmt.Fprintf(&b, "，专业领域为%s", therapist.TherapistType)
+	}
+	if therapist.Specialization != "" {
+		fmt.Fprintf(&b, "，擅长%s", therapist.Specialization)
+	}
+	if therapist.Experience > 0 {
+		fmt.Fprintf(&b, "，有%d年从业经验", therapist.Experience)
+	}
+	b.WriteString("。\n你正在为")
+	if age > 0 {
+		fmt.Fprintf(&b, "%d岁的", age)
+	}
+	fmt.Fprintf(&b, "%s的家长提供咨询。\n", archive.ChildName)
+	b.WriteString(`
+回答要求：
+- 只根据家长消息中提供的参考资料和对话内容回答，在依据的句子后用 [编号] 标注资料来源，如 [2] 或 [1, 3]
+- 资料不足以回答时直接说明，不要编造记录中没有的情况
+- 用家长容易理解的语言，给出具体、可在家中执行的建议
+- 不做医学诊断，不给出用药或剂量建议；情况持续、加重或涉及安全问题时，建议家长把咨询转给认证康复师或及时就医
+- 方括号占位符（如 [儿童姓名]）原样保留
+`)
+	return b.String()
+}
+
+// consultationQuestionPrompt 将检索到的资料编号后附在家长的问题之前
+func consultationQuestionPrompt(documents []consultationDocument, question string) string {
+	var b strings.Builder
+	b.WriteString("参考资料：\n")
+	for _, document := range documents {
+		text := strings.Join(strings.Fields(document.text), " ")
+		fmt.Fprintf(&b, "[%d] %s：%s\n", document.source.Index, document.source.Title, truncateRunes(text, consultationPromptRunes))
+	}
+	fmt.Fprintf(&b, "\n家长的问题：%s", question)
+	return b.String()
+}
+
+// Escalate 家长把咨询转给认证康复师，指定康复师时通知该康复师
+func (s *ConsultationService) Escalate(userID string, threadID uint, req *request.EscalateConsultationRequest) (*model.ConsultationEscalation, error) {
+	thread, err := s.consultationDAO.GetThreadByID(threadID)
+	if err != nil {
+		return nil, ErrConsultationNotFound
+	}
+	if thread.UserID != userID {
+		return nil, ErrPermissionDenied
+	}
+	if _, err := s.consultationDAO.GetActiveEscalation(thread.ID); err == nil {
+		return nil, invalidRequest("该咨询已有进行中的转介申请")
+	}
+	if req.TherapistUserID != "" {
+		therapist, err := s.userDAO.GetUserByID(req.TherapistUserID)
+		if err != nil || !isCertifiedTherapist(therapist) {
+			return nil, invalidRequest("指定的用户不是认证康复师")
+		}
+	}
+	escalation := &model.ConsultationEscalation{
+		ThreadID:        thread.ID,
+		ChildArchiveID:  thread.ChildArchiveID,
+		RequesterID:     userID,
+		TherapistUserID: req.TherapistUserID,
+		Reason:          strings.TrimSpace(req.Reason),
+		Status:          model.EscalationPending,
+	}
+	if err := s.consultationDAO.CreateEscalation(escalation); err != nil {
+		return nil, fmt.Errorf("创建转介申请失败: %v", err)
+	}
+	if escalation.TherapistUserID != "" {
+		s.notify(escalation.TherapistUserID, "新的咨询转介",
+			fmt.Sprintf("有家长希望您接手咨询「%s」：%s", thread.Title, truncateRunes(escalation.Reason, 100)), thread)
+	}
+	return escalation, nil
+}
+
+// ListEscalations 认证康复师查看可以接手的、指定给自己的和自己接手的转介申请
+func (s *ConsultationService) ListEscalations(userID, status string) ([]model.ConsultationEscalation, error) {
+	if err := s.checkCertifiedTherapist(userID); err != nil {
+		return nil, err
+	}
+	escalations, err := s.consultationDAO.ListTherapistEscalations(userID, status)
+	if err != nil {
+		return nil, fmt.Errorf("获取转介申请失败: %v", err)
+	}
+	return escalations, nil
+}
+
+// AcceptEscalation 认证康复师接手转介申请，之后家长的消息由康复师回复
+func (s *ConsultationService) AcceptEscalation(userID string, escalationID uint) (*model.ConsultationEscalation, error) {
+	if err := s.checkCertifiedTherapist(userID); err != nil {
+		return nil, err
+	}
+	escalation, err := s.consultationDAO.GetEscalationByID(escalationID)
+	if err != nil {
+		return nil, ErrEscalationNotFound
+	}
+	if escalation.TherapistUserID != "" && escalation.TherapistUserID != userID {
+		return nil, ErrPermissionDenied
+	}
+	accepted, err := s.consultationDAO.AcceptEscalation(escalation, userID)
+	if err != nil {
+		return nil, fmt.Errorf("接手转介申请失败: %v", err)
+	}
+	if !accepted {
+		return nil, ErrEscalationClosed
+	}
+	if thread, err := s.consultationDAO.GetThreadByID(escalation.ThreadID); err == nil {
+		name := "认证康复师"
+		if user, err := s.userDAO.GetUserByID(userID); err == nil && user.Name != "" {
+			name = "康复师" + user.Name
+		}
+		s.notify(escalation.RequesterID, "康复师已接手咨询", fmt.Sprintf("%s已接手咨询「%s」，之后的消息将由康复师回复。", name, thread.Title), thread)
+	}
+	return escalation, nil
+}
+
+// CloseEscalation 结束转介：家长可以撤回等待接手的申请，家长或接手的康复师可以结束已接手的转介。结束后会话恢复由虚拟疗愈导师回复
+func (s *ConsultationService) CloseEscalation(userID string, escalationID uint, note string) (*model.ConsultationEscalation, error) {
+	escalation, err := s.consultationDAO.GetEscalationByID(escalationID)
+	if err != nil {
+		return nil, ErrEscalationNotFound
+	}
+	var toStatus string
+	switch escalation.Status {
+	case model.EscalationPending:
+		if userID != escalation.RequesterID {
+			return nil, ErrPermissionDenied
+		}
+		toStatus = model.EscalationCancelled
+	case model.EscalationAccepted:
+		if userID != escalation.RequesterID && userID != escalation.TherapistUserID {
+			return nil, ErrPermissionDenied
+		}
+		toStatus = model.EscalationResolved
+	default:
+		return nil, ErrEscalationClosed
+	}
+	fromStatus := escalation.Status
+	closed, err := s.consultationDAO.CloseEscalation(escalation, fromStatus, toStatus, strings.TrimSpace(note))
+	if err != nil {
+		return nil, fmt.Errorf("结束转介失败: %v", err)
+	}
+	if !closed {
+		return nil, ErrEscalationClosed
+	}
+	if toStatus == model.EscalationResolved {
+		if thread, err := s.consultationDAO.GetThreadByID(escalation.ThreadID); err == nil {
+			recipient := escalation.TherapistUserID
+			if userID == escalation.TherapistUserID {
+				recipient = escalation.RequesterID
+			}
+			s.notify(recipient, "咨询转介已结束", fmt.Sprintf("咨询「%s」的转介已结束，之后的消息将由虚拟疗愈导师回复。", thread.Title), thread)
+		}
+	}
+	return escalation, nil
+}
+
+// notify 发送与咨询会话相关的通知
+func (s *ConsultationService) notify(userID, title, content string, thread *model.ConsultationThread) {
+	if err := s.notificationService.Notify(userID, model.NotificationConsultation, title, content,
+		"consultation", strconv.FormatUint(uint64(thread.ID), 10)); err != nil {
+		log.Printf("发送咨询通知失败: %v", err)
+	}
+}
+
+// getReadableThread 获取用户可以查看的咨询会话
+func (s *ConsultationService) getReadableThread(userID string, threadID uint) (*model.ConsultationThread, error) {
+	thread, err := s.consultationDAO.GetThreadByID(threadID)
+	if err != nil {
+		return nil, ErrConsultationNotFound
+	}
+	if thread.UserID == userID || (thread.TherapistUserID != "" && thread.TherapistUserID == userID) {
+		return thread, nil
+	}
+	// 等待接手的转介申请对可以接手的认证康复师开放，便于接手前了解情况
+	escalation, err := s.consultationDAO.GetActiveEscalation(thread.ID)
+	if err == nil && escalation.Status == model.EscalationPending &&
+		(escalation.TherapistUserID == "" || escalation.TherapistUserID == userID) &&
+		s.checkCertifiedTherapist(userID) == nil {
+		return thread, nil
+	}
+	return nil, ErrPermissionDenied
+}
+
+func (s *ConsultationService) checkCertifiedTherapist(userID string) error {
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil || !isCertifiedTherapist(user) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// getOwnChild 咨询会话只能由孩子的家长发起
+func (s *ConsultationService) getOwnChild(userID, childArchiveID string) (*DAO.ChildArchive, error) {
+	archive, err := s.userDAO.GetChildArchiveByID(childArchiveID)
+	if err != nil {
+		return nil, ErrChildNotFound
+	}
+	if archive.UserID != userID {
+		return nil, ErrPermissionDenied
+	}
+	return archive, nil
+}
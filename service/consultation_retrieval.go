@@ -0,0 +1,239 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"melody_cure/DAO"
+	"melody_cure/model"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 检索资料的长度限制（字）
+const (
+	consultationChunkRunes   = 400 // 报告按段落切分后每段的最大长度
+	consultationExcerptRunes = 160 // 引用来源中展示的摘录长度
+	consultationPromptRunes  = 600 // 提示词中每条资料的最大长度
+)
+
+// consultationDocument 参与检索的一条资料：儿童档案、一条疗愈记录或报告的一段
+type consultationDocument struct {
+	source model.ConsultationSource
+	text   string
+	terms  map[string]int
+}
+
+func newConsultationDocument(sourceType, id, title string, at *time.Time, text string) consultationDocument {
+	text = strings.TrimSpace(text)
+	return consultationDocument{
+		source: model.ConsultationSource{
+			Type:    sourceType,
+			ID:      id,
+			Title:   title,
+			Time:    at,
+			Excerpt: truncateRunes(strings.Join(strings.Fields(text), " "), consultationExcerptRunes),
+		},
+		text:  text,
+		terms: retrievalTerms(text),
+	}
+}
+
+// archiveDocument 儿童档案中与咨询相关的字段
+func archiveDocument(archive *DAO.ChildArchive, age int) consultationDocument {
+	var b strings.Builder
+	fmt.Fprintf(&b, "姓名：%s", archive.ChildName)
+	if archive.Gender != "" {
+		fmt.Fprintf(&b, "\n性别：%s", archive.Gender)
+	}
+	if age > 0 {
+		fmt.Fprintf(&b, "\n年龄：%d岁", age)
+	}
+	for _, field := range []struct{ label, value string }{
+		{"诊断", archive.Diagnosis},
+		{"病情描述", archive.Condition},
+		{"治疗方案", archive.Treatment},
+		{"康复进展", archive.Progress},
+		{"备注", archive.Notes},
+	} {
+		if value := strings.TrimSpace(field.value); value != "" {
+			fmt.Fprintf(&b, "\n%s：%s", field.label, value)
+		}
+	}
+	if archive.TreatmentStartDate != nil {
+		fmt.Fprintf(&b, "\n治疗开始日期：%s", archive.TreatmentStartDate.Format("2006-01-02"))
+	}
+	updated := archive.UpdatedAt
+	return newConsultationDocument(model.ConsultationSourceArchive, archive.ID, "儿童档案", &updated, b.String())
+}
+
+// healingLogDocument 一条疗愈记录的正文、模板答案和指标
+func healingLogDocument(log *model.HealingLog) consultationDocument {
+	parts := []string{log.Content}
+	for _, answer := range log.TemplateAnswers {
+		parts = append(parts, answer.Label+"："+formatTemplateAnswerValue(answer))
+	}
+	for _, metric := range log.Metrics {
+		parts = append(parts, fmt.Sprintf("%s：%s%s", metric.Name, strconv.FormatFloat(metric.Value, 'f', -1, 64), metric.Unit))
+	}
+	at := log.CreatedAt
+	return newConsultationDocument(model.ConsultationSourceLog, strconv.FormatUint(uint64(log.ID), 10),
+		"疗愈记录 "+at.Format("2006-01-02 15:04"), &at, strings.Join(parts, "\n"))
+}
+
+// reportDocuments 将报告按段落切分为多段资料，每段不超过 consultationChunkRunes 字
+func reportDocuments(report *model.GeneratedReport, reportName string) []consultationDocument {
+	at := report.GeneratedAt
+	title := fmt.Sprintf("%s %s", reportName, at.Format("2006-01-02"))
+	id := strconv.FormatUint(uint64(report.ID), 10)
+	var documents []consultationDocument
+	for _, chunk := range splitTextChunks(report.Content, consultationChunkRunes) {
+		documents = append(documents, newConsultationDocument(model.ConsultationSourceReport, id, title, &at, chunk))
+	}
+	return documents
+}
+
+// splitTextChunks 按段落将文本切分为不超过 limit 字的多段，相邻的短段落合并，超长的段落截断
+func splitTextChunks(text string, limit int) []string {
+	var chunks []string
+	var chunk strings.Builder
+	flush := func() {
+		if strings.TrimSpace(chunk.String()) != "" {
+			chunks = append(chunks, chunk.String())
+		}
+		chunk.Reset()
+	}
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		if chunk.Len() > 0 && len([]rune(chunk.String()))+len([]rune(paragraph)) > limit {
+			flush()
+		}
+		if chunk.Len() > 0 {
+			chunk.WriteString("\n\n")
+		}
+		chunk.WriteString(truncateRunes(paragraph, limit))
+	}
+	flush()
+	return chunks
+}
+
+// retrievalTerms 将文本切分为检索词：连续汉字取相邻两字，英文和数字按整词，统计出现次数
+func retrievalTerms(text string) map[string]int {
+	terms := make(map[string]int)
+	var han, word []rune
+	flushHan := func() {
+		if len(han) == 1 {
+			terms[string(han)]++
+		}
+		for i := 0; i+1 < len(han); i++ {
+			terms[string(han[i:i+2])]++
+		}
+		han = han[:0]
+	}
+	flushWord := func() {
+		if len(word) > 1 {
+			terms[string(word)]++
+		}
+		word = word[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushHan()
+			flushWord()
+		}
+	}
+	flushHan()
+	flushWord()
+	return terms
+}
+
+// rankConsultationDocuments 按与问题的相关度选出最多 k 条资料。相关度为问题中各检索词在资料中出现时的逆文档频率之和，
+// 同分时较新的资料优先；没有任何资料与问题相关时按时间选取最新的资料
+func rankConsultationDocuments(question string, documents []consultationDocument, k int) []consultationDocument {
+	if k <= 0 || len(documents) == 0 {
+		return nil
+	}
+	queryTerms := retrievalTerms(question)
+	df := make(map[string]int, len(queryTerms))
+	for _, document := range documents {
+		for term := range queryTerms {
+			if document.terms[term] > 0 {
+				df[term]++
+			}
+		}
+	}
+	type scored struct {
+		document consultationDocument
+		score    float64
+	}
+	ranked := make([]scored, len(documents))
+	relevant := false
+	for i, document := range documents {
+		score := 0.0
+		for term := range queryTerms {
+			if tf := document.terms[term]; tf > 0 {
+				idf := math.Log(1 + float64(len(documents))/float64(df[term]))
+				score += idf * (1 + math.Log(float64(tf)))
+			}
+		}
+		ranked[i] = scored{document: document, score: score}
+		relevant = relevant || score > 0
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if relevant && ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return documentTime(ranked[i].document).After(documentTime(ranked[j].document))
+	})
+	var selected []consultationDocument
+	for _, item := range ranked {
+		if len(selected) == k || (relevant && item.score == 0) {
+			break
+		}
+		selected = append(selected, item.document)
+	}
+	return selected
+}
+
+func documentTime(document consultationDocument) time.Time {
+	if document.source.Time == nil {
+		return time.Time{}
+	}
+	return *document.source.Time
+}
+
+// 回复中的引用标注，如 [2] 或 [1, 3]
+var (
+	consultationCitationPattern   = regexp.MustCompile(`\[(\d+(?:\s*[,，、]\s*\d+)*)\]`)
+	consultationCitationSeparator = regexp.MustCompile(`\s*[,，、]\s*`)
+)
+
+// consultationCitations 按回复中首次出现的顺序返回被引用的资料，忽略超出范围的编号
+func consultationCitations(reply string, sources []model.ConsultationSource) []model.ConsultationSource {
+	var cited []model.ConsultationSource
+	seen := make(map[int]bool)
+	for _, match := range consultationCitationPattern.FindAllStringSubmatch(reply, -1) {
+		for _, number := range consultationCitationSeparator.Split(match[1], -1) {
+			index, err := strconv.Atoi(number)
+			if err != nil || index < 1 || index > len(sources) || seen[index] {
+				continue
+			}
+			seen[index] = true
+			cited = append(cited, sources[index-1])
+		}
+	}
+	return cited
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"math"
+	"melody_cure/config"
+	"strings"
+)
+
+// EmbeddingProvider 文本向量化提供商，返回的向量需已归一化，相似度直接取点积
+type EmbeddingProvider interface {
+	Name() string
+	Dimensions() int
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// 支持的向量化提供商
+const (
+	EmbeddingProviderLocal = "local" // 离线的本地实现
+)
+
+// NewEmbeddingProvider 根据语义索引配置创建向量化提供商
+func NewEmbeddingProvider() EmbeddingProvider {
+	cfg := config.GetSemanticConfig()
+	switch strings.ToLower(cfg.Provider) {
+	case EmbeddingProviderLocal, "fake", "":
+	default:
+		log.Printf("不支持的向量化提供商 %s，使用本地实现", cfg.Provider)
+	}
+	return NewLocalEmbeddingProvider(cfg.Dimensions)
+}
+
+// LocalEmbeddingProvider 离线的向量化实现：按检索词（相邻两个汉字、英文单词）做特征哈希，
+// 词频取对数后归一化。只反映字面上的相近程度，用于开发测试和没有向量化服务的部署
+type LocalEmbeddingProvider struct {
+	dimensions int
+}
+
+func NewLocalEmbeddingProvider(dimensions int) *LocalEmbeddingProvider {
+	if dimensions <= 0 {
+		dimensions = 256
+	}
+	return &LocalEmbeddingProvider{dimensions: dimensions}
+}
+
+func (p *LocalEmbeddingProvider) Name() string {
+	return EmbeddingProviderLocal
+}
+
+func (p *LocalEmbeddingProvider) Dimensions() int {
+	return p.dimensions
+}
+
+func (p *LocalEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float64, p.dimensions)
+		for term, count := range retrievalTerms(text) {
+			h := fnv.New64a()
+			h.Write([]byte(term))
+			sum := h.Sum64()
+			// 用哈希的最高位决定符号，减少不同检索词落在同一维度时的相互抵消偏差
+			sign := 1.0
+			if sum>>63 == 1 {
+				sign = -1
+			}
+			vector[sum%uint64(p.dimensions)] += sign * (1 + math.Log(float64(count)))
+		}
+		vectors[i] = normalizeVector(vector)
+	}
+	return vectors, nil
+}
+
+// normalizeVector 归一化为单位向量，零向量原样返回
+func normalizeVector(vector []float64) []float32 {
+	norm := 0.0
+	for _, v := range vector {
+		norm += v * v
+	}
+	norm = math.Sqrt(norm)
+	result := make([]float32, len(vector))
+	if norm == 0 {
+		return result
+	}
+	for i, v := range vector {
+		result[i] = float32(v / norm)
+	}
+	return result
+}
+
+// dotProduct 两个归一化向量的余弦相似度，维度不同时返回0
+func dotProduct(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	sum := 0.0
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 业务层错误的类别，控制器据此返回 400 或 404，其余错误视为服务端错误
+var (
+	ErrInvalidRequest = errors.New("请求无效")
+	ErrNotFound       = errors.New("资源不存在")
+)
+
+// requestError 带具体说明的请求错误，errors.Is 与所属类别匹配
+type requestError struct {
+	kind    error
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func (e *requestError) Is(target error) bool {
+	return target == e.kind
+}
+
+// invalidRequest 参数或业务状态不满足要求，调用方修正后可以重试
+func invalidRequest(format string, args ...any) error {
+	return &requestError{kind: ErrInvalidRequest, message: fmt.Sprintf(format, args...)}
+}
+
+// notFound 请求的资源不存在
+func notFound(format string, args ...any) error {
+	return &requestError{kind: ErrNotFound, message: fmt.Sprintf(format, args...)}
+}
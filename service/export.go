@@ -0,0 +1,676 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"math"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/model"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 导出格式
+const (
+	ExportFormatPDF  = "pdf"
+	ExportFormatDOCX = "docx"
+)
+
+// 档案导出的默认和最大条数
+const (
+	defaultDossierLogLimit    = 10
+	maxDossierLogLimit        = 50
+	defaultDossierReportLimit = 3
+	maxDossierReportLimit     = 10
+	maxDossierCharts          = 6
+	maxBrandingLogoSize       = 512 * 1024
+)
+
+var (
+	brandingColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	fileNameUnsafe       = regexp.MustCompile(`[\\/:*?"<>|\s]+`)
+)
+
+// defaultBrandingColor 未设置机构主题色时使用平台主题色
+var defaultBrandingColor = color.RGBA{R: 74, G: 123, B: 208, A: 255}
+
+type ExportService struct {
+	userDAO              *DAO.UserDAO
+	healingLogDAO        *DAO.HealingLogDAO
+	generatedReportDAO   *DAO.GeneratedReportDAO
+	brandingDAO          *DAO.InstitutionBrandingDAO
+	aiReportService      *AIReportService
+	treatmentPlanService *TreatmentPlanService
+}
+
+func NewExportService(userDAO *DAO.UserDAO, healingLogDAO *DAO.HealingLogDAO, generatedReportDAO *DAO.GeneratedReportDAO, brandingDAO *DAO.InstitutionBrandingDAO, aiReportService *AIReportService, treatmentPlanService *TreatmentPlanService) *ExportService {
+	return &ExportService{
+		userDAO:              userDAO,
+		healingLogDAO:        healingLogDAO,
+		generatedReportDAO:   generatedReportDAO,
+		brandingDAO:          brandingDAO,
+		aiReportService:      aiReportService,
+		treatmentPlanService: treatmentPlanService,
+	}
+}
+
+// ExportFile 导出的文件
+type ExportFile struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// DossierOptions 儿童康复档案的导出范围
+type DossierOptions struct {
+	Start       *time.Time // 日志、指标和报告的开始时间
+	End         *time.Time // 结束时间（不含）
+	LogIDs      []uint     // 指定导出的日志，为空时导出最近的日志
+	LogLimit    int
+	ReportLimit int
+}
+
+// exportBranding 导出文档使用的信头
+type exportBranding struct {
+	Name       string
+	Tagline    string
+	Contact    string // 地址、电话和网址
+	Footer     string
+	Color      color.RGBA
+	Logo       []byte // PNG 或 JPEG
+	LogoType   string // png, jpeg
+	LogoWidth  int
+	LogoHeight int
+}
+
+func defaultExportBranding() *exportBranding {
+	return &exportBranding{
+		Name:    "音愈 Melody Cure",
+		Tagline: "儿童康复疗愈服务平台",
+		Footer:  "本文件包含儿童健康信息，请妥善保管，勿随意转发",
+		Color:   defaultBrandingColor,
+	}
+}
+
+// ExportReport 导出 AI 报告，信头使用导出人所属机构的设置
+func (s *ExportService) ExportReport(userID string, reportID uint, format string) (*ExportFile, error) {
+	if err := checkExportFormat(format); err != nil {
+		return nil, err
+	}
+	report, err := s.aiReportService.getAccessibleReport(userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	doc, name, err := s.reportDocument(report, report.Content, report.CurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	return s.render(userID, doc, format, name)
+}
+
+// reportDocument 生成报告指定版本内容的导出文档，返回文档和文件名
+func (s *ExportService) reportDocument(report *model.GeneratedReport, content string, version int) (*exportDocument, string, error) {
+	archive, err := s.userDAO.GetChildArchiveByID(report.ChildArchiveID)
+	if err != nil {
+		return nil, "", ErrChildNotFound
+	}
+
+	typeName := s.aiReportService.ReportTypeName(report.ReportType)
+	doc := &exportDocument{
+		Title:    typeName + "报告",
+		Subtitle: archive.ChildName + " 的康复报告",
+		Fields: []exportField{
+			{Label: "儿童", Value: archive.ChildName},
+			{Label: "报告类型", Value: typeName},
+			{Label: "生成时间", Value: report.GeneratedAt.Format("2006-01-02 15:04")},
+			{Label: "导出时间", Value: time.Now().Format("2006-01-02 15:04")},
+		},
+	}
+	if version > 0 {
+		doc.Fields = append(doc.Fields, exportField{Label: "版本", Value: fmt.Sprintf("第 %d 版", version)})
+	}
+	if report.IsEdited {
+		doc.Fields = append(doc.Fields, exportField{Label: "人工审阅", Value: "已编辑"})
+	}
+	if language := reportLanguageOrDefault(report.Language); language != DefaultReportLanguage {
+		doc.Fields = append(doc.Fields, exportField{Label: "语言", Value: languageDisplayName(language, DefaultReportLanguage)})
+	}
+	doc.Blocks = append(doc.Blocks, markdownBlocks(content, 0)...)
+	if disclaimer := safetyDisclaimer(report.Language); !strings.Contains(content, disclaimer) {
+		// 安全审查启用前生成的报告没有免责声明
+		doc.note(disclaimer)
+	}
+	return doc, archive.ChildName + "_" + typeName + "报告", nil
+}
+
+// ExportChildDossier 导出儿童康复档案：基本信息、治疗目标进度、指标趋势图、日志摘录和最近的 AI 报告
+func (s *ExportService) ExportChildDossier(userID, childArchiveID, format string, options DossierOptions) (*ExportFile, error) {
+	if err := checkExportFormat(format); err != nil {
+		return nil, err
+	}
+	branding := s.branding(userID)
+	doc, name, err := s.dossierDocument(userID, childArchiveID, options, branding.Color)
+	if err != nil {
+		return nil, err
+	}
+	return s.renderWithBranding(doc, format, name, branding)
+}
+
+// dossierDocument 生成儿童康复档案的导出文档，返回文档和文件名，趋势图使用信头的主题色
+func (s *ExportService) dossierDocument(userID, childArchiveID string, options DossierOptions, chartColor color.RGBA) (*exportDocument, string, error) {
+	archive, err := checkChildAccess(s.userDAO, userID, childArchiveID)
+	if err != nil {
+		return nil, "", err
+	}
+	childID, err := strconv.ParseUint(archive.ID, 10, 64)
+	if err != nil {
+		return nil, "", invalidRequest("儿童档案ID格式错误")
+	}
+	options.LogLimit = clampLimit(options.LogLimit, defaultDossierLogLimit, maxDossierLogLimit)
+	options.ReportLimit = clampLimit(options.ReportLimit, defaultDossierReportLimit, maxDossierReportLimit)
+
+	logs, err := s.healingLogDAO.GetHealingLogsByChildIDWithDateFilter(uint(childID), options.Start, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("获取疗愈日志失败: %v", err)
+	}
+	if options.End != nil {
+		inRange := logs[:0]
+		for _, healingLog := range logs {
+			if healingLog.CreatedAt.Before(*options.End) {
+				inRange = append(inRange, healingLog)
+			}
+		}
+		logs = inRange
+	}
+	goals, err := s.treatmentPlanService.GetGoalsWithProgress(archive.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	reports, err := s.generatedReportDAO.GetGeneratedReportsByChildIDWithDateFilter(archive.ID, options.Start, options.End)
+	if err != nil {
+		return nil, "", fmt.Errorf("获取AI报告失败: %v", err)
+	}
+
+	doc := &exportDocument{
+		Title:    "儿童康复档案",
+		Subtitle: archive.ChildName + "　" + dossierPeriod(options.Start, options.End),
+		Fields: []exportField{
+			{Label: "姓名", Value: archive.ChildName},
+			{Label: "性别", Value: archive.Gender},
+			{Label: "出生日期", Value: formatDate(&archive.BirthDate)},
+			{Label: "年龄", Value: childAge(archive.BirthDate)},
+			{Label: "诊断", Value: archive.Diagnosis},
+			{Label: "开始治疗", Value: formatDate(archive.TreatmentStartDate)},
+			{Label: "已疗愈天数", Value: strconv.Itoa(archive.HealedDays)},
+			{Label: "导出时间", Value: time.Now().Format("2006-01-02 15:04")},
+		},
+	}
+
+	doc.heading(1, "基本情况")
+	for _, item := range []exportField{
+		{Label: "病情描述", Value: archive.Condition},
+		{Label: "治疗方案", Value: archive.Treatment},
+		{Label: "康复进展", Value: archive.Progress},
+		{Label: "备注", Value: archive.Notes},
+	} {
+		if strings.TrimSpace(item.Value) != "" {
+			doc.heading(3, item.Label)
+			doc.paragraph(item.Value)
+		}
+	}
+
+	doc.heading(1, "治疗目标")
+	if len(goals) == 0 {
+		doc.paragraph("暂未制定治疗目标。")
+	} else {
+		rows := [][]string{{"目标", "领域", "状态", "进度", "目标日期"}}
+		for _, goal := range goals {
+			rows = append(rows, []string{
+				goal.Title,
+				goalDomainNames[goal.Domain],
+				goalStatusNames[goal.Status],
+				fmt.Sprintf("%.0f%%", goal.Progress.Percent),
+				formatDate(goal.TargetDate),
+			})
+		}
+		doc.table(rows, 3, 1, 1, 1, 1.4)
+	}
+
+	if err := s.addMetricCharts(doc, logs, chartColor); err != nil {
+		return nil, "", err
+	}
+
+	selected, err := selectDossierLogs(logs, options)
+	if err != nil {
+		return nil, "", err
+	}
+	doc.heading(1, "疗愈日志")
+	if len(selected) == 0 {
+		doc.paragraph("所选时间段内没有疗愈日志。")
+	} else if len(options.LogIDs) == 0 && len(logs) > len(selected) {
+		doc.note(fmt.Sprintf("共 %d 条日志，以下为最近的 %d 条。", len(logs), len(selected)))
+	}
+	for _, healingLog := range selected {
+		addLogBlocks(doc, healingLog)
+	}
+
+	doc.heading(1, "AI报告")
+	if len(reports) == 0 {
+		doc.paragraph("所选时间段内没有AI报告。")
+	}
+	if len(reports) > options.ReportLimit {
+		reports = reports[:options.ReportLimit]
+	}
+	for _, report := range reports {
+		doc.heading(2, fmt.Sprintf("%s报告（%s）", s.aiReportService.ReportTypeName(report.ReportType), report.GeneratedAt.Format("2006-01-02")))
+		doc.Blocks = append(doc.Blocks, markdownBlocks(report.Content, 2)...)
+	}
+	if len(reports) > 0 {
+		doc.note(safetyDisclaimer(DefaultReportLanguage))
+	}
+
+	return doc, archive.ChildName + "_康复档案", nil
+}
+
+// addMetricCharts 为每项指标绘制趋势图，指标较多时只取记录最多的几项
+func (s *ExportService) addMetricCharts(doc *exportDocument, logs []model.HealingLog, line color.RGBA) error {
+	series := map[string][]model.LogMetric{}
+	for _, healingLog := range logs {
+		for _, metric := range healingLog.Metrics {
+			series[metric.Name] = append(series[metric.Name], metric)
+		}
+	}
+	if len(series) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(series[names[i]]) != len(series[names[j]]) {
+			return len(series[names[i]]) > len(series[names[j]])
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > maxDossierCharts {
+		names = names[:maxDossierCharts]
+	}
+
+	doc.heading(1, "指标趋势")
+	for _, name := range names {
+		metrics := series[name]
+		sort.Slice(metrics, func(i, j int) bool { return metrics[i].RecordedAt.Before(metrics[j].RecordedAt) })
+		values := make([]float64, len(metrics))
+		low, high := math.Inf(1), math.Inf(-1)
+		for i, metric := range metrics {
+			values[i] = metric.Value
+			low, high = math.Min(low, metric.Value), math.Max(high, metric.Value)
+		}
+		chart, err := renderLineChart(values, line)
+		if err != nil {
+			return fmt.Errorf("绘制指标趋势图失败: %v", err)
+		}
+		unit := metrics[0].Unit
+		title := name
+		if unit != "" {
+			title += "（" + unit + "）"
+		}
+		doc.heading(3, title)
+		doc.Blocks = append(doc.Blocks, exportBlock{Kind: exportBlockImage, Image: chart, Width: chartWidth, Height: chartHeight})
+		first, last := metrics[0], metrics[len(metrics)-1]
+		doc.note(fmt.Sprintf("%s 至 %s 共 %d 次记录；首次 %s，最近 %s，最低 %s，最高 %s",
+			first.RecordedAt.Format("2006-01-02"), last.RecordedAt.Format("2006-01-02"), len(metrics),
+			formatMetricValue(first.Value), formatMetricValue(last.Value), formatMetricValue(low), formatMetricValue(high)))
+	}
+	return nil
+}
+
+// selectDossierLogs 选出要导出的日志，指定日志ID时必须都属于该儿童且在时间范围内
+func selectDossierLogs(logs []model.HealingLog, options DossierOptions) ([]model.HealingLog, error) {
+	if len(options.LogIDs) == 0 {
+		if len(logs) > options.LogLimit {
+			return logs[:options.LogLimit], nil
+		}
+		return logs, nil
+	}
+	if len(uniqueUints(options.LogIDs)) > maxDossierLogLimit {
+		return nil, invalidRequest("最多导出 %d 条日志", maxDossierLogLimit)
+	}
+	byID := make(map[uint]model.HealingLog, len(logs))
+	for _, healingLog := range logs {
+		byID[healingLog.ID] = healingLog
+	}
+	var selected []model.HealingLog
+	for _, id := range uniqueUints(options.LogIDs) {
+		healingLog, ok := byID[id]
+		if !ok {
+			return nil, invalidRequest("日志 %d 不存在或不在导出时间范围内", id)
+		}
+		selected = append(selected, healingLog)
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].CreatedAt.After(selected[j].CreatedAt) })
+	return selected, nil
+}
+
+func addLogBlocks(doc *exportDocument, healingLog model.HealingLog) {
+	title := healingLog.CreatedAt.Format("2006-01-02 15:04")
+	var tags []string
+	switch healingLog.Phase {
+	case model.PhaseBaseline:
+		tags = append(tags, "基线")
+	case model.PhaseFollowUp:
+		tags = append(tags, "跟进")
+	}
+	if healingLog.Skill != "" {
+		tags = append(tags, healingLog.Skill)
+	}
+	if len(tags) > 0 {
+		title += "（" + strings.Join(tags, "·") + "）"
+	}
+	doc.heading(3, title)
+	doc.paragraph(healingLog.Content)
+
+	var items []string
+	for _, answer := range healingLog.TemplateAnswers {
+		if value := templateAnswerText(answer.Value); value != "" {
+			items = append(items, answer.Label+"："+value)
+		}
+	}
+	for _, metric := range healingLog.Metrics {
+		items = append(items, metric.Name+"："+formatMetricValue(metric.Value)+metric.Unit)
+	}
+	if len(items) > 0 {
+		doc.Blocks = append(doc.Blocks, exportBlock{Kind: exportBlockBullets, Items: items})
+	}
+	if len(healingLog.Media) > 0 {
+		doc.note(fmt.Sprintf("附 %d 个图片/视频，请在应用中查看。", len(healingLog.Media)))
+	}
+}
+
+func templateAnswerText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, "、")
+	case bool:
+		if v {
+			return "是"
+		}
+		return "否"
+	case float64:
+		return formatMetricValue(v)
+	}
+	return fmt.Sprint(value)
+}
+
+func (s *ExportService) render(userID string, doc *exportDocument, format, baseName string) (*ExportFile, error) {
+	return s.renderWithBranding(doc, format, baseName, s.branding(userID))
+}
+
+func (s *ExportService) renderWithBranding(doc *exportDocument, format, baseName string, branding *exportBranding) (*ExportFile, error) {
+	file := &ExportFile{FileName: exportFileName(baseName, format)}
+	var err error
+	switch format {
+	case ExportFormatDOCX:
+		file.ContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+		file.Data, err = renderDOCX(doc, branding)
+	default:
+		file.ContentType = "application/pdf"
+		file.Data, err = renderPDF(doc, branding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// branding 导出人所属机构的信头，未加入机构或机构未设置时使用平台信头
+func (s *ExportService) branding(userID string) *exportBranding {
+	branding := defaultExportBranding()
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil || institutionOf(user) == "" {
+		return branding
+	}
+	settings, err := s.brandingDAO.GetBrandingByInstitutionID(institutionOf(user))
+	if err != nil {
+		return branding
+	}
+
+	if settings.DisplayName != "" {
+		branding.Name = settings.DisplayName
+	}
+	branding.Tagline = settings.Tagline
+	var contact []string
+	for _, item := range []string{settings.Address, settings.Phone, settings.Website} {
+		if item != "" {
+			contact = append(contact, item)
+		}
+	}
+	branding.Contact = strings.Join(contact, "　")
+	if settings.FooterText != "" {
+		branding.Footer = settings.FooterText
+	}
+	if c, ok := parseBrandingColor(settings.PrimaryColor); ok {
+		branding.Color = c
+	}
+	if len(settings.Logo) > 0 {
+		if config, _, err := image.DecodeConfig(bytes.NewReader(settings.Logo)); err == nil {
+			branding.Logo, branding.LogoType = settings.Logo, settings.LogoType
+			branding.LogoWidth, branding.LogoHeight = config.Width, config.Height
+		}
+	}
+	return branding
+}
+
+// GetBranding 获取机构的信头设置，机构成员获取所属机构的设置，管理员可指定机构
+func (s *ExportService) GetBranding(userID, institutionID string) (*model.InstitutionBranding, error) {
+	institutionID, err := s.brandingInstitution(userID, institutionID, false)
+	if err != nil {
+		return nil, err
+	}
+	branding, err := s.brandingDAO.GetBrandingByInstitutionID(institutionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.InstitutionBranding{InstitutionID: institutionID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取信头设置失败: %v", err)
+	}
+	branding.HasLogo = len(branding.Logo) > 0
+	return branding, nil
+}
+
+// UpdateBranding 更新机构的信头设置，仅机构账号本身和管理员可以修改
+func (s *ExportService) UpdateBranding(userID string, req *request.UpdateBrandingRequest) (*model.InstitutionBranding, error) {
+	institutionID, err := s.brandingInstitution(userID, req.InstitutionID, true)
+	if err != nil {
+		return nil, err
+	}
+	if req.PrimaryColor != "" && !brandingColorPattern.MatchString(req.PrimaryColor) {
+		return nil, invalidRequest("主题色格式应为 #RRGGBB")
+	}
+
+	branding, err := s.brandingDAO.GetBrandingByInstitutionID(institutionID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("获取信头设置失败: %v", err)
+	}
+	branding.InstitutionID = institutionID
+	branding.DisplayName = strings.TrimSpace(req.DisplayName)
+	branding.Tagline = strings.TrimSpace(req.Tagline)
+	branding.Address = strings.TrimSpace(req.Address)
+	branding.Phone = strings.TrimSpace(req.Phone)
+	branding.Website = strings.TrimSpace(req.Website)
+	branding.FooterText = strings.TrimSpace(req.FooterText)
+	branding.PrimaryColor = strings.ToUpper(req.PrimaryColor)
+	branding.UpdatedBy = userID
+	switch {
+	case req.RemoveLogo:
+		branding.Logo, branding.LogoType = nil, ""
+	case req.Logo != "":
+		logo, err := normalizeLogo(req.Logo)
+		if err != nil {
+			return nil, err
+		}
+		branding.Logo, branding.LogoType = logo, "png"
+	}
+
+	if err := s.brandingDAO.SaveBranding(branding); err != nil {
+		return nil, fmt.Errorf("保存信头设置失败: %v", err)
+	}
+	branding.HasLogo = len(branding.Logo) > 0
+	return branding, nil
+}
+
+// brandingInstitution 确定要读取或修改信头的机构
+func (s *ExportService) brandingInstitution(userID, institutionID string, write bool) (string, error) {
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil {
+		return "", notFound("用户不存在")
+	}
+	if isAdmin(user) {
+		if institutionID == "" {
+			return "", invalidRequest("请指定机构ID")
+		}
+		institution, err := s.userDAO.GetUserByID(institutionID)
+		if err != nil || institution.Identity != DAO.IdentityInstitution {
+			return "", notFound("机构不存在")
+		}
+		return institutionID, nil
+	}
+	own := institutionOf(user)
+	if own == "" || (institutionID != "" && institutionID != own) {
+		return "", ErrPermissionDenied
+	}
+	if write && user.Identity != DAO.IdentityInstitution {
+		return "", ErrPermissionDenied
+	}
+	return own, nil
+}
+
+// normalizeLogo 校验 base64 编码的 PNG 或 JPEG 徽标，统一转换为 8 位 PNG 以便两种导出格式都能嵌入
+func normalizeLogo(encoded string) ([]byte, error) {
+	if i := strings.Index(encoded, ","); strings.HasPrefix(encoded, "data:") && i > 0 {
+		encoded = encoded[i+1:]
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalidRequest("徽标不是有效的base64编码")
+	}
+	if len(data) > maxBrandingLogoSize {
+		return nil, invalidRequest("徽标不能超过 %dKB", maxBrandingLogoSize/1024)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil || (format != "png" && format != "jpeg") {
+		return nil, invalidRequest("徽标仅支持PNG或JPEG图片")
+	}
+	normalized := image.NewNRGBA(img.Bounds())
+	draw.Draw(normalized, normalized.Bounds(), img, img.Bounds().Min, draw.Src)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, normalized); err != nil {
+		return nil, fmt.Errorf("处理徽标失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func parseBrandingColor(value string) (color.RGBA, bool) {
+	if !brandingColorPattern.MatchString(value) {
+		return color.RGBA{}, false
+	}
+	rgb, _ := strconv.ParseUint(value[1:], 16, 32)
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 255}, true
+}
+
+func checkExportFormat(format string) error {
+	if format != ExportFormatPDF && format != ExportFormatDOCX {
+		return invalidRequest("导出格式仅支持 pdf 或 docx")
+	}
+	return nil
+}
+
+func exportFileName(baseName, format string) string {
+	return fileNameUnsafe.ReplaceAllString(baseName, "_") + "_" + time.Now().Format("20060102") + "." + format
+}
+
+func clampLimit(value, defaultValue, maxValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+	if value > maxValue {
+		return maxValue
+	}
+	return value
+}
+
+func dossierPeriod(start, end *time.Time) string {
+	switch {
+	case start != nil && end != nil:
+		return start.Format("2006-01-02") + " 至 " + end.AddDate(0, 0, -1).Format("2006-01-02")
+	case start != nil:
+		return start.Format("2006-01-02") + " 至今"
+	case end != nil:
+		return "截至 " + end.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	return "全部记录"
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+func childAge(birthDate time.Time) string {
+	if birthDate.IsZero() {
+		return "-"
+	}
+	now := time.Now()
+	months := (now.Year()-birthDate.Year())*12 + int(now.Month()-birthDate.Month())
+	if now.Day() < birthDate.Day() {
+		months--
+	}
+	if months < 0 {
+		return "-"
+	}
+	if months < 12 {
+		return fmt.Sprintf("%d个月", months)
+	}
+	return fmt.Sprintf("%d岁%d个月", months/12, months%12)
+}
+
+func formatMetricValue(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+var goalDomainNames = map[string]string{
+	model.GoalDomainLanguage: "语言",
+	model.GoalDomainSocial:   "社交",
+	model.GoalDomainMotor:    "运动",
+	model.GoalDomainEmotion:  "情绪",
+}
+
+var goalStatusNames = map[string]string{
+	model.GoalStatusNotStarted: "未开始",
+	model.GoalStatusInProgress: "进行中",
+	model.GoalStatusAchieved:   "已达成",
+	model.GoalStatusPaused:     "暂停",
+	model.GoalStatusDropped:    "已放弃",
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+)
+
+// 指标趋势图的尺寸（像素）
+const (
+	chartWidth   = 1200
+	chartHeight  = 420
+	chartPadding = 40
+)
+
+// renderLineChart 将指标数值绘制为折线图 PNG，图中不含文字，刻度和说明由文档在图下方给出
+func renderLineChart(values []float64, line color.RGBA) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	left, right := chartPadding, chartWidth-chartPadding
+	top, bottom := chartPadding, chartHeight-chartPadding
+	grid := color.RGBA{R: 225, G: 228, B: 232, A: 255}
+	axis := color.RGBA{R: 150, G: 155, B: 160, A: 255}
+	for i := 0; i <= 4; i++ {
+		y := top + (bottom-top)*i/4
+		drawLine(img, left, y, right, y, 1, grid)
+	}
+	drawLine(img, left, top, left, bottom, 2, axis)
+	drawLine(img, left, bottom, right, bottom, 2, axis)
+
+	if len(values) == 0 {
+		return encodePNG(img)
+	}
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low, high = math.Min(low, v), math.Max(high, v)
+	}
+	if high == low {
+		// 数值相同时画在中间
+		low, high = low-1, high+1
+	}
+	// 上下各留出 10% 的空白
+	margin := (high - low) * 0.1
+	low, high = low-margin, high+margin
+
+	points := make([]image.Point, len(values))
+	for i, v := range values {
+		x := (left + right) / 2
+		if len(values) > 1 {
+			x = left + (right-left)*i/(len(values)-1)
+		}
+		y := bottom - int(math.Round((v-low)/(high-low)*float64(bottom-top)))
+		points[i] = image.Point{X: x, Y: y}
+	}
+	for i := 1; i < len(points); i++ {
+		drawLine(img, points[i-1].X, points[i-1].Y, points[i].X, points[i].Y, 3, line)
+	}
+	for _, p := range points {
+		fillCircle(img, p.X, p.Y, 6, line)
+		fillCircle(img, p.X, p.Y, 3, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	}
+	return encodePNG(img)
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine 以指定粗细绘制线段
+func drawLine(img *image.RGBA, x0, y0, x1, y1, width int, c color.RGBA) {
+	steps := int(math.Max(math.Abs(float64(x1-x0)), math.Abs(float64(y1-y0))))
+	if steps == 0 {
+		steps = 1
+	}
+	radius := width / 2
+	for i := 0; i <= steps; i++ {
+		x := x0 + (x1-x0)*i/steps
+		y := y0 + (y1-y0)*i/steps
+		if radius == 0 {
+			img.SetRGBA(x, y, c)
+			continue
+		}
+		fillCircle(img, x, y, radius, c)
+	}
+}
+
+func fillCircle(img *image.RGBA, cx, cy, r int, c color.RGBA) {
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if dx*dx+dy*dy <= r*r {
+				img.SetRGBA(cx+dx, cy+dy, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 导出文档的内容块类型
+const (
+	exportBlockHeading   = "heading"
+	exportBlockParagraph = "paragraph"
+	exportBlockBullets   = "bullets"
+	exportBlockTable     = "table"
+	exportBlockImage     = "image"
+	exportBlockNote      = "note" // 灰色小字，如图表说明和免责声明
+)
+
+// exportDocument 导出文档的中间结构，PDF 和 DOCX 按同一结构渲染，分享链接的档案快照也保存为该结构
+type exportDocument struct {
+	Title    string        `json:"title"`
+	Subtitle string        `json:"subtitle,omitempty"`
+	Fields   []exportField `json:"fields,omitempty"` // 标题下方的基本信息
+	Blocks   []exportBlock `json:"blocks"`
+}
+
+// exportField 基本信息中的一项
+type exportField struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// exportBlock 文档内容块
+type exportBlock struct {
+	Kind   string     `json:"kind"`
+	Level  int        `json:"level,omitempty"`  // 标题级别 1-3
+	Text   string     `json:"text,omitempty"`   // 标题、段落和说明的文字
+	Items  []string   `json:"items,omitempty"`  // 列表项
+	Rows   [][]string `json:"rows,omitempty"`   // 表格，第一行为表头
+	Widths []float64  `json:"widths,omitempty"` // 表格各列的相对宽度，为空时平均分配
+	Image  []byte     `json:"image,omitempty"`  // PNG 图片，JSON 中为 base64
+	Width  int        `json:"width,omitempty"`  // 图片像素宽度
+	Height int        `json:"height,omitempty"` // 图片像素高度
+}
+
+func (d *exportDocument) heading(level int, text string) {
+	d.Blocks = append(d.Blocks, exportBlock{Kind: exportBlockHeading, Level: level, Text: text})
+}
+
+func (d *exportDocument) paragraph(text string) {
+	if text = strings.TrimSpace(text); text != "" {
+		d.Blocks = append(d.Blocks, exportBlock{Kind: exportBlockParagraph, Text: text})
+	}
+}
+
+func (d *exportDocument) note(text string) {
+	d.Blocks = append(d.Blocks, exportBlock{Kind: exportBlockNote, Text: text})
+}
+
+func (d *exportDocument) table(rows [][]string, widths ...float64) {
+	d.Blocks = append(d.Blocks, exportBlock{Kind: exportBlockTable, Rows: rows, Widths: widths})
+}
+
+var (
+	markdownHeading   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownBullet    = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(.*)$`)
+	markdownRule      = regexp.MustCompile(`^\s*(?:-{3,}|\*{3,}|_{3,})\s*$`)
+	markdownTableSep  = regexp.MustCompile(`^\s*\|?\s*:?-{3,}:?\s*(?:\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+	markdownLink      = regexp.MustCompile(`\[([^\]]*)\]\(([^)]*)\)`)
+	markdownEmphasis  = regexp.MustCompile(`\*\*|__|~~|` + "`")
+	markdownSingleEmp = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*]*)\*`)
+)
+
+// markdownBlocks 将报告的 Markdown 转换为内容块，支持标题、段落、列表和表格，
+// 标题级别加上 baseLevel 后最多为 3 级
+func markdownBlocks(markdown string, baseLevel int) []exportBlock {
+	var blocks []exportBlock
+	var paragraph []string
+	var bullets []string
+	var rows [][]string
+
+	// flush 结束当前的段落、列表或表格，keep 指定的类型继续累积
+	flush := func(keep string) {
+		if len(paragraph) > 0 && keep != exportBlockParagraph {
+			blocks = append(blocks, exportBlock{Kind: exportBlockParagraph, Text: joinLines(paragraph)})
+			paragraph = nil
+		}
+		if len(bullets) > 0 && keep != exportBlockBullets {
+			blocks = append(blocks, exportBlock{Kind: exportBlockBullets, Items: bullets})
+			bullets = nil
+		}
+		if len(rows) > 0 && keep != exportBlockTable {
+			blocks = append(blocks, exportBlock{Kind: exportBlockTable, Rows: rows})
+			rows = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || markdownRule.MatchString(trimmed):
+			flush("")
+		case markdownHeading.MatchString(trimmed):
+			flush("")
+			match := markdownHeading.FindStringSubmatch(trimmed)
+			level := len(match[1]) + baseLevel
+			if level > 3 {
+				level = 3
+			}
+			blocks = append(blocks, exportBlock{Kind: exportBlockHeading, Level: level, Text: plainMarkdown(match[2])})
+		case strings.HasPrefix(trimmed, "|"):
+			flush(exportBlockTable)
+			if markdownTableSep.MatchString(trimmed) {
+				continue
+			}
+			cells := strings.Split(strings.Trim(trimmed, "|"), "|")
+			row := make([]string, len(cells))
+			for i, cell := range cells {
+				row[i] = plainMarkdown(strings.TrimSpace(cell))
+			}
+			rows = append(rows, row)
+		case markdownBullet.MatchString(line):
+			flush(exportBlockBullets)
+			bullets = append(bullets, plainMarkdown(markdownBullet.FindStringSubmatch(line)[1]))
+		case strings.HasPrefix(trimmed, ">"):
+			flush("")
+			blocks = append(blocks, exportBlock{Kind: exportBlockNote, Text: plainMarkdown(strings.TrimSpace(strings.TrimLeft(trimmed, ">")))})
+		case len(bullets) > 0 && strings.HasPrefix(line, " "):
+			// 列表项的续行
+			bullets[len(bullets)-1] = joinLines([]string{bullets[len(bullets)-1], plainMarkdown(trimmed)})
+		default:
+			flush(exportBlockParagraph)
+			paragraph = append(paragraph, plainMarkdown(trimmed))
+		}
+	}
+	flush("")
+	return blocks
+}
+
+// plainMarkdown 去除行内的 Markdown 标记，链接保留文字和地址
+func plainMarkdown(text string) string {
+	text = markdownLink.ReplaceAllStringFunc(text, func(link string) string {
+		match := markdownLink.FindStringSubmatch(link)
+		if match[2] == "" || match[1] == match[2] {
+			return match[1]
+		}
+		return match[1] + "（" + match[2] + "）"
+	})
+	text = markdownEmphasis.ReplaceAllString(text, "")
+	text = markdownSingleEmp.ReplaceAllString(text, "$1$2")
+	return text
+}
+
+// joinLines 合并段落中的多行，中文之间不加空格
+func joinLines(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 && !endsWithCJK(lines[i-1]) && !startsWithCJK(line) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+func endsWithCJK(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return r >= 0x2E80
+}
+
+func startsWithCJK(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return r >= 0x2E80
+}
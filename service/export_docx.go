@@ -0,0 +1,395 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DOCX 版式：A4，页边距与 PDF 一致（twip，1毫米约56.7twip）
+const (
+	docxPageWidth    = 11906
+	docxPageHeight   = 16838
+	docxMargin       = 1021
+	docxMarginTop    = 1928
+	docxContentWidth = docxPageWidth - 2*docxMargin
+	docxEMUPerTwip   = 635
+)
+
+// docxFontKey 嵌入字体的混淆密钥，DOCX 要求嵌入的字体按 ECMA-376 用该 GUID 混淆前 32 字节
+const docxFontKey = "{6A1F2C9E-3B57-4D08-A9C4-71E05B2D8F36}"
+
+var (
+	obfuscatedFontOnce sync.Once
+	obfuscatedFont     []byte
+)
+
+// docxObfuscatedFont 按 fontKey 混淆后的内嵌字体：GUID 的 16 字节倒序后与字体前 32 字节异或
+func docxObfuscatedFont() []byte {
+	obfuscatedFontOnce.Do(func() {
+		key, _ := hex.DecodeString(strings.NewReplacer("{", "", "}", "", "-", "").Replace(docxFontKey))
+		obfuscatedFont = append([]byte(nil), exportFont...)
+		for i := 0; i < 32; i++ {
+			obfuscatedFont[i] ^= key[len(key)-1-i%len(key)]
+		}
+	})
+	return obfuscatedFont
+}
+
+// docxWriter 生成 WordprocessingML 正文并记录引用的图片
+type docxWriter struct {
+	body   strings.Builder
+	images [][]byte // 正文中的图片，依次对应 rIdImage1...
+	shapes int      // 图片对象编号
+}
+
+// renderDOCX 将文档渲染为 DOCX，页眉为机构信头，页脚带页码，并嵌入中文字体以便在未安装该字体的电脑上正常显示
+func renderDOCX(doc *exportDocument, branding *exportBranding) ([]byte, error) {
+	color := fmt.Sprintf("%02X%02X%02X", branding.Color.R, branding.Color.G, branding.Color.B)
+	w := &docxWriter{}
+
+	w.paragraph("Title", "", docxRuns(doc.Title, ""))
+	if doc.Subtitle != "" {
+		w.paragraph("Subtitle", "", docxRuns(doc.Subtitle, ""))
+	}
+	if len(doc.Fields) > 0 {
+		rows := make([][]string, 0, (len(doc.Fields)+1)/2)
+		for i := 0; i < len(doc.Fields); i += 2 {
+			row := []string{doc.Fields[i].Label, doc.Fields[i].Value, "", ""}
+			if i+1 < len(doc.Fields) {
+				row[2], row[3] = doc.Fields[i+1].Label, doc.Fields[i+1].Value
+			}
+			rows = append(rows, row)
+		}
+		w.table(rows, []float64{1, 2, 1, 2}, false, color)
+		w.paragraph("", "", "")
+	}
+
+	for _, block := range doc.Blocks {
+		switch block.Kind {
+		case exportBlockHeading:
+			level := block.Level
+			if level < 1 || level > 3 {
+				level = 3
+			}
+			w.paragraph(fmt.Sprintf("Heading%d", level), "", docxRuns(block.Text, ""))
+		case exportBlockParagraph:
+			w.paragraph("", "", docxRuns(block.Text, ""))
+		case exportBlockNote:
+			w.paragraph("Note", "", docxRuns(block.Text, ""))
+		case exportBlockBullets:
+			for _, item := range block.Items {
+				w.paragraph("ListBullet", "", docxRuns("•\t"+item, ""))
+			}
+		case exportBlockTable:
+			w.table(block.Rows, block.Widths, true, color)
+			w.paragraph("", "", "")
+		case exportBlockImage:
+			w.images = append(w.images, block.Image)
+			width := docxContentWidth * docxEMUPerTwip
+			height := width * block.Height / block.Width
+			w.paragraph("", "", w.drawing(fmt.Sprintf("rIdImage%d", len(w.images)), width, height))
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(docxContentTypes(branding))},
+		{"_rels/.rels", []byte(docxPackageRels)},
+		{"docProps/core.xml", []byte(docxCoreProps(doc.Title, branding.Name))},
+		{"word/document.xml", []byte(docxDocumentXML(w.body.String()))},
+		{"word/_rels/document.xml.rels", []byte(docxDocumentRels(len(w.images)))},
+		{"word/styles.xml", []byte(docxStyles(color))},
+		{"word/settings.xml", []byte(docxSettings)},
+		{"word/fontTable.xml", []byte(docxFontTable)},
+		{"word/_rels/fontTable.xml.rels", []byte(docxFontTableRels)},
+		{"word/fonts/font1.odttf", docxObfuscatedFont()},
+		{"word/header1.xml", []byte(docxHeader(branding, color))},
+		{"word/footer1.xml", []byte(docxFooter(branding.Footer))},
+	}
+	if len(branding.Logo) > 0 {
+		files = append(files,
+			struct {
+				name string
+				data []byte
+			}{"word/_rels/header1.xml.rels", []byte(docxHeaderRels(branding.LogoType))},
+			struct {
+				name string
+				data []byte
+			}{"word/media/logo." + branding.LogoType, branding.Logo},
+		)
+	}
+	for i, image := range w.images {
+		files = append(files, struct {
+			name string
+			data []byte
+		}{fmt.Sprintf("word/media/image%d.png", i+1), image})
+	}
+
+	for _, file := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return nil, fmt.Errorf("生成DOCX失败: %v", err)
+		}
+		if _, err := fw.Write(file.data); err != nil {
+			return nil, fmt.Errorf("生成DOCX失败: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("生成DOCX失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// paragraph 写入段落，style 为段落样式ID，props 为额外的段落属性
+func (w *docxWriter) paragraph(style, props, runs string) {
+	w.body.WriteString("<w:p>")
+	if style != "" || props != "" {
+		w.body.WriteString("<w:pPr>")
+		if style != "" {
+			fmt.Fprintf(&w.body, `<w:pStyle w:val="%s"/>`, style)
+		}
+		w.body.WriteString(props)
+		w.body.WriteString("</w:pPr>")
+	}
+	w.body.WriteString(runs)
+	w.body.WriteString("</w:p>")
+}
+
+// table 写入表格，header 为真时第一行为表头并在跨页时重复；否则为基本信息表，偶数列为标签
+func (w *docxWriter) table(rows [][]string, weights []float64, header bool, color string) {
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	widths := columnWidths(weights, columns, docxContentWidth)
+
+	w.body.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="5000" w:type="pct"/><w:tblLayout w:type="fixed"/></w:tblPr><w:tblGrid>`)
+	for _, width := range widths {
+		fmt.Fprintf(&w.body, `<w:gridCol w:w="%d"/>`, int(width))
+	}
+	w.body.WriteString("</w:tblGrid>")
+	for r, row := range rows {
+		isHeader := header && r == 0
+		w.body.WriteString("<w:tr>")
+		if isHeader {
+			w.body.WriteString("<w:trPr><w:tblHeader/></w:trPr>")
+		}
+		for c := 0; c < columns; c++ {
+			fill, runProps := "FFFFFF", ""
+			switch {
+			case isHeader:
+				fill, runProps = color, `<w:b/><w:color w:val="FFFFFF"/>`
+			case !header && c%2 == 0:
+				fill, runProps = "F5F6F8", `<w:color w:val="646464"/>`
+			}
+			text := ""
+			if c < len(row) {
+				text = row[c]
+			}
+			fmt.Fprintf(&w.body, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/><w:shd w:val="clear" w:color="auto" w:fill="%s"/></w:tcPr>`, int(widths[c]), fill)
+			w.paragraph("TableText", "", docxRuns(text, runProps))
+			w.body.WriteString("</w:tc>")
+		}
+		w.body.WriteString("</w:tr>")
+	}
+	w.body.WriteString("</w:tbl>")
+}
+
+// drawing 返回内嵌图片的 run，宽高单位为 EMU
+func (w *docxWriter) drawing(relID string, width, height int) string {
+	w.shapes++
+	return docxDrawing(relID, w.shapes, width, height)
+}
+
+func docxDrawing(relID string, id, width, height int) string {
+	return fmt.Sprintf(`<w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0"><wp:extent cx="%[3]d" cy="%[4]d"/><wp:docPr id="%[2]d" name="图片%[2]d"/>`+
+		`<a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture"><pic:nvPicPr><pic:cNvPr id="%[2]d" name="图片%[2]d"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="%[1]s"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%[3]d" cy="%[4]d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr></pic:pic>`+
+		`</a:graphicData></a:graphic></wp:inline></w:drawing></w:r>`, relID, id, width, height)
+}
+
+// docxRuns 将文本转换为 run，换行转换为 w:br，制表符转换为 w:tab
+func docxRuns(text, runProps string) string {
+	if text == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("<w:r>")
+	if runProps != "" {
+		b.WriteString("<w:rPr>" + runProps + "</w:rPr>")
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			b.WriteString("<w:br/>")
+		}
+		for j, part := range strings.Split(line, "\t") {
+			if j > 0 {
+				b.WriteString("<w:tab/>")
+			}
+			if part != "" {
+				b.WriteString(`<w:t xml:space="preserve">`)
+				xml.EscapeText(&b, []byte(part))
+				b.WriteString("</w:t>")
+			}
+		}
+	}
+	b.WriteString("</w:r>")
+	return b.String()
+}
+
+const docxNamespaces = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+	`xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"`
+
+func docxDocumentXML(body string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document ` + docxNamespaces + `><w:body>` + body +
+		fmt.Sprintf(`<w:sectPr><w:headerReference w:type="default" r:id="rIdHeader"/><w:footerReference w:type="default" r:id="rIdFooter"/>`+
+			`<w:pgSz w:w="%d" w:h="%d"/><w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="567" w:footer="567" w:gutter="0"/></w:sectPr>`,
+			docxPageWidth, docxPageHeight, docxMarginTop, docxMargin, docxMargin+113, docxMargin) +
+		`</w:body></w:document>`
+}
+
+func docxHeader(branding *exportBranding, color string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?><w:hdr ` + docxNamespaces + `>`)
+	b.WriteString(`<w:p><w:pPr><w:spacing w:after="0"/></w:pPr>`)
+	if len(branding.Logo) > 0 && branding.LogoHeight > 0 {
+		height := 504000 // 14毫米
+		width := height * branding.LogoWidth / branding.LogoHeight
+		b.WriteString(docxDrawing("rIdLogo", 1000, width, height))
+		b.WriteString(`<w:r><w:t xml:space="preserve">  </w:t></w:r>`)
+	}
+	b.WriteString(docxRuns(branding.Name, fmt.Sprintf(`<w:b/><w:color w:val="%s"/><w:sz w:val="28"/>`, color)))
+	b.WriteString(`</w:p>`)
+	var lines []string
+	for _, line := range []string{branding.Tagline, branding.Contact} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	fmt.Fprintf(&b, `<w:p><w:pPr><w:pBdr><w:bottom w:val="single" w:sz="12" w:space="4" w:color="%s"/></w:pBdr><w:spacing w:after="0"/></w:pPr>`, color)
+	b.WriteString(docxRuns(strings.Join(lines, "\n"), `<w:color w:val="6E6E6E"/><w:sz w:val="17"/>`))
+	b.WriteString(`</w:p></w:hdr>`)
+	return b.String()
+}
+
+func docxFooter(footer string) string {
+	small := `<w:rPr><w:color w:val="828282"/><w:sz w:val="16"/></w:rPr>`
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><w:ftr ` + docxNamespaces + `>` +
+		fmt.Sprintf(`<w:p><w:pPr><w:tabs><w:tab w:val="right" w:pos="%d"/></w:tabs></w:pPr>`, docxContentWidth) +
+		docxRuns(footer, `<w:color w:val="828282"/><w:sz w:val="16"/>`) +
+		`<w:r>` + small + `<w:tab/><w:t xml:space="preserve">第 </w:t></w:r>` +
+		`<w:fldSimple w:instr=" PAGE "><w:r>` + small + `<w:t>1</w:t></w:r></w:fldSimple>` +
+		`<w:r>` + small + `<w:t xml:space="preserve"> 页 / 共 </w:t></w:r>` +
+		`<w:fldSimple w:instr=" NUMPAGES "><w:r>` + small + `<w:t>1</w:t></w:r></w:fldSimple>` +
+		`<w:r>` + small + `<w:t xml:space="preserve"> 页</w:t></w:r></w:p></w:ftr>`
+}
+
+func docxStyles(color string) string {
+	fonts := fmt.Sprintf(`<w:rFonts w:ascii="%[1]s" w:hAnsi="%[1]s" w:eastAsia="%[1]s" w:cs="%[1]s"/>`, docxFontFamily)
+	heading := func(id, name string, size, before int, headingColor string) string {
+		return fmt.Sprintf(`<w:style w:type="paragraph" w:styleId="%s"><w:name w:val="%s"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>`+
+			`<w:pPr><w:keepNext/><w:spacing w:before="%d" w:after="80"/><w:outlineLvl w:val="%d"/></w:pPr><w:rPr><w:b/><w:color w:val="%s"/><w:sz w:val="%d"/></w:rPr></w:style>`,
+			id, name, before, int(id[len(id)-1]-'1'), headingColor, size)
+	}
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><w:styles ` + docxNamespaces + `>` +
+		`<w:docDefaults><w:rPrDefault><w:rPr>` + fonts + `<w:sz w:val="21"/><w:szCs w:val="21"/><w:lang w:val="en-US" w:eastAsia="zh-CN"/></w:rPr></w:rPrDefault>` +
+		`<w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="312" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+		`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/><w:rPr><w:color w:val="282828"/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:spacing w:after="60"/></w:pPr><w:rPr><w:b/><w:color w:val="1E1E1E"/><w:sz w:val="40"/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:rPr><w:color w:val="646464"/><w:sz w:val="22"/></w:rPr></w:style>` +
+		heading("Heading1", "heading 1", 30, 240, color) +
+		heading("Heading2", "heading 2", 25, 200, "282828") +
+		heading("Heading3", "heading 3", 22, 160, "282828") +
+		`<w:style w:type="paragraph" w:styleId="Note"><w:name w:val="Note"/><w:basedOn w:val="Normal"/><w:rPr><w:color w:val="787878"/><w:sz w:val="17"/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="ListBullet"><w:name w:val="List Bullet"/><w:basedOn w:val="Normal"/><w:pPr><w:tabs><w:tab w:val="left" w:pos="420"/></w:tabs><w:spacing w:after="40"/><w:ind w:left="420" w:hanging="280"/></w:pPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="TableText"><w:name w:val="Table Text"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:before="40" w:after="40" w:line="264" w:lineRule="auto"/></w:pPr><w:rPr><w:sz w:val="19"/></w:rPr></w:style>` +
+		`<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:tblPr><w:tblBorders>` +
+		`<w:top w:val="single" w:sz="4" w:color="D2D6DC"/><w:left w:val="single" w:sz="4" w:color="D2D6DC"/><w:bottom w:val="single" w:sz="4" w:color="D2D6DC"/>` +
+		`<w:right w:val="single" w:sz="4" w:color="D2D6DC"/><w:insideH w:val="single" w:sz="4" w:color="D2D6DC"/><w:insideV w:val="single" w:sz="4" w:color="D2D6DC"/>` +
+		`</w:tblBorders><w:tblCellMar><w:left w:w="85" w:type="dxa"/><w:right w:w="85" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>` +
+		`</w:styles>`
+}
+
+func docxContentTypes(branding *exportBranding) string {
+	logo := ""
+	if len(branding.Logo) > 0 && branding.LogoType == "jpeg" {
+		logo = `<Default Extension="jpeg" ContentType="image/jpeg"/>`
+	}
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Default Extension="png" ContentType="image/png"/>` + logo +
+		`<Default Extension="odttf" ContentType="application/vnd.openxmlformats-officedocument.obfuscatedFont"/>` +
+		`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+		`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+		`<Override PartName="/word/settings.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"/>` +
+		`<Override PartName="/word/fontTable.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.fontTable+xml"/>` +
+		`<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/>` +
+		`<Override PartName="/word/footer1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"/>` +
+		`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+		`</Types>`
+}
+
+const docxPackageRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+func docxDocumentRels(images int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	b.WriteString(`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	b.WriteString(`<Relationship Id="rIdSettings" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings" Target="settings.xml"/>`)
+	b.WriteString(`<Relationship Id="rIdFontTable" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/fontTable" Target="fontTable.xml"/>`)
+	b.WriteString(`<Relationship Id="rIdHeader" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>`)
+	b.WriteString(`<Relationship Id="rIdFooter" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer1.xml"/>`)
+	for i := 1; i <= images; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rIdImage%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image%d.png"/>`, i, i)
+	}
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func docxHeaderRels(logoType string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rIdLogo" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/logo.` + logoType + `"/>` +
+		`</Relationships>`
+}
+
+const docxSettings = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:embedTrueTypeFonts/><w:defaultTabStop w:val="420"/><w:characterSpacingControl w:val="compressPunctuation"/></w:settings>`
+
+const docxFontTable = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><w:fonts ` + docxNamespaces + `>` +
+	`<w:font w:name="` + docxFontFamily + `"><w:charset w:val="86"/><w:family w:val="swiss"/><w:pitch w:val="variable"/>` +
+	`<w:embedRegular r:id="rIdFont1" w:fontKey="` + docxFontKey + `"/></w:font></w:fonts>`
+
+const docxFontTableRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rIdFont1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/font" Target="fonts/font1.odttf"/>` +
+	`</Relationships>`
+
+func docxCoreProps(title, creator string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?><cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><dc:title>`)
+	xml.EscapeText(&b, []byte(title))
+	b.WriteString("</dc:title><dc:creator>")
+	xml.EscapeText(&b, []byte(creator))
+	fmt.Fprintf(&b, `</dc:creator><dcterms:created xsi:type="dcterms:W3CDTF">%s</dcterms:created></cp:coreProperties>`, time.Now().UTC().Format(time.RFC3339))
+	return b.String()
+}
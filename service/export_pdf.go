@@ -0,0 +1,258 @@
+package service
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+
+	"github.com/go-pdf/fpdf"
+)
+
+//go:embed fonts/wqy-microhei.ttf
+var exportFont []byte
+
+// 导出文档使用的字体名称
+const (
+	pdfFontFamily  = "wqy"
+	docxFontFamily = "WenQuanYi Micro Hei"
+)
+
+// A4 纵向页面的版式（毫米）
+const (
+	pdfMarginLeft   = 18.0
+	pdfMarginRight  = 18.0
+	pdfMarginTop    = 34.0 // 信头下方开始正文
+	pdfMarginBottom = 20.0
+	pdfLineHeight   = 6.5
+)
+
+// renderPDF 将文档渲染为 A4 PDF，每页带机构信头和页码，中文使用内嵌字体并按实际用到的字形子集化
+func renderPDF(doc *exportDocument, branding *exportBranding) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMarginLeft, pdfMarginTop, pdfMarginRight)
+	pdf.SetAutoPageBreak(true, pdfMarginBottom)
+	pdf.SetTitle(doc.Title, true)
+	pdf.SetCreator(branding.Name, true)
+	// 字体没有粗体字形，粗体样式使用同一字体
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "", exportFont)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "B", exportFont)
+	pdf.AliasNbPages("{nb}")
+
+	logoName := ""
+	if len(branding.Logo) > 0 {
+		logoName = "logo"
+		imageType := "PNG"
+		if branding.LogoType == "jpeg" {
+			imageType = "JPG"
+		}
+		pdf.RegisterImageOptionsReader(logoName, fpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(branding.Logo))
+	}
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentWidth := pageWidth - pdfMarginLeft - pdfMarginRight
+	r, g, b := int(branding.Color.R), int(branding.Color.G), int(branding.Color.B)
+
+	pdf.SetHeaderFunc(func() {
+		textX := pdfMarginLeft
+		if logoName != "" {
+			pdf.ImageOptions(logoName, pdfMarginLeft, 10, 0, 14, false, fpdf.ImageOptions{}, 0, "")
+			if info := pdf.GetImageInfo(logoName); info != nil && info.Height() > 0 {
+				textX += 14*info.Width()/info.Height() + 4
+			}
+		}
+		pdf.SetXY(textX, 10)
+		pdf.SetFont(pdfFontFamily, "B", 14)
+		pdf.SetTextColor(r, g, b)
+		pdf.CellFormat(0, 7, branding.Name, "", 1, "L", false, 0, "")
+		pdf.SetFont(pdfFontFamily, "", 8.5)
+		pdf.SetTextColor(110, 110, 110)
+		for _, line := range []string{branding.Tagline, branding.Contact} {
+			if line != "" {
+				pdf.SetX(textX)
+				pdf.CellFormat(0, 4.5, line, "", 1, "L", false, 0, "")
+			}
+		}
+		pdf.SetDrawColor(r, g, b)
+		pdf.SetLineWidth(0.6)
+		pdf.Line(pdfMarginLeft, 28, pageWidth-pdfMarginRight, 28)
+		pdf.SetXY(pdfMarginLeft, pdfMarginTop)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-14)
+		pdf.SetFont(pdfFontFamily, "", 8)
+		pdf.SetTextColor(130, 130, 130)
+		pdf.CellFormat(contentWidth*0.75, 5, branding.Footer, "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentWidth*0.25, 5, fmt.Sprintf("第 %d 页 / 共 {nb} 页", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+	pdf.SetFont(pdfFontFamily, "B", 20)
+	pdf.SetTextColor(30, 30, 30)
+	pdf.MultiCell(0, 10, doc.Title, "", "L", false)
+	if doc.Subtitle != "" {
+		pdf.SetFont(pdfFontFamily, "", 11)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.MultiCell(0, 6, doc.Subtitle, "", "L", false)
+	}
+	pdf.Ln(3)
+	if len(doc.Fields) > 0 {
+		rows := make([][]string, 0, (len(doc.Fields)+1)/2)
+		for i := 0; i < len(doc.Fields); i += 2 {
+			row := []string{doc.Fields[i].Label, doc.Fields[i].Value, "", ""}
+			if i+1 < len(doc.Fields) {
+				row[2], row[3] = doc.Fields[i+1].Label, doc.Fields[i+1].Value
+			}
+			rows = append(rows, row)
+		}
+		pdfTable(pdf, rows, []float64{1, 2, 1, 2}, contentWidth, pageHeight, false, branding)
+		pdf.Ln(2)
+	}
+
+	for i, block := range doc.Blocks {
+		switch block.Kind {
+		case exportBlockHeading:
+			sizes := map[int]float64{1: 15, 2: 12.5, 3: 11}
+			size := sizes[block.Level]
+			if size == 0 {
+				size = 11
+			}
+			// 标题不单独留在页尾
+			if pdf.GetY()+size+pdfLineHeight*2 > pageHeight-pdfMarginBottom {
+				pdf.AddPage()
+			} else if i > 0 {
+				pdf.Ln(2.5)
+			}
+			pdf.SetFont(pdfFontFamily, "B", size)
+			if block.Level == 1 {
+				pdf.SetTextColor(r, g, b)
+			} else {
+				pdf.SetTextColor(40, 40, 40)
+			}
+			pdf.MultiCell(0, size*0.55, block.Text, "", "L", false)
+			pdf.Ln(1)
+		case exportBlockParagraph:
+			pdf.SetFont(pdfFontFamily, "", 10.5)
+			pdf.SetTextColor(40, 40, 40)
+			pdf.MultiCell(0, pdfLineHeight, block.Text, "", "L", false)
+			pdf.Ln(1.5)
+		case exportBlockNote:
+			pdf.SetFont(pdfFontFamily, "", 8.5)
+			pdf.SetTextColor(120, 120, 120)
+			pdf.MultiCell(0, 4.8, block.Text, "", "L", false)
+			pdf.Ln(1.5)
+		case exportBlockBullets:
+			pdf.SetFont(pdfFontFamily, "", 10.5)
+			pdf.SetTextColor(40, 40, 40)
+			for _, item := range block.Items {
+				pdf.SetX(pdfMarginLeft + 2)
+				pdf.CellFormat(4, pdfLineHeight, "•", "", 0, "L", false, 0, "")
+				pdf.MultiCell(contentWidth-6, pdfLineHeight, item, "", "L", false)
+			}
+			pdf.Ln(1.5)
+		case exportBlockTable:
+			pdfTable(pdf, block.Rows, block.Widths, contentWidth, pageHeight, true, branding)
+			pdf.Ln(2)
+		case exportBlockImage:
+			name := fmt.Sprintf("image%d", i)
+			pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(block.Image))
+			height := contentWidth * float64(block.Height) / float64(block.Width)
+			if pdf.GetY()+height > pageHeight-pdfMarginBottom {
+				pdf.AddPage()
+			}
+			pdf.ImageOptions(name, pdfMarginLeft, pdf.GetY(), contentWidth, height, false, fpdf.ImageOptions{}, 0, "")
+			pdf.SetY(pdf.GetY() + height + 1)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("生成PDF失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// pdfTable 绘制表格，单元格文字自动换行，行高取该行最多的行数，跨页时在新页重复表头
+func pdfTable(pdf *fpdf.Fpdf, rows [][]string, weights []float64, contentWidth, pageHeight float64, header bool, branding *exportBranding) {
+	if len(rows) == 0 {
+		return
+	}
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	widths := columnWidths(weights, columns, contentWidth)
+
+	const cellLine, padding = 5.2, 1.5
+	var drawRow func(row []string, bold bool)
+	drawRow = func(row []string, bold bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont(pdfFontFamily, style, 9.5)
+		lines := 1
+		for c := 0; c < columns; c++ {
+			if c < len(row) {
+				if n := len(pdf.SplitText(row[c], widths[c]-2*padding)); n > lines {
+					lines = n
+				}
+			}
+		}
+		height := float64(lines)*cellLine + 2*padding
+		if pdf.GetY()+height > pageHeight-pdfMarginBottom {
+			pdf.AddPage()
+			if header && !bold {
+				drawRow(rows[0], true)
+			}
+		}
+		// 信头会修改线条颜色，每行重新设置
+		pdf.SetDrawColor(210, 214, 220)
+		pdf.SetLineWidth(0.2)
+		x, y := pdfMarginLeft, pdf.GetY()
+		for c := 0; c < columns; c++ {
+			if bold {
+				pdf.SetFillColor(int(branding.Color.R), int(branding.Color.G), int(branding.Color.B))
+				pdf.SetTextColor(255, 255, 255)
+			} else if !header && c%2 == 0 {
+				// 基本信息表的标签列
+				pdf.SetFillColor(245, 246, 248)
+				pdf.SetTextColor(100, 100, 100)
+			} else {
+				pdf.SetFillColor(255, 255, 255)
+				pdf.SetTextColor(40, 40, 40)
+			}
+			pdf.Rect(x, y, widths[c], height, "FD")
+			text := ""
+			if c < len(row) {
+				text = row[c]
+			}
+			pdf.SetXY(x+padding, y+padding)
+			pdf.MultiCell(widths[c]-2*padding, cellLine, text, "", "L", false)
+			x += widths[c]
+		}
+		pdf.SetXY(pdfMarginLeft, y+height)
+	}
+
+	for i, row := range rows {
+		drawRow(row, header && i == 0)
+	}
+}
+
+// columnWidths 按相对宽度分配表格列宽，未指定时平均分配
+func columnWidths(weights []float64, columns int, total float64) []float64 {
+	widths := make([]float64, columns)
+	sum := 0.0
+	for c := 0; c < columns; c++ {
+		weight := 1.0
+		if c < len(weights) && weights[c] > 0 {
+			weight = weights[c]
+		}
+		widths[c] = weight
+		sum += weight
+	}
+	for c := range widths {
+		widths[c] = widths[c] / sum * total
+	}
+	return widths
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"fmt"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/model"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type HealingComparisonService struct {
+	healingLogDAO *DAO.HealingLogDAO
+	userDAO       *DAO.UserDAO
+}
+
+func NewHealingComparisonService(healingLogDAO *DAO.HealingLogDAO, userDAO *DAO.UserDAO) *HealingComparisonService {
+	return &HealingComparisonService{
+		healingLogDAO: healingLogDAO,
+		userDAO:       userDAO,
+	}
+}
+
+// ComparisonQuery 对比的技能和两个时间段，时间段为空时使用全部已标记的记录
+type ComparisonQuery struct {
+	Skill         string
+	BaselineStart *time.Time
+	BaselineEnd   *time.Time
+	FollowUpStart *time.Time
+	FollowUpEnd   *time.Time
+}
+
+// ComparisonPeriod 对比时间段
+type ComparisonPeriod struct {
+	Start *time.Time `json:"start"`
+	End   *time.Time `json:"end"`
+}
+
+// ComparisonEntry 参与对比的一条日志
+type ComparisonEntry struct {
+	LogID   uint              `json:"log_id"`
+	Date    time.Time         `json:"date"`
+	Content string            `json:"content"`
+	Metrics []model.LogMetric `json:"metrics"`
+}
+
+// ComparisonPair 按时间顺序配对的基线与跟进记录，某一侧缺少记录时为空
+type ComparisonPair struct {
+	Baseline *ComparisonEntry `json:"baseline"`
+	FollowUp *ComparisonEntry `json:"follow_up"`
+}
+
+// MetricDelta 同一指标在两个阶段的均值变化
+type MetricDelta struct {
+	Name          string   `json:"name"`
+	Unit          string   `json:"unit"`
+	BaselineAvg   float64  `json:"baseline_avg"`
+	FollowUpAvg   float64  `json:"follow_up_avg"`
+	Delta         float64  `json:"delta"`
+	ChangeRate    *float64 `json:"change_rate"` // 变化百分比，基线均值为0时为空
+	BaselineCount int      `json:"baseline_count"`
+	FollowUpCount int      `json:"follow_up_count"`
+}
+
+// HealingComparison 疗愈前后对比结果
+type HealingComparison struct {
+	Skill          string           `json:"skill"`
+	BaselinePeriod ComparisonPeriod `json:"baseline_period"`
+	FollowUpPeriod ComparisonPeriod `json:"follow_up_period"`
+	Pairs          []ComparisonPair `json:"pairs"`
+	MetricDeltas   []MetricDelta    `json:"metric_deltas"`
+	BaselineMedia  []model.LogMedia `json:"baseline_media"`
+	FollowUpMedia  []model.LogMedia `json:"follow_up_media"`
+}
+
+// ParseComparisonRequest 解析对比请求中的日期，结束日期包含当天
+func ParseComparisonRequest(req *request.ComparisonRequest) (*ComparisonQuery, error) {
+	query := &ComparisonQuery{Skill: strings.TrimSpace(req.Skill)}
+	fields := []struct {
+		value  string
+		target **time.Time
+		endDay bool
+	}{
+		{req.BaselineStart, &query.BaselineStart, false},
+		{req.BaselineEnd, &query.BaselineEnd, true},
+		{req.FollowUpStart, &query.FollowUpStart, false},
+		{req.FollowUpEnd, &query.FollowUpEnd, true},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		parsed, err := time.ParseInLocation("2006-01-02", field.value, time.Local)
+		if err != nil {
+			return nil, invalidRequest("日期格式错误，请使用 YYYY-MM-DD 格式: %s", field.value)
+		}
+		if field.endDay {
+			parsed = parsed.Add(24*time.Hour - time.Second)
+		}
+		*field.target = &parsed
+	}
+	return query, nil
+}
+
+// MarkPhase 标记日志或其中的媒体为基线/跟进记录
+func (s *HealingComparisonService) MarkPhase(userID string, logID uint, req *request.MarkLogPhaseRequest) error {
+	healingLog, err := s.healingLogDAO.GetHealingLogByID(logID)
+	if err != nil {
+		return ErrHealingLogNotFound
+	}
+	if _, err := checkChildAccess(s.userDAO, userID, strconv.FormatUint(uint64(healingLog.ChildArchiveID), 10)); err != nil {
+		return err
+	}
+	skill := strings.TrimSpace(req.Skill)
+	if len(req.MediaIDs) > 0 {
+		err = s.healingLogDAO.UpdateLogMediaPhase(logID, req.MediaIDs, req.Phase, skill)
+	} else {
+		err = s.healingLogDAO.UpdateHealingLogPhase(logID, req.Phase, skill)
+	}
+	if err != nil {
+		return fmt.Errorf("标记失败: %v", err)
+	}
+	return nil
+}
+
+// Compare 对比儿童在基线和跟进两个阶段的记录、指标和媒体
+func (s *HealingComparisonService) Compare(userID, childArchiveID string, query *ComparisonQuery) (*HealingComparison, error) {
+	if _, err := checkChildAccess(s.userDAO, userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	childID, err := strconv.ParseUint(childArchiveID, 10, 64)
+	if err != nil {
+		return nil, invalidRequest("儿童档案ID格式错误: %v", err)
+	}
+	return s.BuildComparison(uint(childID), query)
+}
+
+// BuildComparison 构建对比结果，不做权限校验，供报告生成等内部流程使用
+func (s *HealingComparisonService) BuildComparison(childID uint, query *ComparisonQuery) (*HealingComparison, error) {
+	if query.BaselineStart != nil && query.FollowUpStart != nil && query.FollowUpStart.Before(*query.BaselineStart) {
+		return nil, invalidRequest("跟进阶段不能早于基线阶段")
+	}
+
+	baselineLogs, err := s.healingLogDAO.GetHealingLogsByChildIDWithDateFilter(childID, query.BaselineStart, query.BaselineEnd)
+	if err != nil {
+		return nil, fmt.Errorf("获取基线记录失败: %v", err)
+	}
+	followUpLogs, err := s.healingLogDAO.GetHealingLogsByChildIDWithDateFilter(childID, query.FollowUpStart, query.FollowUpEnd)
+	if err != nil {
+		return nil, fmt.Errorf("获取跟进记录失败: %v", err)
+	}
+
+	baselinePeriodGiven := query.BaselineStart != nil || query.BaselineEnd != nil
+	followUpPeriodGiven := query.FollowUpStart != nil || query.FollowUpEnd != nil
+	baselineEntries, baselineMedia := collectPhase(baselineLogs, model.PhaseBaseline, query.Skill, baselinePeriodGiven)
+	followUpEntries, followUpMedia := collectPhase(followUpLogs, model.PhaseFollowUp, query.Skill, followUpPeriodGiven)
+
+	comparison := &HealingComparison{
+		Skill:          query.Skill,
+		BaselinePeriod: ComparisonPeriod{Start: query.BaselineStart, End: query.BaselineEnd},
+		FollowUpPeriod: ComparisonPeriod{Start: query.FollowUpStart, End: query.FollowUpEnd},
+		BaselineMedia:  baselineMedia,
+		FollowUpMedia:  followUpMedia,
+		MetricDeltas:   computeMetricDeltas(baselineEntries, followUpEntries),
+	}
+
+	pairCount := len(baselineEntries)
+	if len(followUpEntries) > pairCount {
+		pairCount = len(followUpEntries)
+	}
+	for i := 0; i < pairCount; i++ {
+		var pair ComparisonPair
+		if i < len(baselineEntries) {
+			pair.Baseline = &baselineEntries[i]
+		}
+		if i < len(followUpEntries) {
+			pair.FollowUp = &followUpEntries[i]
+		}
+		comparison.Pairs = append(comparison.Pairs, pair)
+	}
+	return comparison, nil
+}
+
+// collectPhase 从日志中挑选属于指定阶段的记录和媒体。
+// 明确标记了阶段的日志按标记和技能筛选；未标记的日志只有在指定了该阶段的时间段时才计入。
+func collectPhase(logs []model.HealingLog, phase, skill string, periodGiven bool) ([]ComparisonEntry, []model.LogMedia) {
+	var entries []ComparisonEntry
+	var media []model.LogMedia
+
+	// 按时间升序，便于两个阶段依次配对
+	sort.Slice(logs, func(i, j int) bool { return logs[i].CreatedAt.Before(logs[j].CreatedAt) })
+
+	for _, healingLog := range logs {
+		logPhase := healingLog.Phase
+		if logPhase == "" && periodGiven {
+			logPhase = phase
+		}
+		logMatches := logPhase == phase && (skill == "" || healingLog.Skill == skill || (healingLog.Phase == "" && healingLog.Skill == ""))
+		if logMatches {
+			entries = append(entries, ComparisonEntry{
+				LogID:   healingLog.ID,
+				Date:    healingLog.CreatedAt,
+				Content: healingLog.Content,
+				Metrics: healingLog.Metrics,
+			})
+		}
+
+		for _, item := range healingLog.Media {
+			mediaPhase, mediaSkill := item.Phase, item.Skill
+			if mediaPhase == "" {
+				if !logMatches {
+					continue
+				}
+				mediaPhase = phase
+			}
+			if mediaSkill == "" {
+				mediaSkill = healingLog.Skill
+			}
+			if mediaPhase == phase && (skill == "" || mediaSkill == skill || mediaSkill == "") {
+				media = append(media, item)
+			}
+		}
+	}
+	return entries, media
+}
+
+// computeMetricDeltas 计算两个阶段都出现过的指标的均值变化
+func computeMetricDeltas(baseline, followUp []ComparisonEntry) []MetricDelta {
+	type accumulator struct {
+		sum   float64
+		count int
+		unit  string
+	}
+	aggregate := func(entries []ComparisonEntry) map[string]*accumulator {
+		result := make(map[string]*accumulator)
+		for _, entry := range entries {
+			for _, metric := range entry.Metrics {
+				acc, ok := result[metric.Name]
+				if !ok {
+					acc = &accumulator{unit: metric.Unit}
+					result[metric.Name] = acc
+				}
+				acc.sum += metric.Value
+				acc.count++
+			}
+		}
+		return result
+	}
+
+	before := aggregate(baseline)
+	after := aggregate(followUp)
+
+	var deltas []MetricDelta
+	for name, b := range before {
+		a, ok := after[name]
+		if !ok {
+			continue
+		}
+		delta := MetricDelta{
+			Name:          name,
+			Unit:          b.unit,
+			BaselineAvg:   b.sum / float64(b.count),
+			FollowUpAvg:   a.sum / float64(a.count),
+			BaselineCount: b.count,
+			FollowUpCount: a.count,
+		}
+		delta.Delta = delta.FollowUpAvg - delta.BaselineAvg
+		if delta.BaselineAvg != 0 {
+			rate := delta.Delta / delta.BaselineAvg * 100
+			delta.ChangeRate = &rate
+		}
+		deltas = append(deltas, delta)
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].Name < deltas[j].Name })
+	return deltas
+}
+
+// buildComparisonPrompt 将对比结果整理为报告提示词的一部分
+func buildComparisonPrompt(comparison *HealingComparison) string {
+	if comparison == nil {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("**疗愈前后对比数据：**\n\n")
+	if comparison.Skill != "" {
+		builder.WriteString(fmt.Sprintf("- **对比技能：** %s\n", comparison.Skill))
+	}
+	builder.WriteString(fmt.Sprintf("- **基线阶段：** %s，共 %d 条记录、%d 个媒体文件\n",
+		formatComparisonPeriod(comparison.BaselinePeriod), countSide(comparison.Pairs, true), len(comparison.BaselineMedia)))
+	builder.WriteString(fmt.Sprintf("- **跟进阶段：** %s，共 %d 条记录、%d 个媒体文件\n",
+		formatComparisonPeriod(comparison.FollowUpPeriod), countSide(comparison.Pairs, false), len(comparison.FollowUpMedia)))
+
+	if len(comparison.MetricDeltas) > 0 {
+		builder.WriteString("\n**指标变化：**\n")
+		for _, delta := range comparison.MetricDeltas {
+			line := fmt.Sprintf("- %s：基线均值 %.2f%s → 跟进均值 %.2f%s（变化 %+.2f",
+				delta.Name, delta.BaselineAvg, delta.Unit, delta.FollowUpAvg, delta.Unit, delta.Delta)
+			if delta.ChangeRate != nil {
+				line += fmt.Sprintf("，%+.1f%%", *delta.ChangeRate)
+			}
+			builder.WriteString(line + "）\n")
+		}
+	}
+
+	if len(comparison.Pairs) > 0 {
+		builder.WriteString("\n**配对记录：**\n")
+		for i, pair := range comparison.Pairs {
+			builder.WriteString(fmt.Sprintf("\n对比 %d\n", i+1))
+			if pair.Baseline != nil {
+				builder.WriteString(fmt.Sprintf("- 基线（%s）：%s\n", pair.Baseline.Date.Format("2006年01月02日"), pair.Baseline.Content))
+			}
+			if pair.FollowUp != nil {
+				builder.WriteString(fmt.Sprintf("- 跟进（%s）：%s\n", pair.FollowUp.Date.Format("2006年01月02日"), pair.FollowUp.Content))
+			}
+		}
+	}
+	builder.WriteString("\n**对比分析指导：** 请结合以上基线与跟进数据，具体说明疗愈前后的变化幅度和可能原因。\n\n")
+	return builder.String()
+}
+
+func formatComparisonPeriod(period ComparisonPeriod) string {
+	format := func(t *time.Time) string {
+		if t == nil {
+			return "不限"
+		}
+		return t.Format("2006年01月02日")
+	}
+	if period.Start == nil && period.End == nil {
+		return "全部已标记记录"
+	}
+	return format(period.Start) + " 至 " + format(period.End)
+}
+
+func countSide(pairs []ComparisonPair, baseline bool) int {
+	count := 0
+	for _, pair := range pairs {
+		if baseline && pair.Baseline != nil || !baseline && pair.FollowUp != nil {
+			count++
+		}
+	}
+	return count
+}
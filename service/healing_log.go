@@ -1,22 +1,84 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"melody_cure/DAO"
 	"melody_cure/model"
+	"strconv"
 	"time"
 )
 
+var ErrHealingLogNotFound = errors.New("疗愈日志不存在")
+
 type HealingLogService struct {
-	healingLogDAO *DAO.HealingLogDAO
+	healingLogDAO   *DAO.HealingLogDAO
+	logTemplateDAO  *DAO.LogTemplateDAO
+	progressService *ChildProgressService
+	planService     *TreatmentPlanService
+	semanticIndex   *SemanticIndexService
+	userDAO         *DAO.UserDAO
 }
 
-func NewHealingLogService(healingLogDAO *DAO.HealingLogDAO) *HealingLogService {
-	return &HealingLogService{healingLogDAO: healingLogDAO}
+func NewHealingLogService(healingLogDAO *DAO.HealingLogDAO, logTemplateDAO *DAO.LogTemplateDAO, progressService *ChildProgressService, planService *TreatmentPlanService, semanticIndex *SemanticIndexService, userDAO *DAO.UserDAO) *HealingLogService {
+	return &HealingLogService{
+		healingLogDAO:   healingLogDAO,
+		logTemplateDAO:  logTemplateDAO,
+		progressService: progressService,
+		planService:     planService,
+		semanticIndex:   semanticIndex,
+		userDAO:         userDAO,
+	}
 }
 
-// CreateHealingLog 创建疗愈日志
-func (s *HealingLogService) CreateHealingLog(log *model.HealingLog) error {
-	return s.healingLogDAO.CreateHealingLog(log)
+// CreateHealingLog 创建疗愈日志，使用模板时按模板校验并保存结构化答案，可同时关联治疗目标
+func (s *HealingLogService) CreateHealingLog(userID string, log *model.HealingLog) error {
+	if _, err := checkChildAccess(s.userDAO, userID, strconv.FormatUint(uint64(log.ChildArchiveID), 10)); err != nil {
+		return err
+	}
+	// UserID 字段与字符串形式的用户ID不兼容，忽略请求中的值
+	log.UserID = 0
+	if log.TemplateID != nil {
+		template, err := s.logTemplateDAO.GetLogTemplateByID(*log.TemplateID)
+		if err != nil || !template.IsActive {
+			return invalidRequest("日志模板不存在或已停用")
+		}
+		answers, err := normalizeTemplateAnswers(template, log.TemplateAnswers)
+		if err != nil {
+			return err
+		}
+		log.TemplateAnswers = answers
+	} else {
+		log.TemplateAnswers = nil
+	}
+
+	// 量表答案同时作为量化指标保存，便于统计趋势
+	now := time.Now()
+	for _, answer := range log.TemplateAnswers {
+		if score, ok := answer.Value.(float64); ok && answer.Type == model.TemplateItemScale {
+			log.Metrics = append(log.Metrics, model.LogMetric{Name: answer.Label, Value: score})
+		}
+	}
+	for i := range log.Metrics {
+		log.Metrics[i].ChildArchiveID = log.ChildArchiveID
+		if log.Metrics[i].RecordedAt.IsZero() {
+			log.Metrics[i].RecordedAt = now
+		}
+	}
+
+	if err := s.healingLogDAO.CreateHealingLog(log); err != nil {
+		return err
+	}
+	s.progressService.RefreshByChildID(log.ChildArchiveID)
+	s.indexHealingLog(log)
+
+	childArchiveID := strconv.FormatUint(uint64(log.ChildArchiveID), 10)
+	if err := s.planService.TagRecord(childArchiveID, model.GoalLinkHealingLog, log.ID, log.GoalIDs); err != nil {
+		return fmt.Errorf("日志已保存，但关联目标失败: %w", err)
+	}
+	return nil
 }
 
 // GetHealingLogsByChildID 获取指定儿童的所有疗愈日志
@@ -29,9 +91,50 @@ func (s *HealingLogService) GetHealingLogByID(logID uint) (*model.HealingLog, er
 	return s.healingLogDAO.GetHealingLogByID(logID)
 }
 
-// DeleteHealingLog 删除疗愈日志
-func (s *HealingLogService) DeleteHealingLog(logID uint) error {
-	return s.healingLogDAO.DeleteHealingLog(logID)
+// DeleteHealingLog 删除疗愈日志，只有可以访问该儿童档案的用户可以删除
+func (s *HealingLogService) DeleteHealingLog(userID string, logID uint) error {
+	log, err := s.healingLogDAO.GetHealingLogByID(logID)
+	if err != nil {
+		return ErrHealingLogNotFound
+	}
+	if _, err := checkChildAccess(s.userDAO, userID, strconv.FormatUint(uint64(log.ChildArchiveID), 10)); err != nil {
+		return err
+	}
+	if err := s.healingLogDAO.DeleteHealingLog(logID); err != nil {
+		return err
+	}
+	if err := s.planService.RemoveRecordLinks(model.GoalLinkHealingLog, logID); err != nil {
+		return err
+	}
+	s.progressService.RefreshByChildID(log.ChildArchiveID)
+	if err := s.semanticIndex.RemoveHealingLog(logID); err != nil {
+		return fmt.Errorf("日志已删除，但移除语义索引失败: %v", err)
+	}
+	return nil
+}
+
+// UpdateHealingLog 编辑疗愈日志内容并更新语义索引，只有可以访问该儿童档案的用户可以编辑
+func (s *HealingLogService) UpdateHealingLog(userID string, logID uint, content string) (*model.HealingLog, error) {
+	healingLog, err := s.healingLogDAO.GetHealingLogByID(logID)
+	if err != nil {
+		return nil, ErrHealingLogNotFound
+	}
+	if _, err := checkChildAccess(s.userDAO, userID, strconv.FormatUint(uint64(healingLog.ChildArchiveID), 10)); err != nil {
+		return nil, err
+	}
+	if err := s.healingLogDAO.UpdateHealingLogContent(logID, content); err != nil {
+		return nil, fmt.Errorf("更新日志失败: %v", err)
+	}
+	healingLog.Content = content
+	s.indexHealingLog(healingLog)
+	return healingLog, nil
+}
+
+// indexHealingLog 更新日志的语义索引，失败时只记录日志，可通过重建语义索引补建
+func (s *HealingLogService) indexHealingLog(healingLog *model.HealingLog) {
+	if err := s.semanticIndex.IndexHealingLog(context.Background(), healingLog); err != nil {
+		log.Printf("更新疗愈日志 %d 的语义索引失败: %v", healingLog.ID, err)
+	}
 }
 
 // GetHealingLogsByChildIDWithDateFilter 获取指定儿童的疗愈日志，支持日期筛选
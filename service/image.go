@@ -42,23 +42,11 @@ func (s *ImageService) GenerateQiniuUploadToken() (*QiniuUploadToken, error) {
 		"returnBody": `{"key":"$(key)","hash":"$(etag)","fsize":$(fsize),"bucket":"$(bucket)","name":"$(x:name)"}`,
 	}
 	
-	// 将策略转换为JSON
-	putPolicyJSON, err := json.Marshal(putPolicy)
+	token, err := qiniuUploadToken(s.qiniuConfig, putPolicy)
 	if err != nil {
 		return nil, err
 	}
 	
-	// Base64编码策略
-	encodedPutPolicy := base64.URLEncoding.EncodeToString(putPolicyJSON)
-	
-	// 使用HMAC-SHA1签名
-	h := hmac.New(sha1.New, []byte(s.qiniuConfig.SecretKey))
-	h.Write([]byte(encodedPutPolicy))
-	sign := base64.URLEncoding.EncodeToString(h.Sum(nil))
-	
-	// 生成最终token
-	token := s.qiniuConfig.AccessKey + ":" + sign + ":" + encodedPutPolicy
-	
 	return &QiniuUploadToken{
 		Token:     token,
 		Domain:    s.qiniuConfig.Domain,
@@ -66,4 +54,24 @@ func (s *ImageService) GenerateQiniuUploadToken() (*QiniuUploadToken, error) {
 		ExpiresAt: deadline,
 		UseHTTPS:  s.qiniuConfig.UseHTTPS,
 	}, nil
-}
\ No newline at end of file
+}
+
+// qiniuUploadToken 按上传策略生成七牛云上传token
+func qiniuUploadToken(cfg config.QiniuConfig, putPolicy map[string]interface{}) (string, error) {
+	// 将策略转换为JSON
+	putPolicyJSON, err := json.Marshal(putPolicy)
+	if err != nil {
+		return "", err
+	}
+
+	// Base64编码策略
+	encodedPutPolicy := base64.URLEncoding.EncodeToString(putPolicyJSON)
+
+	// 使用HMAC-SHA1签名
+	h := hmac.New(sha1.New, []byte(cfg.SecretKey))
+	h.Write([]byte(encodedPutPolicy))
+	sign := base64.URLEncoding.EncodeToString(h.Sum(nil))
+
+	// 生成最终token
+	return cfg.AccessKey + ":" + sign + ":" + encodedPutPolicy, nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"melody_cure/config"
+	"sync"
+	"time"
+)
+
+// 熔断器状态
+const (
+	breakerClosed   = "closed"    // 正常放行
+	breakerOpen     = "open"      // 熔断中，直接返回 ErrLLMCircuitOpen
+	breakerHalfOpen = "half_open" // 熔断到期，放行一次试探请求
+)
+
+// circuitBreaker 统计提供商连续的限流、不可用和超时错误，达到阈值后熔断一段时间
+type circuitBreaker struct {
+	mu          sync.Mutex
+	state       string
+	failures    int
+	openedUntil time.Time
+	threshold   int
+	openFor     time.Duration
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	cfg := config.GetAIConfig().CircuitBreaker
+	b := &circuitBreaker{
+		state:     breakerClosed,
+		threshold: cfg.FailureThreshold,
+		openFor:   time.Duration(cfg.OpenSeconds) * time.Second,
+	}
+	if b.threshold <= 0 {
+		b.threshold = 5
+	}
+	if b.openFor <= 0 {
+		b.openFor = time.Minute
+	}
+	return b
+}
+
+// allow 判断是否放行请求，熔断中返回剩余的熔断时间
+func (b *circuitBreaker) allow(now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if now.Before(b.openedUntil) {
+			return false, b.openedUntil.Sub(now)
+		}
+		b.state = breakerHalfOpen
+		return true, 0
+	case breakerHalfOpen:
+		// 试探请求尚未结束，其余请求继续熔断
+		return false, b.openFor
+	}
+	return true, 0
+}
+
+// record 记录请求结果，返回熔断器是否因此进入熔断
+func (b *circuitBreaker) record(err error, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case err == nil:
+		b.state = breakerClosed
+		b.failures = 0
+		return false
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		// 调用方取消，试探未完成，下一个请求重新试探
+		if b.state == breakerHalfOpen {
+			b.state = breakerOpen
+			b.openedUntil = now
+		}
+		return false
+	case !isRetryableLLMError(err):
+		// 提供商有响应（如请求被拒绝），与可用性无关
+		if b.state == breakerHalfOpen {
+			b.state = breakerClosed
+		}
+		return false
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedUntil = now.Add(b.openFor)
+		b.failures = 0
+		return true
+	}
+	return false
+}
+
+// CircuitBreakerLLMProvider 提供商连续失败后熔断，熔断期间直接返回 ErrLLMCircuitOpen，
+// 由 FallbackLLMProvider 切换到备用提供商，或由报告任务队列在熔断结束后重试
+type CircuitBreakerLLMProvider struct {
+	provider LLMProvider
+	breaker  *circuitBreaker
+}
+
+func NewCircuitBreakerLLMProvider(provider LLMProvider) *CircuitBreakerLLMProvider {
+	return &CircuitBreakerLLMProvider{provider: provider, breaker: newCircuitBreaker()}
+}
+
+func (p *CircuitBreakerLLMProvider) Name() string {
+	return p.provider.Name()
+}
+
+func (p *CircuitBreakerLLMProvider) Chat(ctx context.Context, req *LLMRequest) (*LLMResponse, error) {
+	if err := p.before(); err != nil {
+		return nil, err
+	}
+	resp, err := p.provider.Chat(ctx, req)
+	p.after(ctx, err)
+	return resp, err
+}
+
+func (p *CircuitBreakerLLMProvider) ChatStream(ctx context.Context, req *LLMRequest, onDelta LLMStreamHandler) (*LLMResponse, error) {
+	if err := p.before(); err != nil {
+		return nil, err
+	}
+	resp, err := chatStream(ctx, p.provider, req, onDelta)
+	p.after(ctx, err)
+	return resp, err
+}
+
+func (p *CircuitBreakerLLMProvider) before() error {
+	if ok, wait := p.breaker.allow(time.Now()); !ok {
+		return &LLMError{Kind: ErrLLMCircuitOpen, Provider: p.Name(), RetryAfter: wait}
+	}
+	return nil
+}
+
+func (p *CircuitBreakerLLMProvider) after(ctx context.Context, err error) {
+	if err != nil && ctx.Err() != nil {
+		// 调用方取消或超时，不计入提供商的失败
+		err = ctx.Err()
+	}
+	if p.breaker.record(err, time.Now()) {
+		log.Printf("AI提供商 %s 连续失败，熔断 %s: %v", p.Name(), p.breaker.openFor, err)
+	}
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"melody_cure/config"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 大模型调用失败的类型，控制器据此返回对应的HTTP状态码
+var (
+	ErrLLMRateLimited     = errors.New("AI服务请求过于频繁")
+	ErrLLMUnavailable     = errors.New("AI服务暂时不可用")
+	ErrLLMTimeout         = errors.New("AI服务响应超时")
+	ErrLLMCircuitOpen     = errors.New("AI服务连续失败，已暂停调用")
+	ErrLLMAuth            = errors.New("AI服务认证失败")
+	ErrLLMBadRequest      = errors.New("AI服务拒绝了请求")
+	ErrLLMInvalidResponse = errors.New("AI服务响应无效")
+)
+
+// LLMError 大模型调用错误，Kind 为上面的错误类型之一
+type LLMError struct {
+	Kind       error
+	Provider   string
+	StatusCode int           // 上游HTTP状态码，未收到响应时为0
+	RetryAfter time.Duration // 建议的重试等待时间，未知时为0
+	Message    string
+}
+
+func (e *LLMError) Error() string {
+	msg := fmt.Sprintf("%s(%s)", e.Kind.Error(), e.Provider)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf("，状态码: %d", e.StatusCode)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *LLMError) Unwrap() error {
+	return e.Kind
+}
+
+// IsTransientLLMError 是否为稍后重试可能成功的错误（限流、服务不可用、超时、熔断）
+func IsTransientLLMError(err error) bool {
+	return errors.Is(err, ErrLLMRateLimited) || errors.Is(err, ErrLLMUnavailable) ||
+		errors.Is(err, ErrLLMTimeout) || errors.Is(err, ErrLLMCircuitOpen)
+}
+
+// LLMRetryAfter 返回错误建议的重试等待时间，未知时返回0
+func LLMRetryAfter(err error) time.Duration {
+	var llmErr *LLMError
+	if errors.As(err, &llmErr) {
+		return llmErr.RetryAfter
+	}
+	return 0
+}
+
+// maxLLMErrorBody 错误信息中保留的上游响应长度
+const maxLLMErrorBody = 512
+
+// llmHTTPClient 各提供商共用的HTTP客户端：传递调用方的 context，
+// 对 429、5xx 和网络错误按带随机抖动的指数退避重试，并遵循 Retry-After
+type llmHTTPClient struct {
+	client      *http.Client
+	timeout     time.Duration // 非流式请求每次尝试的超时时间，流式请求只限制等待响应头的时间
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+func newLLMHTTPClient(timeout time.Duration) *llmHTTPClient {
+	retry := config.GetAIConfig().Retry
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	c := &llmHTTPClient{
+		client:      &http.Client{Transport: transport},
+		timeout:     timeout,
+		maxAttempts: retry.MaxAttempts,
+		baseDelay:   time.Duration(retry.BaseDelay) * time.Millisecond,
+		maxDelay:    time.Duration(retry.MaxDelay) * time.Second,
+	}
+	if c.maxAttempts <= 0 {
+		c.maxAttempts = 1
+	}
+	if c.baseDelay <= 0 {
+		c.baseDelay = 500 * time.Millisecond
+	}
+	if c.maxDelay <= 0 {
+		c.maxDelay = 30 * time.Second
+	}
+	return c
+}
+
+// PostJSON 发送非流式请求并读取完整响应体，非 200 响应转换为 LLMError
+func (c *llmHTTPClient) PostJSON(ctx context.Context, provider, url string, headers map[string]string, body []byte) ([]byte, error) {
+	var data []byte
+	err := c.retry(ctx, provider, func() error {
+		attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+		resp, err := c.send(attemptCtx, provider, url, headers, body)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		data, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return transportError(attemptCtx, provider, fmt.Errorf("读取响应失败: %v", err))
+		}
+		return nil
+	})
+	return data, err
+}
+
+// OpenStream 发送流式请求，成功时返回响应由调用方读取并关闭。只在收到响应前重试，已开始读取后不再重试
+func (c *llmHTTPClient) OpenStream(ctx context.Context, provider, url string, headers map[string]string, body []byte) (*http.Response, error) {
+	var resp *http.Response
+	err := c.retry(ctx, provider, func() error {
+		var err error
+		resp, err = c.send(ctx, provider, url, headers, body)
+		return err
+	})
+	return resp, err
+}
+
+// send 发送一次请求，非 200 响应读取响应体后转换为 LLMError
+func (c *llmHTTPClient) send(ctx context.Context, provider, url string, headers map[string]string, body []byte) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, transportError(ctx, provider, err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxLLMErrorBody))
+	return nil, &LLMError{
+		Kind:       statusErrorKind(resp.StatusCode),
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Message:    string(data),
+	}
+}
+
+// retry 执行请求，可重试的错误按退避间隔重试。等待时间超过上限或 context 截止时间时直接返回，
+// 由上层切换备用提供商或由任务队列稍后重试
+func (c *llmHTTPClient) retry(ctx context.Context, provider string, attempt func() error) error {
+	for i := 1; ; i++ {
+		err := attempt()
+		if err == nil {
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if i >= c.maxAttempts || !isRetryableLLMError(err) {
+			return err
+		}
+
+		delay := c.backoff(i)
+		if retryAfter := LLMRetryAfter(err); retryAfter > delay {
+			delay = retryAfter
+		}
+		if delay > c.maxDelay {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		log.Printf("AI提供商 %s 第 %d 次请求失败，%s 后重试: %v", provider, i, delay.Round(time.Millisecond), err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff 第 attempt 次失败后的等待时间：基础间隔 * 2^(attempt-1)，在 [一半, 全部] 之间随机取值，避免多个副本同时重试
+func (c *llmHTTPClient) backoff(attempt int) time.Duration {
+	delay := c.baseDelay
+	for i := 1; i < attempt && delay < c.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > c.maxDelay {
+		delay = c.maxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// isRetryableLLMError 单次调用内可重试的错误，熔断不在此重试
+func isRetryableLLMError(err error) bool {
+	return errors.Is(err, ErrLLMRateLimited) || errors.Is(err, ErrLLMUnavailable) || errors.Is(err, ErrLLMTimeout)
+}
+
+// transportError 将网络错误转换为 LLMError，调用方取消时原样返回
+func transportError(ctx context.Context, provider string, err error) error {
+	if ctx.Err() == context.Canceled {
+		return err
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &LLMError{Kind: ErrLLMTimeout, Provider: provider, Message: err.Error()}
+	}
+	return &LLMError{Kind: ErrLLMUnavailable, Provider: provider, Message: err.Error()}
+}
+
+func statusErrorKind(status int) error {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrLLMRateLimited
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrLLMAuth
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return ErrLLMTimeout
+	case status >= 500:
+		return ErrLLMUnavailable
+	}
+	return ErrLLMBadRequest
+}
+
+// parseRetryAfter 解析 Retry-After 响应头，支持秒数和HTTP日期
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// invalidLLMResponse 上游返回了无法使用的响应（格式错误、内容为空或响应体中的错误）
+func invalidLLMResponse(provider, format string, args ...interface{}) error {
+	return &LLMError{Kind: ErrLLMInvalidResponse, Provider: provider, Message: fmt.Sprintf(format, args...)}
+}
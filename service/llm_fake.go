@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"melody_cure/config"
+	"melody_cure/model"
+	"strings"
+	"time"
+)
+
+// fakeStreamChunkSize 模拟流式输出时每个数据块的字符数
+const fakeStreamChunkSize = 16
+
+// FakeLLMProvider 确定性的模拟提供商，未配置API密钥、离线开发或测试时使用。报告内容由模板根据请求中的
+// 真实记录统计生成（记录数、时间范围、高频关键词、指标变化），相同的种子和请求总是返回相同的内容
+type FakeLLMProvider struct {
+	Seed int64 // 随机种子，用于选择措辞
+}
+
+func NewFakeLLMProvider() *FakeLLMProvider {
+	return &FakeLLMProvider{Seed: config.GetAIConfig().MockSeed}
+}
+
+func (p *FakeLLMProvider) Name() string {
+	return LLMProviderFake
+}
+
+func (p *FakeLLMProvider) Chat(ctx context.Context, req *LLMRequest) (*LLMResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if req.ReportType == safetyClassifierReportType {
+		// 模拟分类器不识别风险内容，关键词和正则规则仍然生效
+		return &LLMResponse{Content: `{"findings":[]}`, Provider: p.Name(), Model: "fake"}, nil
+	}
+
+	content := localizedMock(mockPlainContents, req.Language)
+	switch {
+	case req.ReportType == translationReportType:
+		content = mockTranslation(req)
+	case req.ReportType == companionChatReportType:
+		content = mockCompanionReply(req, p.Seed)
+	case req.ReportType == companionMemoryReportType:
+		content = mockCompanionMemory(req)
+	case req.ReportType == consultationReportType:
+		content = mockConsultationReply(req, p.Seed)
+	case req.ReportType != "":
+		content = mockDataReport(req, p.Seed)
+	}
+	if req.JSONMode {
+		content = mockStructuredReport(content, req)
+	}
+	modelName := req.Model
+	if modelName == "" {
+		modelName = "fake"
+	}
+	return &LLMResponse{Content: content, Provider: p.Name(), Model: modelName}, nil
+}
+
+// ChatStream 将模拟内容按固定长度分块输出，用于在没有真实模型时演示流式生成
+func (p *FakeLLMProvider) ChatStream(ctx context.Context, req *LLMRequest, onDelta LLMStreamHandler) (*LLMResponse, error) {
+	resp, err := p.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(resp.Content)
+	for start := 0; start < len(runes); start += fakeStreamChunkSize {
+		end := start + fakeStreamChunkSize
+		if end > len(runes) {
+			end = len(runes)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+		}
+		if err := onDelta(string(runes[start:end])); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// localizedMock 按语言选择模拟内容，没有对应语言的内容时使用中文
+func localizedMock(contents map[string]string, language string) string {
+	if content, ok := contents[language]; ok {
+		return content
+	}
+	return contents[DefaultReportLanguage]
+}
+
+// mockPlainContents 未识别报告类型时的模拟内容
+var mockPlainContents = map[string]string{
+	"zh": "AI生成的模拟内容",
+	"en": "AI-generated mock content",
+}
+
+// mockTranslationNotices 模拟翻译时附在原文前的说明
+var mockTranslationNotices = map[string]string{
+	"zh": "> 模拟翻译：未配置AI服务，以下为原文。",
+	"en": "> Mock translation: no AI service is configured, the original text follows.",
+}
+
+// mockTranslation 模拟翻译，不翻译原文，只在前面附加目标语言的说明
+func mockTranslation(req *LLMRequest) string {
+	original := ""
+	if len(req.Messages) > 0 {
+		original = req.Messages[len(req.Messages)-1].Content
+	}
+	return localizedMock(mockTranslationNotices, req.Language) + "\n\n" + original
+}
+
+// mockStructuredTexts 模拟结构化报告没有标题时使用的文字
+var mockStructuredTexts = map[string]string{
+	"zh": "AI生成的模拟报告",
+	"en": "AI-generated mock report",
+}
+
+// mockStructuredReport 将模拟的 Markdown 报告按标题拆分为结构化报告，变化较大的指标列为风险提示
+func mockStructuredReport(markdown string, req *LLMRequest) string {
+	fallbackTitle := localizedMock(mockStructuredTexts, req.Language)
+	report := model.StructuredReport{
+		Sections:              []model.ReportSection{},
+		RiskFlags:             mockRiskFlags(req),
+		RecommendedActivities: []model.RecommendedActivity{},
+	}
+	for _, line := range strings.Split(markdown, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# "):
+			report.Title = strings.TrimPrefix(line, "# ")
+		case strings.HasPrefix(line, "## "):
+			report.Sections = append(report.Sections, model.ReportSection{Heading: strings.TrimPrefix(line, "## ")})
+		case len(report.Sections) > 0:
+			report.Sections[len(report.Sections)-1].Content += line + "\n"
+		}
+	}
+	if report.Title == "" {
+		report.Title = fallbackTitle
+	}
+	if len(report.Sections) == 0 {
+		report.Sections = append(report.Sections, model.ReportSection{Heading: fallbackTitle, Content: markdown})
+	}
+	for i := range report.Sections {
+		report.Sections[i].Content = strings.TrimSpace(report.Sections[i].Content)
+	}
+	// 摘要取第一段正文，跳过列表项
+	report.Summary = strings.SplitN(report.Sections[0].Content, "\n", 2)[0]
+	for _, section := range report.Sections {
+		if line := strings.SplitN(section.Content, "\n", 2)[0]; line != "" && !strings.HasPrefix(line, "- ") {
+			report.Summary = line
+			break
+		}
+	}
+
+	data, _ := json.Marshal(report)
+	return string(data)
+}
+
+// mockCompanionReplies 模拟陪伴回复，%s 为儿童消息的开头
+var mockCompanionReplies = []string{
+	"我听到你说“%s”。能再多告诉我一点吗？",
+	"谢谢你告诉我“%s”！那时候你的心情是怎样的呢？",
+	"哇，“%s”，听起来很有意思！后来发生了什么？",
+	"“%s”——我记住啦。你最喜欢其中的哪一部分呀？",
+}
+
+// mockCompanionReply 模拟陪伴回复，复述儿童消息的开头并追问，相同的种子和对话总是选择相同的回复
+func mockCompanionReply(req *LLMRequest, seed int64) string {
+	last := ""
+	if len(req.Messages) > 0 {
+		last = req.Messages[len(req.Messages)-1].Content
+	}
+	rng := rand.New(rand.NewSource(mockSeed(seed, req)))
+	return fmt.Sprintf(mockCompanionReplies[rng.Intn(len(mockCompanionReplies))], truncateRunes(strings.TrimSpace(last), 20))
+}
+
+// mockCompanionMemory 模拟记忆摘要：保留已有记忆，追加新对话中儿童说过的话
+func mockCompanionMemory(req *LLMRequest) string {
+	prompt := ""
+	if len(req.Messages) > 0 {
+		prompt = req.Messages[len(req.Messages)-1].Content
+	}
+	var memory string
+	var topics []string
+	for _, line := range strings.Split(prompt, "\n") {
+		switch {
+		case strings.HasPrefix(line, "已有记忆："):
+			if existing := strings.TrimPrefix(line, "已有记忆："); existing != "无" {
+				memory = existing
+			}
+		case strings.HasPrefix(line, "儿童："):
+			topics = append(topics, truncateRunes(strings.TrimPrefix(line, "儿童："), 20))
+		}
+	}
+	if len(topics) > 0 {
+		memory += "孩子聊到：" + strings.Join(topics, "；") + "。"
+	}
+	// 只保留最近的记忆
+	if runes := []rune(memory); len(runes) > 300 {
+		memory = string(runes[len(runes)-300:])
+	}
+	return memory
+}
+
+// mockConsultationAdvice 模拟咨询回复的建议部分
+var mockConsultationAdvice = []string{
+	"建议继续按现在的节奏记录，重点留意这类情况出现前后的环境变化，下次咨询时我们可以一起对比。",
+	"可以先在家中保持固定的作息和训练时间，情况有变化时及时记录下来。",
+	"建议把观察到的变化记录得更具体一些，比如持续时间和当时的活动，便于判断趋势。",
+}
+
+// mockConsultationReply 模拟咨询回复：逐条复述检索到的资料并标注引用编号，儿童档案放在最后，
+// 相同的种子和对话总是选择相同的建议
+func mockConsultationReply(req *LLMRequest, seed int64) string {
+	prompt := ""
+	if len(req.Messages) > 0 {
+		prompt = req.Messages[len(req.Messages)-1].Content
+	}
+	question := ""
+	var references []string
+	for _, line := range strings.Split(prompt, "\n") {
+		switch {
+		case strings.HasPrefix(line, "家长的问题："):
+			question = strings.TrimPrefix(line, "家长的问题：")
+		case strings.HasPrefix(line, "["):
+			references = append(references, line)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "关于“%s”，我参考了孩子的资料：\n\n", truncateRunes(strings.TrimSpace(question), 30))
+	cited := 0
+	for _, reference := range append(references[min(1, len(references)):], references[:min(1, len(references))]...) {
+		end := strings.Index(reference, "] ")
+		if end < 0 || cited == 3 {
+			continue
+		}
+		label, text, _ := strings.Cut(reference[end+2:], "：")
+		fmt.Fprintf(&b, "- %s：%s %s\n", label, truncateRunes(strings.TrimSpace(text), 40), reference[:end+1])
+		cited++
+	}
+	if cited == 0 {
+		b.WriteString("- 目前还没有可以参考的记录。\n")
+	}
+	rng := rand.New(rand.NewSource(mockSeed(seed, req)))
+	fmt.Fprintf(&b, "\n%s如果情况持续或加重，可以把这次咨询转给认证康复师做进一步评估。", mockConsultationAdvice[rng.Intn(len(mockConsultationAdvice))])
+	return b.String()
+}
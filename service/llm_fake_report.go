@@ -0,0 +1,781 @@
+package service
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"melody_cure/model"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// mockKeywordLimit 模拟报告列出的高频关键词数量
+const mockKeywordLimit = 5
+
+// mockReportStats 模拟提供商从提示词数据中统计的记录概况
+type mockReportStats struct {
+	LogCount   int
+	First      time.Time
+	Last       time.Time
+	SpanDays   int         // 首末记录跨越的天数（含首尾）
+	ActiveDays int         // 有记录的天数，只有分段摘要时为0
+	PerWeek    float64     // 平均每周记录数
+	Media      []mockCount // 附件类型及数量
+	Keywords   []mockCount // 高频关键词及出现的记录数
+	Metrics    []mockMetricTrend
+	Logs       []ReportPromptLog
+	Windows    []ReportPromptWindow
+}
+
+// mockCount 名称及计数
+type mockCount struct {
+	Name  string
+	Count int
+}
+
+// mockMetricTrend 指标汇总及首末变化
+type mockMetricTrend struct {
+	ReportPromptMetric
+	Change    float64 // 最近一次与首次记录的差值
+	ChangePct float64 // 变化百分比，首次记录为0时为0
+	Trend     int     // 1 上升，-1 下降，0 基本持平
+}
+
+// newMockReportStats 统计提示词数据，数据为空时返回零值统计
+func newMockReportStats(data *ReportPromptData) *mockReportStats {
+	stats := &mockReportStats{}
+	if data == nil {
+		return stats
+	}
+	stats.LogCount = data.LogCount
+	stats.First, stats.Last = data.FirstLogAt, data.LastLogAt
+	stats.Logs = data.Logs
+	stats.Windows = data.WindowSummaries
+	if stats.LogCount == 0 {
+		stats.LogCount = len(data.Logs)
+	}
+	if stats.LogCount == 0 {
+		return stats
+	}
+
+	first := time.Date(stats.First.Year(), stats.First.Month(), stats.First.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(stats.Last.Year(), stats.Last.Month(), stats.Last.Day(), 0, 0, 0, 0, time.UTC)
+	stats.SpanDays = int(last.Sub(first).Hours()/24) + 1
+	weeks := math.Max(float64(stats.SpanDays)/7, 1)
+	stats.PerWeek = roundTo(float64(stats.LogCount)/weeks, 1)
+
+	days := make(map[string]bool)
+	media := make(map[string]int)
+	for _, entry := range data.Logs {
+		days[entry.Time.Format("2006-01-02")] = true
+		for _, mediaType := range entry.MediaTypes {
+			media[mediaType]++
+		}
+	}
+	stats.ActiveDays = len(days)
+	for name, count := range media {
+		stats.Media = append(stats.Media, mockCount{Name: name, Count: count})
+	}
+	sortMockCounts(stats.Media)
+	stats.Keywords = extractMockKeywords(data.Logs, mockKeywordLimit)
+
+	for _, metric := range data.Metrics {
+		trend := mockMetricTrend{ReportPromptMetric: metric, Change: metric.Last - metric.First}
+		if metric.First != 0 {
+			trend.ChangePct = trend.Change / math.Abs(metric.First) * 100
+		}
+		// 变化小于首次记录或波动区间的5%视为持平
+		threshold := math.Max(math.Abs(metric.First), metric.Max-metric.Min) * 0.05
+		switch {
+		case metric.Count < 2 || math.Abs(trend.Change) <= threshold:
+			trend.Trend = 0
+		case trend.Change > 0:
+			trend.Trend = 1
+		default:
+			trend.Trend = -1
+		}
+		stats.Metrics = append(stats.Metrics, trend)
+	}
+	return stats
+}
+
+// trendCounts 上升、下降、持平的指标数量
+func (s *mockReportStats) trendCounts() (up, down, flat int) {
+	for _, metric := range s.Metrics {
+		switch metric.Trend {
+		case 1:
+			up++
+		case -1:
+			down++
+		default:
+			flat++
+		}
+	}
+	return up, down, flat
+}
+
+func sortMockCounts(counts []mockCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		if li, lj := len([]rune(counts[i].Name)), len([]rune(counts[j].Name)); li != lj {
+			return li > lj
+		}
+		return counts[i].Name < counts[j].Name
+	})
+}
+
+// mockSeed 由配置的种子和请求内容计算随机种子，相同的种子和数据总是选择相同的措辞
+func mockSeed(seed int64, req *LLMRequest) int64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(seed))
+	h.Write(buf[:])
+	h.Write([]byte(req.ReportType + "\x00" + req.Language + "\x00"))
+	if data := req.PromptData; data != nil {
+		h.Write([]byte(data.Child.ID + "\x00"))
+		for _, entry := range data.Logs {
+			binary.LittleEndian.PutUint64(buf[:], uint64(entry.Time.Unix()))
+			h.Write(buf[:])
+			h.Write([]byte(entry.Content + "\x00"))
+		}
+		for _, window := range data.WindowSummaries {
+			h.Write([]byte(window.Summary + "\x00"))
+		}
+	} else if n := len(req.Messages); n > 0 {
+		// 没有提示词数据的对话请求按最后一条消息区分
+		h.Write([]byte(req.Messages[n-1].Content))
+	}
+	return int64(h.Sum64())
+}
+
+var (
+	// mockPlaceholderPattern 脱敏占位符，不参与关键词统计
+	mockPlaceholderPattern = regexp.MustCompile(`\[[^\]]*\]`)
+	// mockStopWords 记录中常见但没有分析意义的词
+	mockStopWords = []string{"今天", "昨天", "明天", "早上", "上午", "中午", "下午", "晚上", "时候", "孩子", "儿童", "宝宝", "小朋友",
+		"老师", "家长", "妈妈", "爸爸", "然后", "因为", "所以", "但是", "已经", "可以", "一些", "一下", "一起", "比较", "非常",
+		"没有", "自己", "什么", "怎么", "这样", "那样", "有点", "还是", "就是", "现在", "开始", "进行", "一次", "一个", "继续"}
+	mockEnglishStopWords = map[string]bool{"the": true, "and": true, "for": true, "with": true, "was": true, "were": true,
+		"are": true, "has": true, "have": true, "had": true, "his": true, "her": true, "she": true, "him": true, "they": true,
+		"them": true, "this": true, "that": true, "then": true, "than": true, "today": true, "yesterday": true, "child": true,
+		"very": true, "but": true, "not": true, "after": true, "before": true, "when": true, "from": true, "into": true,
+		"also": true, "did": true, "does": true, "out": true, "about": true, "again": true, "some": true, "more": true,
+		"our": true, "its": true, "been": true, "will": true, "would": true, "could": true, "can": true, "just": true,
+		"there": true, "their": true, "what": true, "which": true, "who": true}
+)
+
+// mockStopChars 中文虚词，关键词不跨越这些字
+const mockStopChars = "的了着过是在和与及也都很就还又把被我你他她它们这那吗呢吧啊呀个得地"
+
+// extractMockKeywords 按出现的记录数统计高频关键词。中文取 2~6 字的片段，英文取单词；
+// 被出现次数相同的更长片段包含的片段不单独列出
+func extractMockKeywords(logs []ReportPromptLog, limit int) []mockCount {
+	counts := make(map[string]int)
+	for _, entry := range logs {
+		texts := []string{entry.Content}
+		for _, answer := range entry.Answers {
+			texts = append(texts, answer.Value)
+		}
+		seen := make(map[string]bool)
+		for _, term := range mockTerms(strings.Join(texts, "\n")) {
+			if !seen[term] {
+				seen[term] = true
+				counts[term]++
+			}
+		}
+	}
+
+	var candidates []mockCount
+	for term, count := range counts {
+		if count >= 2 {
+			candidates = append(candidates, mockCount{Name: term, Count: count})
+		}
+	}
+	var keywords []mockCount
+	for _, candidate := range candidates {
+		covered := false
+		for _, other := range candidates {
+			if len(other.Name) > len(candidate.Name) && other.Count >= candidate.Count && strings.Contains(other.Name, candidate.Name) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			keywords = append(keywords, candidate)
+		}
+	}
+	sortMockCounts(keywords)
+	if len(keywords) > limit {
+		keywords = keywords[:limit]
+	}
+	return keywords
+}
+
+// mockTerms 将文本切分为候选关键词
+func mockTerms(text string) []string {
+	text = mockPlaceholderPattern.ReplaceAllString(text, " ")
+	for _, word := range mockStopWords {
+		text = strings.ReplaceAll(text, word, " ")
+	}
+
+	var terms []string
+	var run, word []rune
+	flushRun := func() {
+		for n := 2; n <= 6; n++ {
+			for i := 0; i+n <= len(run); i++ {
+				terms = append(terms, string(run[i:i+n]))
+			}
+		}
+		run = run[:0]
+	}
+	flushWord := func() {
+		if w := strings.ToLower(string(word)); len(word) >= 3 && !mockEnglishStopWords[w] {
+			terms = append(terms, w)
+		}
+		word = word[:0]
+	}
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r) && !strings.ContainsRune(mockStopChars, r):
+			flushWord()
+			run = append(run, r)
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			flushRun()
+			word = append(word, r)
+		default:
+			flushRun()
+			flushWord()
+		}
+	}
+	flushRun()
+	flushWord()
+	return terms
+}
+
+// mockPhrases 模拟报告的措辞，多个候选时按随机种子选择
+type mockPhrases struct {
+	Titles map[string]string
+
+	Overview, Keywords, Metrics, Observations, Conclusion string
+	Basis, Suggestions, Family                            string
+	Baseline, Trajectory, Milestones                      string
+
+	Child, Range, Count, CountNoDays, Media, MediaItem string
+	NoLogs                                             string
+	Separator                                          string
+	KeywordItem, NoKeywords                            string
+	KeywordLeads                                       []string
+	MetricLine, Up, Down, Flat, NoMetrics              string
+	EarliestLog, LatestLog                             string
+	Windows, WindowItem                                string
+	TrendCounts                                        string
+	Closings                                           []string
+
+	KeywordSuggestions               []string
+	MetricDown, MetricUp, MetricFlat string
+	LowFrequency, GoodFrequency      string
+	FamilyTips                       []string
+	Halves, HalfItem                 string
+	Milestone, NoMilestone           string
+	RiskCategory, RiskDescription    string
+	WindowSummary, WindowKeywords    string
+	WindowMetrics, WindowMetricItem  string
+	MediaNames                       map[string]string
+	Units                            string // 数值与单位之间的分隔
+	SentenceSep                      string // 句子之间的分隔
+	DefaultTitle                     string
+}
+
+var mockReportPhrases = map[string]*mockPhrases{
+	"zh": {
+		Titles: map[string]string{
+			"summary":    "儿童疗愈总结报告",
+			"suggestion": "儿童疗愈建议报告",
+			"progress":   "儿童疗愈进度分析报告",
+		},
+		Overview: "记录概况", Keywords: "高频关注点", Metrics: "指标变化", Observations: "记录摘录", Conclusion: "阶段性总结",
+		Basis: "数据依据", Suggestions: "训练建议", Family: "家庭配合",
+		Baseline: "基线与最近对比", Trajectory: "发展轨迹", Milestones: "里程碑",
+
+		Child:       "- 儿童：%s，%d岁",
+		Range:       "- 记录时间：%s 至 %s（跨度 %d 天）",
+		Count:       "- 疗愈记录：共 %d 条，覆盖 %d 天，平均每周 %s 条",
+		CountNoDays: "- 疗愈记录：共 %d 条，平均每周 %s 条",
+		Media:       "- 附件：%s",
+		MediaItem:   "%[1]s %[2]d 个",
+		NoLogs:      "所选时间段内暂无疗愈记录，无法进行基于数据的分析。建议先坚持记录日常疗愈情况，再生成报告。",
+		Separator:   "、",
+		KeywordItem: "「%s」（%d 条记录）",
+		NoKeywords:  "记录内容较少，暂未发现反复出现的关键词。",
+		KeywordLeads: []string{
+			"记录中反复出现的内容是：%s。",
+			"从记录文字看，最常被提到的是：%s。",
+			"家长和康复师在记录中最常提到：%s。",
+		},
+		MetricLine:  "- **%s**：首次 %s，最近 %s（%s），平均 %s，区间 %s ~ %s，共 %d 次记录",
+		Up:          "上升 %s",
+		Down:        "下降 %s",
+		Flat:        "基本持平",
+		NoMetrics:   "本时间段内没有记录量化指标。",
+		EarliestLog: "- 最早的记录（%s）：%s",
+		LatestLog:   "- 最近的记录（%s）：%s",
+		Windows:     "记录较多，按 %d 个时间段汇总：",
+		WindowItem:  "- %s 至 %s（%d 条）：%s",
+		TrendCounts: "本阶段共记录 %d 项指标，其中 %d 项上升、%d 项下降、%d 项基本持平。",
+		Closings: []string{
+			"建议继续保持记录频率，结合指标变化与康复师讨论下一阶段的训练重点。",
+			"以上结论基于记录数据自动统计，建议与康复师一起结合日常观察进行解读。",
+			"建议在下一阶段保持当前的记录方式，便于持续比较各项变化。",
+		},
+		KeywordSuggestions: []string{
+			"- 围绕「%s」继续安排有针对性的练习，并在记录中写明当时的情境和孩子的反应",
+			"- 「%s」在记录中出现较多，可与康复师讨论是否将其作为下一阶段的重点目标",
+			"- 针对「%s」相关的情境，提前做好预告和准备，记录哪些做法更有效",
+		},
+		MetricDown:    "- 「%s」较首次记录%s，建议结合同期记录分析原因，必要时与康复师沟通调整方案",
+		MetricUp:      "- 「%s」较首次记录%s，可保持当前的训练节奏并逐步提高要求",
+		MetricFlat:    "- 「%s」变化不大，可尝试调整练习方式或频率，观察是否带来改善",
+		LowFrequency:  "- 目前平均每周记录 %s 条，建议每周至少记录 3 次，便于更准确地评估变化",
+		GoodFrequency: "- 目前平均每周记录 %s 条，记录频率良好，请继续保持",
+		FamilyTips: []string{
+			"- 保持稳定、可预测的日常作息，变化前提前告知孩子",
+			"- 孩子完成练习后及时给予具体的表扬和鼓励",
+			"- 家庭成员之间统一引导方式，与康复师的方法保持一致",
+			"- 记录中尽量写明时间、情境和孩子的反应，便于分析变化原因",
+			"- 每天安排固定的亲子互动时间，在游戏中巩固训练内容",
+		},
+		Halves:           "按记录时间将本阶段分为前后两段：",
+		HalfItem:         "- %s 至 %s：%d 条记录，高频内容：%s",
+		Milestone:        "- 「%s」最近一次记录达到本阶段最高值 %s",
+		NoMilestone:      "本阶段暂未出现指标新高，建议继续保持记录，观察后续变化。",
+		RiskCategory:     "指标变化",
+		RiskDescription:  "「%s」较首次记录%s，变化幅度较大，需要结合记录持续观察",
+		WindowSummary:    "%s 至 %s 共 %d 条疗愈记录。",
+		WindowKeywords:   "高频内容：%s。",
+		WindowMetrics:    "指标：%s。",
+		WindowMetricItem: "%s 由 %s 变为 %s",
+		MediaNames:       map[string]string{"image": "图片", "video": "视频", "audio": "音频"},
+		Units:            "",
+		SentenceSep:      "",
+		DefaultTitle:     "AI生成的模拟报告",
+	},
+	"en": {
+		Titles: map[string]string{
+			"summary":    "Healing Summary Report",
+			"suggestion": "Rehabilitation Recommendations Report",
+			"progress":   "Progress Analysis Report",
+		},
+		Overview: "Overview", Keywords: "Recurring Topics", Metrics: "Metric Trends", Observations: "Log Excerpts", Conclusion: "Summary of This Stage",
+		Basis: "Data Basis", Suggestions: "Training Recommendations", Family: "Family Support",
+		Baseline: "Baseline vs. Latest", Trajectory: "Trajectory", Milestones: "Milestones",
+
+		Child:       "- Child: %s, age %d",
+		Range:       "- Period: %s to %s (%d days)",
+		Count:       "- Logs: %d in total on %d days, %s per week on average",
+		CountNoDays: "- Logs: %d in total, %s per week on average",
+		Media:       "- Attachments: %s",
+		MediaItem:   "%[2]d %[1]s",
+		NoLogs:      "There are no healing logs in the selected period, so no data-based analysis is possible. Keep recording daily sessions and generate the report again later.",
+		Separator:   ", ",
+		KeywordItem: "\"%s\" (%d logs)",
+		NoKeywords:  "There are too few logs to identify recurring keywords.",
+		KeywordLeads: []string{
+			"The topics that recur most often in the logs are: %s.",
+			"Judging by the log text, the most frequently mentioned topics are: %s.",
+			"Parents and therapists most often wrote about: %s.",
+		},
+		MetricLine:  "- **%s**: first %s, latest %s (%s), average %s, range %s to %s, %d records",
+		Up:          "up %s",
+		Down:        "down %s",
+		Flat:        "roughly unchanged",
+		NoMetrics:   "No quantitative metrics were recorded in this period.",
+		EarliestLog: "- Earliest log (%s): %s",
+		LatestLog:   "- Latest log (%s): %s",
+		Windows:     "There are many logs, summarized in %d periods:",
+		WindowItem:  "- %s to %s (%d logs): %s",
+		TrendCounts: "%d metrics were recorded in this stage: %d went up, %d went down and %d stayed roughly unchanged.",
+		Closings: []string{
+			"Keep up the current logging frequency and discuss the next training focus with the therapist in light of these trends.",
+			"These conclusions are computed automatically from the logs; review them with the therapist together with day-to-day observations.",
+			"Keep logging the same way in the next stage so the changes can continue to be compared.",
+		},
+		KeywordSuggestions: []string{
+			"- Keep practising around \"%s\" and note the situation and the child's response in each log",
+			"- \"%s\" comes up often; discuss with the therapist whether it should be a focus for the next stage",
+			"- Prepare the child in advance for situations involving \"%s\" and record which approaches work best",
+		},
+		MetricDown:    "- \"%s\" is %s compared with the first record; review the logs from the same period and talk to the therapist if needed",
+		MetricUp:      "- \"%s\" is %s compared with the first record; keep the current pace and raise the expectations gradually",
+		MetricFlat:    "- \"%s\" has changed little; try adjusting the type or frequency of practice and watch for improvement",
+		LowFrequency:  "- Logs are currently recorded %s times per week; aim for at least 3 per week so changes can be assessed more accurately",
+		GoodFrequency: "- Logs are currently recorded %s times per week, which is a good frequency; please keep it up",
+		FamilyTips: []string{
+			"- Keep a stable, predictable daily routine and tell the child about changes in advance",
+			"- Give specific praise and encouragement right after the child completes a practice",
+			"- Agree on a consistent approach within the family that matches the therapist's methods",
+			"- Note the time, situation and the child's response in each log to help explain changes",
+			"- Set aside a fixed time for play every day to reinforce what is practised in therapy",
+		},
+		Halves:           "Splitting this stage into two halves by log time:",
+		HalfItem:         "- %s to %s: %d logs, recurring topics: %s",
+		Milestone:        "- \"%s\" reached its highest value of this stage in the latest record: %s",
+		NoMilestone:      "No metric reached a new high in this stage; keep recording to follow further changes.",
+		RiskCategory:     "Metric change",
+		RiskDescription:  "\"%s\" is %s compared with the first record; this is a large change and should be monitored together with the logs",
+		WindowSummary:    "%d healing logs from %s to %s.",
+		WindowKeywords:   "Recurring topics: %s.",
+		WindowMetrics:    "Metrics: %s.",
+		WindowMetricItem: "%s from %s to %s",
+		MediaNames:       map[string]string{"image": "images", "video": "videos", "audio": "audio clips"},
+		Units:            " ",
+		SentenceSep:      " ",
+		DefaultTitle:     "AI-generated mock report",
+	},
+}
+
+// mockPhrasesFor 按语言选择措辞，没有对应语言时使用中文
+func mockPhrasesFor(language string) *mockPhrases {
+	if phrases, ok := mockReportPhrases[language]; ok {
+		return phrases
+	}
+	return mockReportPhrases[DefaultReportLanguage]
+}
+
+// mockReportBuilder 根据记录统计生成模拟报告
+type mockReportBuilder struct {
+	b       strings.Builder
+	p       *mockPhrases
+	rng     *rand.Rand
+	data    *ReportPromptData
+	stats   *mockReportStats
+	english bool
+}
+
+// mockDataReport 根据提示词数据中的真实记录生成模拟报告，相同的种子和数据生成相同的内容
+func mockDataReport(req *LLMRequest, seed int64) string {
+	data := req.PromptData
+	if data == nil {
+		data = &ReportPromptData{ReportType: req.ReportType}
+	}
+	r := &mockReportBuilder{
+		p:       mockPhrasesFor(req.Language),
+		rng:     rand.New(rand.NewSource(mockSeed(seed, req))),
+		data:    data,
+		stats:   newMockReportStats(data),
+		english: req.Language == "en",
+	}
+	if req.ReportType == windowSummaryReportType {
+		return r.windowSummary()
+	}
+
+	title := r.p.DefaultTitle
+	kind := ""
+	for _, key := range []string{"summary", "suggestion", "progress"} {
+		if strings.Contains(req.ReportType, key) {
+			title, kind = r.p.Titles[key], key
+			break
+		}
+	}
+	if kind == "" && data.ReportName != "" && !r.english {
+		title = data.ReportName + "报告"
+	}
+	fmt.Fprintf(&r.b, "# %s\n", title)
+
+	r.overview()
+	if r.stats.LogCount == 0 {
+		return strings.TrimRight(r.b.String(), "\n")
+	}
+	switch kind {
+	case "suggestion":
+		r.suggestions()
+	case "progress":
+		r.baseline()
+		r.trajectory()
+		r.milestones()
+		r.conclusion()
+	default:
+		r.keywords()
+		r.metrics()
+		r.observations()
+		r.conclusion()
+	}
+	return strings.TrimRight(r.b.String(), "\n")
+}
+
+func (r *mockReportBuilder) section(heading string) {
+	fmt.Fprintf(&r.b, "\n## %s\n", heading)
+}
+
+func (r *mockReportBuilder) line(format string, args ...interface{}) {
+	fmt.Fprintf(&r.b, format+"\n", args...)
+}
+
+func (r *mockReportBuilder) pick(options []string) string {
+	return options[r.rng.Intn(len(options))]
+}
+
+func (r *mockReportBuilder) date(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func (r *mockReportBuilder) value(v float64, unit string) string {
+	text := strconv.FormatFloat(roundTo(v, 2), 'f', -1, 64)
+	if unit != "" {
+		text += r.p.Units + unit
+	}
+	return text
+}
+
+// change 指标的变化描述，首次记录为0时使用差值
+func (r *mockReportBuilder) change(metric mockMetricTrend) string {
+	if metric.Trend == 0 {
+		return r.p.Flat
+	}
+	amount := r.value(math.Abs(metric.Change), metric.Unit)
+	if metric.First != 0 {
+		amount = strconv.FormatFloat(roundTo(math.Abs(metric.ChangePct), 1), 'f', -1, 64) + "%"
+	}
+	if metric.Trend > 0 {
+		return fmt.Sprintf(r.p.Up, amount)
+	}
+	return fmt.Sprintf(r.p.Down, amount)
+}
+
+func (r *mockReportBuilder) keywordList(keywords []mockCount) string {
+	if len(keywords) == 0 {
+		return "-"
+	}
+	items := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		items[i] = fmt.Sprintf(r.p.KeywordItem, keyword.Name, keyword.Count)
+	}
+	return strings.Join(items, r.p.Separator)
+}
+
+func (r *mockReportBuilder) excerpt(entry ReportPromptLog) string {
+	text := strings.TrimSpace(entry.Content)
+	if text == "" {
+		values := make([]string, 0, len(entry.Answers))
+		for _, answer := range entry.Answers {
+			values = append(values, answer.Value)
+		}
+		text = strings.Join(values, r.p.Separator)
+	}
+	return truncateRunes(strings.Join(strings.Fields(text), " "), 60)
+}
+
+func (r *mockReportBuilder) overview() {
+	stats := r.stats
+	r.section(r.p.Overview)
+	if child := r.data.Child; child.Name != "" {
+		r.line(r.p.Child, child.Name, child.Age)
+	}
+	if stats.LogCount == 0 {
+		r.line(r.p.NoLogs)
+		return
+	}
+	r.line(r.p.Range, r.date(stats.First), r.date(stats.Last), stats.SpanDays)
+	perWeek := strconv.FormatFloat(stats.PerWeek, 'f', -1, 64)
+	if stats.ActiveDays > 0 {
+		r.line(r.p.Count, stats.LogCount, stats.ActiveDays, perWeek)
+	} else {
+		r.line(r.p.CountNoDays, stats.LogCount, perWeek)
+	}
+	if len(stats.Media) > 0 {
+		items := make([]string, len(stats.Media))
+		for i, media := range stats.Media {
+			name := media.Name
+			if localized, ok := r.p.MediaNames[name]; ok {
+				name = localized
+			}
+			items[i] = fmt.Sprintf(r.p.MediaItem, name, media.Count)
+		}
+		r.line(r.p.Media, strings.Join(items, r.p.Separator))
+	}
+}
+
+func (r *mockReportBuilder) keywords() {
+	r.section(r.p.Keywords)
+	if len(r.stats.Keywords) == 0 {
+		r.line(r.p.NoKeywords)
+		return
+	}
+	r.line(r.pick(r.p.KeywordLeads), r.keywordList(r.stats.Keywords))
+}
+
+func (r *mockReportBuilder) metricLines() {
+	if len(r.stats.Metrics) == 0 {
+		r.line(r.p.NoMetrics)
+		return
+	}
+	for _, metric := range r.stats.Metrics {
+		r.line(r.p.MetricLine, metric.Name, r.value(metric.First, metric.Unit), r.value(metric.Last, metric.Unit), r.change(metric),
+			r.value(metric.Avg, metric.Unit), r.value(metric.Min, metric.Unit), r.value(metric.Max, metric.Unit), metric.Count)
+	}
+}
+
+func (r *mockReportBuilder) metrics() {
+	r.section(r.p.Metrics)
+	r.metricLines()
+}
+
+func (r *mockReportBuilder) observations() {
+	r.section(r.p.Observations)
+	if logs := r.stats.Logs; len(logs) > 0 {
+		r.line(r.p.EarliestLog, r.date(logs[0].Time), r.excerpt(logs[0]))
+		if len(logs) > 1 {
+			last := logs[len(logs)-1]
+			r.line(r.p.LatestLog, r.date(last.Time), r.excerpt(last))
+		}
+		return
+	}
+	r.windowLines()
+}
+
+func (r *mockReportBuilder) windowLines() {
+	r.line(r.p.Windows, len(r.stats.Windows))
+	for _, window := range r.stats.Windows {
+		r.line(r.p.WindowItem, r.date(window.Start), r.date(window.End), window.LogCount, truncateRunes(window.Summary, 80))
+	}
+}
+
+func (r *mockReportBuilder) conclusion() {
+	r.section(r.p.Conclusion)
+	if len(r.stats.Metrics) > 0 {
+		up, down, flat := r.stats.trendCounts()
+		r.line(r.p.TrendCounts, len(r.stats.Metrics), up, down, flat)
+	}
+	r.line(r.pick(r.p.Closings))
+}
+
+func (r *mockReportBuilder) suggestions() {
+	stats := r.stats
+	r.section(r.p.Basis)
+	if len(stats.Keywords) > 0 {
+		r.line(r.pick(r.p.KeywordLeads), r.keywordList(stats.Keywords))
+	} else {
+		r.line(r.p.NoKeywords)
+	}
+	r.b.WriteString("\n")
+	r.metricLines()
+
+	r.section(r.p.Suggestions)
+	for i, keyword := range stats.Keywords {
+		if i == 3 {
+			break
+		}
+		r.line(r.pick(r.p.KeywordSuggestions), keyword.Name)
+	}
+	for _, metric := range stats.Metrics {
+		switch metric.Trend {
+		case -1:
+			r.line(r.p.MetricDown, metric.Name, r.change(metric))
+		case 1:
+			r.line(r.p.MetricUp, metric.Name, r.change(metric))
+		default:
+			r.line(r.p.MetricFlat, metric.Name)
+		}
+	}
+	perWeek := strconv.FormatFloat(stats.PerWeek, 'f', -1, 64)
+	if stats.PerWeek < 3 {
+		r.line(r.p.LowFrequency, perWeek)
+	} else {
+		r.line(r.p.GoodFrequency, perWeek)
+	}
+
+	r.section(r.p.Family)
+	tips := append([]string(nil), r.p.FamilyTips...)
+	r.rng.Shuffle(len(tips), func(i, j int) { tips[i], tips[j] = tips[j], tips[i] })
+	for _, tip := range tips[:3] {
+		r.line(tip)
+	}
+}
+
+func (r *mockReportBuilder) baseline() {
+	r.section(r.p.Baseline)
+	r.metricLines()
+}
+
+func (r *mockReportBuilder) trajectory() {
+	r.section(r.p.Trajectory)
+	logs := r.stats.Logs
+	if len(logs) < 2 {
+		if len(r.stats.Windows) > 0 {
+			r.windowLines()
+		} else {
+			r.line(r.p.NoKeywords)
+		}
+		return
+	}
+	r.line(r.p.Halves)
+	mid := len(logs) / 2
+	for _, half := range [][]ReportPromptLog{logs[:mid], logs[mid:]} {
+		r.line(r.p.HalfItem, r.date(half[0].Time), r.date(half[len(half)-1].Time), len(half),
+			r.keywordList(extractMockKeywords(half, 3)))
+	}
+}
+
+func (r *mockReportBuilder) milestones() {
+	r.section(r.p.Milestones)
+	found := false
+	for _, metric := range r.stats.Metrics {
+		if metric.Trend > 0 && metric.Last == metric.Max {
+			r.line(r.p.Milestone, metric.Name, r.value(metric.Max, metric.Unit))
+			found = true
+		}
+	}
+	if !found {
+		r.line(r.p.NoMilestone)
+	}
+}
+
+// windowSummary 模拟分段摘要：记录数量、时间范围、高频内容和指标变化
+func (r *mockReportBuilder) windowSummary() string {
+	stats := r.stats
+	var sentences []string
+	if r.english {
+		sentences = append(sentences, fmt.Sprintf(r.p.WindowSummary, stats.LogCount, r.date(stats.First), r.date(stats.Last)))
+	} else {
+		sentences = append(sentences, fmt.Sprintf(r.p.WindowSummary, r.date(stats.First), r.date(stats.Last), stats.LogCount))
+	}
+	if len(stats.Keywords) > 0 {
+		sentences = append(sentences, fmt.Sprintf(r.p.WindowKeywords, r.keywordList(stats.Keywords)))
+	}
+	if len(stats.Metrics) > 0 {
+		items := make([]string, len(stats.Metrics))
+		for i, metric := range stats.Metrics {
+			items[i] = fmt.Sprintf(r.p.WindowMetricItem, metric.Name, r.value(metric.First, metric.Unit), r.value(metric.Last, metric.Unit))
+		}
+		sentences = append(sentences, fmt.Sprintf(r.p.WindowMetrics, strings.Join(items, r.p.Separator)))
+	}
+	for _, window := range stats.Windows {
+		sentences = append(sentences, truncateRunes(window.Summary, 120))
+	}
+	return strings.Join(sentences, r.p.SentenceSep)
+}
+
+// mockRiskFlags 较首次记录变化超过20%的指标列为低风险提示，提醒结合记录观察
+func mockRiskFlags(req *LLMRequest) []model.ReportRiskFlag {
+	stats := newMockReportStats(req.PromptData)
+	r := &mockReportBuilder{p: mockPhrasesFor(req.Language)}
+	flags := []model.ReportRiskFlag{}
+	for _, metric := range stats.Metrics {
+		if metric.Trend == 0 || metric.First == 0 || math.Abs(metric.ChangePct) < 20 {
+			continue
+		}
+		flags = append(flags, model.ReportRiskFlag{
+			Level:       model.RiskLevelLow,
+			Category:    r.p.RiskCategory,
+			Description: fmt.Sprintf(r.p.RiskDescription, metric.Name, r.change(metric)),
+		})
+	}
+	return flags
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testMockPromptData 两周内的几条疗愈记录和一项指标
+func testMockPromptData() *ReportPromptData {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	contents := []string{
+		"音乐治疗时跟着节奏拍手，眼神交流明显增多，主动模仿老师唱歌",
+		"情绪稳定，能安静完成绘画活动，节奏游戏中主动轮流",
+		"午睡前有些烦躁，听舒缓音乐后平静下来，眼神交流持续时间变长",
+		"节奏游戏完成得很好，能说出两个新词，主动模仿打鼓动作",
+	}
+	logs := make([]ReportPromptLog, len(contents))
+	for i, content := range contents {
+		logs[i] = ReportPromptLog{
+			Index:      i + 1,
+			Time:       start.AddDate(0, 0, i*4),
+			Content:    content,
+			MediaTypes: []string{"audio"},
+			Metrics:    []ReportPromptLogMetric{{Name: "专注时长", Value: float64(5 + i*2), Unit: "分钟"}},
+		}
+	}
+	return &ReportPromptData{
+		ReportType: "summary",
+		ReportName: "疗愈总结",
+		Child:      ReportPromptChild{ID: "child-1", Name: "[儿童姓名]", Age: 6},
+		Logs:       logs,
+		LogCount:   len(logs),
+		FirstLogAt: logs[0].Time,
+		LastLogAt:  logs[len(logs)-1].Time,
+		Metrics:    []ReportPromptMetric{{Name: "专注时长", Unit: "分钟", Count: 4, First: 5, Last: 11, Min: 5, Max: 11, Avg: 8}},
+	}
+}
+
+func testMockReportRequest(reportType, language string) *LLMRequest {
+	data := testMockPromptData()
+	data.ReportType = reportType
+	return &LLMRequest{
+		Messages:   []LLMMessage{{Role: "user", Content: "生成报告"}},
+		ReportType: reportType,
+		Language:   language,
+		PromptData: data,
+	}
+}
+
+func TestFakeLLMProviderSameSeedSameReport(t *testing.T) {
+	for _, reportType := range []string{"summary", "suggestion", "progress"} {
+		for _, language := range []string{"zh", "en"} {
+			first, err := (&FakeLLMProvider{Seed: 42}).Chat(context.Background(), testMockReportRequest(reportType, language))
+			if err != nil {
+				t.Fatalf("%s/%s: Chat 返回错误: %v", reportType, language, err)
+			}
+			// 新建的提供商和请求，排除任何调用之间共享的状态
+			second, err := (&FakeLLMProvider{Seed: 42}).Chat(context.Background(), testMockReportRequest(reportType, language))
+			if err != nil {
+				t.Fatalf("%s/%s: Chat 返回错误: %v", reportType, language, err)
+			}
+			if first.Content != second.Content {
+				t.Errorf("%s/%s: 相同的种子和记录生成了不同的报告:\n%s\n---\n%s", reportType, language, first.Content, second.Content)
+			}
+			if !strings.HasPrefix(first.Content, "# ") {
+				t.Errorf("%s/%s: 报告缺少标题: %q", reportType, language, first.Content)
+			}
+		}
+	}
+}
+
+func TestFakeLLMProviderSeedChangesWording(t *testing.T) {
+	distinct := make(map[string]bool)
+	for seed := int64(1); seed <= 20; seed++ {
+		resp, err := (&FakeLLMProvider{Seed: seed}).Chat(context.Background(), testMockReportRequest("summary", "zh"))
+		if err != nil {
+			t.Fatalf("种子 %d: Chat 返回错误: %v", seed, err)
+		}
+		distinct[resp.Content] = true
+	}
+	if len(distinct) < 2 {
+		t.Errorf("不同的种子应选择不同的措辞，20 个种子只生成了 %d 种报告", len(distinct))
+	}
+}
+
+func TestFakeLLMProviderRecordsChangeReport(t *testing.T) {
+	provider := &FakeLLMProvider{Seed: 42}
+	original, err := provider.Chat(context.Background(), testMockReportRequest("summary", "zh"))
+	if err != nil {
+		t.Fatalf("Chat 返回错误: %v", err)
+	}
+
+	req := testMockReportRequest("summary", "zh")
+	req.PromptData.Logs = req.PromptData.Logs[:2]
+	req.PromptData.LogCount = 2
+	req.PromptData.LastLogAt = req.PromptData.Logs[1].Time
+	changed, err := provider.Chat(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Chat 返回错误: %v", err)
+	}
+	if original.Content == changed.Content {
+		t.Errorf("记录不同时报告内容应随之变化")
+	}
+}
+
+func TestFakeLLMProviderSameSeedSameStructuredReport(t *testing.T) {
+	var contents []string
+	for i := 0; i < 2; i++ {
+		req := testMockReportRequest("summary", "zh")
+		req.JSONMode = true
+		resp, err := (&FakeLLMProvider{Seed: 7}).Chat(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Chat 返回错误: %v", err)
+		}
+		if !json.Valid([]byte(resp.Content)) {
+			t.Fatalf("结构化报告不是有效的 JSON: %s", resp.Content)
+		}
+		contents = append(contents, resp.Content)
+	}
+	if contents[0] != contents[1] {
+		t.Errorf("相同的种子和记录生成了不同的结构化报告:\n%s\n---\n%s", contents[0], contents[1])
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// OllamaProvider Ollama 本地模型的 /api/chat 接口
+type OllamaProvider struct {
+	baseURL string
+	model   string
+	client  *llmHTTPClient
+}
+
+func NewOllamaProvider(baseURL, model string, client *llmHTTPClient) *OllamaProvider {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+	return &OllamaProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		client:  client,
+	}
+}
+
+func (p *OllamaProvider) Name() string {
+	return LLMProviderOllama
+}
+
+func (p *OllamaProvider) Chat(ctx context.Context, req *LLMRequest) (*LLMResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = p.model
+	}
+
+	requestBody := map[string]interface{}{
+		"model":    model,
+		"messages": req.Messages,
+		"stream":   false,
+		"options": map[string]interface{}{
+			"temperature": req.Temperature,
+			"num_predict": req.MaxTokens,
+		},
+	}
+	if req.JSONMode {
+		requestBody["format"] = "json"
+	}
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("构建请求体失败: %v", err)
+	}
+
+	body, err := p.client.PostJSON(ctx, p.Name(), p.baseURL+"/api/chat", nil, jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Model   string `json:"model"`
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		PromptEvalCount int    `json:"prompt_eval_count"`
+		EvalCount       int    `json:"eval_count"`
+		Error           string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, invalidLLMResponse(p.Name(), "解析响应失败: %v", err)
+	}
+	if response.Error != "" {
+		return nil, invalidLLMResponse(p.Name(), "本地模型错误: %s", response.Error)
+	}
+	if response.Message.Content == "" {
+		return nil, invalidLLMResponse(p.Name(), "本地模型生成的内容为空")
+	}
+	if response.Model != "" {
+		model = response.Model
+	}
+	return &LLMResponse{
+		Content:          response.Message.Content,
+		Provider:         p.Name(),
+		Model:            model,
+		PromptTokens:     response.PromptEvalCount,
+		CompletionTokens: response.EvalCount,
+	}, nil
+}
+
+// ChatStream 以 stream 模式调用 /api/chat，响应为逐行的 JSON 数据块
+func (p *OllamaProvider) ChatStream(ctx context.Context, req *LLMRequest, onDelta LLMStreamHandler) (*LLMResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = p.model
+	}
+
+	requestBody := map[string]interface{}{
+		"model":    model,
+		"messages": req.Messages,
+		"stream":   true,
+		"options": map[string]interface{}{
+			"temperature": req.Temperature,
+			"num_predict": req.MaxTokens,
+		},
+	}
+	if req.JSONMode {
+		requestBody["format"] = "json"
+	}
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("构建请求体失败: %v", err)
+	}
+
+	resp, err := p.client.OpenStream(ctx, p.Name(), p.baseURL+"/api/chat", nil, jsonData)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &LLMResponse{Provider: p.Name(), Model: model}
+	var content strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk struct {
+			Model   string `json:"model"`
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			Done            bool   `json:"done"`
+			PromptEvalCount int    `json:"prompt_eval_count"`
+			EvalCount       int    `json:"eval_count"`
+			Error           string `json:"error"`
+		}
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, invalidLLMResponse(p.Name(), "解析响应失败: %v", err)
+		}
+		if chunk.Error != "" {
+			return nil, invalidLLMResponse(p.Name(), "本地模型错误: %s", chunk.Error)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			if err := onDelta(chunk.Message.Content); err != nil {
+				return nil, err
+			}
+		}
+		if chunk.Done {
+			result.PromptTokens = chunk.PromptEvalCount
+			result.CompletionTokens = chunk.EvalCount
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, transportError(ctx, p.Name(), fmt.Errorf("读取响应失败: %v", err))
+	}
+	if content.Len() == 0 {
+		return nil, invalidLLMResponse(p.Name(), "本地模型生成的内容为空")
+	}
+	result.Content = content.String()
+	return result, nil
+}
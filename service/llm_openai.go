@@ -0,0 +1,197 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// OpenAIProvider OpenAI 兼容的 chat/completions 接口
+type OpenAIProvider struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *llmHTTPClient
+}
+
+func NewOpenAIProvider(baseURL, apiKey, model string, client *llmHTTPClient) *OpenAIProvider {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	return &OpenAIProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		client:  client,
+	}
+}
+
+func (p *OpenAIProvider) Name() string {
+	return LLMProviderOpenAI
+}
+
+func (p *OpenAIProvider) Chat(ctx context.Context, req *LLMRequest) (*LLMResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = p.model
+	}
+
+	requestBody := map[string]interface{}{
+		"model":       model,
+		"messages":    req.Messages,
+		"max_tokens":  req.MaxTokens,
+		"temperature": req.Temperature,
+	}
+	if req.JSONMode {
+		requestBody["response_format"] = map[string]interface{}{"type": "json_object"}
+	}
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("构建请求体失败: %v", err)
+	}
+
+	body, err := p.client.PostJSON(ctx, p.Name(), p.baseURL+"/chat/completions", map[string]string{
+		"Authorization": "Bearer " + p.apiKey,
+	}, jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+		Error struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, invalidLLMResponse(p.Name(), "解析响应失败: %v", err)
+	}
+	if response.Error.Message != "" {
+		return nil, invalidLLMResponse(p.Name(), "%s (%s)", response.Error.Message, response.Error.Type)
+	}
+	if len(response.Choices) == 0 {
+		return nil, invalidLLMResponse(p.Name(), "AI响应为空")
+	}
+
+	content := response.Choices[0].Message.Content
+	if content == "" {
+		return nil, invalidLLMResponse(p.Name(), "AI生成的内容为空")
+	}
+	if response.Model != "" {
+		model = response.Model
+	}
+	return &LLMResponse{
+		Content:          content,
+		Provider:         p.Name(),
+		Model:            model,
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+	}, nil
+}
+
+// ChatStream 以 stream 模式调用 chat/completions，逐条解析 SSE 数据块
+func (p *OpenAIProvider) ChatStream(ctx context.Context, req *LLMRequest, onDelta LLMStreamHandler) (*LLMResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = p.model
+	}
+
+	requestBody := map[string]interface{}{
+		"model":       model,
+		"messages":    req.Messages,
+		"max_tokens":  req.MaxTokens,
+		"temperature": req.Temperature,
+		"stream":      true,
+		// 在最后一个数据块中返回用量
+		"stream_options": map[string]interface{}{"include_usage": true},
+	}
+	if req.JSONMode {
+		requestBody["response_format"] = map[string]interface{}{"type": "json_object"}
+	}
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("构建请求体失败: %v", err)
+	}
+
+	resp, err := p.client.OpenStream(ctx, p.Name(), p.baseURL+"/chat/completions", map[string]string{
+		"Accept":        "text/event-stream",
+		"Authorization": "Bearer " + p.apiKey,
+	}, jsonData)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &LLMResponse{Provider: p.Name(), Model: model}
+	var content strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			Model   string `json:"model"`
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+			} `json:"usage"`
+			Error struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, invalidLLMResponse(p.Name(), "解析响应失败: %v", err)
+		}
+		if chunk.Error.Message != "" {
+			return nil, invalidLLMResponse(p.Name(), "%s (%s)", chunk.Error.Message, chunk.Error.Type)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.PromptTokens = chunk.Usage.PromptTokens
+			result.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		delta := chunk.Choices[0].Delta.Content
+		content.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, transportError(ctx, p.Name(), fmt.Errorf("读取响应失败: %v", err))
+	}
+	if content.Len() == 0 {
+		return nil, invalidLLMResponse(p.Name(), "AI生成的内容为空")
+	}
+	result.Content = content.String()
+	return result, nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"melody_cure/config"
+	"strings"
+	"time"
+)
+
+// LLMMessage 对话消息
+type LLMMessage struct {
+	Role    string `json:"role"` // system, user, assistant
+	Content string `json:"content"`
+}
+
+// LLMRequest 大模型请求
+type LLMRequest struct {
+	Model       string // 为空时使用提供商的默认模型
+	Messages    []LLMMessage
+	MaxTokens   int
+	Temperature float64
+	ReportType  string // 报告类型，仅供模拟提供商选择内容
+	Language    string // 报告语言，仅供模拟提供商选择内容
+	JSONMode    bool   // 要求模型只输出 JSON 对象
+
+	PromptData *ReportPromptData // 渲染提示词的数据，仅供模拟提供商根据真实记录生成内容
+}
+
+// LLMResponse 大模型响应
+type LLMResponse struct {
+	Content          string
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// LLMProvider 大模型提供商
+type LLMProvider interface {
+	Name() string
+	Chat(ctx context.Context, req *LLMRequest) (*LLMResponse, error)
+}
+
+// LLMStreamHandler 接收流式生成的增量内容，返回错误时中止生成
+type LLMStreamHandler func(delta string) error
+
+// StreamingLLMProvider 支持流式输出的提供商
+type StreamingLLMProvider interface {
+	LLMProvider
+	ChatStream(ctx context.Context, req *LLMRequest, onDelta LLMStreamHandler) (*LLMResponse, error)
+}
+
+// chatStream 流式调用提供商，不支持流式输出的提供商生成完成后一次性返回全部内容
+func chatStream(ctx context.Context, provider LLMProvider, req *LLMRequest, onDelta LLMStreamHandler) (*LLMResponse, error) {
+	if streaming, ok := provider.(StreamingLLMProvider); ok {
+		return streaming.ChatStream(ctx, req, onDelta)
+	}
+	resp, err := provider.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := onDelta(resp.Content); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 支持的提供商
+const (
+	LLMProviderOpenAI = "openai" // OpenAI 及兼容接口（DeepSeek、通义千问兼容模式等）
+	LLMProviderOllama = "ollama" // Ollama 等本地 HTTP 模型
+	LLMProviderFake   = "fake"   // 确定性的模拟提供商
+)
+
+// NewLLMProvider 根据 AI 配置创建提供商。各提供商共用带重试的HTTP客户端并各自熔断，
+// 配置了备用提供商时主提供商失败或熔断后自动切换，每次成功调用都记录用量
+func NewLLMProvider(usageService *UsageService) LLMProvider {
+	aiConfig := config.GetAIConfig()
+	client := newLLMHTTPClient(time.Duration(aiConfig.Timeout) * time.Second)
+	provider := newLLMProvider(aiConfig.Provider, aiConfig.APIKey, aiConfig.BaseURL, aiConfig.Model, client)
+	if aiConfig.Fallback.Provider != "" {
+		secondary := newLLMProvider(aiConfig.Fallback.Provider, aiConfig.Fallback.APIKey, aiConfig.Fallback.BaseURL, aiConfig.Fallback.Model, client)
+		provider = &FallbackLLMProvider{primary: provider, secondary: secondary}
+	}
+	return &MeteredLLMProvider{provider: provider, usageService: usageService}
+}
+
+func newLLMProvider(provider, apiKey, baseURL, model string, client *llmHTTPClient) LLMProvider {
+	switch strings.ToLower(provider) {
+	case LLMProviderFake, "mock":
+		return NewFakeLLMProvider()
+	case LLMProviderOllama, "local":
+		return NewCircuitBreakerLLMProvider(NewOllamaProvider(baseURL, model, client))
+	default:
+		// 未配置真实的API密钥时使用模拟数据
+		if apiKey == "" || apiKey == "your_ai_api_key_here" {
+			return NewFakeLLMProvider()
+		}
+		return NewCircuitBreakerLLMProvider(NewOpenAIProvider(baseURL, apiKey, model, client))
+	}
+}
+
+// FallbackLLMProvider 主提供商请求失败或熔断时使用备用提供商
+type FallbackLLMProvider struct {
+	primary   LLMProvider
+	secondary LLMProvider
+}
+
+func (p *FallbackLLMProvider) Name() string {
+	return p.primary.Name()
+}
+
+func (p *FallbackLLMProvider) Chat(ctx context.Context, req *LLMRequest) (*LLMResponse, error) {
+	resp, err := p.primary.Chat(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+
+	log.Printf("AI提供商 %s 请求失败，切换到备用提供商 %s: %v", p.primary.Name(), p.secondary.Name(), err)
+	// 模型覆盖针对主提供商，备用提供商使用自己的默认模型
+	fallbackReq := *req
+	fallbackReq.Model = ""
+	resp, fallbackErr := p.secondary.Chat(ctx, &fallbackReq)
+	if fallbackErr != nil {
+		// 保留备用提供商的错误类型，两者都不可用时由调用方稍后重试
+		return nil, fmt.Errorf("主提供商失败: %v；备用提供商失败: %w", err, fallbackErr)
+	}
+	return resp, nil
+}
+
+// ChatStream 主提供商在输出任何内容前失败时切换到备用提供商，已开始输出后失败则直接返回错误
+func (p *FallbackLLMProvider) ChatStream(ctx context.Context, req *LLMRequest, onDelta LLMStreamHandler) (*LLMResponse, error) {
+	started := false
+	resp, err := chatStream(ctx, p.primary, req, func(delta string) error {
+		started = true
+		return onDelta(delta)
+	})
+	if err == nil {
+		return resp, nil
+	}
+	if started || ctx.Err() != nil {
+		return nil, err
+	}
+
+	log.Printf("AI提供商 %s 流式请求失败，切换到备用提供商 %s: %v", p.primary.Name(), p.secondary.Name(), err)
+	fallbackReq := *req
+	fallbackReq.Model = ""
+	resp, fallbackErr := chatStream(ctx, p.secondary, &fallbackReq, onDelta)
+	if fallbackErr != nil {
+		// 保留备用提供商的错误类型，两者都不可用时由调用方稍后重试
+		return nil, fmt.Errorf("主提供商失败: %v；备用提供商失败: %w", err, fallbackErr)
+	}
+	return resp, nil
+}
+
+// modelForReportType 返回报告类型对应的模型覆盖配置，未配置时返回空字符串
+func modelForReportType(reportType string) string {
+	return config.GetAIConfig().ModelOverrides[strings.ToLower(reportType)]
+}
@@ -0,0 +1,474 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestLLMClient 不读取配置、重试间隔很短的HTTP客户端
+func newTestLLMClient(maxAttempts int) *llmHTTPClient {
+	return &llmHTTPClient{
+		client:      &http.Client{},
+		timeout:     2 * time.Second,
+		maxAttempts: maxAttempts,
+		baseDelay:   time.Millisecond,
+		maxDelay:    time.Second,
+	}
+}
+
+func newTestBreakerProvider(provider LLMProvider, threshold int) *CircuitBreakerLLMProvider {
+	return &CircuitBreakerLLMProvider{
+		provider: provider,
+		breaker:  &circuitBreaker{state: breakerClosed, threshold: threshold, openFor: time.Minute},
+	}
+}
+
+func testLLMRequest() *LLMRequest {
+	return &LLMRequest{Messages: []LLMMessage{{Role: "user", Content: "你好"}}, MaxTokens: 100}
+}
+
+const openAIChatResponse = `{"model":"gpt-test","choices":[{"message":{"content":"报告内容"}}],"usage":{"prompt_tokens":12,"completion_tokens":34}}`
+
+const ollamaChatResponse = `{"model":"qwen-test","message":{"content":"本地报告"},"prompt_eval_count":5,"eval_count":6}`
+
+func TestOpenAIProviderChat(t *testing.T) {
+	var auth, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, path = r.Header.Get("Authorization"), r.URL.Path
+		fmt.Fprint(w, openAIChatResponse)
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(server.URL+"/v1/", "sk-test", "gpt-default", newTestLLMClient(1))
+	resp, err := provider.Chat(context.Background(), testLLMRequest())
+	if err != nil {
+		t.Fatalf("Chat 返回错误: %v", err)
+	}
+	if auth != "Bearer sk-test" || path != "/v1/chat/completions" {
+		t.Errorf("请求头或路径错误: auth=%q path=%q", auth, path)
+	}
+	if resp.Content != "报告内容" || resp.Model != "gpt-test" || resp.PromptTokens != 12 || resp.CompletionTokens != 34 {
+		t.Errorf("响应解析错误: %+v", resp)
+	}
+}
+
+func TestOpenAIProviderChatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"model\":\"gpt-test\",\"choices\":[{\"delta\":{\"content\":\"报告\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"内容\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":4}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	var deltas []string
+	provider := NewOpenAIProvider(server.URL, "sk-test", "gpt-default", newTestLLMClient(1))
+	resp, err := provider.ChatStream(context.Background(), testLLMRequest(), func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream 返回错误: %v", err)
+	}
+	if strings.Join(deltas, "|") != "报告|内容" || resp.Content != "报告内容" {
+		t.Errorf("流式内容错误: deltas=%v content=%q", deltas, resp.Content)
+	}
+	if resp.PromptTokens != 3 || resp.CompletionTokens != 4 {
+		t.Errorf("流式用量错误: %+v", resp)
+	}
+}
+
+func TestOllamaProviderChat(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		fmt.Fprint(w, ollamaChatResponse)
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(server.URL, "qwen-default", newTestLLMClient(1))
+	resp, err := provider.Chat(context.Background(), testLLMRequest())
+	if err != nil {
+		t.Fatalf("Chat 返回错误: %v", err)
+	}
+	if path != "/api/chat" {
+		t.Errorf("请求路径错误: %q", path)
+	}
+	if resp.Content != "本地报告" || resp.Model != "qwen-test" || resp.PromptTokens != 5 || resp.CompletionTokens != 6 {
+		t.Errorf("响应解析错误: %+v", resp)
+	}
+}
+
+func TestOllamaProviderChatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"model":"qwen-test","message":{"content":"本地"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"content":"报告"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"content":""},"done":true,"prompt_eval_count":7,"eval_count":8}`)
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(server.URL, "qwen-default", newTestLLMClient(1))
+	resp, err := provider.ChatStream(context.Background(), testLLMRequest(), func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("ChatStream 返回错误: %v", err)
+	}
+	if resp.Content != "本地报告" || resp.Model != "qwen-test" || resp.PromptTokens != 7 || resp.CompletionTokens != 8 {
+		t.Errorf("流式响应解析错误: %+v", resp)
+	}
+}
+
+func TestLLMProviderErrorMapping(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"限流", http.StatusTooManyRequests, `{"error":{"message":"rate limited"}}`, ErrLLMRateLimited},
+		{"认证失败", http.StatusUnauthorized, `{"error":{"message":"bad key"}}`, ErrLLMAuth},
+		{"无权限", http.StatusForbidden, `{}`, ErrLLMAuth},
+		{"请求错误", http.StatusBadRequest, `{"error":{"message":"bad request"}}`, ErrLLMBadRequest},
+		{"服务端错误", http.StatusInternalServerError, `oops`, ErrLLMUnavailable},
+		{"服务不可用", http.StatusServiceUnavailable, ``, ErrLLMUnavailable},
+		{"网关超时", http.StatusGatewayTimeout, ``, ErrLLMTimeout},
+		{"响应体错误", http.StatusOK, `{"error":{"message":"model overloaded","type":"server_error"}}`, ErrLLMInvalidResponse},
+		{"响应格式错误", http.StatusOK, `not json`, ErrLLMInvalidResponse},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer server.Close()
+
+			providers := []LLMProvider{
+				NewOpenAIProvider(server.URL, "sk-test", "gpt-default", newTestLLMClient(1)),
+				NewOllamaProvider(server.URL, "qwen-default", newTestLLMClient(1)),
+			}
+			for _, provider := range providers {
+				_, err := provider.Chat(context.Background(), testLLMRequest())
+				if !errors.Is(err, tc.want) {
+					t.Errorf("%s: 期望 %v，实际 %v", provider.Name(), tc.want, err)
+				}
+				var llmErr *LLMError
+				if !errors.As(err, &llmErr) || llmErr.Provider != provider.Name() {
+					t.Errorf("%s: 错误未标记提供商: %v", provider.Name(), err)
+				}
+			}
+		})
+	}
+}
+
+func TestLLMProviderEmptyContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/chat" {
+			fmt.Fprint(w, `{"message":{"content":""}}`)
+			return
+		}
+		fmt.Fprint(w, `{"choices":[]}`)
+	}))
+	defer server.Close()
+
+	providers := []LLMProvider{
+		NewOpenAIProvider(server.URL, "sk-test", "gpt-default", newTestLLMClient(1)),
+		NewOllamaProvider(server.URL, "qwen-default", newTestLLMClient(1)),
+	}
+	for _, provider := range providers {
+		if _, err := provider.Chat(context.Background(), testLLMRequest()); !errors.Is(err, ErrLLMInvalidResponse) {
+			t.Errorf("%s: 期望 %v，实际 %v", provider.Name(), ErrLLMInvalidResponse, err)
+		}
+	}
+}
+
+func TestLLMProviderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := newTestLLMClient(1)
+	client.timeout = 50 * time.Millisecond
+	_, err := NewOpenAIProvider(server.URL, "sk-test", "gpt-default", client).Chat(context.Background(), testLLMRequest())
+	if !errors.Is(err, ErrLLMTimeout) {
+		t.Errorf("期望 %v，实际 %v", ErrLLMTimeout, err)
+	}
+}
+
+func TestLLMProviderRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, openAIChatResponse)
+	}))
+	defer server.Close()
+
+	resp, err := NewOpenAIProvider(server.URL, "sk-test", "gpt-default", newTestLLMClient(3)).Chat(context.Background(), testLLMRequest())
+	if err != nil {
+		t.Fatalf("重试后仍失败: %v", err)
+	}
+	if resp.Content != "报告内容" || atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("重试次数错误: calls=%d", calls)
+	}
+}
+
+func TestLLMProviderNoRetryOnBadRequest(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := NewOllamaProvider(server.URL, "qwen-default", newTestLLMClient(3)).Chat(context.Background(), testLLMRequest())
+	if !errors.Is(err, ErrLLMBadRequest) || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("请求被拒绝时不应重试: err=%v calls=%d", err, calls)
+	}
+}
+
+func TestLLMProviderRetryAfterTooLong(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewOpenAIProvider(server.URL, "sk-test", "gpt-default", newTestLLMClient(3)).Chat(context.Background(), testLLMRequest())
+	if !errors.Is(err, ErrLLMRateLimited) || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Retry-After 超过等待上限时不应重试: err=%v calls=%d", err, calls)
+	}
+	if LLMRetryAfter(err) != 120*time.Second {
+		t.Errorf("Retry-After 解析错误: %v", LLMRetryAfter(err))
+	}
+}
+
+func TestFallbackLLMProvider(t *testing.T) {
+	var primaryCalls int32
+	var fallbackModel string
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fallbackModel = string(body)
+		fmt.Fprint(w, ollamaChatResponse)
+	}))
+	defer secondary.Close()
+
+	provider := &FallbackLLMProvider{
+		primary:   NewOpenAIProvider(primary.URL, "sk-test", "gpt-default", newTestLLMClient(1)),
+		secondary: NewOllamaProvider(secondary.URL, "qwen-default", newTestLLMClient(1)),
+	}
+	req := testLLMRequest()
+	req.Model = "gpt-override"
+	resp, err := provider.Chat(context.Background(), req)
+	if err != nil {
+		t.Fatalf("切换备用提供商后仍失败: %v", err)
+	}
+	if resp.Provider != LLMProviderOllama || resp.Content != "本地报告" {
+		t.Errorf("未使用备用提供商的响应: %+v", resp)
+	}
+	if !strings.Contains(fallbackModel, `"model":"qwen-default"`) {
+		t.Errorf("备用提供商应使用自己的默认模型: %s", fallbackModel)
+	}
+	if atomic.LoadInt32(&primaryCalls) != 1 {
+		t.Errorf("主提供商请求次数错误: calls=%d", primaryCalls)
+	}
+	if req.Model != "gpt-override" {
+		t.Errorf("不应修改调用方的请求: %q", req.Model)
+	}
+}
+
+func TestFallbackLLMProviderBothFail(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer secondary.Close()
+
+	provider := &FallbackLLMProvider{
+		primary:   NewOpenAIProvider(primary.URL, "sk-test", "gpt-default", newTestLLMClient(1)),
+		secondary: NewOllamaProvider(secondary.URL, "qwen-default", newTestLLMClient(1)),
+	}
+	_, err := provider.Chat(context.Background(), testLLMRequest())
+	// 返回备用提供商的错误类型
+	if !errors.Is(err, ErrLLMRateLimited) || errors.Is(err, ErrLLMUnavailable) {
+		t.Errorf("期望备用提供商的错误 %v，实际 %v", ErrLLMRateLimited, err)
+	}
+}
+
+func TestFallbackLLMProviderStreamStarted(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"部分\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"error\":{\"message\":\"stream broken\"}}\n\n")
+	}))
+	defer primary.Close()
+	var secondaryCalls int32
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&secondaryCalls, 1)
+		fmt.Fprint(w, ollamaChatResponse)
+	}))
+	defer secondary.Close()
+
+	provider := &FallbackLLMProvider{
+		primary:   NewOpenAIProvider(primary.URL, "sk-test", "gpt-default", newTestLLMClient(1)),
+		secondary: NewOllamaProvider(secondary.URL, "qwen-default", newTestLLMClient(1)),
+	}
+	_, err := provider.ChatStream(context.Background(), testLLMRequest(), func(string) error { return nil })
+	if !errors.Is(err, ErrLLMInvalidResponse) {
+		t.Errorf("期望 %v，实际 %v", ErrLLMInvalidResponse, err)
+	}
+	if atomic.LoadInt32(&secondaryCalls) != 0 {
+		t.Errorf("已开始输出后不应切换备用提供商")
+	}
+}
+
+func TestCircuitBreakerLLMProvider(t *testing.T) {
+	var calls int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, openAIChatResponse)
+	}))
+	defer server.Close()
+
+	provider := newTestBreakerProvider(NewOpenAIProvider(server.URL, "sk-test", "gpt-default", newTestLLMClient(1)), 3)
+	for i := 0; i < 3; i++ {
+		if _, err := provider.Chat(context.Background(), testLLMRequest()); !errors.Is(err, ErrLLMUnavailable) {
+			t.Fatalf("第 %d 次请求期望 %v，实际 %v", i+1, ErrLLMUnavailable, err)
+		}
+	}
+
+	// 达到阈值后熔断，不再请求提供商
+	_, err := provider.Chat(context.Background(), testLLMRequest())
+	if !errors.Is(err, ErrLLMCircuitOpen) || !IsTransientLLMError(err) {
+		t.Fatalf("期望 %v，实际 %v", ErrLLMCircuitOpen, err)
+	}
+	if LLMRetryAfter(err) <= 0 {
+		t.Errorf("熔断错误应带有剩余熔断时间")
+	}
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("熔断期间不应请求提供商: calls=%d", calls)
+	}
+
+	// 熔断到期后放行一次试探请求，成功则恢复
+	healthy.Store(true)
+	provider.breaker.openedUntil = time.Now().Add(-time.Second)
+	if _, err := provider.Chat(context.Background(), testLLMRequest()); err != nil {
+		t.Fatalf("试探请求失败: %v", err)
+	}
+	if provider.breaker.state != breakerClosed {
+		t.Errorf("试探成功后应恢复，实际状态 %s", provider.breaker.state)
+	}
+}
+
+func TestCircuitBreakerIgnoresBadRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	provider := newTestBreakerProvider(NewOllamaProvider(server.URL, "qwen-default", newTestLLMClient(1)), 2)
+	for i := 0; i < 5; i++ {
+		if _, err := provider.Chat(context.Background(), testLLMRequest()); !errors.Is(err, ErrLLMBadRequest) {
+			t.Fatalf("第 %d 次请求期望 %v，实际 %v", i+1, ErrLLMBadRequest, err)
+		}
+	}
+	if provider.breaker.state != breakerClosed {
+		t.Errorf("请求被拒绝不应触发熔断，实际状态 %s", provider.breaker.state)
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider := newTestBreakerProvider(NewOpenAIProvider(server.URL, "sk-test", "gpt-default", newTestLLMClient(1)), 1)
+	provider.Chat(context.Background(), testLLMRequest())
+	if provider.breaker.state != breakerOpen {
+		t.Fatalf("期望熔断，实际状态 %s", provider.breaker.state)
+	}
+
+	// 试探请求失败后重新熔断
+	provider.breaker.openedUntil = time.Now().Add(-time.Second)
+	if _, err := provider.Chat(context.Background(), testLLMRequest()); !errors.Is(err, ErrLLMUnavailable) {
+		t.Fatalf("试探请求期望 %v，实际 %v", ErrLLMUnavailable, err)
+	}
+	if _, err := provider.Chat(context.Background(), testLLMRequest()); !errors.Is(err, ErrLLMCircuitOpen) {
+		t.Errorf("试探失败后期望 %v，实际 %v", ErrLLMCircuitOpen, err)
+	}
+}
+
+func TestFallbackLLMProviderPrimaryCircuitOpen(t *testing.T) {
+	var primaryCalls int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, ollamaChatResponse)
+	}))
+	defer secondary.Close()
+
+	provider := &FallbackLLMProvider{
+		primary:   newTestBreakerProvider(NewOpenAIProvider(primary.URL, "sk-test", "gpt-default", newTestLLMClient(1)), 1),
+		secondary: newTestBreakerProvider(NewOllamaProvider(secondary.URL, "qwen-default", newTestLLMClient(1)), 1),
+	}
+	// 第一次主提供商失败并熔断，之后直接使用备用提供商
+	for i := 0; i < 3; i++ {
+		resp, err := provider.Chat(context.Background(), testLLMRequest())
+		if err != nil || resp.Provider != LLMProviderOllama {
+			t.Fatalf("第 %d 次请求未切换到备用提供商: resp=%+v err=%v", i+1, resp, err)
+		}
+	}
+	if atomic.LoadInt32(&primaryCalls) != 1 {
+		t.Errorf("主提供商熔断后不应再被请求: calls=%d", primaryCalls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"30":                            30 * time.Second,
+		"-5":                            0,
+		"Mon, 01 Jan 2024 00:01:00 GMT": time.Minute,
+		"Sun, 31 Dec 2023 23:00:00 GMT": 0,
+		"invalid":                       0,
+	}
+	for value, want := range cases {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v，期望 %v", value, got, want)
+		}
+	}
+}
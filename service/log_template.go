@@ -0,0 +1,309 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/model"
+	"strings"
+)
+
+type LogTemplateService struct {
+	logTemplateDAO *DAO.LogTemplateDAO
+	userDAO        *DAO.UserDAO
+}
+
+func NewLogTemplateService(logTemplateDAO *DAO.LogTemplateDAO, userDAO *DAO.UserDAO) *LogTemplateService {
+	return &LogTemplateService{
+		logTemplateDAO: logTemplateDAO,
+		userDAO:        userDAO,
+	}
+}
+
+// CreateTemplate 创建日志模板（仅管理员和已认证康复师）
+func (s *LogTemplateService) CreateTemplate(userID string, req *request.LogTemplateRequest) (*model.LogTemplate, error) {
+	if err := s.checkManagePermission(userID); err != nil {
+		return nil, err
+	}
+	if err := validateTemplateItems(req.Items); err != nil {
+		return nil, err
+	}
+
+	template := &model.LogTemplate{
+		Name:        req.Name,
+		Description: req.Description,
+		Diagnosis:   strings.TrimSpace(req.Diagnosis),
+		Items:       req.Items,
+		CreatedBy:   userID,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+
+	if err := s.logTemplateDAO.CreateLogTemplate(template); err != nil {
+		return nil, fmt.Errorf("创建模板失败: %v", err)
+	}
+	return template, nil
+}
+
+// UpdateTemplate 更新日志模板（仅管理员和已认证康复师）
+func (s *LogTemplateService) UpdateTemplate(userID string, templateID uint, req *request.LogTemplateRequest) (*model.LogTemplate, error) {
+	if err := s.checkManagePermission(userID); err != nil {
+		return nil, err
+	}
+	if err := validateTemplateItems(req.Items); err != nil {
+		return nil, err
+	}
+
+	template, err := s.logTemplateDAO.GetLogTemplateByID(templateID)
+	if err != nil {
+		return nil, notFound("模板不存在")
+	}
+
+	template.Name = req.Name
+	template.Description = req.Description
+	template.Diagnosis = strings.TrimSpace(req.Diagnosis)
+	template.Items = req.Items
+	if req.IsActive != nil {
+		template.IsActive = *req.IsActive
+	}
+
+	if err := s.logTemplateDAO.UpdateLogTemplate(template); err != nil {
+		return nil, fmt.Errorf("更新模板失败: %v", err)
+	}
+	return template, nil
+}
+
+// DeleteTemplate 删除日志模板（仅管理员和已认证康复师）
+func (s *LogTemplateService) DeleteTemplate(userID string, templateID uint) error {
+	if err := s.checkManagePermission(userID); err != nil {
+		return err
+	}
+	return s.logTemplateDAO.DeleteLogTemplate(templateID)
+}
+
+// GetTemplate 获取模板详情
+func (s *LogTemplateService) GetTemplate(templateID uint) (*model.LogTemplate, error) {
+	return s.logTemplateDAO.GetLogTemplateByID(templateID)
+}
+
+// ListTemplates 获取模板库
+func (s *LogTemplateService) ListTemplates(diagnosis string, onlyActive bool) ([]model.LogTemplate, error) {
+	return s.logTemplateDAO.ListLogTemplates(diagnosis, onlyActive)
+}
+
+// AssignTemplate 手动为儿童分配模板
+func (s *LogTemplateService) AssignTemplate(userID, childArchiveID string, templateID uint) (*model.ChildLogTemplate, error) {
+	if _, err := checkChildAccess(s.userDAO, userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	template, err := s.logTemplateDAO.GetLogTemplateByID(templateID)
+	if err != nil || !template.IsActive {
+		return nil, invalidRequest("模板不存在或已停用")
+	}
+	if existing, err := s.logTemplateDAO.GetAssignment(childArchiveID, templateID); err == nil {
+		return existing, nil
+	}
+
+	assignment := &model.ChildLogTemplate{
+		ChildArchiveID: childArchiveID,
+		TemplateID:     templateID,
+		Source:         model.TemplateSourceManual,
+		AssignedBy:     userID,
+		Template:       *template,
+	}
+	if err := s.logTemplateDAO.AssignTemplate(assignment); err != nil {
+		return nil, fmt.Errorf("分配模板失败: %v", err)
+	}
+	return assignment, nil
+}
+
+// UnassignTemplate 取消儿童的手动模板分配
+func (s *LogTemplateService) UnassignTemplate(userID, childArchiveID string, templateID uint) error {
+	if _, err := checkChildAccess(s.userDAO, userID, childArchiveID); err != nil {
+		return err
+	}
+	return s.logTemplateDAO.DeleteAssignment(childArchiveID, templateID)
+}
+
+// GetTemplatesForChild 获取儿童可用的模板：手动分配的模板加上根据诊断匹配的模板
+func (s *LogTemplateService) GetTemplatesForChild(userID, childArchiveID string) ([]model.ChildLogTemplate, error) {
+	archive, err := checkChildAccess(s.userDAO, userID, childArchiveID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.logTemplateDAO.GetAssignmentsByChildID(childArchiveID)
+	if err != nil {
+		return nil, fmt.Errorf("获取模板分配失败: %v", err)
+	}
+
+	result := make([]model.ChildLogTemplate, 0, len(assignments))
+	assigned := make(map[uint]bool)
+	for _, assignment := range assignments {
+		if !assignment.Template.IsActive {
+			continue
+		}
+		assigned[assignment.TemplateID] = true
+		result = append(result, assignment)
+	}
+
+	if archive.Diagnosis == "" {
+		return result, nil
+	}
+	templates, err := s.logTemplateDAO.GetActiveDiagnosisTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("获取诊断模板失败: %v", err)
+	}
+	for _, template := range templates {
+		if assigned[template.ID] || !strings.Contains(archive.Diagnosis, template.Diagnosis) {
+			continue
+		}
+		result = append(result, model.ChildLogTemplate{
+			ChildArchiveID: childArchiveID,
+			TemplateID:     template.ID,
+			Source:         model.TemplateSourceDiagnosis,
+			Template:       template,
+		})
+	}
+	return result, nil
+}
+
+func (s *LogTemplateService) checkManagePermission(userID string) error {
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil || !canManageLibrary(user) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// validateTemplateItems 校验模板条目定义
+func validateTemplateItems(items []model.LogTemplateItem) error {
+	if len(items) == 0 {
+		return invalidRequest("模板至少需要一个条目")
+	}
+	keys := make(map[string]bool)
+	for _, item := range items {
+		if item.Key == "" || item.Label == "" {
+			return invalidRequest("模板条目的key和label不能为空")
+		}
+		if keys[item.Key] {
+			return invalidRequest("模板条目key重复: %s", item.Key)
+		}
+		keys[item.Key] = true
+
+		switch item.Type {
+		case model.TemplateItemQuestion:
+		case model.TemplateItemChecklist:
+			if len(item.Options) == 0 {
+				return invalidRequest("清单条目 %s 缺少可选项", item.Key)
+			}
+		case model.TemplateItemScale:
+			if item.Max <= item.Min {
+				return invalidRequest("量表条目 %s 的取值范围无效", item.Key)
+			}
+		default:
+			return invalidRequest("不支持的条目类型: %s", item.Type)
+		}
+	}
+	return nil
+}
+
+// normalizeTemplateAnswers 按模板校验答案，并补全条目的标题和类型，保持模板中的顺序
+func normalizeTemplateAnswers(template *model.LogTemplate, answers []model.LogTemplateAnswer) ([]model.LogTemplateAnswer, error) {
+	values := make(map[string]interface{}, len(answers))
+	for _, answer := range answers {
+		values[answer.Key] = answer.Value
+	}
+
+	normalized := make([]model.LogTemplateAnswer, 0, len(template.Items))
+	for _, item := range template.Items {
+		value, ok := values[item.Key]
+		if !ok || value == nil || value == "" {
+			if item.Required {
+				return nil, invalidRequest("缺少必填项: %s", item.Label)
+			}
+			continue
+		}
+
+		switch item.Type {
+		case model.TemplateItemQuestion:
+			text, ok := value.(string)
+			if !ok {
+				return nil, invalidRequest("%s 的答案必须是文本", item.Label)
+			}
+			value = strings.TrimSpace(text)
+		case model.TemplateItemChecklist:
+			selected, err := toStringSlice(value)
+			if err != nil {
+				return nil, invalidRequest("%s 的答案必须是选项列表", item.Label)
+			}
+			for _, option := range selected {
+				if !containsString(item.Options, option) {
+					return nil, invalidRequest("%s 包含无效选项: %s", item.Label, option)
+				}
+			}
+			value = selected
+		case model.TemplateItemScale:
+			score, ok := value.(float64)
+			if !ok || score < float64(item.Min) || score > float64(item.Max) {
+				return nil, invalidRequest("%s 的评分必须在 %d 到 %d 之间", item.Label, item.Min, item.Max)
+			}
+			value = score
+		}
+
+		normalized = append(normalized, model.LogTemplateAnswer{
+			Key:   item.Key,
+			Label: item.Label,
+			Type:  item.Type,
+			Value: value,
+		})
+	}
+	return normalized, nil
+}
+
+func toStringSlice(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case []string:
+		return v, nil
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			text, ok := item.(string)
+			if !ok {
+				return nil, errors.New("invalid option")
+			}
+			result = append(result, text)
+		}
+		return result, nil
+	}
+	return nil, errors.New("invalid options")
+}
+
+func containsString(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+// formatTemplateAnswerValue 将答案值格式化为可读文本
+func formatTemplateAnswerValue(answer model.LogTemplateAnswer) string {
+	switch v := answer.Value.(type) {
+	case []string:
+		return strings.Join(v, "、")
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, "、")
+	case float64:
+		return fmt.Sprintf("%g", v)
+	}
+	return fmt.Sprint(answer.Value)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"melody_cure/DAO"
+	"melody_cure/model"
+)
+
+type NotificationService struct {
+	notificationDAO *DAO.NotificationDAO
+}
+
+func NewNotificationService(notificationDAO *DAO.NotificationDAO) *NotificationService {
+	return &NotificationService{notificationDAO: notificationDAO}
+}
+
+// Notify 给用户发送一条站内通知
+func (s *NotificationService) Notify(userID, notificationType, title, content, relatedType, relatedID string) error {
+	return s.notificationDAO.CreateNotification(&model.Notification{
+		UserID:      userID,
+		Type:        notificationType,
+		Title:       title,
+		Content:     content,
+		RelatedType: relatedType,
+		RelatedID:   relatedID,
+	})
+}
+
+// GetNotifications 获取用户的通知列表及未读数量
+func (s *NotificationService) GetNotifications(userID string, unreadOnly bool) ([]model.Notification, int64, error) {
+	notifications, err := s.notificationDAO.GetNotificationsByUserID(userID, unreadOnly)
+	if err != nil {
+		return nil, 0, err
+	}
+	unread, err := s.notificationDAO.CountUnread(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, unread, nil
+}
+
+// MarkAsRead 标记通知为已读
+func (s *NotificationService) MarkAsRead(userID string, notificationID uint) error {
+	return s.notificationDAO.MarkAsRead(userID, notificationID)
+}
+
+// MarkAllAsRead 标记全部通知为已读
+func (s *NotificationService) MarkAllAsRead(userID string) error {
+	return s.notificationDAO.MarkAllAsRead(userID)
+}
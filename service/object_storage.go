@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"melody_cure/config"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ObjectStorage 保存服务端生成的文件，如合成的语音和上传的语音记录
+type ObjectStorage interface {
+	Name() string
+	// Put 保存对象并返回访问地址，key 已存在时覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	// Lookup 返回已存在对象的访问地址，对象不存在时返回 false
+	Lookup(ctx context.Context, key string) (string, bool, error)
+}
+
+// 支持的对象存储
+const (
+	ObjectStorageLocal = "local" // 本地目录，由服务在 storage.baseURL 下提供文件
+	ObjectStorageQiniu = "qiniu" // 七牛云，使用 qiniu 配置
+)
+
+// NewObjectStorage 根据存储配置创建对象存储
+func NewObjectStorage() (ObjectStorage, error) {
+	cfg := config.GetStorageConfig()
+	switch strings.ToLower(cfg.Provider) {
+	case ObjectStorageQiniu:
+		return NewQiniuObjectStorage(config.GetQiniuConfig()), nil
+	case ObjectStorageLocal, "":
+	default:
+		log.Printf("不支持的对象存储 %s，使用本地存储", cfg.Provider)
+	}
+	return NewLocalObjectStorage(cfg.LocalDir, cfg.BaseURL)
+}
+
+// validObjectKey 对象键只能是不含 .. 的相对路径
+func validObjectKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "..") {
+		return fmt.Errorf("对象键无效: %s", key)
+	}
+	return nil
+}
+
+// LocalObjectStorage 将对象保存到本地目录
+type LocalObjectStorage struct {
+	dir       string
+	baseURL   string
+	servePath string
+}
+
+// NewLocalObjectStorage baseURL 可以是路径（如 /media）或经 CDN 等转发到本服务的完整地址，
+// 服务在其路径部分下提供文件，路径不能为根路径或位于 /api 下
+func NewLocalObjectStorage(dir, baseURL string) (*LocalObjectStorage, error) {
+	baseURL = strings.TrimRight(baseURL, "/")
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("storage.baseURL 无效: %v", err)
+	}
+	servePath := strings.TrimRight(parsed.Path, "/")
+	if !strings.HasPrefix(servePath, "/") || path.Clean(servePath) != servePath || strings.ContainsAny(servePath, ":*") ||
+		servePath == "/api" || strings.HasPrefix(servePath, "/api/") {
+		return nil, fmt.Errorf("storage.baseURL 的路径 %q 不能作为文件访问路径，请使用如 /media 的路径", parsed.Path)
+	}
+	return &LocalObjectStorage{dir: dir, baseURL: baseURL, servePath: servePath}, nil
+}
+
+func (s *LocalObjectStorage) Name() string {
+	return ObjectStorageLocal
+}
+
+// Dir 本地存储目录，用于注册静态文件路由
+func (s *LocalObjectStorage) Dir() string {
+	return s.dir
+}
+
+// ServePath 服务提供本地存储文件的路由路径，即 storage.baseURL 的路径部分
+func (s *LocalObjectStorage) ServePath() string {
+	return s.servePath
+}
+
+func (s *LocalObjectStorage) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	if err := validObjectKey(key); err != nil {
+		return "", err
+	}
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("创建存储目录失败: %v", err)
+	}
+	// 先写入临时文件再重命名，避免读到写了一半的文件
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("保存文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("保存文件失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("保存文件失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", fmt.Errorf("保存文件失败: %v", err)
+	}
+	return s.baseURL + "/" + key, nil
+}
+
+func (s *LocalObjectStorage) Lookup(ctx context.Context, key string) (string, bool, error) {
+	if err := validObjectKey(key); err != nil {
+		return "", false, err
+	}
+	if _, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(key))); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return s.baseURL + "/" + key, true, nil
+}
+
+// qiniuUploadHosts 各存储区域的上传地址
+var qiniuUploadHosts = map[string]string{
+	"z0":  "up.qiniup.com",
+	"z1":  "up-z1.qiniup.com",
+	"z2":  "up-z2.qiniup.com",
+	"na0": "up-na0.qiniup.com",
+	"as0": "up-as0.qiniup.com",
+}
+
+// QiniuObjectStorage 通过表单上传将对象保存到七牛云，存储空间需要绑定可公开访问的域名
+type QiniuObjectStorage struct {
+	cfg    config.QiniuConfig
+	client *http.Client
+}
+
+func NewQiniuObjectStorage(cfg config.QiniuConfig) *QiniuObjectStorage {
+	return &QiniuObjectStorage{cfg: cfg, client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+func (s *QiniuObjectStorage) Name() string {
+	return ObjectStorageQiniu
+}
+
+func (s *QiniuObjectStorage) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	if err := validObjectKey(key); err != nil {
+		return "", err
+	}
+	expires := s.cfg.Expires
+	if expires <= 0 {
+		expires = 3600
+	}
+	// 指定 key 的上传策略允许覆盖已有对象
+	token, err := qiniuUploadToken(s.cfg, map[string]interface{}{
+		"scope":    s.cfg.Bucket + ":" + key,
+		"deadline": time.Now().Unix() + expires,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("token", token)
+	writer.WriteField("key", key)
+	part, err := writer.CreateFormFile("file", path.Base(key))
+	if err != nil {
+		return "", err
+	}
+	part.Write(data)
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	host, ok := qiniuUploadHosts[s.cfg.Zone]
+	if !ok {
+		host = qiniuUploadHosts["z0"]
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.scheme()+host, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("上传到七牛云失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var result struct {
+			Error string `json:"error"`
+		}
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		json.Unmarshal(raw, &result)
+		return "", fmt.Errorf("上传到七牛云失败: HTTP %d %s", resp.StatusCode, result.Error)
+	}
+	return s.objectURL(key), nil
+}
+
+func (s *QiniuObjectStorage) Lookup(ctx context.Context, key string) (string, bool, error) {
+	if err := validObjectKey(key); err != nil {
+		return "", false, err
+	}
+	url := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return "", false, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return url, true, nil
+	case http.StatusNotFound:
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("查询七牛云对象失败: HTTP %d", resp.StatusCode)
+}
+
+func (s *QiniuObjectStorage) scheme() string {
+	if s.cfg.UseHTTPS {
+		return "https://"
+	}
+	return "http://"
+}
+
+// objectURL 对象的公开访问地址，绑定的域名未包含协议时按 use_https 补全
+func (s *QiniuObjectStorage) objectURL(key string) string {
+	domain := strings.TrimRight(s.cfg.Domain, "/")
+	if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
+		domain = s.scheme() + domain
+	}
+	return domain + "/" + key
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"melody_cure/DAO"
+)
+
+var (
+	ErrPermissionDenied = errors.New("没有操作权限")
+	ErrChildNotFound    = errors.New("儿童档案不存在")
+)
+
+// isAdmin 是否为管理员
+func isAdmin(user *DAO.User) bool {
+	return user.Identity == DAO.IdentityAdmin
+}
+
+// isCertifiedTherapist 是否为已通过认证的康复师
+func isCertifiedTherapist(user *DAO.User) bool {
+	return user.Identity == DAO.IdentityTherapist && user.Certification
+}
+
+// canManageLibrary 管理员和已认证的康复师可以维护模板库等专业内容
+func canManageLibrary(user *DAO.User) bool {
+	return isAdmin(user) || isCertifiedTherapist(user)
+}
+
+// checkChildAccess 校验用户是否可以访问指定儿童档案：家长本人、管理员，
+// 或获得家长授权、正在接手该儿童咨询转介的认证康复师
+func checkChildAccess(userDAO *DAO.UserDAO, userID, childArchiveID string) (*DAO.ChildArchive, error) {
+	archive, err := userDAO.GetChildArchiveByID(childArchiveID)
+	if err != nil {
+		return nil, ErrChildNotFound
+	}
+	if archive.UserID == userID {
+		return archive, nil
+	}
+	user, err := userDAO.GetUserByID(userID)
+	if err != nil {
+		return nil, ErrPermissionDenied
+	}
+	if isAdmin(user) {
+		return archive, nil
+	}
+	if isCertifiedTherapist(user) {
+		if granted, err := userDAO.HasTherapistAccess(childArchiveID, userID); err == nil && granted {
+			return archive, nil
+		}
+	}
+	return nil, ErrPermissionDenied
+}
+
+// checkTherapistGrant 校验用户是否为获得该儿童授权（家长授权或接手咨询转介）的认证康复师，
+// 用于只能由负责该儿童的康复师进行的专业操作，家长和管理员不因身份而通过
+func checkTherapistGrant(userDAO *DAO.UserDAO, user *DAO.User, childArchiveID string) error {
+	if !isCertifiedTherapist(user) {
+		return ErrPermissionDenied
+	}
+	granted, err := userDAO.HasTherapistAccess(childArchiveID, user.ID)
+	if err != nil {
+		return fmt.Errorf("查询康复师授权失败: %v", err)
+	}
+	if !granted {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// checkChildOwner 校验用户是否为儿童的家长或管理员，用于授权等只能由家长决定的操作
+func checkChildOwner(userDAO *DAO.UserDAO, userID, childArchiveID string) (*DAO.ChildArchive, error) {
+	archive, err := userDAO.GetChildArchiveByID(childArchiveID)
+	if err != nil {
+		return nil, ErrChildNotFound
+	}
+	if archive.UserID == userID {
+		return archive, nil
+	}
+	user, err := userDAO.GetUserByID(userID)
+	if err != nil || !isAdmin(user) {
+		return nil, ErrPermissionDenied
+	}
+	return archive, nil
+}
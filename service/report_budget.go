@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"melody_cure/config"
+	"time"
+)
+
+const (
+	// windowSummaryReportType 分段摘要请求的报告类型，仅供模拟提供商识别
+	windowSummaryReportType = "window_summary"
+	// promptTokenMargin 估算误差的预留空间
+	promptTokenMargin = 256
+)
+
+// estimateTokens 粗略估算文本的 token 数：中日韩字符按每字1.5个 token，其余字符按每4个字符1个 token
+func estimateTokens(text string) int {
+	cjk, other := 0, 0
+	for _, r := range text {
+		if r >= 0x2E80 {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + cjk/2 + (other+3)/4
+}
+
+// promptBudget 用户提示词可使用的 token 数：上下文长度减去输出上限和系统消息
+func promptBudget(systemPrompt string) int {
+	aiConfig := config.GetAIConfig()
+	budget := aiConfig.Budget.ContextTokens - aiConfig.MaxTokens - estimateTokens(systemPrompt) - promptTokenMargin
+	if budget < 1000 {
+		budget = 1000
+	}
+	return budget
+}
+
+// fitPromptToBudget 提示词超出模型上下文时，将日志按时间窗口分段摘要（map），再用各段摘要渲染最终提示词（reduce）。
+// 摘要过多仍超出预算时逐级合并相邻摘要
+func (s *AIReportService) fitPromptToBudget(ctx context.Context, prepared *preparedReport, data *ReportPromptData) error {
+	budget := promptBudget(prepared.systemPrompt)
+	if estimateTokens(prepared.prompt) <= budget {
+		return nil
+	}
+
+	windows, err := s.summarizeWindows(ctx, data, splitLogWindows(data.Logs, config.GetAIConfig().Budget.WindowDays))
+	if err != nil {
+		return err
+	}
+	data.Logs = nil
+	for {
+		data.WindowSummaries = windows
+		prompt, err := renderPrompt(prepared.template.Template, data)
+		if err != nil {
+			return fmt.Errorf("构建AI请求失败: %v", err)
+		}
+		if estimateTokens(prompt) <= budget {
+			log.Printf("报告 %s 提示词超出预算，已按 %d 个时间段摘要后汇总", prepared.reportType.Key, len(windows))
+			prepared.prompt = prompt
+			return nil
+		}
+		if len(windows) <= 1 {
+			return invalidRequest("疗愈记录过多，摘要后仍超出模型上下文长度，请缩短报告时间段")
+		}
+		if windows, err = s.mergeWindowSummaries(ctx, data, windows); err != nil {
+			return err
+		}
+	}
+}
+
+// splitLogWindows 按固定天数的时间窗口切分日志，窗口从 Unix 纪元起对齐，
+// 重叠时间段的报告会得到相同的窗口，从而复用缓存的摘要。单个窗口超出预算时再按 token 数切分
+func splitLogWindows(logs []ReportPromptLog, windowDays int) [][]ReportPromptLog {
+	if windowDays <= 0 {
+		windowDays = 7
+	}
+	window := time.Duration(windowDays) * 24 * time.Hour
+	chunkBudget := windowPromptBudget()
+
+	var windows [][]ReportPromptLog
+	var current []ReportPromptLog
+	currentTokens := 0
+	var currentBucket int64 = -1
+	for _, entry := range logs {
+		_, offset := entry.Time.Zone()
+		bucket := (entry.Time.Unix() + int64(offset)) / int64(window/time.Second)
+		tokens := estimateTokens(entry.Content) + 50*len(entry.Answers) + 20
+		if len(current) > 0 && (bucket != currentBucket || currentTokens+tokens > chunkBudget) {
+			windows = append(windows, current)
+			current, currentTokens = nil, 0
+		}
+		current = append(current, entry)
+		currentTokens += tokens
+		currentBucket = bucket
+	}
+	if len(current) > 0 {
+		windows = append(windows, current)
+	}
+	return windows
+}
+
+// windowPromptBudget 分段摘要请求的提示词预算
+func windowPromptBudget() int {
+	aiConfig := config.GetAIConfig()
+	budget := aiConfig.Budget.ContextTokens - aiConfig.Budget.WindowSummaryTokens - 1000
+	if budget < 1000 {
+		budget = 1000
+	}
+	return budget
+}
+
+// summarizeWindows 为每个时间窗口生成摘要
+func (s *AIReportService) summarizeWindows(ctx context.Context, data *ReportPromptData, windows [][]ReportPromptLog) ([]ReportPromptWindow, error) {
+	summaries := make([]ReportPromptWindow, 0, len(windows))
+	for _, logs := range windows {
+		// 窗口内重新编号，使相同日志集合渲染出相同的提示词
+		entries := make([]ReportPromptLog, len(logs))
+		for i, entry := range logs {
+			entry.Index = i + 1
+			entries[i] = entry
+		}
+		windowData := &ReportPromptData{
+			ReportType: data.ReportType,
+			ReportName: data.ReportName,
+			Child:      data.Child,
+			Logs:       entries,
+			LogCount:   len(entries),
+			FirstLogAt: entries[0].Time,
+			LastLogAt:  entries[len(entries)-1].Time,
+			Metrics:    summarizeLogMetrics(entries),
+		}
+		summary, err := s.summarizeWindow(ctx, windowData)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, ReportPromptWindow{
+			Start:    windowData.FirstLogAt,
+			End:      windowData.LastLogAt,
+			LogCount: len(entries),
+			Summary:  summary,
+		})
+	}
+	return summaries, nil
+}
+
+// mergeWindowSummaries 两两合并相邻的时间段摘要
+func (s *AIReportService) mergeWindowSummaries(ctx context.Context, data *ReportPromptData, windows []ReportPromptWindow) ([]ReportPromptWindow, error) {
+	merged := make([]ReportPromptWindow, 0, (len(windows)+1)/2)
+	for i := 0; i < len(windows); i += 2 {
+		if i+1 == len(windows) {
+			merged = append(merged, windows[i])
+			break
+		}
+		pair := windows[i : i+2]
+		windowData := &ReportPromptData{
+			ReportType:      data.ReportType,
+			ReportName:      data.ReportName,
+			Child:           data.Child,
+			LogCount:        pair[0].LogCount + pair[1].LogCount,
+			FirstLogAt:      pair[0].Start,
+			LastLogAt:       pair[1].End,
+			WindowSummaries: pair,
+		}
+		summary, err := s.summarizeWindow(ctx, windowData)
+		if err != nil {
+			return nil, err
+		}
+		merged = append(merged, ReportPromptWindow{
+			Start:    pair[0].Start,
+			End:      pair[1].End,
+			LogCount: windowData.LogCount,
+			Summary:  summary,
+		})
+	}
+	return merged, nil
+}
+
+// summarizeWindow 生成一个时间段的摘要，相同的提示词直接使用缓存
+func (s *AIReportService) summarizeWindow(ctx context.Context, data *ReportPromptData) (string, error) {
+	text, err := promptFS.ReadFile("prompts/window_summary.tmpl")
+	if err != nil {
+		return "", err
+	}
+	prompt, err := renderPrompt(string(text), data)
+	if err != nil {
+		return "", fmt.Errorf("构建分段摘要请求失败: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(s.llmProvider.Name() + "\x00" + reportSystemMessage + "\x00" + prompt))
+	key := hex.EncodeToString(sum[:])
+	if summary, ok, err := s.reportCacheDAO.GetWindowSummary(ctx, key); err != nil {
+		log.Printf("读取分段摘要缓存失败: %v", err)
+	} else if ok {
+		return summary, nil
+	}
+
+	budget := config.GetAIConfig().Budget
+	resp, err := s.llmProvider.Chat(ctx, &LLMRequest{
+		Messages: []LLMMessage{
+			{Role: "system", Content: reportSystemMessage},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   budget.WindowSummaryTokens,
+		Temperature: 0.3,
+		ReportType:  windowSummaryReportType,
+		PromptData:  data,
+	})
+	if err != nil {
+		return "", fmt.Errorf("生成分段摘要失败: %w", err)
+	}
+
+	ttl := time.Duration(budget.SummaryCacheTTL) * time.Hour
+	if ttl > 0 {
+		if err := s.reportCacheDAO.SetWindowSummary(ctx, key, resp.Content, ttl); err != nil {
+			log.Printf("写入分段摘要缓存失败: %v", err)
+		}
+	}
+	return resp.Content, nil
+}
+
+// summarizeLogMetrics 汇总日志中的指标
+func summarizeLogMetrics(logs []ReportPromptLog) []ReportPromptMetric {
+	var metrics []ReportPromptMetric
+	index := make(map[string]int)
+	for _, entry := range logs {
+		for _, metric := range entry.Metrics {
+			idx, ok := index[metric.Name]
+			if !ok {
+				idx = len(metrics)
+				index[metric.Name] = idx
+				metrics = append(metrics, ReportPromptMetric{Name: metric.Name, Unit: metric.Unit, First: metric.Value, Min: metric.Value, Max: metric.Value})
+			}
+			summary := &metrics[idx]
+			summary.Count++
+			summary.Last = metric.Value
+			if metric.Value < summary.Min {
+				summary.Min = metric.Value
+			}
+			if metric.Value > summary.Max {
+				summary.Max = metric.Value
+			}
+			summary.Avg += (metric.Value - summary.Avg) / float64(summary.Count)
+		}
+	}
+	return metrics
+}
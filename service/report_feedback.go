@@ -0,0 +1,236 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/model"
+	"strings"
+	"time"
+)
+
+type ReportFeedbackService struct {
+	feedbackDAO     *DAO.ReportFeedbackDAO
+	userDAO         *DAO.UserDAO
+	aiReportService *AIReportService
+}
+
+func NewReportFeedbackService(feedbackDAO *DAO.ReportFeedbackDAO, userDAO *DAO.UserDAO, aiReportService *AIReportService) *ReportFeedbackService {
+	return &ReportFeedbackService{
+		feedbackDAO:     feedbackDAO,
+		userDAO:         userDAO,
+		aiReportService: aiReportService,
+	}
+}
+
+// ReportReviewForm 报告中可评价的章节和建议，供前端生成评价表单
+type ReportReviewForm struct {
+	Sections    []string `json:"sections"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// ReportFeedbackDetail 报告的评价及其生成信息
+type ReportFeedbackDetail struct {
+	ReportID        uint                   `json:"report_id"`
+	ReportVersion   int                    `json:"report_version"`
+	TemplateVersion int                    `json:"template_version"`
+	Provider        string                 `json:"provider"`
+	Model           string                 `json:"model"`
+	Form            ReportReviewForm       `json:"form"`
+	Feedback        []model.ReportFeedback `json:"feedback"`
+}
+
+// FeedbackGroupStats 一个模板版本和模型组合的评价汇总
+type FeedbackGroupStats struct {
+	model.ReportFeedbackStats
+	ReportTypeName    string  `json:"report_type_name"`
+	HallucinationRate float64 `json:"hallucination_rate"` // 标记了幻觉的评价占比
+	AdoptionRate      float64 `json:"adoption_rate"`      // 建议采纳率
+}
+
+// FeedbackAnalytics 评价汇总
+type FeedbackAnalytics struct {
+	Start   *time.Time           `json:"start,omitempty"`
+	End     *time.Time           `json:"end,omitempty"`
+	Reviews int64                `json:"reviews"`
+	Groups  []FeedbackGroupStats `json:"groups"`
+}
+
+// SubmitFeedback 获得儿童授权的认证康复师评价报告的当前版本，重复提交时更新自己的评价
+func (s *ReportFeedbackService) SubmitFeedback(userID string, reportID uint, req *request.ReportFeedbackRequest) (*model.ReportFeedback, error) {
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil || !isCertifiedTherapist(user) {
+		return nil, ErrPermissionDenied
+	}
+	report, err := s.aiReportService.getAccessibleReport(userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTherapistGrant(s.userDAO, user, report.ChildArchiveID); err != nil {
+		return nil, err
+	}
+
+	form := reportReviewForm(report)
+	known := make(map[string]bool, len(form.Sections))
+	for _, section := range form.Sections {
+		known[section] = true
+	}
+	feedback := &model.ReportFeedback{
+		ReportID:        report.ID,
+		ReviewerID:      userID,
+		ReportVersion:   report.CurrentVersion,
+		ReportEdited:    report.IsEdited,
+		ReportType:      report.ReportType,
+		TemplateID:      report.TemplateID,
+		TemplateVersion: report.TemplateVersion,
+		Provider:        report.Provider,
+		Model:           report.Model,
+		Accuracy:        req.Accuracy,
+		Usefulness:      req.Usefulness,
+		Safety:          req.Safety,
+		Comment:         strings.TrimSpace(req.Comment),
+	}
+	if feedback.ReportVersion == 0 {
+		feedback.ReportVersion = 1
+	}
+	for _, section := range req.Sections {
+		if len(known) > 0 && !known[section.Section] {
+			return nil, invalidRequest("报告中没有章节“%s”", section.Section)
+		}
+		feedback.Sections = append(feedback.Sections, model.SectionFeedback{
+			Section:    section.Section,
+			Accuracy:   section.Accuracy,
+			Usefulness: section.Usefulness,
+			Safety:     section.Safety,
+			Comment:    strings.TrimSpace(section.Comment),
+		})
+	}
+	for _, flag := range req.Hallucinations {
+		feedback.Hallucinations = append(feedback.Hallucinations, model.HallucinationFlag{
+			Section: flag.Section,
+			Excerpt: strings.TrimSpace(flag.Excerpt),
+			Comment: strings.TrimSpace(flag.Comment),
+		})
+	}
+	for _, suggestion := range req.Suggestions {
+		feedback.Suggestions = append(feedback.Suggestions, model.SuggestionFeedback{
+			Suggestion: strings.TrimSpace(suggestion.Suggestion),
+			Adopted:    suggestion.Adopted,
+			Comment:    strings.TrimSpace(suggestion.Comment),
+		})
+		if suggestion.Adopted {
+			feedback.AdoptedCount++
+		}
+	}
+	feedback.HallucinationCount = len(feedback.Hallucinations)
+	feedback.SuggestionCount = len(feedback.Suggestions)
+
+	if err := s.feedbackDAO.SaveReportFeedback(feedback); err != nil {
+		return nil, fmt.Errorf("保存报告评价失败: %v", err)
+	}
+	return feedback, nil
+}
+
+// GetReportFeedback 获取报告的评价和评价表单，仅管理员和获得儿童授权的认证康复师可以查看
+func (s *ReportFeedbackService) GetReportFeedback(userID string, reportID uint) (*ReportFeedbackDetail, error) {
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil || !canManageLibrary(user) {
+		return nil, ErrPermissionDenied
+	}
+	report, err := s.aiReportService.getAccessibleReport(userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin(user) {
+		if err := checkTherapistGrant(s.userDAO, user, report.ChildArchiveID); err != nil {
+			return nil, err
+		}
+	}
+	feedback, err := s.feedbackDAO.GetFeedbackByReportID(report.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取报告评价失败: %v", err)
+	}
+	return &ReportFeedbackDetail{
+		ReportID:        report.ID,
+		ReportVersion:   report.CurrentVersion,
+		TemplateVersion: report.TemplateVersion,
+		Provider:        report.Provider,
+		Model:           report.Model,
+		Form:            reportReviewForm(report),
+		Feedback:        feedback,
+	}, nil
+}
+
+// GetFeedbackAnalytics 按报告类型、提示词模板版本和模型汇总评价，用于比较提示词修改前后的效果（仅管理员）
+func (s *ReportFeedbackService) GetFeedbackAnalytics(userID string, filter DAO.ReportFeedbackFilter) (*FeedbackAnalytics, error) {
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil || !isAdmin(user) {
+		return nil, ErrPermissionDenied
+	}
+	stats, err := s.feedbackDAO.SummarizeFeedback(filter)
+	if err != nil {
+		return nil, fmt.Errorf("汇总报告评价失败: %v", err)
+	}
+
+	analytics := &FeedbackAnalytics{Start: filter.Start, End: filter.End, Groups: make([]FeedbackGroupStats, 0, len(stats))}
+	for _, stat := range stats {
+		stat.AvgAccuracy = roundTo(stat.AvgAccuracy, 2)
+		stat.AvgUsefulness = roundTo(stat.AvgUsefulness, 2)
+		stat.AvgSafety = roundTo(stat.AvgSafety, 2)
+		group := FeedbackGroupStats{ReportFeedbackStats: stat, ReportTypeName: s.aiReportService.ReportTypeName(stat.ReportType)}
+		if stat.Reviews > 0 {
+			group.HallucinationRate = roundTo(float64(stat.HallucinatedReviews)/float64(stat.Reviews), 3)
+		}
+		if stat.Suggestions > 0 {
+			group.AdoptionRate = roundTo(float64(stat.AdoptedSuggestions)/float64(stat.Suggestions), 3)
+		}
+		analytics.Reviews += stat.Reviews
+		analytics.Groups = append(analytics.Groups, group)
+	}
+	return analytics, nil
+}
+
+// reportReviewForm 提取报告的章节和建议。结构化报告取章节标题和推荐活动；
+// Markdown 报告取一、二级标题，以及标题含“建议”或“推荐”的章节下的列表项
+func reportReviewForm(report *model.GeneratedReport) ReportReviewForm {
+	form := ReportReviewForm{Sections: []string{}, Suggestions: []string{}}
+	if len(report.StructuredContent) > 0 {
+		var structured model.StructuredReport
+		if err := json.Unmarshal(report.StructuredContent, &structured); err == nil && len(structured.Sections) > 0 {
+			for _, section := range structured.Sections {
+				form.Sections = append(form.Sections, section.Heading)
+			}
+			for _, activity := range structured.RecommendedActivities {
+				title := activity.Title
+				if title == "" {
+					title = activity.ID
+				}
+				form.Suggestions = append(form.Suggestions, title+"："+activity.Reason)
+			}
+			return form
+		}
+	}
+
+	inSuggestions := false
+	for _, block := range markdownBlocks(report.Content, 0) {
+		switch block.Kind {
+		case exportBlockHeading:
+			if block.Level <= 2 {
+				form.Sections = append(form.Sections, block.Text)
+			}
+			inSuggestions = strings.Contains(block.Text, "建议") || strings.Contains(block.Text, "推荐")
+		case exportBlockBullets:
+			if inSuggestions {
+				form.Suggestions = append(form.Suggestions, block.Items...)
+			}
+		}
+	}
+	return form
+}
+
+func roundTo(value float64, digits int) float64 {
+	scale := math.Pow(10, float64(digits))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,441 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"melody_cure/DAO"
+	"melody_cure/config"
+	"melody_cure/model"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	reportJobPollInterval   = time.Second
+	reportJobCancelInterval = 2 * time.Second
+	reportJobStaleAfter     = 2 * time.Minute // 心跳超过该时间未更新的执行中任务视为中断
+	reportJobMaxRetryDelay  = 10 * time.Minute
+)
+
+var ErrReportJobFinished = errors.New("任务已结束，无法取消")
+
+// ReportJobService 报告异步生成任务：入队、执行、重试、取消与完成通知
+type ReportJobService struct {
+	reportJobDAO        *DAO.ReportJobDAO
+	userDAO             *DAO.UserDAO
+	aiReportService     *AIReportService
+	notificationService *NotificationService
+}
+
+func NewReportJobService(reportJobDAO *DAO.ReportJobDAO, userDAO *DAO.UserDAO, aiReportService *AIReportService, notificationService *NotificationService) *ReportJobService {
+	return &ReportJobService{
+		reportJobDAO:        reportJobDAO,
+		userDAO:             userDAO,
+		aiReportService:     aiReportService,
+		notificationService: notificationService,
+	}
+}
+
+// SubmitJob 校验权限后创建报告生成任务并放入队列
+func (s *ReportJobService) SubmitJob(ctx context.Context, userID, childArchiveID, reportType string, startDate, endDate *time.Time, opts *ReportOptions) (*model.ReportJob, error) {
+	if _, err := checkChildAccess(s.userDAO, userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	if err := s.aiReportService.CheckReportType(reportType); err != nil {
+		return nil, err
+	}
+
+	job := &model.ReportJob{
+		UserID:         userID,
+		ChildArchiveID: childArchiveID,
+		ReportType:     reportType,
+		StartDate:      startDate,
+		EndDate:        endDate,
+	}
+	if opts != nil {
+		job.Language = opts.Language
+	}
+	if opts != nil && opts.Comparison != nil {
+		job.Comparison = &model.ReportJobComparison{
+			Skill:         opts.Comparison.Skill,
+			BaselineStart: opts.Comparison.BaselineStart,
+			BaselineEnd:   opts.Comparison.BaselineEnd,
+			FollowUpStart: opts.Comparison.FollowUpStart,
+			FollowUpEnd:   opts.Comparison.FollowUpEnd,
+		}
+	}
+	return job, s.EnqueueJob(ctx, job)
+}
+
+// EnqueueJob 保存任务并放入队列，不做权限校验，供定时任务等内部调用
+func (s *ReportJobService) EnqueueJob(ctx context.Context, job *model.ReportJob) error {
+	now := time.Now()
+	job.Status = model.ReportJobPending
+	job.MaxAttempts = config.GetReportJobConfig().MaxAttempts
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = 1
+	}
+	job.NextRunAt = &now
+	if err := s.reportJobDAO.CreateReportJob(job); err != nil {
+		return fmt.Errorf("创建报告任务失败: %v", err)
+	}
+	if err := s.reportJobDAO.EnqueueReportJob(ctx, job.ID, now); err != nil {
+		s.reportJobDAO.TransitionReportJob(job.ID, model.ReportJobPending, map[string]interface{}{
+			"status":      model.ReportJobFailed,
+			"last_error":  "任务入队失败: " + err.Error(),
+			"finished_at": now,
+		})
+		return fmt.Errorf("报告任务入队失败: %v", err)
+	}
+	return nil
+}
+
+// GetJob 获取任务状态，成功时同时返回生成的报告
+func (s *ReportJobService) GetJob(userID string, jobID uint) (*model.ReportJob, *model.GeneratedReport, error) {
+	job, err := s.getAccessibleJob(userID, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != model.ReportJobSucceeded || job.ReportID == nil {
+		return job, nil, nil
+	}
+	report, err := s.aiReportService.GetReportByID(*job.ReportID)
+	if err != nil {
+		return job, nil, nil
+	}
+	return job, report, nil
+}
+
+// ListJobs 获取儿童最近的报告任务
+func (s *ReportJobService) ListJobs(userID, childArchiveID string) ([]model.ReportJob, error) {
+	if _, err := checkChildAccess(s.userDAO, userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	return s.reportJobDAO.GetReportJobsByChildID(childArchiveID, 50)
+}
+
+// CancelJob 取消任务：排队中的任务直接移出队列，执行中的任务通知执行副本中断模型请求
+func (s *ReportJobService) CancelJob(ctx context.Context, userID string, jobID uint) (*model.ReportJob, error) {
+	job, err := s.getAccessibleJob(userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled := map[string]interface{}{"status": model.ReportJobCancelled, "finished_at": time.Now()}
+	switch job.Status {
+	case model.ReportJobPending:
+		ok, err := s.reportJobDAO.TransitionReportJob(job.ID, model.ReportJobPending, cancelled)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if err := s.reportJobDAO.RemoveQueuedReportJob(ctx, job.ID); err != nil {
+				log.Printf("移除报告任务 %d 队列记录失败: %v", job.ID, err)
+			}
+			break
+		}
+		// 状态已变化（刚开始执行），按执行中任务处理
+		fallthrough
+	case model.ReportJobRunning:
+		ok, err := s.reportJobDAO.TransitionReportJob(job.ID, model.ReportJobRunning, cancelled)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrReportJobFinished
+		}
+		if err := s.reportJobDAO.RequestReportJobCancel(ctx, job.ID); err != nil {
+			log.Printf("发送报告任务 %d 取消信号失败: %v", job.ID, err)
+		}
+	default:
+		return nil, ErrReportJobFinished
+	}
+	return s.reportJobDAO.GetReportJobByID(job.ID)
+}
+
+// StartWorkers 启动报告生成任务的执行协程和中断任务回收协程，ctx 取消后退出
+func (s *ReportJobService) StartWorkers(ctx context.Context, workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go s.workerLoop(ctx)
+	}
+	go s.reapLoop(ctx)
+	log.Printf("报告生成任务已启动，并发数 %d", workers)
+}
+
+func (s *ReportJobService) workerLoop(ctx context.Context) {
+	ticker := time.NewTicker(reportJobPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for ctx.Err() == nil {
+			jobID, ok, err := s.reportJobDAO.ClaimDueReportJob(ctx, time.Now())
+			if err != nil {
+				log.Printf("获取报告任务失败: %v", err)
+				break
+			}
+			if !ok {
+				break
+			}
+			s.runJob(ctx, jobID)
+		}
+	}
+}
+
+// runJob 执行一次任务，执行期间定期检查取消信号并更新心跳
+func (s *ReportJobService) runJob(ctx context.Context, jobID uint) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("报告任务 %d 异常: %v", jobID, r)
+			s.handleFailure(jobID, fmt.Errorf("任务异常: %v", r))
+		}
+	}()
+
+	now := time.Now()
+	ok, err := s.reportJobDAO.TransitionReportJob(jobID, model.ReportJobPending, map[string]interface{}{
+		"status":       model.ReportJobRunning,
+		"attempts":     gorm.Expr("attempts + 1"),
+		"started_at":   now,
+		"heartbeat_at": now,
+	})
+	if err != nil {
+		log.Printf("更新报告任务 %d 状态失败: %v", jobID, err)
+		return
+	}
+	if !ok {
+		// 任务已被取消或已由其他副本处理
+		return
+	}
+	job, err := s.reportJobDAO.GetReportJobByID(jobID)
+	if err != nil {
+		log.Printf("获取报告任务 %d 失败: %v", jobID, err)
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.watchJob(runCtx, cancel, jobID)
+	runCtx = WithUsageScope(runCtx, job.UserID, model.AIUsageSourceJob)
+
+	report, err := s.aiReportService.GenerateReport(runCtx, job.ChildArchiveID, job.ReportType, job.StartDate, job.EndDate, reportOptionsFromJob(job))
+	if err != nil {
+		if runCtx.Err() != nil && ctx.Err() == nil {
+			// 用户取消，状态已由取消接口更新
+			return
+		}
+		var held *ReportHeldError
+		if errors.As(err, &held) {
+			// 报告已转机构人工审核，审核结果由安全审核更新任务状态并通知
+			if _, err := s.reportJobDAO.TransitionReportJob(jobID, model.ReportJobRunning, map[string]interface{}{
+				"status":           model.ReportJobInReview,
+				"safety_review_id": held.Review.ID,
+				"last_error":       "",
+			}); err != nil {
+				log.Printf("更新报告任务 %d 状态失败: %v", jobID, err)
+			}
+			return
+		}
+		s.handleFailure(jobID, err)
+		return
+	}
+
+	ok, err = s.reportJobDAO.TransitionReportJob(jobID, model.ReportJobRunning, map[string]interface{}{
+		"status":      model.ReportJobSucceeded,
+		"report_id":   report.ID,
+		"last_error":  "",
+		"finished_at": time.Now(),
+	})
+	if err != nil || !ok {
+		// 生成期间任务被取消，丢弃生成的报告
+		if err := s.aiReportService.DeleteReport(report.ID); err != nil {
+			log.Printf("删除已取消任务 %d 的报告失败: %v", jobID, err)
+		}
+		return
+	}
+	s.notifyJobResult(job, model.NotificationReportReady, "AI报告已生成", fmt.Sprintf("%s报告已生成，快去看看吧。", s.aiReportService.ReportTypeName(job.ReportType)), strconv.FormatUint(uint64(report.ID), 10))
+}
+
+// watchJob 轮询取消信号并更新心跳
+func (s *ReportJobService) watchJob(ctx context.Context, cancel context.CancelFunc, jobID uint) {
+	ticker := time.NewTicker(reportJobCancelInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if requested, err := s.reportJobDAO.IsReportJobCancelRequested(ctx, jobID); err == nil && requested {
+			cancel()
+			return
+		}
+		if err := s.reportJobDAO.TouchReportJob(jobID); err != nil {
+			log.Printf("更新报告任务 %d 心跳失败: %v", jobID, err)
+		}
+	}
+}
+
+// handleFailure 未达到最大尝试次数时按指数退避重新入队，否则标记失败并通知。
+// 提供商熔断时未实际发出请求，不计入尝试次数，在熔断结束后重新执行；认证失败和请求被拒绝重试无效，直接失败
+func (s *ReportJobService) handleFailure(jobID uint, cause error) {
+	job, err := s.reportJobDAO.GetReportJobByID(jobID)
+	if err != nil {
+		log.Printf("获取报告任务 %d 失败: %v", jobID, err)
+		return
+	}
+
+	now := time.Now()
+	circuitOpen := errors.Is(cause, ErrLLMCircuitOpen)
+	permanent := errors.Is(cause, ErrLLMAuth) || errors.Is(cause, ErrLLMBadRequest)
+	if (job.Attempts < job.MaxAttempts && !permanent) || circuitOpen {
+		delay := reportJobRetryDelay(job.Attempts)
+		if retryAfter := LLMRetryAfter(cause); retryAfter > delay {
+			delay = retryAfter
+		}
+		nextRunAt := now.Add(delay)
+		updates := map[string]interface{}{
+			"status":      model.ReportJobPending,
+			"last_error":  cause.Error(),
+			"next_run_at": nextRunAt,
+		}
+		if circuitOpen {
+			updates["attempts"] = gorm.Expr("attempts - 1")
+		}
+		ok, err := s.reportJobDAO.TransitionReportJob(jobID, model.ReportJobRunning, updates)
+		if err != nil || !ok {
+			return
+		}
+		if err := s.reportJobDAO.EnqueueReportJob(context.Background(), jobID, nextRunAt); err != nil {
+			log.Printf("报告任务 %d 重新入队失败: %v", jobID, err)
+		}
+		log.Printf("报告任务 %d 第 %d 次执行失败，将于 %s 重试: %v", jobID, job.Attempts, nextRunAt.Format("15:04:05"), cause)
+		return
+	}
+
+	ok, err := s.reportJobDAO.TransitionReportJob(jobID, model.ReportJobRunning, map[string]interface{}{
+		"status":      model.ReportJobFailed,
+		"last_error":  cause.Error(),
+		"finished_at": now,
+	})
+	if err != nil || !ok {
+		return
+	}
+	s.notifyJobResult(job, model.NotificationReportFailed, "AI报告生成失败", fmt.Sprintf("%s报告生成失败，请稍后重新生成。", s.aiReportService.ReportTypeName(job.ReportType)), strconv.FormatUint(uint64(job.ID), 10))
+}
+
+// reapLoop 回收因副本退出而中断的执行中任务，并重新放入已从队列取出但未开始执行的任务
+func (s *ReportJobService) reapLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		jobs, err := s.reportJobDAO.GetStaleRunningReportJobs(time.Now().Add(-reportJobStaleAfter))
+		if err != nil {
+			log.Printf("获取中断的报告任务失败: %v", err)
+			continue
+		}
+		for _, job := range jobs {
+			s.handleFailure(job.ID, errors.New("任务执行中断"))
+		}
+		s.requeueLostJobs(ctx)
+	}
+}
+
+// requeueLostJobs 重新放入超过执行时间仍在等待、但不在队列中的任务。
+// 副本从队列取出任务后、更新为执行中之前退出，或重试时入队失败，都会留下这样的任务
+func (s *ReportJobService) requeueLostJobs(ctx context.Context) {
+	jobs, err := s.reportJobDAO.GetOverduePendingReportJobs(time.Now().Add(-reportJobStaleAfter))
+	if err != nil {
+		log.Printf("获取等待中的报告任务失败: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		requeued, err := s.reportJobDAO.RequeueReportJob(ctx, job.ID, time.Now())
+		if err != nil {
+			log.Printf("报告任务 %d 重新入队失败: %v", job.ID, err)
+			continue
+		}
+		if requeued {
+			log.Printf("报告任务 %d 不在队列中，已重新入队", job.ID)
+		}
+	}
+}
+
+// notifyJobResult 通知儿童家长，发起人不是家长时同时通知发起人
+func (s *ReportJobService) notifyJobResult(job *model.ReportJob, notificationType, title, content, relatedID string) {
+	relatedType := "generated_report"
+	if notificationType == model.NotificationReportFailed {
+		relatedType = "report_job"
+	}
+	recipients := []string{job.UserID}
+	if archive, err := s.userDAO.GetChildArchiveByID(job.ChildArchiveID); err == nil {
+		content = archive.ChildName + "的" + content
+		if archive.UserID != job.UserID {
+			recipients = append(recipients, archive.UserID)
+		}
+	}
+	for _, userID := range recipients {
+		if err := s.notificationService.Notify(userID, notificationType, title, content, relatedType, relatedID); err != nil {
+			log.Printf("发送报告任务通知失败: %v", err)
+		}
+	}
+}
+
+func (s *ReportJobService) getAccessibleJob(userID string, jobID uint) (*model.ReportJob, error) {
+	job, err := s.reportJobDAO.GetReportJobByID(jobID)
+	if err != nil {
+		return nil, notFound("任务不存在")
+	}
+	if job.UserID == userID {
+		return job, nil
+	}
+	if _, err := checkChildAccess(s.userDAO, userID, job.ChildArchiveID); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func reportOptionsFromJob(job *model.ReportJob) *ReportOptions {
+	jobID := job.ID
+	opts := &ReportOptions{RequestedBy: job.UserID, JobID: &jobID, Language: job.Language}
+	if job.Comparison != nil {
+		opts.Comparison = &ComparisonQuery{
+			Skill:         job.Comparison.Skill,
+			BaselineStart: job.Comparison.BaselineStart,
+			BaselineEnd:   job.Comparison.BaselineEnd,
+			FollowUpStart: job.Comparison.FollowUpStart,
+			FollowUpEnd:   job.Comparison.FollowUpEnd,
+		}
+	}
+	return opts
+}
+
+// reportJobRetryDelay 第 attempts 次失败后的重试间隔：基础间隔 * 2^(attempts-1)，最长10分钟
+func reportJobRetryDelay(attempts int) time.Duration {
+	base := time.Duration(config.GetReportJobConfig().RetryBaseDelay) * time.Second
+	if base <= 0 {
+		base = 10 * time.Second
+	}
+	delay := base
+	for i := 1; i < attempts && delay < reportJobMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > reportJobMaxRetryDelay {
+		delay = reportJobMaxRetryDelay
+	}
+	return delay
+}
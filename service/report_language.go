@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"melody_cure/model"
+	"strings"
+)
+
+// DefaultReportLanguage 未指定语言时的报告语言，内置模板和报告类型的当前版本使用该语言
+const DefaultReportLanguage = model.DefaultLanguage
+
+var ErrUnsupportedLanguage = errors.New("不支持的报告语言")
+
+// ReportLanguage 支持的报告语言
+type ReportLanguage struct {
+	Code       string `json:"code"`        // ISO 639-1 语言代码
+	Name       string `json:"name"`        // 中文名称
+	NativeName string `json:"native_name"` // 该语言的自称
+}
+
+// reportLanguages 支持的报告语言，没有对应语言模板时使用中文模板并要求模型以该语言输出
+var reportLanguages = []ReportLanguage{
+	{Code: "zh", Name: "中文", NativeName: "中文"},
+	{Code: "en", Name: "英语", NativeName: "English"},
+	{Code: "ug", Name: "维吾尔语", NativeName: "ئۇيغۇرچە"},
+	{Code: "bo", Name: "藏语", NativeName: "བོད་ཡིག"},
+	{Code: "mn", Name: "蒙古语", NativeName: "ᠮᠣᠩᠭᠣᠯ ᠬᠡᠯᠡ"},
+	{Code: "ko", Name: "朝鲜语", NativeName: "조선말"},
+}
+
+// ReportLanguages 支持的报告语言列表
+func ReportLanguages() []ReportLanguage {
+	return reportLanguages
+}
+
+// NormalizeReportLanguage 将 en-US、zh_CN 等语言标签规范为语言代码，为空时返回默认语言
+func NormalizeReportLanguage(language string) (string, error) {
+	code := strings.ToLower(strings.TrimSpace(language))
+	if code == "" {
+		return DefaultReportLanguage, nil
+	}
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+	if _, ok := findReportLanguage(code); !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedLanguage, language)
+	}
+	return code, nil
+}
+
+func findReportLanguage(code string) (ReportLanguage, bool) {
+	for _, language := range reportLanguages {
+		if language.Code == code {
+			return language, true
+		}
+	}
+	return ReportLanguage{}, false
+}
+
+// reportLanguageOrDefault 已保存的报告和任务在多语言上线前没有语言，视为默认语言
+func reportLanguageOrDefault(language string) string {
+	if language == "" {
+		return DefaultReportLanguage
+	}
+	return language
+}
+
+// languageInstruction 模板语言与报告语言不一致时追加到系统消息的输出语言要求
+func languageInstruction(language string) string {
+	lang, ok := findReportLanguage(language)
+	if !ok || language == DefaultReportLanguage {
+		return ""
+	}
+	if language == "en" {
+		return "\n\nWrite the entire report in English, including the title, section headings and every text field of the structured output. Keep game and course IDs unchanged."
+	}
+	return fmt.Sprintf("\n\n请使用%s（%s）撰写整份报告，包括标题、章节名和结构化输出中的所有文字字段，游戏和课程的 ID 保持不变；专业术语可在括号中附中文。", lang.Name, lang.NativeName)
+}
+
+// reportTexts 报告中由系统生成的固定文字，缺少对应语言时使用中文
+var reportTexts = map[string]map[string]string{
+	"zh": {
+		"risk_flags":             "风险提示",
+		"recommended_activities": "推荐活动",
+		"risk_high":              "高风险",
+		"risk_medium":            "中风险",
+		"risk_low":               "低风险",
+		"game":                   "游戏",
+		"course":                 "课程",
+		"safety_note":            "安全提示",
+		"disclaimer":             "免责声明",
+		"blocked":                blockedPlaceholder,
+		"default_disclaimer":     defaultReportDisclaimer,
+		"translated_from":        "本报告由AI译自%s原文（报告 #%d 第 %d 版），如有出入以原文为准。",
+	},
+	"en": {
+		"risk_flags":             "Risk Flags",
+		"recommended_activities": "Recommended Activities",
+		"risk_high":              "High risk",
+		"risk_medium":            "Medium risk",
+		"risk_low":               "Low risk",
+		"game":                   "Game",
+		"course":                 "Course",
+		"safety_note":            "Safety note",
+		"disclaimer":             "Disclaimer",
+		"blocked":                "(This content has been removed under the safety policy.)",
+		"default_disclaimer":     "This report was generated with AI assistance from logs recorded by parents and therapists. It is for reference only and does not replace diagnosis or treatment advice from a qualified doctor or therapist.",
+		"translated_from":        "This report was translated by AI from the %s original (report #%d, version %d). If anything differs, the original prevails.",
+	},
+}
+
+// reportText 获取报告固定文字，缺少对应语言时使用中文
+func reportText(language, key string) string {
+	if texts, ok := reportTexts[language]; ok {
+		if text, ok := texts[key]; ok {
+			return text
+		}
+	}
+	return reportTexts[DefaultReportLanguage][key]
+}
+
+// languageDisplayName 用报告语言称呼另一种语言，用于译文说明
+func languageDisplayName(code, inLanguage string) string {
+	lang, ok := findReportLanguage(code)
+	if !ok {
+		return code
+	}
+	if inLanguage == DefaultReportLanguage {
+		return lang.Name
+	}
+	if inLanguage == "en" {
+		return englishLanguageNames[code]
+	}
+	return lang.NativeName
+}
+
+var englishLanguageNames = map[string]string{
+	"zh": "Chinese",
+	"en": "English",
+	"ug": "Uyghur",
+	"bo": "Tibetan",
+	"mn": "Mongolian",
+	"ko": "Korean",
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"melody_cure/DAO"
+	"melody_cure/model"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 脱敏内容的类别
+const (
+	RedactChildName      = "child_name"
+	RedactChildGivenName = "child_given_name"
+	RedactName           = "name"
+	RedactPhone          = "phone"
+	RedactIDNumber       = "id_number"
+	RedactEmail          = "email"
+	RedactAddress        = "address"
+	RedactSchool         = "school"
+)
+
+// redactionLabels 各类别占位符的名称
+var redactionLabels = map[string]string{
+	RedactChildName:      "儿童姓名",
+	RedactChildGivenName: "儿童名字",
+	RedactName:           "姓名",
+	RedactPhone:          "电话",
+	RedactIDNumber:       "证件号",
+	RedactEmail:          "邮箱",
+	RedactAddress:        "地址",
+	RedactSchool:         "学校",
+}
+
+// redactionNotice 提示模型保留占位符，附加在系统消息后
+const redactionNotice = "\n\n为保护隐私，资料中的姓名、电话、证件号、地址、学校等信息已替换为方括号占位符（如 [儿童姓名]、[姓名1]、[地址1]），请在输出中原样使用这些占位符指代对应内容，不要猜测、补全或改写。"
+
+// piiSurnames 识别“姓氏+称谓”形式姓名时使用的常见姓氏，不含易与虚词混淆的字
+const piiSurnames = "王李张刘陈杨黄赵吴周徐孙马朱胡郭何林罗郑梁谢宋唐许韩冯邓曹彭曾肖田董潘袁蔡蒋余杜叶程魏苏吕丁任沈姚卢姜崔钟谭陆汪范金石廖贾夏韦付傅邹孟熊秦邱江尹薛闫段雷侯龙史黎贺顾毛郝龚邵万钱严覃武戴莫孔汤"
+
+// piiPatterns 按顺序匹配的敏感信息，身份证号需先于手机号匹配。group 为要替换的子匹配序号
+var piiPatterns = []struct {
+	category string
+	re       *regexp.Regexp
+	group    int
+}{
+	{RedactIDNumber, regexp.MustCompile(`[1-9]\d{5}(?:19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]`), 0},
+	{RedactPhone, regexp.MustCompile(`(?:\+?86[- ]?)?1[3-9]\d[- ]?\d{4}[- ]?\d{4}`), 0},
+	{RedactPhone, regexp.MustCompile(`0\d{2,3}-\d{7,8}`), 0},
+	{RedactEmail, regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), 0},
+	{RedactAddress, regexp.MustCompile(`\p{Han}{2,12}(?:路|街|大道|巷|弄|胡同)\d+号(?:[A-Za-z0-9\p{Han}]{0,8}?(?:栋|幢|号楼|单元|室))*`), 0},
+	{RedactAddress, regexp.MustCompile(`\p{Han}{2,10}(?:小区|花园|公寓|大厦|新村)(?:\d+(?:栋|幢|号楼|单元|室))*`), 0},
+	{RedactSchool, regexp.MustCompile(`\p{Han}{2,10}(?:幼儿园|小学|中学|特教学校|特教中心)`), 0},
+	{RedactName, regexp.MustCompile(`([` + piiSurnames + `]\p{Han}{0,2}?)(?:老师|医生|大夫|治疗师|同学|阿姨|叔叔|小朋友)`), 1},
+}
+
+// piiContextChars 地址和学校匹配到的前缀中出现这些字时，从其后开始截取，避免替换掉前面的正文
+const piiContextChars = "在去到的了是和从回住于往跟与送接离上读"
+
+// piiNameContextChars 可能出现在称谓前、又与姓氏相同的字
+const piiNameContextChars = "周和与向于常每这那给让对被把跟"
+
+// piiPlaceholderNumbers 占位符编号的取值范围，编号由原文的哈希决定
+const piiPlaceholderNumbers = 10000
+
+// piiRedactor 将发送给模型的文本中的敏感信息替换为占位符，并在本地将响应中的占位符还原。
+// 同一内容始终使用同一占位符，且占位符只取决于原文、不取决于发现的先后，
+// 同一时间段的日志在不同报告中脱敏结果相同，分段摘要的缓存才能复用
+type piiRedactor struct {
+	placeholders map[string]string // 原文 -> 占位符
+	originals    map[string]string // 占位符 -> 原文
+	entries      []*model.RedactionEntry
+}
+
+// newReportRedactor 以儿童姓名及家长的姓名、电话、邮箱、地址作为已知敏感信息创建脱敏器
+func newReportRedactor(archive *DAO.ChildArchive, parent *DAO.User) *piiRedactor {
+	r := &piiRedactor{
+		placeholders: make(map[string]string),
+		originals:    make(map[string]string),
+	}
+	if name := strings.TrimSpace(archive.ChildName); name != "" {
+		r.register(RedactChildName, name)
+		// 三个字及以上的姓名，日志中常只写名字
+		if runes := []rune(name); len(runes) >= 3 {
+			r.register(RedactChildGivenName, string(runes[1:]))
+		}
+	}
+	if parent != nil {
+		r.register(RedactName, strings.TrimSpace(parent.Name))
+		r.register(RedactPhone, strings.TrimSpace(parent.Phone))
+		r.register(RedactEmail, strings.TrimSpace(parent.Email))
+		r.register(RedactAddress, strings.TrimSpace(parent.Address))
+	}
+	return r
+}
+
+// register 为原文分配占位符，已分配的原文直接返回已有占位符
+func (r *piiRedactor) register(category, original string) string {
+	if utf8.RuneCountInString(original) < 2 {
+		return ""
+	}
+	if placeholder, ok := r.placeholders[original]; ok {
+		return placeholder
+	}
+	// 儿童本人的姓名只有一个，不编号
+	placeholder := "[" + redactionLabels[category] + "]"
+	if category != RedactChildName && category != RedactChildGivenName {
+		sum := sha256.Sum256([]byte(category + "\x00" + original))
+		number := binary.BigEndian.Uint64(sum[:8]) % piiPlaceholderNumbers
+		// 编号冲突时顺延，极少发生
+		for {
+			placeholder = fmt.Sprintf("[%s%d]", redactionLabels[category], number)
+			if _, taken := r.originals[placeholder]; !taken {
+				break
+			}
+			number = (number + 1) % piiPlaceholderNumbers
+		}
+	}
+	r.placeholders[original] = placeholder
+	r.originals[placeholder] = original
+	r.entries = append(r.entries, &model.RedactionEntry{
+		Placeholder: placeholder,
+		Category:    category,
+		Masked:      maskPII(original),
+	})
+	return placeholder
+}
+
+// Redact 替换文本中的已知敏感信息和识别到的电话、证件号、邮箱、地址、学校、姓名
+func (r *piiRedactor) Redact(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	for _, pattern := range piiPatterns {
+		for _, match := range pattern.re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := match[2*pattern.group], match[2*pattern.group+1]
+			if start < 0 || !digitBoundary(text, start, end) {
+				continue
+			}
+			value := text[start:end]
+			switch pattern.category {
+			case RedactAddress, RedactSchool:
+				if value = trimPIIContext(value); value == "" {
+					continue
+				}
+			case RedactName:
+				value = trimNameContext(value)
+			}
+			r.register(pattern.category, value)
+		}
+	}
+	return r.replaceKnown(text)
+}
+
+// replaceKnown 按原文长度从长到短替换，避免姓名被其中的名字部分先替换
+func (r *piiRedactor) replaceKnown(text string) string {
+	originals := make([]string, 0, len(r.placeholders))
+	for original := range r.placeholders {
+		originals = append(originals, original)
+	}
+	sort.Slice(originals, func(i, j int) bool {
+		if len(originals[i]) != len(originals[j]) {
+			return len(originals[i]) > len(originals[j])
+		}
+		return originals[i] < originals[j]
+	})
+	for _, original := range originals {
+		if count := strings.Count(text, original); count > 0 {
+			placeholder := r.placeholders[original]
+			text = strings.ReplaceAll(text, original, placeholder)
+			for _, entry := range r.entries {
+				if entry.Placeholder == placeholder {
+					entry.Count += count
+				}
+			}
+		}
+	}
+	return text
+}
+
+// RedactPromptData 对提示词数据中的儿童档案、日志、答案和对比描述脱敏
+func (r *piiRedactor) RedactPromptData(data *ReportPromptData) {
+	data.Child.Name = r.Redact(data.Child.Name)
+	data.Child.Diagnosis = r.Redact(data.Child.Diagnosis)
+	data.Child.Condition = r.Redact(data.Child.Condition)
+	data.Child.Treatment = r.Redact(data.Child.Treatment)
+	for i := range data.Logs {
+		data.Logs[i].Content = r.Redact(data.Logs[i].Content)
+		for j := range data.Logs[i].Answers {
+			data.Logs[i].Answers[j].Value = r.Redact(data.Logs[i].Answers[j].Value)
+		}
+	}
+	for i := range data.RelatedContext {
+		data.RelatedContext[i].Content = r.Redact(data.RelatedContext[i].Content)
+	}
+	data.Comparison = r.Redact(data.Comparison)
+}
+
+// Restore 将模型响应中的占位符还原为原文，兼容模型改用全角括号的情况
+func (r *piiRedactor) Restore(text string) string {
+	if r == nil || len(r.originals) == 0 {
+		return text
+	}
+	return r.replacer(func(original string) string { return original }).Replace(text)
+}
+
+// RestoreJSON 在 JSON 文本中还原占位符，原文按 JSON 字符串转义
+func (r *piiRedactor) RestoreJSON(raw json.RawMessage) json.RawMessage {
+	if r == nil || len(r.originals) == 0 || len(raw) == 0 {
+		return raw
+	}
+	return json.RawMessage(r.replacer(func(original string) string {
+		quoted, _ := json.Marshal(original)
+		return string(quoted[1 : len(quoted)-1])
+	}).Replace(string(raw)))
+}
+
+func (r *piiRedactor) replacer(encode func(string) string) *strings.Replacer {
+	pairs := make([]string, 0, 4*len(r.originals))
+	for placeholder, original := range r.originals {
+		label := strings.Trim(placeholder, "[]")
+		pairs = append(pairs, placeholder, encode(original), "【"+label+"】", encode(original))
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// Log 本次报告的脱敏记录，只包含掩码后的原文
+func (r *piiRedactor) Log() []model.RedactionEntry {
+	if r == nil {
+		return nil
+	}
+	entries := make([]model.RedactionEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		if entry.Count > 0 {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries
+}
+
+// StreamRestorer 包装流式回调，在本地还原占位符。占位符可能被拆分在两个数据块中，
+// 未闭合的方括号之后的内容暂存到下一个数据块
+func (r *piiRedactor) StreamRestorer(onDelta LLMStreamHandler) (LLMStreamHandler, func() error) {
+	if r == nil || len(r.originals) == 0 {
+		return onDelta, func() error { return nil }
+	}
+	pending := ""
+	write := func(delta string) error {
+		text := pending + delta
+		cut := len(text)
+		if i := strings.LastIndexAny(text, "[【"); i >= 0 && !strings.ContainsAny(text[i:], "]】") && len(text)-i <= 32 {
+			cut = i
+		}
+		pending = text[cut:]
+		if cut == 0 {
+			return nil
+		}
+		return onDelta(r.Restore(text[:cut]))
+	}
+	flush := func() error {
+		if pending == "" {
+			return nil
+		}
+		text := pending
+		pending = ""
+		return onDelta(r.Restore(text))
+	}
+	return write, flush
+}
+
+// digitBoundary 数字类匹配不能是更长数字串的一部分
+func digitBoundary(text string, start, end int) bool {
+	isDigit := func(b byte) bool { return b >= '0' && b <= '9' }
+	if !isDigit(text[start]) && text[start] != '+' {
+		return true
+	}
+	if start > 0 && isDigit(text[start-1]) {
+		return false
+	}
+	return end >= len(text) || !isDigit(text[end])
+}
+
+// trimPIIContext 去掉地址、学校匹配中混入的前文，截取后不足四个字（如只剩“小学”）时不视为敏感信息
+func trimPIIContext(value string) string {
+	runes := []rune(value)
+	for i := len(runes) - 1; i >= 0; i-- {
+		if strings.ContainsRune(piiContextChars, runes[i]) {
+			runes = runes[i+1:]
+			break
+		}
+	}
+	if len(runes) < 4 {
+		return ""
+	}
+	return string(runes)
+}
+
+// trimNameContext 姓名匹配以虚词或时间词开头且其后仍是姓氏时（如“这周王老师”中的“周王”），去掉开头的字。
+// 只剩单个姓氏时不视为敏感信息
+func trimNameContext(value string) string {
+	runes := []rune(value)
+	if len(runes) >= 2 && strings.ContainsRune(piiNameContextChars, runes[0]) && strings.ContainsRune(piiSurnames, runes[1]) {
+		return string(runes[1:])
+	}
+	return value
+}
+
+// maskPII 脱敏记录中保留首尾字符，其余以星号代替
+func maskPII(value string) string {
+	runes := []rune(value)
+	switch {
+	case len(runes) <= 2:
+		return string(runes[:1]) + "*"
+	case len(runes) <= 6:
+		return string(runes[:1]) + strings.Repeat("*", len(runes)-2) + string(runes[len(runes)-1:])
+	default:
+		return string(runes[:3]) + strings.Repeat("*", len(runes)-5) + string(runes[len(runes)-2:])
+	}
+}
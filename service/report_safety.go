@@ -0,0 +1,913 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"melody_cure/DAO"
+	"melody_cure/config"
+	"melody_cure/model"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrReportHeld           = errors.New("报告涉及需要人工审核的内容，已提交机构审核")
+	ErrSafetyReviewNotFound = errors.New("审核记录不存在")
+	ErrSafetyReviewClosed   = errors.New("该报告已审核")
+)
+
+// ReportHeldError 报告触发安全审查，已转机构人工审核，未保存为正式报告
+type ReportHeldError struct {
+	Review *model.ReportSafetyReview
+}
+
+func (e *ReportHeldError) Error() string {
+	return ErrReportHeld.Error()
+}
+
+func (e *ReportHeldError) Unwrap() error {
+	return ErrReportHeld
+}
+
+// defaultReportDisclaimer 未配置免责声明时追加在报告末尾的声明
+const defaultReportDisclaimer = "本报告由AI根据家长和康复师记录的日志辅助生成，仅供参考，不能替代专业医生或康复师的诊断与治疗建议。"
+
+const (
+	// safetyClassifierReportType 安全分类请求的报告类型，仅供模拟提供商识别
+	safetyClassifierReportType = "safety_classifier"
+	// safetyClassifierBatchRunes 每次分类请求的最大文本长度，疗愈记录较多时分批请求
+	safetyClassifierBatchRunes = 6000
+	// safetyExcerptRunes 命中记录中保存的原文片段长度
+	safetyExcerptRunes = 80
+	// blockedPlaceholder 被隐去的句子替换为该文字
+	blockedPlaceholder = "（此处内容已根据安全策略隐去）"
+)
+
+// safetyCategoryMessages 各类别默认的提示说明
+var safetyCategoryMessages = map[string]string{
+	"medication": "报告中涉及用药的内容已隐去，用药请遵医嘱并咨询专科医生。",
+	"diagnosis":  "报告中的表述不构成医学诊断，诊断须由具备资质的医生作出。",
+	"crisis":     "如发现孩子有伤害自己或他人的倾向，请立即联系专业机构或拨打心理援助热线。",
+	"self_harm":  "疗愈记录中出现自伤相关内容，报告已转机构人工审核。",
+	"abuse":      "疗愈记录中出现疑似受到伤害的内容，报告已转机构人工审核。",
+}
+
+// localizedSafetyCategoryMessages 非中文报告中展示的类别提示，缺少对应语言时使用中文。
+// 转人工审核的类别只展示给审核人，不需要翻译
+var localizedSafetyCategoryMessages = map[string]map[string]string{
+	"en": {
+		"medication": "Medication-related content has been removed from this report. Follow your doctor's instructions and consult a specialist about any medication.",
+		"diagnosis":  "Statements in this report are not a medical diagnosis. A diagnosis can only be made by a qualified doctor.",
+		"crisis":     "If the child shows any tendency to harm themselves or others, contact a professional service or a mental health hotline immediately.",
+		"":           "This report contains content that needs attention. Please read it together with professional advice.",
+	},
+}
+
+// safetyNegations 关键词前出现这些词时视为否定表述（如“没有自伤行为”），不算命中
+var safetyNegations = []string{"没有", "没", "无", "未", "否认", "不再"}
+
+var (
+	safetySentenceEnd    = regexp.MustCompile(`(?:[^。！？!?；;.]|\.[^\s.])*(?:[。！？!?；;]+|\.+\s*)?`)
+	safetyMarkdownPrefix = regexp.MustCompile(`^(\s*(#{1,6}\s+|[-*+]\s+|\d+[.)、]\s*|>\s*))*`)
+)
+
+// defaultSafetyRules 未配置规则时使用的内置规则
+func defaultSafetyRules() []config.SafetyRuleConfig {
+	return []config.SafetyRuleConfig{
+		{
+			Name:     "medication_advice",
+			Category: "medication",
+			Scope:    model.SafetyScopeOutput,
+			Action:   model.SafetyActionBlock,
+			Keywords: []string{"利培酮", "阿立哌唑", "哌甲酯", "专注达", "托莫西汀", "择思达", "氟西汀", "舍曲林", "丙戊酸", "褪黑素", "抗精神病药", "抗抑郁药", "镇静剂", "安眠药",
+				"risperidone", "aripiprazole", "methylphenidate", "atomoxetine", "fluoxetine", "sertraline", "valproate", "melatonin", "antipsychotic", "antidepressant", "sedative", "sleeping pill"},
+			Patterns: []string{
+				`(服用|口服|用药|给药|剂量|加量|减量)[^。！？\n]{0,12}\d+(\.\d+)?\s*(mg|毫克|ml|毫升|片|粒|滴)`,
+				`(建议|可以|应当|应该|需要)[^。！？\n]{0,6}(服用|服药|用药|停药|减药|加药)`,
+				`(?i)\b(take|taking|dose|dosage|increase|reduce)\b[^.!?\n]{0,20}\d+(\.\d+)?\s*(mg|ml|tablets?|pills?|drops?)\b`,
+				`(?i)\b(should|recommend|consider)\b[^.!?\n]{0,20}\b(start|stop|take|adjust)(ing)?\s+(the\s+)?medication`,
+			},
+		},
+		{
+			Name:     "diagnostic_statement",
+			Category: "diagnosis",
+			Scope:    model.SafetyScopeOutput,
+			Action:   model.SafetyActionAnnotate,
+			Patterns: []string{
+				`(?i)(确诊|诊断为|诊断是|判断为|判定为|可以确定是)[^。！？\n]{0,8}(自闭症|孤独症|多动症|注意缺陷|adhd|抑郁症|焦虑症|双相|智力障碍|发育迟缓|阿斯伯格|精神分裂)`,
+				`(?i)\b(diagnosed with|diagnosis of|is clearly|definitely has)\b[^.!?\n]{0,12}\b(autism|adhd|depression|anxiety disorder|bipolar|intellectual disability|developmental delay|asperger|schizophrenia)`,
+			},
+		},
+		{
+			Name:     "crisis_signal",
+			Category: "crisis",
+			Scope:    model.SafetyScopeOutput,
+			Action:   model.SafetyActionAnnotate,
+			Keywords: []string{"自杀", "轻生", "自残", "自伤", "伤害自己", "伤害他人", "不想活", "suicid", "self-harm", "self-injur", "hurt themselves", "hurt others"},
+		},
+		{
+			Name:     "self_harm_log",
+			Category: "self_harm",
+			Scope:    model.SafetyScopeLogs,
+			Action:   model.SafetyActionEscalate,
+			Keywords: []string{"自杀", "轻生", "自残", "自伤", "割腕", "伤害自己", "想死", "不想活", "活着没意思"},
+		},
+		{
+			Name:     "abuse_log",
+			Category: "abuse",
+			Scope:    model.SafetyScopeLogs,
+			Action:   model.SafetyActionEscalate,
+			Keywords: []string{"虐待", "家暴", "体罚", "被打", "打骂", "关小黑屋", "猥亵", "性侵"},
+			Patterns: []string{`(爸爸|妈妈|父亲|母亲|老师|家长)[^。！？\n]{0,4}(打|掐|踢|扇)了?(他|她|孩子|我)`},
+		},
+	}
+}
+
+// safetyRule 编译后的安全规则
+type safetyRule struct {
+	name     string
+	category string
+	scope    string
+	action   string
+	message  string
+	keywords []string
+	patterns []*regexp.Regexp
+}
+
+// compileSafetyRules 校验并编译规则，未指定检查范围时检查模型输出，未指定处理方式时附加提示
+func compileSafetyRules(configs []config.SafetyRuleConfig) ([]safetyRule, error) {
+	rules := make([]safetyRule, 0, len(configs))
+	for i, cfg := range configs {
+		rule := safetyRule{
+			name:     cfg.Name,
+			category: cfg.Category,
+			scope:    cfg.Scope,
+			action:   cfg.Action,
+			message:  strings.TrimSpace(cfg.Message),
+		}
+		if rule.name == "" {
+			rule.name = "rule_" + strconv.Itoa(i+1)
+		}
+		if rule.scope == "" {
+			rule.scope = model.SafetyScopeOutput
+		}
+		if rule.action == "" {
+			rule.action = model.SafetyActionAnnotate
+		}
+		switch rule.scope {
+		case model.SafetyScopeOutput, model.SafetyScopeLogs:
+		default:
+			return nil, fmt.Errorf("安全规则 %s 的检查范围无效: %s", rule.name, rule.scope)
+		}
+		switch rule.action {
+		case model.SafetyActionAnnotate, model.SafetyActionEscalate:
+		case model.SafetyActionBlock:
+			if rule.scope == model.SafetyScopeLogs {
+				return nil, fmt.Errorf("安全规则 %s 检查疗愈记录，不支持 block", rule.name)
+			}
+		default:
+			return nil, fmt.Errorf("安全规则 %s 的处理方式无效: %s", rule.name, rule.action)
+		}
+		if rule.message == "" {
+			rule.message = safetyCategoryMessage(rule.category)
+		}
+		for _, keyword := range cfg.Keywords {
+			if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+				rule.keywords = append(rule.keywords, keyword)
+			}
+		}
+		for _, pattern := range cfg.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("安全规则 %s 的正则表达式无效: %v", rule.name, err)
+			}
+			rule.patterns = append(rule.patterns, re)
+		}
+		if len(rule.keywords) == 0 && len(rule.patterns) == 0 {
+			return nil, fmt.Errorf("安全规则 %s 没有关键词或正则表达式", rule.name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func safetyCategoryMessage(category string) string {
+	if message, ok := safetyCategoryMessages[category]; ok {
+		return message
+	}
+	return "报告中存在需要注意的内容，请结合专业意见阅读。"
+}
+
+// matches 文本是否命中规则，关键词不区分大小写，前面紧邻否定词的关键词不算命中
+func (r *safetyRule) matches(text string) bool {
+	lower := strings.ToLower(text)
+	for _, keyword := range r.keywords {
+		offset := 0
+		for {
+			idx := strings.Index(lower[offset:], keyword)
+			if idx < 0 {
+				break
+			}
+			if !negatedBefore(lower[:offset+idx]) {
+				return true
+			}
+			offset += idx + len(keyword)
+		}
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// negatedBefore 关键词前4个字内是否有否定词，“无法”等不视为否定
+func negatedBefore(prefix string) bool {
+	runes := []rune(prefix)
+	if len(runes) > 4 {
+		runes = runes[len(runes)-4:]
+	}
+	window := strings.ReplaceAll(string(runes), "无法", "")
+	for _, negation := range safetyNegations {
+		if strings.Contains(window, negation) {
+			return true
+		}
+	}
+	return false
+}
+
+// SafetyClassification 分类器识别出的一项风险内容
+type SafetyClassification struct {
+	Index    int     `json:"index"` // 文本编号，从1开始
+	Category string  `json:"category"`
+	Score    float64 `json:"score"`
+	Excerpt  string  `json:"excerpt"`
+	Reason   string  `json:"reason"`
+}
+
+// SafetyClassifier 安全分类器，识别关键词规则难以覆盖的风险内容
+type SafetyClassifier interface {
+	Classify(ctx context.Context, texts []string) ([]SafetyClassification, error)
+}
+
+// safetyClassifierPrompt 安全分类的系统消息
+const safetyClassifierPrompt = `你是儿童康复平台的内容安全审核员。请逐段检查用户提供的编号文本，识别以下类别的风险内容：
+- medication：具体的用药、剂量、停药或换药建议
+- diagnosis：对疾病作出诊断性结论
+- crisis：提示儿童处于危机中，如有伤害自己或他人的倾向
+- self_harm：儿童自伤、自杀的意图或行为
+- abuse：儿童可能受到虐待、体罚、忽视或性侵害
+只输出 JSON 对象，格式为 {"findings":[{"index":文本编号,"category":"类别","score":0到1之间的置信度,"excerpt":"原文片段","reason":"判断理由"}]}，没有风险内容时 findings 为空数组。`
+
+// LLMSafetyClassifier 使用大模型进行安全分类
+type LLMSafetyClassifier struct {
+	provider LLMProvider
+	model    string
+}
+
+func NewLLMSafetyClassifier(provider LLMProvider, model string) *LLMSafetyClassifier {
+	return &LLMSafetyClassifier{provider: provider, model: model}
+}
+
+// Classify 对编号文本进行分类，文本编号为其在 texts 中的序号加1
+func (c *LLMSafetyClassifier) Classify(ctx context.Context, texts []string) ([]SafetyClassification, error) {
+	var prompt strings.Builder
+	for i, text := range texts {
+		fmt.Fprintf(&prompt, "[%d]\n%s\n\n", i+1, text)
+	}
+	resp, err := c.provider.Chat(ctx, &LLMRequest{
+		Model: c.model,
+		Messages: []LLMMessage{
+			{Role: "system", Content: safetyClassifierPrompt},
+			{Role: "user", Content: prompt.String()},
+		},
+		MaxTokens:   1024,
+		Temperature: 0,
+		ReportType:  safetyClassifierReportType,
+		JSONMode:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Findings []SafetyClassification `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(extractJSONObject(resp.Content)), &result); err != nil {
+		return nil, fmt.Errorf("%w: 安全分类结果不是有效的JSON: %v", ErrLLMInvalidResponse, err)
+	}
+	return result.Findings, nil
+}
+
+// SafetyOutcome 安全审查结果
+type SafetyOutcome struct {
+	Content    string
+	Structured json.RawMessage
+	Findings   []model.SafetyFinding
+}
+
+// Escalated 是否需要转人工审核
+func (o *SafetyOutcome) Escalated() bool {
+	for _, finding := range o.Findings {
+		if finding.Action == model.SafetyActionEscalate {
+			return true
+		}
+	}
+	return false
+}
+
+// SafetyService 报告生成后的安全审查：按规则和可选的分类器隐去或标注风险内容、追加免责声明，
+// 疗愈记录提示自伤或受虐待时将报告转入机构人工审核队列
+type SafetyService struct {
+	reviewDAO           *DAO.ReportSafetyDAO
+	userDAO             *DAO.UserDAO
+	reportJobDAO        *DAO.ReportJobDAO
+	notificationService *NotificationService
+	rules               []safetyRule
+	classifier          SafetyClassifier // 未启用分类器时为空
+}
+
+// NewSafetyService 编译安全规则，规则无效时返回错误，避免带着失效的规则启动
+func NewSafetyService(reviewDAO *DAO.ReportSafetyDAO, userDAO *DAO.UserDAO, reportJobDAO *DAO.ReportJobDAO, notificationService *NotificationService, llmProvider LLMProvider, usageService *UsageService) (*SafetyService, error) {
+	safetyConfig := config.GetSafetyConfig()
+	ruleConfigs := safetyConfig.Rules
+	if len(ruleConfigs) == 0 {
+		ruleConfigs = defaultSafetyRules()
+	}
+	rules, err := compileSafetyRules(ruleConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SafetyService{
+		reviewDAO:           reviewDAO,
+		userDAO:             userDAO,
+		reportJobDAO:        reportJobDAO,
+		notificationService: notificationService,
+		rules:               rules,
+	}
+	if classifierConfig := safetyConfig.Classifier; classifierConfig.Enabled {
+		provider := llmProvider
+		if classifierConfig.Provider != "" {
+			client := newLLMHTTPClient(time.Duration(config.GetAIConfig().Timeout) * time.Second)
+			provider = &MeteredLLMProvider{
+				provider:     newLLMProvider(classifierConfig.Provider, classifierConfig.APIKey, classifierConfig.BaseURL, classifierConfig.Model, client),
+				usageService: usageService,
+			}
+		}
+		s.classifier = NewLLMSafetyClassifier(provider, classifierConfig.Model)
+	}
+	return s, nil
+}
+
+// Enabled 是否启用安全审查
+func (s *SafetyService) Enabled() bool {
+	return config.GetSafetyConfig().Enabled
+}
+
+// ScanLogs 检查用于生成报告的疗愈记录，在调用模型生成报告前执行，以便需要人工审核的报告不再流式输出
+func (s *SafetyService) ScanLogs(ctx context.Context, logs []ReportPromptLog) []model.SafetyFinding {
+	if !s.Enabled() || len(logs) == 0 {
+		return nil
+	}
+
+	var findings []model.SafetyFinding
+	texts := make([]string, len(logs))
+	for i := range logs {
+		logTime := logs[i].Time
+		texts[i] = promptLogText(&logs[i])
+		for _, rule := range s.rules {
+			if rule.scope != model.SafetyScopeLogs {
+				continue
+			}
+			for _, sentence := range splitSentences(texts[i]) {
+				if rule.matches(sentence) {
+					findings = append(findings, newSafetyFinding(&rule, sentence, &logTime))
+					break
+				}
+			}
+		}
+	}
+
+	if s.classifier != nil {
+		for _, batch := range classifierBatches(texts) {
+			classifications, err := s.classifier.Classify(ctx, texts[batch[0]:batch[1]])
+			if err != nil {
+				findings = s.classifierFailed(findings, model.SafetyScopeLogs, err)
+				break
+			}
+			for _, c := range classifications {
+				index := batch[0] + c.Index - 1
+				if index < batch[0] || index >= batch[1] {
+					continue
+				}
+				logTime := logs[index].Time
+				if finding, ok := s.classifierFinding(c, model.SafetyScopeLogs, &logTime); ok {
+					findings = append(findings, finding)
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// Review 审查模型生成的报告：隐去命中 block 规则的句子，将需要提示的内容和免责声明追加在报告末尾。
+// logFindings 为生成前疗愈记录的检查结果，与报告内容的检查结果合并返回。附加的提示和声明使用报告语言
+func (s *SafetyService) Review(ctx context.Context, content string, structured json.RawMessage, logFindings []model.SafetyFinding, language string) *SafetyOutcome {
+	outcome := &SafetyOutcome{Content: content, Structured: structured, Findings: logFindings}
+	if !s.Enabled() {
+		return outcome
+	}
+
+	outcome.Content = s.applyOutputRules(content, &outcome.Findings, language)
+	if len(structured) > 0 {
+		outcome.Structured = s.applyOutputRulesJSON(structured, language)
+	}
+	if s.classifier != nil {
+		classifications, err := s.classifier.Classify(ctx, []string{content})
+		if err != nil {
+			outcome.Findings = s.classifierFailed(outcome.Findings, model.SafetyScopeOutput, err)
+		}
+		for _, c := range classifications {
+			if finding, ok := s.classifierFinding(c, model.SafetyScopeOutput, nil); ok {
+				outcome.Findings = append(outcome.Findings, finding)
+			}
+		}
+	}
+
+	var notes []string
+	seen := make(map[string]bool)
+	for i, finding := range outcome.Findings {
+		// 转人工审核的说明只展示给审核人
+		if finding.Action == model.SafetyActionEscalate || finding.Message == "" {
+			continue
+		}
+		message := localizeSafetyMessage(finding, language)
+		outcome.Findings[i].Message = message
+		if seen[message] {
+			continue
+		}
+		seen[message] = true
+		notes = append(notes, message)
+	}
+	if len(notes) > 0 {
+		var b strings.Builder
+		b.WriteString(strings.TrimRight(outcome.Content, "\n"))
+		b.WriteString("\n")
+		for _, note := range notes {
+			b.WriteString("\n" + labeledNote(reportText(language, "safety_note"), note, language) + "\n")
+		}
+		outcome.Content = b.String()
+	}
+	outcome.Content = s.EnsureDisclaimer(outcome.Content, language)
+	return outcome
+}
+
+// EnsureDisclaimer 报告末尾没有对应语言的免责声明时追加
+func (s *SafetyService) EnsureDisclaimer(content, language string) string {
+	if !s.Enabled() {
+		return content
+	}
+	disclaimer := safetyDisclaimer(language)
+	if strings.Contains(content, disclaimer) {
+		return content
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + labeledNote(reportText(language, "disclaimer"), disclaimer, language) + "\n"
+}
+
+// safetyDisclaimer 报告语言对应的免责声明：优先使用按语言配置的声明，中文可使用 disclaimer 配置，否则使用内置声明
+func safetyDisclaimer(language string) string {
+	language = reportLanguageOrDefault(language)
+	safetyConfig := config.GetSafetyConfig()
+	if disclaimer := strings.TrimSpace(safetyConfig.Disclaimers[language]); disclaimer != "" {
+		return disclaimer
+	}
+	if disclaimer := strings.TrimSpace(safetyConfig.Disclaimer); disclaimer != "" && language == DefaultReportLanguage {
+		return disclaimer
+	}
+	return reportText(language, "default_disclaimer")
+}
+
+// stripDisclaimer 去除报告末尾追加的免责声明，用于翻译等需要重新追加声明的场景
+func stripDisclaimer(content, language string) string {
+	note := labeledNote(reportText(language, "disclaimer"), safetyDisclaimer(language), language)
+	if idx := strings.LastIndex(content, note); idx >= 0 {
+		content = content[:idx] + content[idx+len(note):]
+	}
+	return strings.TrimRight(content, "\n") + "\n"
+}
+
+// labeledNote 报告末尾的引用块说明，中文使用全角冒号
+func labeledNote(label, text, language string) string {
+	if reportLanguageOrDefault(language) == DefaultReportLanguage {
+		return "> **" + label + "**：" + text
+	}
+	return "> **" + label + ":** " + text
+}
+
+// localizeSafetyMessage 使用内置类别说明的命中在非中文报告中换成对应语言的说明，规则配置了说明时保持不变
+func localizeSafetyMessage(finding model.SafetyFinding, language string) string {
+	messages, ok := localizedSafetyCategoryMessages[language]
+	if !ok || finding.Message != safetyCategoryMessage(finding.Category) {
+		return finding.Message
+	}
+	if message, ok := messages[finding.Category]; ok {
+		return message
+	}
+	return messages[""]
+}
+
+// applyOutputRules 逐句检查 Markdown 内容，记录命中并隐去命中 block 规则的句子，保留标题、列表等行首标记
+func (s *SafetyService) applyOutputRules(content string, findings *[]model.SafetyFinding, language string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		prefix := safetyMarkdownPrefix.FindString(line)
+		body := line[len(prefix):]
+		if strings.TrimSpace(body) == "" {
+			continue
+		}
+
+		var b strings.Builder
+		changed, lastBlocked := false, false
+		for _, sentence := range splitSentences(body) {
+			blocked := false
+			for _, rule := range s.rules {
+				if rule.scope != model.SafetyScopeOutput || !rule.matches(sentence) {
+					continue
+				}
+				if findings != nil {
+					*findings = append(*findings, newSafetyFinding(&rule, sentence, nil))
+				}
+				if rule.action == model.SafetyActionBlock {
+					blocked = true
+				}
+			}
+			switch {
+			case !blocked:
+				b.WriteString(sentence)
+			case !lastBlocked:
+				// 连续被隐去的句子只保留一处说明，保留英文句子之间的空格
+				b.WriteString(reportText(language, "blocked"))
+				b.WriteString(sentence[len(strings.TrimRight(sentence, " ")):])
+			}
+			changed = changed || blocked
+			lastBlocked = blocked
+		}
+		if changed {
+			lines[i] = prefix + b.String()
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// applyOutputRulesJSON 对结构化报告中的每个字符串字段应用 block 规则，未修改时原样返回
+func (s *SafetyService) applyOutputRulesJSON(raw json.RawMessage, language string) json.RawMessage {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return raw
+	}
+	changed := false
+	var walk func(v interface{}) interface{}
+	walk = func(v interface{}) interface{} {
+		switch typed := v.(type) {
+		case string:
+			sanitized := s.applyOutputRules(typed, nil, language)
+			changed = changed || sanitized != typed
+			return sanitized
+		case []interface{}:
+			for i := range typed {
+				typed[i] = walk(typed[i])
+			}
+		case map[string]interface{}:
+			for key := range typed {
+				typed[key] = walk(typed[key])
+			}
+		}
+		return v
+	}
+	value = walk(value)
+	if !changed {
+		return raw
+	}
+	sanitized, err := json.Marshal(value)
+	if err != nil {
+		return raw
+	}
+	return sanitized
+}
+
+// classifierFinding 将分类结果转为命中记录，低于阈值时忽略。处理方式取同类别规则的配置，
+// 分类器无法定位需要隐去的句子，block 按 annotate 处理；没有同类别规则时自伤和虐待转人工审核，其余附加提示
+func (s *SafetyService) classifierFinding(c SafetyClassification, scope string, logTime *time.Time) (model.SafetyFinding, bool) {
+	threshold := config.GetSafetyConfig().Classifier.Threshold
+	if c.Score < threshold || c.Category == "" {
+		return model.SafetyFinding{}, false
+	}
+	finding := model.SafetyFinding{
+		Category: c.Category,
+		Rule:     "classifier",
+		Scope:    scope,
+		Action:   model.SafetyActionAnnotate,
+		Excerpt:  truncateRunes(strings.TrimSpace(c.Excerpt), safetyExcerptRunes),
+		Message:  safetyCategoryMessage(c.Category),
+		Score:    c.Score,
+		LogTime:  logTime,
+	}
+	if c.Category == "self_harm" || c.Category == "abuse" {
+		finding.Action = model.SafetyActionEscalate
+	}
+	for _, rule := range s.rules {
+		if rule.category == c.Category && rule.scope == scope {
+			finding.Message = rule.message
+			if rule.action != model.SafetyActionBlock {
+				finding.Action = rule.action
+			}
+			break
+		}
+	}
+	return finding, true
+}
+
+// classifierFailed 分类器调用失败，配置了 failClosed 时转人工审核，否则仅记录日志并使用规则结果
+func (s *SafetyService) classifierFailed(findings []model.SafetyFinding, scope string, err error) []model.SafetyFinding {
+	log.Printf("安全分类器调用失败: %v", err)
+	if !config.GetSafetyConfig().Classifier.FailClosed {
+		return findings
+	}
+	return append(findings, model.SafetyFinding{
+		Category: "classifier_error",
+		Rule:     "classifier",
+		Scope:    scope,
+		Action:   model.SafetyActionEscalate,
+		Message:  "安全分类器暂时不可用，报告转人工审核。",
+	})
+}
+
+// HoldReport 将需要人工审核的报告放入审核队列，由儿童所属机构审核，家长未加入机构时由发起人所属机构审核，
+// 都没有时由管理员审核。通知机构账号有待审核的报告，并告知发起人报告正在审核
+func (s *SafetyService) HoldReport(report *model.GeneratedReport, opts *ReportOptions) (*model.ReportSafetyReview, error) {
+	review := &model.ReportSafetyReview{
+		ChildArchiveID:    report.ChildArchiveID,
+		ReportType:        report.ReportType,
+		Language:          report.Language,
+		Content:           report.Content,
+		StructuredContent: report.StructuredContent,
+		Provider:          report.Provider,
+		Model:             report.Model,
+		TemplateID:        report.TemplateID,
+		TemplateVersion:   report.TemplateVersion,
+		RedactionLog:      report.RedactionLog,
+		Findings:          report.SafetyFindings,
+		Status:            model.SafetyReviewPending,
+		GeneratedAt:       report.GeneratedAt,
+	}
+	if opts != nil {
+		review.RequestedBy = opts.RequestedBy
+		review.JobID = opts.JobID
+	}
+	if archive, err := s.userDAO.GetChildArchiveByID(report.ChildArchiveID); err == nil {
+		if parent, err := s.userDAO.GetUserByID(archive.UserID); err == nil {
+			review.InstitutionID = institutionOf(parent)
+		}
+	}
+	if review.InstitutionID == "" && review.RequestedBy != "" {
+		if requester, err := s.userDAO.GetUserByID(review.RequestedBy); err == nil {
+			review.InstitutionID = institutionOf(requester)
+		}
+	}
+	if err := s.reviewDAO.CreateSafetyReview(review); err != nil {
+		return nil, fmt.Errorf("提交安全审核失败: %v", err)
+	}
+
+	reviewID := strconv.FormatUint(uint64(review.ID), 10)
+	if review.InstitutionID != "" {
+		if err := s.notificationService.Notify(review.InstitutionID, model.NotificationSafetyReview, "有待审核的AI报告",
+			"一份AI报告触发了安全审查，请尽快审核。", "report_safety_review", reviewID); err != nil {
+			log.Printf("发送安全审核通知失败: %v", err)
+		}
+	}
+	s.notifyRequester(review, model.NotificationReportInReview, "AI报告正在审核",
+		"报告涉及需要专业人员确认的内容，已提交机构审核，审核完成后将通知您。", "report_safety_review", reviewID)
+	return review, nil
+}
+
+// ListReviews 获取审核队列。管理员可查看全部或指定机构，机构账号和所属的认证康复师查看本机构的报告
+func (s *SafetyService) ListReviews(userID, institutionID, status, childArchiveID string) ([]model.ReportSafetyReview, error) {
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil {
+		return nil, ErrPermissionDenied
+	}
+	filter := DAO.SafetyReviewFilter{Status: status, ChildArchiveID: childArchiveID, Limit: 200}
+	switch {
+	case isAdmin(user):
+		filter.InstitutionID = institutionID
+		filter.AllInstitutions = institutionID == ""
+	case isSafetyReviewer(user):
+		filter.InstitutionID = institutionOf(user)
+	default:
+		return nil, ErrPermissionDenied
+	}
+	reviews, err := s.reviewDAO.ListSafetyReviews(filter)
+	if err != nil {
+		return nil, fmt.Errorf("获取审核队列失败: %v", err)
+	}
+	return reviews, nil
+}
+
+// GetReview 获取待审核报告的完整内容
+func (s *SafetyService) GetReview(userID string, reviewID uint) (*model.ReportSafetyReview, error) {
+	return s.getReviewable(userID, reviewID)
+}
+
+// ApproveReview 审核通过，保存为正式报告并通知发起人，后台任务同时标记为成功
+func (s *SafetyService) ApproveReview(userID string, reviewID uint, note string) (*model.ReportSafetyReview, error) {
+	review, err := s.getReviewable(userID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &model.GeneratedReport{
+		ChildArchiveID:    review.ChildArchiveID,
+		ReportType:        review.ReportType,
+		Language:          reportLanguageOrDefault(review.Language),
+		Content:           review.Content,
+		StructuredContent: review.StructuredContent,
+		Provider:          review.Provider,
+		Model:             review.Model,
+		TemplateID:        review.TemplateID,
+		TemplateVersion:   review.TemplateVersion,
+		RedactionLog:      review.RedactionLog,
+		SafetyFindings:    review.Findings,
+		GeneratedAt:       review.GeneratedAt,
+	}
+	ok, err := s.reviewDAO.ApproveSafetyReview(review.ID, map[string]interface{}{
+		"status":      model.SafetyReviewApproved,
+		"reviewer_id": userID,
+		"review_note": strings.TrimSpace(note),
+		"reviewed_at": now,
+	}, report)
+	if err != nil {
+		return nil, fmt.Errorf("保存报告失败: %v", err)
+	}
+	if !ok {
+		return nil, ErrSafetyReviewClosed
+	}
+
+	if review.JobID != nil {
+		if _, err := s.reportJobDAO.TransitionReportJob(*review.JobID, model.ReportJobInReview, map[string]interface{}{
+			"status":      model.ReportJobSucceeded,
+			"report_id":   report.ID,
+			"finished_at": now,
+		}); err != nil {
+			log.Printf("更新报告任务 %d 状态失败: %v", *review.JobID, err)
+		}
+	}
+	s.notifyRequester(review, model.NotificationReportReady, "AI报告已通过审核",
+		"报告已通过机构审核，快去看看吧。", "generated_report", strconv.FormatUint(uint64(report.ID), 10))
+	return s.reviewDAO.GetSafetyReviewByID(review.ID)
+}
+
+// RejectReview 审核不通过，报告不保存，后台任务标记为失败并通知发起人
+func (s *SafetyService) RejectReview(userID string, reviewID uint, note string) (*model.ReportSafetyReview, error) {
+	review, err := s.getReviewable(userID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ok, err := s.reviewDAO.TransitionSafetyReview(review.ID, map[string]interface{}{
+		"status":      model.SafetyReviewRejected,
+		"reviewer_id": userID,
+		"review_note": strings.TrimSpace(note),
+		"reviewed_at": now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("更新审核状态失败: %v", err)
+	}
+	if !ok {
+		return nil, ErrSafetyReviewClosed
+	}
+
+	if review.JobID != nil {
+		if _, err := s.reportJobDAO.TransitionReportJob(*review.JobID, model.ReportJobInReview, map[string]interface{}{
+			"status":      model.ReportJobFailed,
+			"last_error":  "报告未通过安全审核",
+			"finished_at": now,
+		}); err != nil {
+			log.Printf("更新报告任务 %d 状态失败: %v", *review.JobID, err)
+		}
+	}
+	s.notifyRequester(review, model.NotificationReportFailed, "AI报告未通过审核",
+		"报告未通过机构审核，机构工作人员会与您联系。", "report_safety_review", strconv.FormatUint(uint64(review.ID), 10))
+	return s.reviewDAO.GetSafetyReviewByID(review.ID)
+}
+
+// getReviewable 获取当前用户有权审核的记录
+func (s *SafetyService) getReviewable(userID string, reviewID uint) (*model.ReportSafetyReview, error) {
+	review, err := s.reviewDAO.GetSafetyReviewByID(reviewID)
+	if err != nil {
+		return nil, ErrSafetyReviewNotFound
+	}
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil {
+		return nil, ErrPermissionDenied
+	}
+	if isAdmin(user) || (review.InstitutionID != "" && isSafetyReviewer(user) && institutionOf(user) == review.InstitutionID) {
+		return review, nil
+	}
+	return nil, ErrPermissionDenied
+}
+
+// notifyRequester 通知报告发起人和儿童家长
+func (s *SafetyService) notifyRequester(review *model.ReportSafetyReview, notificationType, title, content, relatedType, relatedID string) {
+	var recipients []string
+	if review.RequestedBy != "" {
+		recipients = append(recipients, review.RequestedBy)
+	}
+	if archive, err := s.userDAO.GetChildArchiveByID(review.ChildArchiveID); err == nil {
+		content = archive.ChildName + "的" + content
+		if archive.UserID != review.RequestedBy {
+			recipients = append(recipients, archive.UserID)
+		}
+	}
+	for _, userID := range recipients {
+		if err := s.notificationService.Notify(userID, notificationType, title, content, relatedType, relatedID); err != nil {
+			log.Printf("发送安全审核通知失败: %v", err)
+		}
+	}
+}
+
+// isSafetyReviewer 机构账号和加入机构的认证康复师可以审核本机构的报告
+func isSafetyReviewer(user *DAO.User) bool {
+	if user.Identity == DAO.IdentityInstitution {
+		return true
+	}
+	return isCertifiedTherapist(user) && user.InstitutionID != ""
+}
+
+func newSafetyFinding(rule *safetyRule, sentence string, logTime *time.Time) model.SafetyFinding {
+	return model.SafetyFinding{
+		Category: rule.category,
+		Rule:     rule.name,
+		Scope:    rule.scope,
+		Action:   rule.action,
+		Excerpt:  truncateRunes(strings.TrimSpace(sentence), safetyExcerptRunes),
+		Message:  rule.message,
+		LogTime:  logTime,
+	}
+}
+
+// promptLogText 疗愈记录的正文和模板答案
+func promptLogText(entry *ReportPromptLog) string {
+	parts := []string{entry.Content}
+	for _, answer := range entry.Answers {
+		parts = append(parts, answer.Label+"："+answer.Value)
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// splitSentences 按中英文句末标点和换行切分，保留标点，拼接后与原文一致
+func splitSentences(text string) []string {
+	var sentences []string
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for _, sentence := range safetySentenceEnd.FindAllString(line, -1) {
+			if sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+		}
+	}
+	return sentences
+}
+
+// classifierBatches 按长度将文本分批，返回每批的起止下标
+func classifierBatches(texts []string) [][2]int {
+	var batches [][2]int
+	start, size := 0, 0
+	for i, text := range texts {
+		length := utf8.RuneCountInString(text)
+		if i > start && size+length > safetyClassifierBatchRunes {
+			batches = append(batches, [2]int{start, i})
+			start, size = i, 0
+		}
+		size += length
+	}
+	if start < len(texts) {
+		batches = append(batches, [2]int{start, len(texts)})
+	}
+	return batches
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
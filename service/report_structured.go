@@ -0,0 +1,347 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"melody_cure/model"
+	"sort"
+	"strings"
+)
+
+// maxReportRepairAttempts 模型输出不符合输出结构时，要求其修正的最大次数
+const maxReportRepairAttempts = 2
+
+// jsonSchema 报告输出结构支持的 JSON Schema 子集：type、properties、required、items、enum 及长度和数量限制
+type jsonSchema struct {
+	Type       interface{}            `json:"type"` // 字符串或字符串数组
+	Properties map[string]*jsonSchema `json:"properties"`
+	Required   []string               `json:"required"`
+	Items      *jsonSchema            `json:"items"`
+	Enum       []interface{}          `json:"enum"`
+	MinItems   *int                   `json:"minItems"`
+	MaxItems   *int                   `json:"maxItems"`
+	MinLength  *int                   `json:"minLength"`
+	MaxLength  *int                   `json:"maxLength"`
+}
+
+// parseOutputSchema 解析报告类型的输出结构，根节点必须为 object
+func parseOutputSchema(text string) (*jsonSchema, error) {
+	var schema jsonSchema
+	if err := json.Unmarshal([]byte(text), &schema); err != nil {
+		return nil, invalidRequest("输出结构不是合法的 JSON Schema")
+	}
+	if !schema.allowsType("object") {
+		return nil, invalidRequest("输出结构的根节点类型必须为 object")
+	}
+	return &schema, nil
+}
+
+func (s *jsonSchema) types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func (s *jsonSchema) allowsType(name string) bool {
+	for _, t := range s.types() {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validate 校验值是否符合结构，返回所有不符合项
+func (s *jsonSchema) validate(value interface{}, path string) []string {
+	var problems []string
+	if types := s.types(); len(types) > 0 {
+		actual := jsonTypeOf(value)
+		matched := false
+		for _, t := range types {
+			if t == actual || (t == "number" && actual == "integer") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return []string{fmt.Sprintf("%s 的类型应为 %s，实际为 %s", path, strings.Join(types, "/"), actual)}
+		}
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, option := range s.Enum {
+			if fmt.Sprint(option) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s 的取值 %v 不在允许范围 %v 内", path, value, s.Enum))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			problems = append(problems, fmt.Sprintf("%s 长度不能少于 %d", path, *s.MinLength))
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			problems = append(problems, fmt.Sprintf("%s 长度不能超过 %d", path, *s.MaxLength))
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			problems = append(problems, fmt.Sprintf("%s 至少需要 %d 项", path, *s.MinItems))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			problems = append(problems, fmt.Sprintf("%s 最多 %d 项", path, *s.MaxItems))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				problems = append(problems, s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				problems = append(problems, fmt.Sprintf("缺少必填字段 %s.%s", path, name))
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if field, ok := v[name]; ok {
+				problems = append(problems, s.Properties[name].validate(field, path+"."+name)...)
+			}
+		}
+	}
+	return problems
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
+
+// activityCatalog 可推荐的游戏和课程，键为 类型:ID
+type activityCatalog map[string]string
+
+func activityKey(activityType, id string) string {
+	return activityType + ":" + id
+}
+
+// loadActivityCatalog 获取已发布的游戏和课程，作为报告推荐活动的候选
+func (s *AIReportService) loadActivityCatalog() (activityCatalog, []string, error) {
+	games, err := s.userDAO.GetGames()
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取游戏列表失败: %v", err)
+	}
+	courses, err := s.userDAO.GetCourses()
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取课程列表失败: %v", err)
+	}
+
+	catalog := make(activityCatalog, len(games)+len(courses))
+	lines := make([]string, 0, len(games)+len(courses))
+	for _, game := range games {
+		catalog[activityKey(model.ActivityTypeGame, game.ID)] = game.Title
+		lines = append(lines, fmt.Sprintf("- game | %s | %s | %s | 适用年龄：%s", game.ID, game.Title, game.Category, game.AgeRange))
+	}
+	for _, course := range courses {
+		catalog[activityKey(model.ActivityTypeCourse, course.ID)] = course.Title
+		lines = append(lines, fmt.Sprintf("- course | %s | %s | %s | %s", course.ID, course.Title, course.Category, course.Level))
+	}
+	return catalog, lines, nil
+}
+
+// structuredOutputInstruction 要求模型按输出结构返回 JSON 的说明，附加在系统消息后
+func structuredOutputInstruction(schema string, activities []string) string {
+	var b strings.Builder
+	b.WriteString("\n\n请只输出一个 JSON 对象，不要输出代码块标记或任何其他文字。JSON 必须符合以下 JSON Schema：\n")
+	b.WriteString(schema)
+	b.WriteString("\n\n字段说明：sections 为报告各章节，content 使用 Markdown；risk_flags 列出需要关注的风险，level 取 low、medium 或 high；")
+	b.WriteString("recommended_activities 为推荐的训练活动，type 为 game 或 course，id 必须与下列候选中的 ID 完全一致，不得编造。\n")
+	if len(activities) == 0 {
+		b.WriteString("当前没有可推荐的游戏和课程，recommended_activities 请输出空数组。\n")
+	} else {
+		b.WriteString("可推荐的游戏和课程（类型 | ID | 名称 | 分类 | 说明）：\n")
+		b.WriteString(strings.Join(activities, "\n"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// structuredOutput 模型输出的校验结果
+type structuredOutput struct {
+	raw      json.RawMessage
+	markdown string
+}
+
+// parseStructuredOutput 解析并校验模型输出，通过后渲染为 Markdown
+func (p *preparedReport) parseStructuredOutput(content string) (*structuredOutput, []string) {
+	raw := extractJSONObject(content)
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return nil, []string{fmt.Sprintf("输出不是合法的 JSON: %v", err)}
+	}
+	if problems := p.schema.validate(value, "$"); len(problems) > 0 {
+		return nil, problems
+	}
+
+	var report model.StructuredReport
+	if err := json.Unmarshal([]byte(raw), &report); err != nil {
+		return nil, []string{fmt.Sprintf("输出的字段类型不正确: %v", err)}
+	}
+	var problems []string
+	for i, activity := range report.RecommendedActivities {
+		title, ok := p.activities[activityKey(activity.Type, activity.ID)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("$.recommended_activities[%d] 的 %s ID %q 不在候选列表中", i, activity.Type, activity.ID))
+			continue
+		}
+		report.RecommendedActivities[i].Title = title
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(raw)); err != nil {
+		return nil, []string{err.Error()}
+	}
+	if report.Title == "" {
+		report.Title = p.reportType.Name
+	}
+	return &structuredOutput{
+		raw:      json.RawMessage(compact.Bytes()),
+		markdown: renderStructuredReport(&report, p.language),
+	}, nil
+}
+
+// extractJSONObject 去除模型可能附带的代码块标记和前后说明文字
+func extractJSONObject(content string) string {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return strings.TrimSpace(content)
+	}
+	return content[start : end+1]
+}
+
+// finalizeStructuredReport 校验结构化报告，不符合输出结构时将问题反馈给模型要求修正，
+// 通过后以渲染的 Markdown 作为报告内容。未配置输出结构的报告类型原样返回
+func (s *AIReportService) finalizeStructuredReport(ctx context.Context, prepared *preparedReport, generated *LLMResponse) (*LLMResponse, json.RawMessage, error) {
+	if prepared.schema == nil {
+		return generated, nil, nil
+	}
+
+	req := s.newReportRequest(prepared)
+	for attempt := 0; ; attempt++ {
+		output, problems := prepared.parseStructuredOutput(generated.Content)
+		if output != nil {
+			generated.Content = output.markdown
+			return generated, output.raw, nil
+		}
+		if attempt == maxReportRepairAttempts {
+			return nil, nil, fmt.Errorf("AI输出不符合报告结构: %s", strings.Join(problems, "；"))
+		}
+		log.Printf("报告 %s 的AI输出不符合报告结构，第 %d 次要求修正: %s", prepared.reportType.Key, attempt+1, strings.Join(problems, "；"))
+
+		req.Messages = append(req.Messages,
+			LLMMessage{Role: "assistant", Content: generated.Content},
+			LLMMessage{Role: "user", Content: "上面的输出不符合要求，存在以下问题：\n- " + strings.Join(problems, "\n- ") + "\n请修正后重新输出完整的 JSON 对象，不要输出其他文字。"},
+		)
+		repaired, err := s.llmProvider.Chat(ctx, req)
+		if err != nil {
+			return nil, nil, err
+		}
+		repaired.PromptTokens += generated.PromptTokens
+		repaired.CompletionTokens += generated.CompletionTokens
+		generated = repaired
+	}
+}
+
+// renderStructuredReport 将结构化报告渲染为 Markdown，固定的章节名使用报告语言
+func renderStructuredReport(report *model.StructuredReport, language string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", report.Title)
+	if summary := strings.TrimSpace(report.Summary); summary != "" {
+		fmt.Fprintf(&b, "\n%s\n", summary)
+	}
+	for _, section := range report.Sections {
+		fmt.Fprintf(&b, "\n## %s\n%s\n", section.Heading, strings.TrimSpace(section.Content))
+	}
+	if len(report.RiskFlags) > 0 {
+		fmt.Fprintf(&b, "\n## %s\n", reportText(language, "risk_flags"))
+		for _, flag := range report.RiskFlags {
+			label := riskLevelLabel(flag.Level, language)
+			if flag.Category != "" {
+				label += " · " + flag.Category
+			}
+			if language == DefaultReportLanguage {
+				fmt.Fprintf(&b, "- **%s**：%s\n", label, flag.Description)
+			} else {
+				fmt.Fprintf(&b, "- **%s**: %s\n", label, flag.Description)
+			}
+		}
+	}
+	if len(report.RecommendedActivities) > 0 {
+		fmt.Fprintf(&b, "\n## %s\n", reportText(language, "recommended_activities"))
+		for _, activity := range report.RecommendedActivities {
+			kind := reportText(language, "course")
+			if activity.Type == model.ActivityTypeGame {
+				kind = reportText(language, "game")
+			}
+			if language == DefaultReportLanguage {
+				fmt.Fprintf(&b, "- %s《%s》：%s\n", kind, activity.Title, activity.Reason)
+			} else {
+				fmt.Fprintf(&b, "- %s \"%s\": %s\n", kind, activity.Title, activity.Reason)
+			}
+		}
+	}
+	return b.String()
+}
+
+func riskLevelLabel(level, language string) string {
+	switch level {
+	case model.RiskLevelHigh:
+		return reportText(language, "risk_high")
+	case model.RiskLevelMedium:
+		return reportText(language, "risk_medium")
+	case model.RiskLevelLow:
+		return reportText(language, "risk_low")
+	}
+	return level
+}
@@ -0,0 +1,638 @@
+package service
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/model"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed prompts/*.tmpl prompts/en/*.tmpl
+var promptFS embed.FS
+
+var ErrReportTypeNotFound = errors.New("报告类型不存在或已停用")
+
+var reportTypeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// promptFuncs 提示词模板可用的函数
+var promptFuncs = template.FuncMap{
+	"date": formatPromptDate,
+	"join": strings.Join,
+}
+
+// promptPartials 所有报告模板共用的片段（system_role、child_profile、logs、related_context、metrics、comparison 等），
+// 英文片段以 en/ 为前缀，如 en/system_role
+var promptPartials = template.Must(template.New("partials").Funcs(promptFuncs).ParseFS(promptFS, "prompts/partials.tmpl", "prompts/en/partials.tmpl"))
+
+// defaultOutputSchema 内置报告类型的输出结构
+const defaultOutputSchema = `{
+  "type": "object",
+  "properties": {
+    "title": {"type": "string", "minLength": 1},
+    "summary": {"type": "string"},
+    "sections": {
+      "type": "array",
+      "minItems": 1,
+      "items": {
+        "type": "object",
+        "properties": {
+          "heading": {"type": "string", "minLength": 1},
+          "content": {"type": "string"}
+        },
+        "required": ["heading", "content"]
+      }
+    },
+    "risk_flags": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "level": {"type": "string", "enum": ["low", "medium", "high"]},
+          "category": {"type": "string"},
+          "description": {"type": "string", "minLength": 1}
+        },
+        "required": ["level", "description"]
+      }
+    },
+    "recommended_activities": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "type": {"type": "string", "enum": ["game", "course"]},
+          "id": {"type": "string", "minLength": 1},
+          "title": {"type": "string"},
+          "reason": {"type": "string"}
+        },
+        "required": ["type", "id", "reason"]
+      }
+    }
+  },
+  "required": ["title", "summary", "sections", "risk_flags", "recommended_activities"]
+}`
+
+// defaultReportTypes 内置报告类型，数据库中不存在时自动创建。Localized 为其他语言的内置模板，该语言还没有启用的模板时自动创建并启用
+var defaultReportTypes = []struct {
+	Key         string
+	Name        string
+	Description string
+	File        string
+	Localized   map[string]string
+}{
+	{Key: "summary", Name: "疗愈总结", Description: "总结指定时间段内的整体表现、进步亮点和需要关注的问题", File: "prompts/summary.tmpl",
+		Localized: map[string]string{"en": "prompts/en/summary.tmpl"}},
+	{Key: "suggestion", Name: "康复建议", Description: "基于疗愈记录给出治疗方案优化、家庭配合等具体建议", File: "prompts/suggestion.tmpl",
+		Localized: map[string]string{"en": "prompts/en/suggestion.tmpl"}},
+	{Key: "progress", Name: "进度分析", Description: "与基线对比分析康复进度、治疗效果和里程碑达成情况", File: "prompts/progress.tmpl",
+		Localized: map[string]string{"en": "prompts/en/progress.tmpl"}},
+}
+
+// localizedSystemMessages 其他语言模板未配置系统消息时使用的默认系统消息
+var localizedSystemMessages = map[string]string{
+	"en": "You are a professional pediatric rehabilitation therapist and child mental health specialist with extensive experience in child development and rehabilitation. Based on the healing log data provided, write a professional, detailed and practically useful analysis report. Make sure the content is accurate, clearly written for parents, and that every recommendation is specific and actionable.",
+}
+
+// ReportPromptData 提示词模板的数据
+type ReportPromptData struct {
+	ReportType   string
+	ReportName   string
+	Child        ReportPromptChild
+	StartDate    *time.Time
+	EndDate      *time.Time
+	Logs         []ReportPromptLog // 按记录时间升序，记录过多时为空，使用 WindowSummaries
+	LogCount     int
+	FirstLogAt   time.Time
+	LastLogAt    time.Time
+	Metrics      []ReportPromptMetric
+	Comparison   string // 疗愈前后对比的文字描述，未请求对比时为空
+	OutputSchema string
+
+	WindowSummaries []ReportPromptWindow  // 提示词超出预算时各时间窗口的摘要
+	RelatedContext  []ReportPromptContext // 语义索引中与本期记录最相关的历史资料，不含本期记录
+}
+
+// ReportPromptContext 一条相关历史资料
+type ReportPromptContext struct {
+	Kind    string // log, report, goal
+	Name    string // 报告类型名称或治疗目标标题，疗愈记录为空
+	Time    time.Time
+	Content string
+}
+
+// ReportPromptWindow 一个时间窗口内疗愈记录的摘要
+type ReportPromptWindow struct {
+	Start    time.Time
+	End      time.Time
+	LogCount int
+	Summary  string
+}
+
+// ReportPromptChild 儿童档案信息
+type ReportPromptChild struct {
+	ID        string
+	Name      string
+	Gender    string
+	Age       int
+	Diagnosis string
+	Condition string
+	Treatment string
+}
+
+// ReportPromptLog 一条疗愈日志
+type ReportPromptLog struct {
+	Index      int
+	Time       time.Time
+	Content    string
+	Answers    []ReportPromptAnswer
+	MediaTypes []string
+	Metrics    []ReportPromptLogMetric
+}
+
+// ReportPromptAnswer 模板记录的一项答案
+type ReportPromptAnswer struct {
+	Label string
+	Value string
+}
+
+// ReportPromptLogMetric 日志中记录的指标
+type ReportPromptLogMetric struct {
+	Name  string
+	Value float64
+	Unit  string
+}
+
+// ReportPromptMetric 时间段内同名指标的汇总
+type ReportPromptMetric struct {
+	Name  string
+	Unit  string
+	Count int
+	First float64
+	Last  float64
+	Min   float64
+	Max   float64
+	Avg   float64
+}
+
+// ReportTypeDetail 报告类型及其所有模板版本
+type ReportTypeDetail struct {
+	model.ReportType
+	Versions []model.PromptTemplate `json:"versions"`
+}
+
+// PromptPreview 提示词预览
+type PromptPreview struct {
+	ReportType      string `json:"report_type"`
+	TemplateVersion int    `json:"template_version"` // 预览草稿时为0
+	SystemPrompt    string `json:"system_prompt"`
+	Prompt          string `json:"prompt"`
+	SampleData      bool   `json:"sample_data"` // 是否使用示例数据渲染
+}
+
+// ReportTemplateService 报告类型和提示词模板管理
+type ReportTemplateService struct {
+	reportTypeDAO *DAO.ReportTypeDAO
+	userDAO       *DAO.UserDAO
+}
+
+func NewReportTemplateService(reportTypeDAO *DAO.ReportTypeDAO, userDAO *DAO.UserDAO) (*ReportTemplateService, error) {
+	s := &ReportTemplateService{
+		reportTypeDAO: reportTypeDAO,
+		userDAO:       userDAO,
+	}
+	if err := s.ensureDefaultReportTypes(); err != nil {
+		return nil, fmt.Errorf("初始化内置报告类型失败: %v", err)
+	}
+	return s, nil
+}
+
+// ensureDefaultReportTypes 创建数据库中还不存在的内置报告类型，以及还没有启用模板的语言的内置模板，已存在的不做修改
+func (s *ReportTemplateService) ensureDefaultReportTypes() error {
+	for _, def := range defaultReportTypes {
+		reportType, err := s.reportTypeDAO.GetReportTypeByKey(def.Key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			text, readErr := promptFS.ReadFile(def.File)
+			if readErr != nil {
+				return readErr
+			}
+			reportType = &model.ReportType{Key: def.Key, Name: def.Name, Description: def.Description, Enabled: true}
+			tpl := &model.PromptTemplate{
+				Language:     DefaultReportLanguage,
+				SystemPrompt: reportSystemMessage,
+				Template:     string(text),
+				OutputSchema: defaultOutputSchema,
+				Note:         "内置模板",
+				CreatedBy:    "system",
+			}
+			err = s.reportTypeDAO.CreateReportType(reportType, tpl)
+		}
+		if err != nil {
+			return err
+		}
+
+		for language, file := range def.Localized {
+			if reportType.LocalizedVersions[language] > 0 {
+				continue
+			}
+			text, err := promptFS.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			tpl := &model.PromptTemplate{
+				ReportTypeID: reportType.ID,
+				Language:     language,
+				SystemPrompt: localizedSystemMessages[language],
+				Template:     string(text),
+				OutputSchema: defaultOutputSchema,
+				Note:         "内置模板",
+				CreatedBy:    "system",
+			}
+			if err := s.reportTypeDAO.CreatePromptTemplate(tpl, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveActiveTemplate 获取已启用的报告类型及其在指定语言下的当前模板版本，该语言没有启用的模板时使用默认语言的模板
+func (s *ReportTemplateService) ResolveActiveTemplate(key, language string) (*model.ReportType, *model.PromptTemplate, error) {
+	reportType, err := s.reportTypeDAO.GetReportTypeByKey(key)
+	if err != nil || !reportType.Enabled {
+		return nil, nil, ErrReportTypeNotFound
+	}
+	version := activeVersionFor(reportType, language)
+	tpl, err := s.reportTypeDAO.GetPromptTemplate(reportType.ID, version)
+	if err != nil {
+		return nil, nil, notFound("报告类型 %s 的模板版本 %d 不存在", key, version)
+	}
+	return reportType, tpl, nil
+}
+
+// activeVersionFor 报告类型在指定语言下启用的模板版本号
+func activeVersionFor(reportType *model.ReportType, language string) int {
+	if version := reportType.LocalizedVersions[language]; version > 0 {
+		return version
+	}
+	return reportType.ActiveVersion
+}
+
+// ReportTypeName 报告类型的显示名称，类型不存在时返回标识
+func (s *ReportTemplateService) ReportTypeName(key string) string {
+	reportType, err := s.reportTypeDAO.GetReportTypeByKey(key)
+	if err != nil || reportType.Name == "" {
+		return key
+	}
+	return reportType.Name
+}
+
+// ListReportTypes 获取报告类型列表，管理员可查看已停用的类型
+func (s *ReportTemplateService) ListReportTypes(userID string, includeDisabled bool) ([]model.ReportType, error) {
+	if includeDisabled {
+		if err := s.CheckAdmin(userID); err != nil {
+			return nil, err
+		}
+	}
+	return s.reportTypeDAO.ListReportTypes(!includeDisabled)
+}
+
+// GetReportType 获取报告类型及其所有模板版本（仅管理员）
+func (s *ReportTemplateService) GetReportType(userID, key string) (*ReportTypeDetail, error) {
+	if err := s.CheckAdmin(userID); err != nil {
+		return nil, err
+	}
+	reportType, err := s.getReportType(key)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.reportTypeDAO.GetPromptTemplates(reportType.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取模板版本失败: %v", err)
+	}
+	return &ReportTypeDetail{ReportType: *reportType, Versions: versions}, nil
+}
+
+// CreateReportType 创建报告类型及其第一个模板版本（仅管理员）
+func (s *ReportTemplateService) CreateReportType(userID string, req *request.CreateReportTypeRequest) (*ReportTypeDetail, error) {
+	if err := s.CheckAdmin(userID); err != nil {
+		return nil, err
+	}
+	key := strings.TrimSpace(req.Key)
+	if !reportTypeKeyPattern.MatchString(key) {
+		return nil, invalidRequest("报告类型标识只能包含小写字母、数字和下划线，并以字母开头")
+	}
+	if _, err := s.reportTypeDAO.GetReportTypeByKey(key); err == nil {
+		return nil, invalidRequest("报告类型 %s 已存在", key)
+	}
+	if err := validatePromptTemplate(req.Template, req.OutputSchema); err != nil {
+		return nil, err
+	}
+
+	reportType := &model.ReportType{Key: key, Name: req.Name, Description: req.Description, Enabled: true}
+	if req.Enabled != nil {
+		reportType.Enabled = *req.Enabled
+	}
+	tpl := &model.PromptTemplate{
+		SystemPrompt: req.SystemPrompt,
+		Template:     req.Template,
+		OutputSchema: req.OutputSchema,
+		Note:         req.Note,
+		CreatedBy:    userID,
+	}
+	if err := s.reportTypeDAO.CreateReportType(reportType, tpl); err != nil {
+		return nil, fmt.Errorf("创建报告类型失败: %v", err)
+	}
+	return &ReportTypeDetail{ReportType: *reportType, Versions: []model.PromptTemplate{*tpl}}, nil
+}
+
+// UpdateReportType 更新报告类型的名称、描述和启用状态（仅管理员）
+func (s *ReportTemplateService) UpdateReportType(userID, key string, req *request.UpdateReportTypeRequest) (*model.ReportType, error) {
+	if err := s.CheckAdmin(userID); err != nil {
+		return nil, err
+	}
+	reportType, err := s.getReportType(key)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Enabled != nil {
+		updates["enabled"] = *req.Enabled
+	}
+	if len(updates) > 0 {
+		if err := s.reportTypeDAO.UpdateReportType(reportType.ID, updates); err != nil {
+			return nil, fmt.Errorf("更新报告类型失败: %v", err)
+		}
+	}
+	return s.getReportType(key)
+}
+
+// CreateTemplateVersion 新增模板版本，已有版本不会被修改（仅管理员）
+func (s *ReportTemplateService) CreateTemplateVersion(userID, key string, req *request.PromptTemplateRequest) (*model.PromptTemplate, error) {
+	if err := s.CheckAdmin(userID); err != nil {
+		return nil, err
+	}
+	reportType, err := s.getReportType(key)
+	if err != nil {
+		return nil, err
+	}
+	language, err := NormalizeReportLanguage(req.Language)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePromptTemplate(req.Template, req.OutputSchema); err != nil {
+		return nil, err
+	}
+
+	tpl := &model.PromptTemplate{
+		ReportTypeID: reportType.ID,
+		Language:     language,
+		SystemPrompt: req.SystemPrompt,
+		Template:     req.Template,
+		OutputSchema: req.OutputSchema,
+		Note:         req.Note,
+		CreatedBy:    userID,
+	}
+	if err := s.reportTypeDAO.CreatePromptTemplate(tpl, req.Activate); err != nil {
+		return nil, fmt.Errorf("保存模板版本失败: %v", err)
+	}
+	return tpl, nil
+}
+
+// ActivateTemplateVersion 启用指定的模板版本，用于发布新版本或回滚，只影响该版本所属语言（仅管理员）
+func (s *ReportTemplateService) ActivateTemplateVersion(userID, key string, version int) (*model.ReportType, error) {
+	if err := s.CheckAdmin(userID); err != nil {
+		return nil, err
+	}
+	reportType, err := s.getReportType(key)
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := s.reportTypeDAO.GetPromptTemplate(reportType.ID, version)
+	if err != nil {
+		return nil, notFound("模板版本 %d 不存在", version)
+	}
+	reportType, err = s.reportTypeDAO.ActivatePromptTemplate(tpl)
+	if err != nil {
+		return nil, fmt.Errorf("启用模板版本失败: %v", err)
+	}
+	return reportType, nil
+}
+
+// GetTemplateForPreview 获取用于预览的模板：提供了草稿内容时使用草稿，否则使用指定版本，版本为0时使用报告语言的当前版本
+func (s *ReportTemplateService) GetTemplateForPreview(userID, key, language string, req *request.PreviewPromptRequest) (*model.ReportType, *model.PromptTemplate, error) {
+	if err := s.CheckAdmin(userID); err != nil {
+		return nil, nil, err
+	}
+	reportType, err := s.getReportType(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Template != "" {
+		return reportType, &model.PromptTemplate{
+			ReportTypeID: reportType.ID,
+			Language:     language,
+			SystemPrompt: req.SystemPrompt,
+			Template:     req.Template,
+			OutputSchema: req.OutputSchema,
+		}, nil
+	}
+
+	version := req.Version
+	if version == 0 {
+		version = activeVersionFor(reportType, language)
+	}
+	tpl, err := s.reportTypeDAO.GetPromptTemplate(reportType.ID, version)
+	if err != nil {
+		return nil, nil, notFound("模板版本 %d 不存在", version)
+	}
+	return reportType, tpl, nil
+}
+
+// CheckAdmin 校验是否为管理员
+func (s *ReportTemplateService) CheckAdmin(userID string) error {
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil || !isAdmin(user) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+func (s *ReportTemplateService) getReportType(key string) (*model.ReportType, error) {
+	reportType, err := s.reportTypeDAO.GetReportTypeByKey(key)
+	if err != nil {
+		return nil, notFound("报告类型不存在")
+	}
+	return reportType, nil
+}
+
+// renderPrompt 使用共用片段渲染提示词模板
+func renderPrompt(text string, data *ReportPromptData) (string, error) {
+	tpl, err := promptPartials.Clone()
+	if err != nil {
+		return "", err
+	}
+	if _, err := tpl.New("report").Parse(text); err != nil {
+		return "", invalidRequest("模板语法错误: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.ExecuteTemplate(&buf, "report", data); err != nil {
+		return "", fmt.Errorf("模板渲染失败: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// validatePromptTemplate 使用示例数据试渲染模板，并校验输出结构为合法的 JSON
+func validatePromptTemplate(text, outputSchema string) error {
+	if strings.TrimSpace(text) == "" {
+		return invalidRequest("模板内容不能为空")
+	}
+	if _, err := renderPrompt(text, samplePromptData()); err != nil {
+		return err
+	}
+	if strings.TrimSpace(outputSchema) != "" {
+		if _, err := parseOutputSchema(outputSchema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// samplePromptData 预览和校验模板时使用的示例数据
+func samplePromptData() *ReportPromptData {
+	day := time.Date(2024, 1, 15, 10, 30, 0, 0, time.Local)
+	start, end := day.AddDate(0, 0, -7), day
+	return &ReportPromptData{
+		ReportType: "sample",
+		ReportName: "示例报告",
+		Child: ReportPromptChild{
+			ID:        "1",
+			Name:      "小明",
+			Gender:    "男",
+			Age:       6,
+			Diagnosis: "孤独症谱系障碍",
+			Treatment: "语言训练、感觉统合训练",
+		},
+		StartDate: &start,
+		EndDate:   &end,
+		Logs: []ReportPromptLog{
+			{
+				Index:      1,
+				Time:       day.AddDate(0, 0, -3),
+				Content:    "今天在引导下主动说出了三个新词，情绪比较稳定。",
+				Answers:    []ReportPromptAnswer{{Label: "情绪状态", Value: "平稳"}},
+				MediaTypes: []string{"image"},
+				Metrics:    []ReportPromptLogMetric{{Name: "主动表达次数", Value: 3, Unit: "次"}},
+			},
+			{
+				Index:   2,
+				Time:    day,
+				Content: "在小组游戏中能够等待轮流，结束时有短暂哭闹。",
+				Metrics: []ReportPromptLogMetric{{Name: "主动表达次数", Value: 5, Unit: "次"}},
+			},
+		},
+		LogCount:   2,
+		FirstLogAt: day.AddDate(0, 0, -3),
+		LastLogAt:  day,
+		Metrics: []ReportPromptMetric{
+			{Name: "主动表达次数", Unit: "次", Count: 2, First: 3, Last: 5, Min: 3, Max: 5, Avg: 4},
+		},
+		RelatedContext: []ReportPromptContext{
+			{Kind: model.SemanticSourceLog, Time: day.AddDate(0, -2, 0), Content: "第一次在引导下说出新词，但很快失去兴趣。"},
+			{Kind: model.SemanticSourceGoal, Name: "主动表达需求", Time: day.AddDate(0, -3, 0), Content: "每天主动用语言表达需求5次以上"},
+		},
+		OutputSchema: defaultOutputSchema,
+	}
+}
+
+// buildReportPromptData 由疗愈日志和儿童档案构建提示词模板数据
+func buildReportPromptData(reportType *model.ReportType, tpl *model.PromptTemplate, archive *DAO.ChildArchive, logs []model.HealingLog, comparison *HealingComparison, startDate, endDate *time.Time) *ReportPromptData {
+	data := &ReportPromptData{
+		ReportType:   reportType.Key,
+		ReportName:   reportType.Name,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Comparison:   buildComparisonPrompt(comparison),
+		OutputSchema: tpl.OutputSchema,
+	}
+	if archive != nil {
+		data.Child = ReportPromptChild{
+			ID:        archive.ID,
+			Name:      archive.ChildName,
+			Gender:    archive.Gender,
+			Age:       ageInYears(archive.BirthDate, time.Now()),
+			Diagnosis: archive.Diagnosis,
+			Condition: archive.Condition,
+			Treatment: archive.Treatment,
+		}
+	}
+
+	// DAO 按时间倒序返回，提示词中按时间顺序排列
+	for i := len(logs) - 1; i >= 0; i-- {
+		log := logs[i]
+		entry := ReportPromptLog{
+			Index:   len(data.Logs) + 1,
+			Time:    log.CreatedAt,
+			Content: log.Content,
+		}
+		for _, answer := range log.TemplateAnswers {
+			entry.Answers = append(entry.Answers, ReportPromptAnswer{Label: answer.Label, Value: formatTemplateAnswerValue(answer)})
+		}
+		for _, media := range log.Media {
+			entry.MediaTypes = append(entry.MediaTypes, media.MediaType)
+		}
+		for _, metric := range log.Metrics {
+			entry.Metrics = append(entry.Metrics, ReportPromptLogMetric{Name: metric.Name, Value: metric.Value, Unit: metric.Unit})
+		}
+		data.Logs = append(data.Logs, entry)
+	}
+	data.Metrics = summarizeLogMetrics(data.Logs)
+	data.LogCount = len(data.Logs)
+	if len(data.Logs) > 0 {
+		data.FirstLogAt = data.Logs[0].Time
+		data.LastLogAt = data.Logs[len(data.Logs)-1].Time
+	}
+	return data
+}
+
+// ageInYears 计算周岁，出生日期未填写时返回0
+func ageInYears(birthDate, now time.Time) int {
+	if birthDate.IsZero() || birthDate.After(now) {
+		return 0
+	}
+	age := now.Year() - birthDate.Year()
+	if now.YearDay() < birthDate.YearDay() {
+		age--
+	}
+	return age
+}
+
+func formatPromptDate(t interface{}, layout string) string {
+	switch v := t.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(layout)
+	case *time.Time:
+		if v == nil || v.IsZero() {
+			return ""
+		}
+		return v.Format(layout)
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"melody_cure/DAO"
+	"melody_cure/config"
+	"melody_cure/model"
+	"strings"
+	"time"
+)
+
+// translationReportType 报告翻译请求的报告类型，用于按类型覆盖模型和模拟提供商识别
+const translationReportType = "translation"
+
+// translationSystemMessage 报告翻译的系统消息，%s 为目标语言
+const translationSystemMessage = "你是一位熟悉儿童康复和心理健康领域的专业医学翻译。请将用户提供的 Markdown 报告完整翻译为%s：保持原有的 Markdown 结构、标题层级、列表和加粗格式，专业术语使用该语言的规范译法，数字、日期和单位保持准确；方括号占位符（如 [儿童姓名]、[姓名1]）原样保留不要翻译；不要增删内容，不要添加说明，只输出译文。"
+
+// TranslateReport 将报告翻译为指定语言，译文作为原文的关联版本保存。已有基于原文当前版本的译文时直接返回，
+// 原文编辑后再次请求会重新翻译。对译文请求翻译时以其原文为准
+func (s *AIReportService) TranslateReport(ctx context.Context, userID string, reportID uint, language string) (*model.GeneratedReport, error) {
+	language, err := NormalizeReportLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.getAccessibleReport(userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if source.SourceReportID != nil {
+		if source, err = s.generatedReportDAO.GetGeneratedReportByID(*source.SourceReportID); err != nil {
+			return nil, notFound("原文报告不存在")
+		}
+	}
+	sourceLanguage := reportLanguageOrDefault(source.Language)
+	if language == sourceLanguage {
+		return nil, invalidRequest("报告已是该语言")
+	}
+	sourceVersion := source.CurrentVersion
+	if sourceVersion == 0 {
+		// 版本功能上线前生成且未编辑过的报告
+		sourceVersion = 1
+	}
+	if existing, err := s.generatedReportDAO.GetReportTranslation(source.ID, language); err == nil && existing.SourceVersion == sourceVersion {
+		return existing, nil
+	}
+
+	// 免责声明按目标语言重新追加，原文中的声明不参与翻译
+	content := stripDisclaimer(source.Content, sourceLanguage)
+	var redactor *piiRedactor
+	if config.GetAIConfig().RedactPII {
+		if archive, err := s.userDAO.GetChildArchiveByID(source.ChildArchiveID); err == nil {
+			var parent *DAO.User
+			if user, err := s.userDAO.GetUserByID(archive.UserID); err == nil {
+				parent = user
+			}
+			redactor = newReportRedactor(archive, parent)
+			content = redactor.Redact(content)
+		}
+	}
+
+	target, _ := findReportLanguage(language)
+	aiConfig := config.GetAIConfig()
+	ctx = WithUsageScope(ctx, userID, model.AIUsageSourceTranslate)
+	generated, err := s.llmProvider.Chat(ctx, &LLMRequest{
+		Model: modelForReportType(translationReportType),
+		Messages: []LLMMessage{
+			{Role: "system", Content: fmt.Sprintf(translationSystemMessage, fmt.Sprintf("%s（%s）", target.Name, target.NativeName))},
+			{Role: "user", Content: content},
+		},
+		MaxTokens:   aiConfig.MaxTokens,
+		Temperature: aiConfig.Temperature,
+		ReportType:  translationReportType,
+		Language:    language,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AI翻译失败: %w", err)
+	}
+
+	translated := strings.TrimRight(redactor.Restore(generated.Content), "\n") + "\n\n" +
+		fmt.Sprintf("> "+reportText(language, "translated_from"), languageDisplayName(sourceLanguage, language), source.ID, sourceVersion) + "\n"
+	sourceID := source.ID
+	report := &model.GeneratedReport{
+		ChildArchiveID:  source.ChildArchiveID,
+		ReportType:      source.ReportType,
+		Content:         s.safetyService.EnsureDisclaimer(translated, language),
+		Provider:        generated.Provider,
+		Model:           generated.Model,
+		TemplateID:      source.TemplateID,
+		TemplateVersion: source.TemplateVersion,
+		RedactionLog:    redactor.Log(),
+		SafetyFindings:  source.SafetyFindings,
+		Language:        language,
+		SourceReportID:  &sourceID,
+		SourceVersion:   sourceVersion,
+		GeneratedAt:     time.Now(),
+	}
+	if err := s.generatedReportDAO.CreateGeneratedReport(report); err != nil {
+		return nil, fmt.Errorf("保存译文失败: %v", err)
+	}
+	return report, nil
+}
+
+// ListReportTranslations 获取报告原文及其各语言最新的译文，原文排在第一位
+func (s *AIReportService) ListReportTranslations(userID string, reportID uint) ([]model.GeneratedReport, error) {
+	source, err := s.getAccessibleReport(userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if source.SourceReportID != nil {
+		if source, err = s.generatedReportDAO.GetGeneratedReportByID(*source.SourceReportID); err != nil {
+			return nil, notFound("原文报告不存在")
+		}
+	}
+	translations, err := s.generatedReportDAO.GetReportTranslations(source.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取译文失败: %v", err)
+	}
+	return append([]model.GeneratedReport{*source}, translations...), nil
+}
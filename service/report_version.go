@@ -0,0 +1,243 @@
+package service
+
+import (
+	"fmt"
+	"melody_cure/model"
+	"strings"
+	"time"
+)
+
+// 报告差异的行操作
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine 差异中的一行
+type DiffLine struct {
+	Op   string `json:"op"` // equal, insert, delete
+	Text string `json:"text"`
+}
+
+// ReportVersionDiff 两个报告版本之间的逐行差异
+type ReportVersionDiff struct {
+	ReportID uint       `json:"report_id"`
+	From     int        `json:"from"`
+	To       int        `json:"to"`
+	Added    int        `json:"added"`
+	Removed  int        `json:"removed"`
+	Lines    []DiffLine `json:"lines"`
+}
+
+// ListReports 获取儿童的所有报告，支持按生成日期和报告类型筛选
+func (s *AIReportService) ListReports(userID, childArchiveID, reportType string, startDate, endDate *time.Time) ([]model.GeneratedReport, error) {
+	if _, err := checkChildAccess(s.userDAO, userID, childArchiveID); err != nil {
+		return nil, err
+	}
+
+	reports, err := s.generatedReportDAO.GetGeneratedReportsByChildIDWithDateFilter(childArchiveID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("获取报告列表失败: %v", err)
+	}
+	if reportType == "" {
+		return reports, nil
+	}
+	filtered := make([]model.GeneratedReport, 0, len(reports))
+	for _, report := range reports {
+		if report.ReportType == reportType {
+			filtered = append(filtered, report)
+		}
+	}
+	return filtered, nil
+}
+
+// UpdateReportContent 以新版本保存编辑后的报告内容，保留AI原始内容和此前的所有版本。
+// 编辑后的 Markdown 无法对应回结构化字段，新版本不保存结构化内容，报告的结构化内容随之清空
+func (s *AIReportService) UpdateReportContent(userID string, reportID uint, content string) (*model.GeneratedReportVersion, error) {
+	report, err := s.getAccessibleReport(userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	content = s.safetyService.EnsureDisclaimer(content, report.Language)
+	if content == report.Content {
+		return nil, invalidRequest("报告内容未变化")
+	}
+
+	version := &model.GeneratedReportVersion{
+		ReportID: report.ID,
+		Content:  content,
+		Source:   model.ReportVersionEdit,
+		EditorID: userID,
+	}
+	if err := s.generatedReportDAO.AddReportVersion(version); err != nil {
+		return nil, fmt.Errorf("保存报告版本失败: %v", err)
+	}
+	return version, nil
+}
+
+// ListReportVersions 获取报告的版本历史
+func (s *AIReportService) ListReportVersions(userID string, reportID uint) ([]model.GeneratedReportVersion, error) {
+	report, err := s.getAccessibleReport(userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	return s.reportVersions(report)
+}
+
+// DiffReportVersions 比较报告的两个版本
+func (s *AIReportService) DiffReportVersions(userID string, reportID uint, from, to int) (*ReportVersionDiff, error) {
+	report, err := s.getAccessibleReport(userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	fromVersion, err := s.reportVersion(report, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.reportVersion(report, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &ReportVersionDiff{
+		ReportID: report.ID,
+		From:     from,
+		To:       to,
+		Lines:    diffLines(splitLines(fromVersion.Content), splitLines(toVersion.Content)),
+	}
+	for _, line := range diff.Lines {
+		switch line.Op {
+		case DiffInsert:
+			diff.Added++
+		case DiffDelete:
+			diff.Removed++
+		}
+	}
+	return diff, nil
+}
+
+// RestoreReportVersion 将历史版本的内容和结构化内容保存为新版本，不改写已有版本
+func (s *AIReportService) RestoreReportVersion(userID string, reportID uint, version int) (*model.GeneratedReportVersion, error) {
+	report, err := s.getAccessibleReport(userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if version == report.CurrentVersion {
+		return nil, invalidRequest("该版本已是当前版本")
+	}
+	source, err := s.reportVersion(report, version)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := &model.GeneratedReportVersion{
+		ReportID:          report.ID,
+		Content:           source.Content,
+		StructuredContent: source.StructuredContent,
+		Source:            model.ReportVersionRestore,
+		EditorID:          userID,
+		RestoredFrom:      &version,
+	}
+	if err := s.generatedReportDAO.AddReportVersion(restored); err != nil {
+		return nil, fmt.Errorf("恢复报告版本失败: %v", err)
+	}
+	return restored, nil
+}
+
+func (s *AIReportService) getAccessibleReport(userID string, reportID uint) (*model.GeneratedReport, error) {
+	report, err := s.generatedReportDAO.GetGeneratedReportByID(reportID)
+	if err != nil {
+		return nil, notFound("报告不存在")
+	}
+	if _, err := checkChildAccess(s.userDAO, userID, report.ChildArchiveID); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// reportVersions 获取报告版本，版本功能上线前生成且未编辑过的报告以当前内容作为第一个版本
+func (s *AIReportService) reportVersions(report *model.GeneratedReport) ([]model.GeneratedReportVersion, error) {
+	if report.CurrentVersion == 0 {
+		return []model.GeneratedReportVersion{legacyReportVersion(report)}, nil
+	}
+	versions, err := s.generatedReportDAO.GetReportVersions(report.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取报告版本失败: %v", err)
+	}
+	return versions, nil
+}
+
+func (s *AIReportService) reportVersion(report *model.GeneratedReport, version int) (*model.GeneratedReportVersion, error) {
+	if report.CurrentVersion == 0 && version == 1 {
+		legacy := legacyReportVersion(report)
+		return &legacy, nil
+	}
+	v, err := s.generatedReportDAO.GetReportVersion(report.ID, version)
+	if err != nil {
+		return nil, notFound("版本 %d 不存在", version)
+	}
+	return v, nil
+}
+
+func legacyReportVersion(report *model.GeneratedReport) model.GeneratedReportVersion {
+	return model.GeneratedReportVersion{
+		ReportID:          report.ID,
+		Version:           1,
+		Content:           report.Content,
+		StructuredContent: report.StructuredContent,
+		Source:            model.ReportVersionAI,
+		CreatedAt:         report.GeneratedAt,
+	}
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+}
+
+// diffLines 基于最长公共子序列的逐行差异
+func diffLines(a, b []string) []DiffLine {
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]DiffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, DiffLine{Op: DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, DiffLine{Op: DiffDelete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{Op: DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, DiffLine{Op: DiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, DiffLine{Op: DiffInsert, Text: b[j]})
+	}
+	return lines
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"melody_cure/DAO"
+	"melody_cure/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScheduledJobService 定时任务的具体业务逻辑
+type ScheduledJobService struct {
+	userDAO             *DAO.UserDAO
+	healingLogDAO       *DAO.HealingLogDAO
+	notificationDAO     *DAO.NotificationDAO
+	imageTokenDAO       *DAO.ImageTokenDAO
+	notificationService *NotificationService
+	reportJobService    *ReportJobService
+}
+
+func NewScheduledJobService(userDAO *DAO.UserDAO, healingLogDAO *DAO.HealingLogDAO, notificationDAO *DAO.NotificationDAO, imageTokenDAO *DAO.ImageTokenDAO, notificationService *NotificationService, reportJobService *ReportJobService) *ScheduledJobService {
+	return &ScheduledJobService{
+		userDAO:             userDAO,
+		healingLogDAO:       healingLogDAO,
+		notificationDAO:     notificationDAO,
+		imageTokenDAO:       imageTokenDAO,
+		notificationService: notificationService,
+		reportJobService:    reportJobService,
+	}
+}
+
+// SendLoggingReminders 提醒当天还没有为治疗中的孩子记录日志的家长，每位家长一条通知
+func (s *ScheduledJobService) SendLoggingReminders(ctx context.Context) error {
+	archives, err := s.userDAO.GetChildArchivesInTreatment()
+	if err != nil {
+		return fmt.Errorf("获取治疗中的儿童档案失败: %v", err)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	pending := make(map[string][]string)
+	var parents []string
+	for _, archive := range archives {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		childID, err := strconv.ParseUint(archive.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		logTimes, err := s.healingLogDAO.GetLogTimesByChildID(uint(childID), &today)
+		if err != nil {
+			return fmt.Errorf("获取儿童 %s 的日志记录失败: %v", archive.ID, err)
+		}
+		if len(logTimes) > 0 {
+			continue
+		}
+		if _, ok := pending[archive.UserID]; !ok {
+			parents = append(parents, archive.UserID)
+		}
+		pending[archive.UserID] = append(pending[archive.UserID], archive.ChildName)
+	}
+
+	for _, parentID := range parents {
+		content := fmt.Sprintf("今天还没有为 %s 记录疗愈日志，花一分钟记录一下今天的表现吧。", strings.Join(pending[parentID], "、"))
+		if err := s.notificationService.Notify(parentID, model.NotificationLoggingReminder, "今日疗愈记录提醒", content, "", ""); err != nil {
+			log.Printf("发送记录提醒失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// GenerateWeeklyReports 为过去一周有日志记录的儿童创建总结报告生成任务
+func (s *ScheduledJobService) GenerateWeeklyReports(ctx context.Context) error {
+	archives, err := s.userDAO.GetChildArchivesInTreatment()
+	if err != nil {
+		return fmt.Errorf("获取治疗中的儿童档案失败: %v", err)
+	}
+
+	end := time.Now()
+	start := end.AddDate(0, 0, -7)
+	for _, archive := range archives {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		childID, err := strconv.ParseUint(archive.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		logTimes, err := s.healingLogDAO.GetLogTimesByChildID(uint(childID), &start)
+		if err != nil {
+			return fmt.Errorf("获取儿童 %s 的日志记录失败: %v", archive.ID, err)
+		}
+		if len(logTimes) == 0 {
+			continue
+		}
+
+		// 报告由后台任务生成，完成后由任务通知家长
+		job := &model.ReportJob{
+			UserID:         archive.UserID,
+			ChildArchiveID: archive.ID,
+			ReportType:     "summary",
+			StartDate:      &start,
+			EndDate:        &end,
+		}
+		if err := s.reportJobService.EnqueueJob(ctx, job); err != nil {
+			log.Printf("创建儿童 %s 的周报任务失败: %v", archive.ID, err)
+		}
+	}
+	return nil
+}
+
+// CheckCertificationExpiry 将已过期的认证标记为过期并取消认证状态，即将到期的认证提前提醒一次
+func (s *ScheduledJobService) CheckCertificationExpiry(ctx context.Context, warnDays int) error {
+	now := time.Now()
+	certs, err := s.userDAO.GetApprovedCertificationsExpiringBefore(now.AddDate(0, 0, warnDays))
+	if err != nil {
+		return fmt.Errorf("获取即将到期的认证失败: %v", err)
+	}
+
+	for _, cert := range certs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if cert.ExpiryDate.Before(now) {
+			revoked, err := s.userDAO.ExpireCertification(&cert)
+			if err != nil {
+				return fmt.Errorf("更新认证 %s 状态失败: %v", cert.ID, err)
+			}
+			content := fmt.Sprintf("您的认证「%s」已于 %s 到期，请及时重新提交认证材料。", cert.CertificateName, cert.ExpiryDate.Format("2006-01-02"))
+			if revoked {
+				content = fmt.Sprintf("您的认证「%s」已于 %s 到期，认证状态已失效，请及时重新提交认证材料。", cert.CertificateName, cert.ExpiryDate.Format("2006-01-02"))
+			}
+			if err := s.notificationService.Notify(cert.UserID, model.NotificationCertification, "认证已过期", content, "certification", cert.ID); err != nil {
+				log.Printf("发送认证过期通知失败: %v", err)
+			}
+			continue
+		}
+
+		notified, err := s.notificationDAO.HasNotificationSince(cert.UserID, model.NotificationCertification, cert.ID, now.AddDate(0, 0, -warnDays))
+		if err != nil {
+			return fmt.Errorf("查询认证提醒记录失败: %v", err)
+		}
+		if notified {
+			continue
+		}
+		content := fmt.Sprintf("您的认证「%s」将于 %s 到期，请提前准备续期材料。", cert.CertificateName, cert.ExpiryDate.Format("2006-01-02"))
+		if err := s.notificationService.Notify(cert.UserID, model.NotificationCertification, "认证即将到期", content, "certification", cert.ID); err != nil {
+			log.Printf("发送认证到期提醒失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// CleanupExpiredImageTokens 清理已过期的图床token
+func (s *ScheduledJobService) CleanupExpiredImageTokens(ctx context.Context) error {
+	deleted, err := s.imageTokenDAO.DeleteExpiredImageTokens(time.Now())
+	if err != nil {
+		return fmt.Errorf("清理过期图床token失败: %v", err)
+	}
+	if deleted > 0 {
+		log.Printf("已清理 %d 个过期图床token", deleted)
+	}
+	return nil
+}
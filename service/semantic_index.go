@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/config"
+	"melody_cure/model"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// semanticSimilarMaxK 查找相似记录时最多返回的条数
+const semanticSimilarMaxK = 50
+
+// semanticSourceTypes 语义索引包含的全部资料类型
+var semanticSourceTypes = []string{model.SemanticSourceLog, model.SemanticSourceReport, model.SemanticSourceGoal}
+
+// SemanticMatch 语义检索命中的一条资料，同一资料只返回最相关的一段
+type SemanticMatch struct {
+	SourceType string    `json:"source_type"` // log, report, goal
+	SourceID   uint      `json:"source_id"`
+	Title      string    `json:"title"`
+	Time       time.Time `json:"time"`
+	Excerpt    string    `json:"excerpt"`
+	Score      float64   `json:"score"` // 余弦相似度
+	Content    string    `json:"-"`     // 命中的分段全文，用于构建提示词
+	Name       string    `json:"-"`     // 报告类型名称或目标标题
+}
+
+// SemanticSyncResult 同步索引的结果
+type SemanticSyncResult struct {
+	Indexed   int `json:"indexed"`   // 新增或内容变化后重新索引的资料数
+	Removed   int `json:"removed"`   // 资料已删除而移除的索引数
+	Unchanged int `json:"unchanged"` // 内容未变化的资料数
+}
+
+// SemanticSearchOptions 语义检索的可选条件
+type SemanticSearchOptions struct {
+	K             int
+	SourceTypes   []string      // 为空时检索全部类型
+	ExcludeLogIDs map[uint]bool // 不返回的疗愈记录
+}
+
+// semanticSource 待索引的一条资料
+type semanticSource struct {
+	sourceType string
+	id         uint
+	title      string
+	name       string
+	time       time.Time
+	text       string
+}
+
+// SemanticIndexService 按儿童建立疗愈记录、报告和治疗目标的语义索引，向量保存在数据库中，检索时在内存中计算相似度。
+// 疗愈记录在创建、编辑和删除时增量更新；报告和目标在检索前按内容哈希同步，只重新向量化变化的资料
+type SemanticIndexService struct {
+	indexDAO           *DAO.SemanticIndexDAO
+	healingLogDAO      *DAO.HealingLogDAO
+	generatedReportDAO *DAO.GeneratedReportDAO
+	reportTypeDAO      *DAO.ReportTypeDAO
+	planDAO            *DAO.TreatmentPlanDAO
+	userDAO            *DAO.UserDAO
+	provider           EmbeddingProvider
+}
+
+func NewSemanticIndexService(indexDAO *DAO.SemanticIndexDAO, healingLogDAO *DAO.HealingLogDAO, generatedReportDAO *DAO.GeneratedReportDAO, reportTypeDAO *DAO.ReportTypeDAO, planDAO *DAO.TreatmentPlanDAO, userDAO *DAO.UserDAO, provider EmbeddingProvider) *SemanticIndexService {
+	return &SemanticIndexService{
+		indexDAO:           indexDAO,
+		healingLogDAO:      healingLogDAO,
+		generatedReportDAO: generatedReportDAO,
+		reportTypeDAO:      reportTypeDAO,
+		planDAO:            planDAO,
+		userDAO:            userDAO,
+		provider:           provider,
+	}
+}
+
+// IndexHealingLog 索引新建或编辑后的疗愈记录
+func (s *SemanticIndexService) IndexHealingLog(ctx context.Context, healingLog *model.HealingLog) error {
+	childArchiveID := strconv.FormatUint(uint64(healingLog.ChildArchiveID), 10)
+	return s.indexSource(ctx, childArchiveID, logSemanticSource(healingLog))
+}
+
+// RemoveHealingLog 移除已删除疗愈记录的索引
+func (s *SemanticIndexService) RemoveHealingLog(logID uint) error {
+	return s.indexDAO.DeleteSource(model.SemanticSourceLog, logID)
+}
+
+// Rebuild 同步儿童全部资料的索引，用于补建功能上线前的记录或更换向量化提供商后重建
+func (s *SemanticIndexService) Rebuild(ctx context.Context, userID, childArchiveID string) (*SemanticSyncResult, error) {
+	if _, err := checkChildAccess(s.userDAO, userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	return s.sync(ctx, childArchiveID, semanticSourceTypes...)
+}
+
+// FindSimilar 查找与一段描述或一条疗愈记录相似的过往资料，按相似度降序
+func (s *SemanticIndexService) FindSimilar(ctx context.Context, userID, childArchiveID string, req *request.SimilarEpisodesRequest) ([]SemanticMatch, error) {
+	if _, err := checkChildAccess(s.userDAO, userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	opts := &SemanticSearchOptions{K: req.K}
+	if opts.K <= 0 {
+		opts.K = config.GetSemanticConfig().SimilarK
+	}
+	opts.K = min(opts.K, semanticSimilarMaxK)
+	for _, sourceType := range strings.Split(req.Types, ",") {
+		switch sourceType = strings.TrimSpace(sourceType); sourceType {
+		case "":
+		case model.SemanticSourceLog, model.SemanticSourceReport, model.SemanticSourceGoal:
+			opts.SourceTypes = append(opts.SourceTypes, sourceType)
+		default:
+			return nil, invalidRequest("不支持的资料类型: %s", sourceType)
+		}
+	}
+
+	query := strings.TrimSpace(req.Query)
+	if req.LogID > 0 {
+		healingLog, err := s.healingLogDAO.GetHealingLogByID(req.LogID)
+		if err != nil || strconv.FormatUint(uint64(healingLog.ChildArchiveID), 10) != childArchiveID {
+			return nil, ErrHealingLogNotFound
+		}
+		query = strings.TrimSpace(query + "\n" + logSemanticSource(healingLog).text)
+		opts.ExcludeLogIDs = map[uint]bool{healingLog.ID: true}
+	}
+	if query == "" {
+		return nil, invalidRequest("请提供查询内容或疗愈记录ID")
+	}
+	return s.Search(ctx, childArchiveID, query, opts)
+}
+
+// Search 在儿童的语义索引中检索与查询最相似的资料，同一资料只取最相似的一段，低于 minScore 的结果不返回。
+// 每类资料只在最近的 maxCandidates 段中检索。不校验权限，调用方负责
+func (s *SemanticIndexService) Search(ctx context.Context, childArchiveID, query string, opts *SemanticSearchOptions) ([]SemanticMatch, error) {
+	if opts.K <= 0 {
+		return nil, nil
+	}
+	if err := s.refresh(ctx, childArchiveID); err != nil {
+		return nil, err
+	}
+	vectors, err := s.provider.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("向量化查询失败: %w", err)
+	}
+	cfg := config.GetSemanticConfig()
+	sourceTypes := opts.SourceTypes
+	if len(sourceTypes) == 0 {
+		sourceTypes = semanticSourceTypes
+	}
+	// 每类资料只取最近的 maxCandidates 段参与计算，避免记录很多的儿童检索变慢
+	var chunks []model.SemanticChunk
+	for _, sourceType := range sourceTypes {
+		typeChunks, err := s.indexDAO.GetChunks(childArchiveID, sourceType, cfg.MaxCandidates)
+		if err != nil {
+			return nil, fmt.Errorf("读取语义索引失败: %v", err)
+		}
+		chunks = append(chunks, typeChunks...)
+	}
+
+	type sourceKey struct {
+		sourceType string
+		id         uint
+	}
+	best := make(map[sourceKey]*SemanticMatch)
+	for _, chunk := range chunks {
+		if chunk.SourceType == model.SemanticSourceLog && opts.ExcludeLogIDs[chunk.SourceID] {
+			continue
+		}
+		// 不同提供商或维度的向量不可比较，等待同步后重建
+		if chunk.Provider != s.provider.Name() || len(chunk.Vector) != len(vectors[0]) {
+			continue
+		}
+		score := dotProduct(vectors[0], chunk.Vector)
+		if score < cfg.MinScore {
+			continue
+		}
+		key := sourceKey{chunk.SourceType, chunk.SourceID}
+		if current, ok := best[key]; ok && current.Score >= score {
+			continue
+		}
+		best[key] = &SemanticMatch{
+			SourceType: chunk.SourceType,
+			SourceID:   chunk.SourceID,
+			Title:      chunk.Title,
+			Time:       chunk.SourceTime,
+			Excerpt:    truncateRunes(strings.Join(strings.Fields(chunk.Content), " "), consultationExcerptRunes),
+			Score:      score,
+			Content:    chunk.Content,
+			Name:       chunk.Name,
+		}
+	}
+
+	matches := make([]SemanticMatch, 0, len(best))
+	for _, match := range best {
+		matches = append(matches, *match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Time.After(matches[j].Time)
+	})
+	if len(matches) > opts.K {
+		matches = matches[:opts.K]
+	}
+	return matches, nil
+}
+
+// refresh 检索前同步报告和目标的索引；已索引的疗愈记录数与实际不一致（如功能上线前的记录）或向量化提供商已更换时同时同步疗愈记录
+func (s *SemanticIndexService) refresh(ctx context.Context, childArchiveID string) error {
+	sourceTypes := []string{model.SemanticSourceReport, model.SemanticSourceGoal}
+	childID, err := strconv.ParseUint(childArchiveID, 10, 64)
+	if err != nil {
+		return invalidRequest("儿童档案ID格式错误: %v", err)
+	}
+	logTimes, err := s.healingLogDAO.GetLogTimesByChildID(uint(childID), nil)
+	if err != nil {
+		return fmt.Errorf("获取疗愈记录失败: %v", err)
+	}
+	indexed, err := s.indexDAO.GetSourceHashes(childArchiveID, model.SemanticSourceLog)
+	if err != nil {
+		return fmt.Errorf("读取语义索引失败: %v", err)
+	}
+	stale := len(indexed) != len(logTimes)
+	for _, hash := range indexed {
+		stale = stale || hash.Provider != s.provider.Name()
+	}
+	if stale {
+		sourceTypes = append(sourceTypes, model.SemanticSourceLog)
+	}
+	_, err = s.sync(ctx, childArchiveID, sourceTypes...)
+	return err
+}
+
+// sync 按内容哈希同步儿童指定类型资料的索引
+func (s *SemanticIndexService) sync(ctx context.Context, childArchiveID string, sourceTypes ...string) (*SemanticSyncResult, error) {
+	result := &SemanticSyncResult{}
+	for _, sourceType := range sourceTypes {
+		sources, err := s.loadSources(childArchiveID, sourceType)
+		if err != nil {
+			return nil, err
+		}
+		hashes, err := s.indexDAO.GetSourceHashes(childArchiveID, sourceType)
+		if err != nil {
+			return nil, fmt.Errorf("读取语义索引失败: %v", err)
+		}
+		indexed := make(map[uint]string, len(hashes))
+		for _, hash := range hashes {
+			indexed[hash.SourceID] = hash.ContentHash
+		}
+
+		for _, source := range sources {
+			hash, ok := indexed[source.id]
+			delete(indexed, source.id)
+			if ok && hash == s.contentHash(source) {
+				result.Unchanged++
+				continue
+			}
+			if err := s.indexSource(ctx, childArchiveID, source); err != nil {
+				return nil, err
+			}
+			result.Indexed++
+		}
+		for sourceID := range indexed {
+			if err := s.indexDAO.DeleteSource(sourceType, sourceID); err != nil {
+				return nil, fmt.Errorf("移除语义索引失败: %v", err)
+			}
+			result.Removed++
+		}
+	}
+	return result, nil
+}
+
+// loadSources 读取儿童指定类型的全部资料，报告不含译文
+func (s *SemanticIndexService) loadSources(childArchiveID, sourceType string) ([]semanticSource, error) {
+	var sources []semanticSource
+	switch sourceType {
+	case model.SemanticSourceLog:
+		childID, err := strconv.ParseUint(childArchiveID, 10, 64)
+		if err != nil {
+			return nil, invalidRequest("儿童档案ID格式错误: %v", err)
+		}
+		logs, err := s.healingLogDAO.GetHealingLogsByChildID(uint(childID))
+		if err != nil {
+			return nil, fmt.Errorf("获取疗愈记录失败: %v", err)
+		}
+		for i := range logs {
+			sources = append(sources, logSemanticSource(&logs[i]))
+		}
+	case model.SemanticSourceReport:
+		reports, err := s.generatedReportDAO.GetGeneratedReportsByChildID(childArchiveID)
+		if err != nil {
+			return nil, fmt.Errorf("获取报告失败: %v", err)
+		}
+		names := make(map[string]string)
+		for _, report := range reports {
+			if report.SourceReportID != nil {
+				continue
+			}
+			name, ok := names[report.ReportType]
+			if !ok {
+				name = report.ReportType
+				if reportType, err := s.reportTypeDAO.GetReportTypeByKey(report.ReportType); err == nil {
+					name = reportType.Name
+				}
+				names[report.ReportType] = name
+			}
+			sources = append(sources, semanticSource{
+				sourceType: model.SemanticSourceReport,
+				id:         report.ID,
+				title:      fmt.Sprintf("%s %s", name, report.GeneratedAt.Format("2006-01-02")),
+				name:       name,
+				time:       report.GeneratedAt,
+				text:       report.Content,
+			})
+		}
+	case model.SemanticSourceGoal:
+		goals, err := s.planDAO.GetGoalsByChildID(childArchiveID)
+		if err != nil {
+			return nil, fmt.Errorf("获取治疗目标失败: %v", err)
+		}
+		for i := range goals {
+			sources = append(sources, goalSemanticSource(&goals[i]))
+		}
+	}
+	return sources, nil
+}
+
+// indexSource 将资料按段落切分、向量化后替换已有的索引
+func (s *SemanticIndexService) indexSource(ctx context.Context, childArchiveID string, source semanticSource) error {
+	chunkRunes := config.GetSemanticConfig().ChunkRunes
+	if chunkRunes <= 0 {
+		chunkRunes = consultationChunkRunes
+	}
+	contents := splitTextChunks(source.text, chunkRunes)
+	if len(contents) == 0 {
+		// 没有正文的资料也保留一段，按标题检索，同时使索引数与资料数一致
+		contents = []string{""}
+	}
+	texts := make([]string, len(contents))
+	for i, content := range contents {
+		texts[i] = source.title + "\n" + content
+	}
+	vectors, err := s.provider.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("向量化失败: %w", err)
+	}
+
+	hash := s.contentHash(source)
+	chunks := make([]model.SemanticChunk, len(contents))
+	for i, content := range contents {
+		chunks[i] = model.SemanticChunk{
+			ChildArchiveID: childArchiveID,
+			SourceType:     source.sourceType,
+			SourceID:       source.id,
+			ChunkIndex:     i,
+			Title:          source.title,
+			Name:           source.name,
+			Content:        content,
+			ContentHash:    hash,
+			Provider:       s.provider.Name(),
+			Vector:         vectors[i],
+			SourceTime:     source.time,
+		}
+	}
+	if err := s.indexDAO.ReplaceSource(source.sourceType, source.id, chunks); err != nil {
+		return fmt.Errorf("保存语义索引失败: %v", err)
+	}
+	return nil
+}
+
+// contentHash 资料的索引版本：向量化提供商、维度或资料内容变化时需要重新索引
+func (s *SemanticIndexService) contentHash(source semanticSource) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%d\x00%s\x00%s",
+		s.provider.Name(), s.provider.Dimensions(), config.GetSemanticConfig().ChunkRunes, source.title, source.text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// logSemanticSource 疗愈记录的正文、模板答案和指标
+func logSemanticSource(healingLog *model.HealingLog) semanticSource {
+	document := healingLogDocument(healingLog)
+	return semanticSource{
+		sourceType: model.SemanticSourceLog,
+		id:         healingLog.ID,
+		title:      document.source.Title,
+		time:       healingLog.CreatedAt,
+		text:       document.text,
+	}
+}
+
+// goalSemanticSource 治疗目标的领域、描述、达成标准和状态
+func goalSemanticSource(goal *model.TreatmentGoal) semanticSource {
+	parts := []string{"治疗目标：" + goal.Title}
+	if name, ok := goalDomainNames[goal.Domain]; ok {
+		parts = append(parts, "领域："+name)
+	}
+	if goal.Description != "" {
+		parts = append(parts, "描述："+goal.Description)
+	}
+	if goal.TargetCriteria != "" {
+		parts = append(parts, "达成标准："+goal.TargetCriteria)
+	}
+	if name, ok := goalStatusNames[goal.Status]; ok {
+		parts = append(parts, "状态："+name)
+	}
+	return semanticSource{
+		sourceType: model.SemanticSourceGoal,
+		id:         goal.ID,
+		title:      "治疗目标 " + goal.Title,
+		name:       goal.Title,
+		time:       goal.CreatedAt,
+		text:       strings.Join(parts, "\n"),
+	}
+}
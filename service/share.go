@@ -0,0 +1,387 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/config"
+	"melody_cure/model"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrShareNotFound     = errors.New("分享链接不存在")
+	ErrShareExpired      = errors.New("分享链接已过期或已被撤销")
+	ErrShareCodeRequired = errors.New("请输入访问码")
+	ErrShareCodeInvalid  = errors.New("访问码错误")
+	ErrShareLocked       = errors.New("访问码错误次数过多，请稍后再试")
+)
+
+// 分享链接状态
+const (
+	ShareStatusActive  = "active"
+	ShareStatusExpired = "expired"
+	ShareStatusRevoked = "revoked"
+)
+
+const (
+	shareCodeWindow      = 15 * time.Minute // 统计访问码错误次数的时间窗口
+	maxShareAccessLogs   = 200
+	minShareCodeLength   = 4
+	maxShareCodeLength   = 12
+	sharePublicIDBytes   = 16
+	shareSignatureLength = 22 // 签名截取的 base64 字符数（132 位）
+)
+
+type ShareService struct {
+	shareLinkDAO       *DAO.ShareLinkDAO
+	generatedReportDAO *DAO.GeneratedReportDAO
+	userDAO            *DAO.UserDAO
+	aiReportService    *AIReportService
+	exportService      *ExportService
+}
+
+func NewShareService(shareLinkDAO *DAO.ShareLinkDAO, generatedReportDAO *DAO.GeneratedReportDAO, userDAO *DAO.UserDAO, aiReportService *AIReportService, exportService *ExportService) *ShareService {
+	return &ShareService{
+		shareLinkDAO:       shareLinkDAO,
+		generatedReportDAO: generatedReportDAO,
+		userDAO:            userDAO,
+		aiReportService:    aiReportService,
+		exportService:      exportService,
+	}
+}
+
+// ShareLinkView 分享链接及其访问地址，令牌由链接ID签名得到，可以重复获取
+type ShareLinkView struct {
+	model.ShareLink
+	Token  string `json:"token"`
+	URL    string `json:"url,omitempty"`
+	Status string `json:"status"` // active, expired, revoked
+}
+
+// SharedDocument 通过分享链接查看的只读文档
+type SharedDocument struct {
+	ResourceType string          `json:"resource_type"`
+	Title        string          `json:"title"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+	Content      string          `json:"content,omitempty"` // 报告的 Markdown 内容
+	Document     *exportDocument `json:"document"`          // 与导出文件相同的文档结构
+}
+
+// ShareAccess 访问分享链接的请求信息
+type ShareAccess struct {
+	Code      string
+	IP        string
+	UserAgent string
+}
+
+// CreateShareLink 为报告或儿童康复档案创建分享链接。报告分享当前版本，之后的编辑不影响已分享的内容；
+// 档案在创建时生成快照
+func (s *ShareService) CreateShareLink(userID string, req *request.CreateShareLinkRequest, options DossierOptions) (*ShareLinkView, error) {
+	cfg := config.GetShareConfig()
+	expiresIn := req.ExpiresInHours
+	if expiresIn <= 0 {
+		expiresIn = cfg.DefaultExpireHours
+	}
+	if expiresIn > cfg.MaxExpireDays*24 {
+		return nil, invalidRequest("分享链接有效期最长为 %d 天", cfg.MaxExpireDays)
+	}
+
+	link := &model.ShareLink{
+		CreatedBy:    userID,
+		ResourceType: req.ResourceType,
+		Recipient:    strings.TrimSpace(req.Recipient),
+		ExpiresAt:    time.Now().Add(time.Duration(expiresIn) * time.Hour),
+	}
+	switch req.ResourceType {
+	case model.ShareResourceReport:
+		report, err := s.aiReportService.getAccessibleReport(userID, req.ReportID)
+		if err != nil {
+			return nil, err
+		}
+		version := report.CurrentVersion
+		if version == 0 {
+			// 版本功能上线前的报告以当前内容作为第 1 版
+			version = 1
+		}
+		_, name, err := s.exportService.reportDocument(report, report.Content, version)
+		if err != nil {
+			return nil, err
+		}
+		link.ChildArchiveID, link.ReportID, link.ReportVersion, link.Title = report.ChildArchiveID, report.ID, version, name
+	case model.ShareResourceDossier:
+		doc, name, err := s.exportService.dossierDocument(userID, req.ChildArchiveID, options, s.exportService.branding(userID).Color)
+		if err != nil {
+			return nil, err
+		}
+		snapshot, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("生成档案快照失败: %v", err)
+		}
+		link.ChildArchiveID, link.Snapshot, link.Title = req.ChildArchiveID, snapshot, name
+	default:
+		return nil, invalidRequest("分享类型仅支持 report 或 dossier")
+	}
+
+	if req.AccessCode != "" {
+		if n := len([]rune(req.AccessCode)); n < minShareCodeLength || n > maxShareCodeLength {
+			return nil, invalidRequest("访问码长度应为 %d-%d 位", minShareCodeLength, maxShareCodeLength)
+		}
+		hashed, err := bcrypt.GenerateFromPassword([]byte(req.AccessCode), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("设置访问码失败: %v", err)
+		}
+		link.AccessCodeHash = string(hashed)
+	}
+
+	publicID := make([]byte, sharePublicIDBytes)
+	if _, err := rand.Read(publicID); err != nil {
+		return nil, fmt.Errorf("生成分享链接失败: %v", err)
+	}
+	link.PublicID = base64.RawURLEncoding.EncodeToString(publicID)
+	if err := s.shareLinkDAO.CreateShareLink(link); err != nil {
+		return nil, fmt.Errorf("创建分享链接失败: %v", err)
+	}
+	return newShareLinkView(link, time.Now()), nil
+}
+
+// GetShareLinks 获取用户创建的分享链接，默认只返回有效的链接
+func (s *ShareService) GetShareLinks(userID, childArchiveID string, includeInactive bool) ([]ShareLinkView, error) {
+	now := time.Now()
+	activeAt := &now
+	if includeInactive {
+		activeAt = nil
+	}
+	links, err := s.shareLinkDAO.GetShareLinksByCreator(userID, childArchiveID, activeAt)
+	if err != nil {
+		return nil, fmt.Errorf("获取分享链接失败: %v", err)
+	}
+	views := make([]ShareLinkView, 0, len(links))
+	for i := range links {
+		views = append(views, *newShareLinkView(&links[i], now))
+	}
+	return views, nil
+}
+
+// RevokeShareLink 撤销分享链接，撤销后立即失效
+func (s *ShareService) RevokeShareLink(userID string, id uint) (*ShareLinkView, error) {
+	link, err := s.getOwnShareLink(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if link.RevokedAt == nil {
+		now := time.Now()
+		if err := s.shareLinkDAO.RevokeShareLink(link.ID, now); err != nil {
+			return nil, fmt.Errorf("撤销分享链接失败: %v", err)
+		}
+		link.RevokedAt = &now
+	}
+	return newShareLinkView(link, time.Now()), nil
+}
+
+// GetShareAccessLogs 获取分享链接最近的访问记录
+func (s *ShareService) GetShareAccessLogs(userID string, id uint) ([]model.ShareAccessLog, error) {
+	link, err := s.getOwnShareLink(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := s.shareLinkDAO.GetShareAccessLogs(link.ID, maxShareAccessLogs)
+	if err != nil {
+		return nil, fmt.Errorf("获取访问记录失败: %v", err)
+	}
+	return logs, nil
+}
+
+// ViewSharedDocument 通过分享链接在线查看文档
+func (s *ShareService) ViewSharedDocument(token string, access ShareAccess) (*SharedDocument, error) {
+	link, err := s.openShareLink(token, access)
+	if err != nil {
+		return nil, err
+	}
+	doc, content, err := s.sharedDocument(link)
+	if err != nil {
+		return nil, err
+	}
+	s.recordAccess(link, model.ShareAccessViewed, "", access)
+	return &SharedDocument{
+		ResourceType: link.ResourceType,
+		Title:        link.Title,
+		ExpiresAt:    link.ExpiresAt,
+		Content:      content,
+		Document:     doc,
+	}, nil
+}
+
+// DownloadSharedDocument 通过分享链接下载 PDF 或 DOCX，信头使用分享人所属机构的设置
+func (s *ShareService) DownloadSharedDocument(token, format string, access ShareAccess) (*ExportFile, error) {
+	if err := checkExportFormat(format); err != nil {
+		return nil, err
+	}
+	link, err := s.openShareLink(token, access)
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := s.sharedDocument(link)
+	if err != nil {
+		return nil, err
+	}
+	file, err := s.exportService.renderWithBranding(doc, format, link.Title, s.exportService.branding(link.CreatedBy))
+	if err != nil {
+		return nil, err
+	}
+	s.recordAccess(link, model.ShareAccessDownload, format, access)
+	return file, nil
+}
+
+// openShareLink 校验令牌签名、有效期和访问码，失败的访问同样记录到访问日志
+func (s *ShareService) openShareLink(token string, access ShareAccess) (*model.ShareLink, error) {
+	publicID, ok := verifyShareToken(token)
+	if !ok {
+		return nil, ErrShareNotFound
+	}
+	link, err := s.shareLinkDAO.GetShareLinkByPublicID(publicID)
+	if err != nil {
+		return nil, ErrShareNotFound
+	}
+
+	now := time.Now()
+	switch shareStatus(link, now) {
+	case ShareStatusRevoked:
+		s.logAccess(link, model.ShareAccessRevoked, "", access)
+		return nil, ErrShareExpired
+	case ShareStatusExpired:
+		s.logAccess(link, model.ShareAccessExpired, "", access)
+		return nil, ErrShareExpired
+	}
+
+	if link.AccessCodeHash == "" {
+		return link, nil
+	}
+	failures, err := s.shareLinkDAO.CountShareAccess(link.ID, model.ShareAccessBadCode, now.Add(-shareCodeWindow))
+	if err != nil {
+		return nil, fmt.Errorf("校验访问码失败: %v", err)
+	}
+	if maxAttempts := config.GetShareConfig().MaxCodeAttempts; maxAttempts > 0 && failures >= int64(maxAttempts) {
+		return nil, ErrShareLocked
+	}
+	if access.Code == "" {
+		s.logAccess(link, model.ShareAccessNeedsCode, "", access)
+		return nil, ErrShareCodeRequired
+	}
+	if bcrypt.CompareHashAndPassword([]byte(link.AccessCodeHash), []byte(access.Code)) != nil {
+		s.logAccess(link, model.ShareAccessBadCode, "", access)
+		return nil, ErrShareCodeInvalid
+	}
+	return link, nil
+}
+
+// sharedDocument 分享的文档内容：报告取分享时的版本，档案取快照
+func (s *ShareService) sharedDocument(link *model.ShareLink) (*exportDocument, string, error) {
+	if link.ResourceType == model.ShareResourceDossier {
+		var doc exportDocument
+		if err := json.Unmarshal(link.Snapshot, &doc); err != nil {
+			return nil, "", fmt.Errorf("读取档案快照失败: %v", err)
+		}
+		return &doc, "", nil
+	}
+
+	report, err := s.generatedReportDAO.GetGeneratedReportByID(link.ReportID)
+	if err != nil {
+		// 报告已被删除
+		return nil, "", ErrShareNotFound
+	}
+	version, err := s.aiReportService.reportVersion(report, link.ReportVersion)
+	if err != nil {
+		return nil, "", ErrShareNotFound
+	}
+	doc, _, err := s.exportService.reportDocument(report, version.Content, link.ReportVersion)
+	if err != nil {
+		return nil, "", err
+	}
+	return doc, version.Content, nil
+}
+
+func (s *ShareService) recordAccess(link *model.ShareLink, outcome, format string, access ShareAccess) {
+	if err := s.shareLinkDAO.RecordShareView(link.ID, time.Now()); err != nil {
+		log.Printf("更新分享链接 %d 查看次数失败: %v", link.ID, err)
+	}
+	s.logAccess(link, outcome, format, access)
+}
+
+func (s *ShareService) logAccess(link *model.ShareLink, outcome, format string, access ShareAccess) {
+	userAgent := access.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	entry := &model.ShareAccessLog{ShareLinkID: link.ID, Outcome: outcome, Format: format, IP: access.IP, UserAgent: userAgent}
+	if err := s.shareLinkDAO.CreateShareAccessLog(entry); err != nil {
+		log.Printf("记录分享链接 %d 访问日志失败: %v", link.ID, err)
+	}
+}
+
+// getOwnShareLink 获取分享链接，只有创建人和管理员可以管理
+func (s *ShareService) getOwnShareLink(userID string, id uint) (*model.ShareLink, error) {
+	link, err := s.shareLinkDAO.GetShareLinkByID(id)
+	if err != nil {
+		return nil, ErrShareNotFound
+	}
+	if link.CreatedBy != userID {
+		user, err := s.userDAO.GetUserByID(userID)
+		if err != nil || !isAdmin(user) {
+			return nil, ErrPermissionDenied
+		}
+	}
+	return link, nil
+}
+
+func newShareLinkView(link *model.ShareLink, now time.Time) *ShareLinkView {
+	link.HasAccessCode = link.AccessCodeHash != ""
+	view := &ShareLinkView{ShareLink: *link, Token: signShareToken(link.PublicID), Status: shareStatus(link, now)}
+	if baseURL := config.GetShareConfig().BaseURL; baseURL != "" {
+		view.URL = baseURL + view.Token
+	}
+	return view
+}
+
+func shareStatus(link *model.ShareLink, now time.Time) string {
+	switch {
+	case link.RevokedAt != nil:
+		return ShareStatusRevoked
+	case !now.Before(link.ExpiresAt):
+		return ShareStatusExpired
+	}
+	return ShareStatusActive
+}
+
+// signShareToken 令牌为 随机ID.签名，签名校验通过后才查询数据库，数据库中只保存随机ID
+func signShareToken(publicID string) string {
+	return publicID + "." + shareSignature(publicID)
+}
+
+func verifyShareToken(token string) (string, bool) {
+	publicID, signature, ok := strings.Cut(token, ".")
+	if !ok || publicID == "" {
+		return "", false
+	}
+	return publicID, hmac.Equal([]byte(signature), []byte(shareSignature(publicID)))
+}
+
+func shareSignature(publicID string) string {
+	secret := config.GetShareConfig().Secret
+	if secret == "" {
+		secret = config.GetJWTConfig().SecretKey
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("share:" + publicID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))[:shareSignatureLength]
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"melody_cure/config"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// audioFormats 允许上传的语音格式（扩展名到 Content-Type）
+var audioFormats = map[string]string{
+	"wav":  "audio/wav",
+	"mp3":  "audio/mpeg",
+	"m4a":  "audio/mp4",
+	"aac":  "audio/aac",
+	"ogg":  "audio/ogg",
+	"webm": "audio/webm",
+	"amr":  "audio/amr",
+}
+
+// SynthesizedSpeech 合成语音的访问地址
+type SynthesizedSpeech struct {
+	URL    string `json:"url"`
+	Voice  string `json:"voice"`
+	Cached bool   `json:"cached"` // 是否命中已合成的缓存
+}
+
+// SpeechService 语音合成、识别以及音频文件的存储。合成结果按提供商、音色和文字缓存在对象存储中，相同内容只合成一次
+type SpeechService struct {
+	provider SpeechProvider
+	storage  ObjectStorage
+}
+
+func NewSpeechService(provider SpeechProvider, storage ObjectStorage) *SpeechService {
+	return &SpeechService{provider: provider, storage: storage}
+}
+
+// Synthesize 以指定音色合成语音，音色为空时使用配置的默认音色，超出字数限制的部分会被截断
+func (s *SpeechService) Synthesize(ctx context.Context, text, voice string) (*SynthesizedSpeech, error) {
+	cfg := config.GetSpeechConfig()
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, invalidRequest("合成的文字不能为空")
+	}
+	if cfg.MaxSynthesisRunes > 0 && utf8.RuneCountInString(text) > cfg.MaxSynthesisRunes {
+		text = truncateRunes(text, cfg.MaxSynthesisRunes)
+	}
+	voice = strings.TrimSpace(voice)
+	if voice == "" {
+		voice = cfg.DefaultVoice
+	}
+
+	sum := sha256.Sum256([]byte(s.provider.Name() + "\x00" + voice + "\x00" + text))
+	key := "speech/" + hex.EncodeToString(sum[:]) + "." + s.provider.AudioFormat()
+	if url, ok, err := s.storage.Lookup(ctx, key); err != nil {
+		log.Printf("查询合成语音缓存失败: %v", err)
+	} else if ok {
+		return &SynthesizedSpeech{URL: url, Voice: voice, Cached: true}, nil
+	}
+
+	audio, err := s.provider.Synthesize(ctx, &SpeechSynthesisRequest{Text: text, Voice: voice})
+	if err != nil {
+		return nil, fmt.Errorf("语音合成失败: %w", err)
+	}
+	url, err := s.storage.Put(ctx, key, audio.Data, audio.ContentType)
+	if err != nil {
+		return nil, fmt.Errorf("保存合成语音失败: %w", err)
+	}
+	return &SynthesizedSpeech{URL: url, Voice: voice}, nil
+}
+
+// Transcribe 识别语音中的文字
+func (s *SpeechService) Transcribe(ctx context.Context, audio []byte, format, language string) (*SpeechTranscript, error) {
+	transcript, err := s.provider.Transcribe(ctx, &SpeechTranscriptionRequest{
+		Audio:       audio,
+		ContentType: audioFormats[format],
+		Format:      format,
+		Language:    language,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("语音识别失败: %w", err)
+	}
+	transcript.Text = strings.TrimSpace(transcript.Text)
+	return transcript, nil
+}
+
+// StoreRecording 以随机文件名保存上传的录音，返回访问地址
+func (s *SpeechService) StoreRecording(ctx context.Context, dir string, audio []byte, format string) (string, error) {
+	key := path.Join(dir, generateUUID()+"."+format)
+	url, err := s.storage.Put(ctx, key, audio, audioFormats[format])
+	if err != nil {
+		return "", fmt.Errorf("保存录音失败: %w", err)
+	}
+	return url, nil
+}
+
+// audioFormat 根据文件名扩展名确定语音格式，不支持时返回错误
+func audioFormat(filename string) (string, error) {
+	format := strings.ToLower(strings.TrimPrefix(path.Ext(filename), "."))
+	if _, ok := audioFormats[format]; !ok {
+		return "", invalidRequest("不支持的语音格式: %s", path.Ext(filename))
+	}
+	return format, nil
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"log"
+	"math"
+	"melody_cure/config"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SpeechSynthesisRequest 语音合成请求
+type SpeechSynthesisRequest struct {
+	Text     string
+	Voice    string // 音色，对应AI陪伴的语音类型
+	Language string
+}
+
+// SpeechAudio 合成的音频
+type SpeechAudio struct {
+	Data        []byte
+	ContentType string
+	Format      string // 文件扩展名，如 wav
+	Duration    time.Duration
+}
+
+// SpeechTranscriptionRequest 语音识别请求
+type SpeechTranscriptionRequest struct {
+	Audio       []byte
+	ContentType string
+	Format      string // 文件扩展名
+	Language    string
+}
+
+// SpeechTranscript 语音识别结果，无法识别时 Text 为空
+type SpeechTranscript struct {
+	Text     string        `json:"text"`
+	Language string        `json:"language"`
+	Duration time.Duration `json:"duration" swaggertype:"integer"` // 音频时长（纳秒），无法解析时为0
+	Provider string        `json:"provider"`
+}
+
+// SpeechProvider 语音合成与识别提供商
+type SpeechProvider interface {
+	Name() string
+	// AudioFormat 合成音频的格式（扩展名），用于确定缓存的对象键
+	AudioFormat() string
+	Synthesize(ctx context.Context, req *SpeechSynthesisRequest) (*SpeechAudio, error)
+	Transcribe(ctx context.Context, req *SpeechTranscriptionRequest) (*SpeechTranscript, error)
+}
+
+// 支持的语音提供商
+const (
+	SpeechProviderLocal = "local" // 离线的本地替代实现
+)
+
+// NewSpeechProvider 根据语音配置创建提供商
+func NewSpeechProvider() SpeechProvider {
+	cfg := config.GetSpeechConfig()
+	switch strings.ToLower(cfg.Provider) {
+	case SpeechProviderLocal, "fake", "":
+	default:
+		log.Printf("不支持的语音提供商 %s，使用本地替代实现", cfg.Provider)
+	}
+	return NewLocalSpeechProvider()
+}
+
+// localSpeechVoice 本地替代实现的音色：基准音高（Hz）和每个字的时长
+type localSpeechVoice struct {
+	baseHz float64
+	unit   time.Duration
+}
+
+// localSpeechVoices 内置音色，其他语音类型按名称映射到固定的音高
+var localSpeechVoices = map[string]localSpeechVoice{
+	"warm":   {baseHz: 220, unit: 130 * time.Millisecond},
+	"child":  {baseHz: 330, unit: 110 * time.Millisecond},
+	"female": {baseHz: 262, unit: 120 * time.Millisecond},
+	"male":   {baseHz: 131, unit: 130 * time.Millisecond},
+	"robot":  {baseHz: 180, unit: 100 * time.Millisecond},
+}
+
+// 本地替代实现输出的音频格式：16kHz 单声道 16 位 PCM
+const (
+	localSpeechSampleRate = 16000
+	localSpeechPause      = 200 * time.Millisecond // 标点处的停顿
+	localSpeechSoftware   = "melody_cure local speech"
+)
+
+// LocalSpeechProvider 离线的语音替代实现。合成时每个字生成一段由字决定音高的音调，并把原文写入 WAV 的 INFO 注释；
+// 识别时读取该注释，因此能识别自己合成的音频，其他音频只解析时长、不识别文字
+type LocalSpeechProvider struct{}
+
+func NewLocalSpeechProvider() *LocalSpeechProvider {
+	return &LocalSpeechProvider{}
+}
+
+func (p *LocalSpeechProvider) Name() string {
+	return SpeechProviderLocal
+}
+
+func (p *LocalSpeechProvider) AudioFormat() string {
+	return "wav"
+}
+
+func (p *LocalSpeechProvider) Synthesize(ctx context.Context, req *SpeechSynthesisRequest) (*SpeechAudio, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, errors.New("合成的文字不能为空")
+	}
+	voice := localVoice(req.Voice)
+
+	var samples []int16
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			samples = appendSilence(samples, voice.unit/2)
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			samples = appendSilence(samples, localSpeechPause)
+		default:
+			// 按字在十二平均律中取一个半音，同一个字总是同一个音高
+			freq := voice.baseHz * math.Pow(2, float64(int(r)%12)/12)
+			samples = appendTone(samples, freq, voice.unit)
+		}
+	}
+	data := encodeWAV(samples, map[string]string{"ICMT": text, "ISFT": localSpeechSoftware})
+	return &SpeechAudio{
+		Data:        data,
+		ContentType: "audio/wav",
+		Format:      p.AudioFormat(),
+		Duration:    time.Duration(len(samples)) * time.Second / localSpeechSampleRate,
+	}, nil
+}
+
+func (p *LocalSpeechProvider) Transcribe(ctx context.Context, req *SpeechTranscriptionRequest) (*SpeechTranscript, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	transcript := &SpeechTranscript{Language: req.Language, Provider: p.Name()}
+	info, duration, ok := parseWAV(req.Audio)
+	if !ok {
+		return transcript, nil
+	}
+	transcript.Duration = duration
+	if info["ISFT"] == localSpeechSoftware {
+		transcript.Text = info["ICMT"]
+	}
+	return transcript, nil
+}
+
+// localVoice 内置音色直接使用，其他语音类型按名称哈希到 110~440Hz 之间的音高
+func localVoice(name string) localSpeechVoice {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if voice, ok := localSpeechVoices[name]; ok {
+		return voice
+	}
+	if name == "" {
+		return localSpeechVoices["warm"]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return localSpeechVoice{baseHz: 110 * math.Pow(2, float64(h.Sum32()%24)/12), unit: 120 * time.Millisecond}
+}
+
+func appendSilence(samples []int16, d time.Duration) []int16 {
+	return append(samples, make([]int16, int(d.Seconds()*localSpeechSampleRate))...)
+}
+
+// appendTone 追加一段正弦音，首尾各 10ms 渐入渐出避免爆音
+func appendTone(samples []int16, freq float64, d time.Duration) []int16 {
+	n := int(d.Seconds() * localSpeechSampleRate)
+	ramp := localSpeechSampleRate / 100
+	for i := 0; i < n; i++ {
+		gain := 1.0
+		if i < ramp {
+			gain = float64(i) / float64(ramp)
+		} else if n-i < ramp {
+			gain = float64(n-i) / float64(ramp)
+		}
+		value := math.Sin(2*math.Pi*freq*float64(i)/localSpeechSampleRate) * gain * 0.3
+		samples = append(samples, int16(value*math.MaxInt16))
+	}
+	return samples
+}
+
+// encodeWAV 编码为 PCM WAV，info 写入 LIST/INFO 块
+func encodeWAV(samples []int16, info map[string]string) []byte {
+	var list bytes.Buffer
+	list.WriteString("INFO")
+	for _, id := range []string{"ISFT", "ICMT"} {
+		value, ok := info[id]
+		if !ok {
+			continue
+		}
+		payload := append([]byte(value), 0)
+		list.WriteString(id)
+		binary.Write(&list, binary.LittleEndian, uint32(len(payload)))
+		list.Write(payload)
+		if len(payload)%2 == 1 {
+			list.WriteByte(0)
+		}
+	}
+
+	dataSize := len(samples) * 2
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(4+(8+16)+(8+list.Len())+(8+dataSize)))
+	b.WriteString("WAVE")
+	b.WriteString("fmt ")
+	binary.Write(&b, binary.LittleEndian, uint32(16))
+	binary.Write(&b, binary.LittleEndian, uint16(1)) // PCM
+	binary.Write(&b, binary.LittleEndian, uint16(1)) // 单声道
+	binary.Write(&b, binary.LittleEndian, uint32(localSpeechSampleRate))
+	binary.Write(&b, binary.LittleEndian, uint32(localSpeechSampleRate*2))
+	binary.Write(&b, binary.LittleEndian, uint16(2))
+	binary.Write(&b, binary.LittleEndian, uint16(16))
+	b.WriteString("LIST")
+	binary.Write(&b, binary.LittleEndian, uint32(list.Len()))
+	b.Write(list.Bytes())
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(dataSize))
+	binary.Write(&b, binary.LittleEndian, samples)
+	return b.Bytes()
+}
+
+// parseWAV 解析 WAV 的 INFO 注释和时长，不是 WAV 文件时返回 false
+func parseWAV(data []byte) (map[string]string, time.Duration, bool) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, false
+	}
+	info := make(map[string]string)
+	var byteRate uint32
+	var dataSize int
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := data[offset+8 : min(offset+8+size, len(data))]
+		switch id {
+		case "fmt ":
+			if len(body) >= 12 {
+				byteRate = binary.LittleEndian.Uint32(body[8:12])
+			}
+		case "data":
+			dataSize = len(body)
+		case "LIST":
+			if len(body) >= 4 && string(body[:4]) == "INFO" {
+				for pos := 4; pos+8 <= len(body); {
+					subID := string(body[pos : pos+4])
+					subSize := int(binary.LittleEndian.Uint32(body[pos+4 : pos+8]))
+					value := body[pos+8 : min(pos+8+subSize, len(body))]
+					info[subID] = strings.TrimRight(string(value), "\x00")
+					pos += 8 + subSize + subSize%2
+				}
+			}
+		}
+		offset += 8 + size + size%2
+	}
+	var duration time.Duration
+	if byteRate > 0 {
+		duration = time.Duration(float64(dataSize) / float64(byteRate) * float64(time.Second))
+	}
+	return info, duration, true
+}
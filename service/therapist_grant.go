@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/model"
+	"strings"
+)
+
+var ErrTherapistGrantNotFound = errors.New("授权不存在")
+
+// TherapistGrantService 家长授权认证康复师访问孩子的资料
+type TherapistGrantService struct {
+	userDAO             *DAO.UserDAO
+	notificationService *NotificationService
+}
+
+func NewTherapistGrantService(userDAO *DAO.UserDAO, notificationService *NotificationService) *TherapistGrantService {
+	return &TherapistGrantService{userDAO: userDAO, notificationService: notificationService}
+}
+
+// GrantTherapist 家长或管理员授权认证康复师，已授权时返回已有的授权
+func (s *TherapistGrantService) GrantTherapist(userID string, req *request.GrantTherapistRequest) (*model.ChildTherapistGrant, error) {
+	archive, err := checkChildOwner(s.userDAO, userID, req.ChildArchiveID)
+	if err != nil {
+		return nil, err
+	}
+	therapist, err := s.userDAO.GetUserByID(req.TherapistUserID)
+	if err != nil || !isCertifiedTherapist(therapist) {
+		return nil, invalidRequest("只能授权已认证的康复师")
+	}
+	if existing, err := s.userDAO.GetTherapistGrant(req.ChildArchiveID, req.TherapistUserID); err == nil {
+		return existing, nil
+	}
+
+	grant := &model.ChildTherapistGrant{
+		ChildArchiveID:  req.ChildArchiveID,
+		TherapistUserID: req.TherapistUserID,
+		GrantedBy:       userID,
+		Note:            strings.TrimSpace(req.Note),
+	}
+	if err := s.userDAO.CreateTherapistGrant(grant); err != nil {
+		return nil, fmt.Errorf("保存授权失败: %w", err)
+	}
+	if err := s.notificationService.Notify(therapist.ID, model.NotificationTherapistGrant, "获得儿童资料访问授权",
+		fmt.Sprintf("家长已授权您查看和管理%s的疗愈记录和报告。", archive.ChildName),
+		"child_archive", archive.ID); err != nil {
+		log.Printf("发送授权通知失败: %v", err)
+	}
+	return grant, nil
+}
+
+// ListChildGrants 家长或管理员查看孩子的授权
+func (s *TherapistGrantService) ListChildGrants(userID, childArchiveID string) ([]model.ChildTherapistGrant, error) {
+	if _, err := checkChildOwner(s.userDAO, userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	grants, err := s.userDAO.ListChildTherapistGrants(childArchiveID)
+	if err != nil {
+		return nil, fmt.Errorf("获取授权失败: %w", err)
+	}
+	return grants, nil
+}
+
+// ListMyGrants 康复师查看自己获得的授权
+func (s *TherapistGrantService) ListMyGrants(userID string) ([]model.ChildTherapistGrant, error) {
+	grants, err := s.userDAO.ListTherapistGrants(userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取授权失败: %w", err)
+	}
+	return grants, nil
+}
+
+// RevokeGrant 家长或管理员撤销授权，康复师也可以放弃自己获得的授权
+func (s *TherapistGrantService) RevokeGrant(userID string, grantID uint) error {
+	grant, err := s.userDAO.GetTherapistGrantByID(grantID)
+	if err != nil {
+		return ErrTherapistGrantNotFound
+	}
+	if grant.TherapistUserID != userID {
+		if _, err := checkChildOwner(s.userDAO, userID, grant.ChildArchiveID); err != nil {
+			return err
+		}
+	}
+	if err := s.userDAO.DeleteTherapistGrant(grant.ID); err != nil {
+		return fmt.Errorf("撤销授权失败: %w", err)
+	}
+	return nil
+}
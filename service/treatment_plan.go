@@ -0,0 +1,542 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/model"
+	"strconv"
+	"time"
+)
+
+type TreatmentPlanService struct {
+	treatmentPlanDAO *DAO.TreatmentPlanDAO
+	gameSessionDAO   *DAO.GameSessionDAO
+	healingLogDAO    *DAO.HealingLogDAO
+	userDAO          *DAO.UserDAO
+}
+
+func NewTreatmentPlanService(treatmentPlanDAO *DAO.TreatmentPlanDAO, gameSessionDAO *DAO.GameSessionDAO, healingLogDAO *DAO.HealingLogDAO, userDAO *DAO.UserDAO) *TreatmentPlanService {
+	return &TreatmentPlanService{
+		treatmentPlanDAO: treatmentPlanDAO,
+		gameSessionDAO:   gameSessionDAO,
+		healingLogDAO:    healingLogDAO,
+		userDAO:          userDAO,
+	}
+}
+
+// 目标进度的计算方式
+const (
+	GoalProgressByStatus   = "status"   // 已手动标记为达成
+	GoalProgressByMetric   = "metric"   // 根据日志指标相对基线和目标值计算
+	GoalProgressByActivity = "activity" // 根据关联的日志和游戏记录次数计算
+	GoalProgressNone       = "none"     // 未设置可量化的达成标准
+)
+
+// GoalProgress 目标进度
+type GoalProgress struct {
+	Percent        float64  `json:"percent"` // 0-100
+	Method         string   `json:"method"`
+	BaselineValue  *float64 `json:"baseline_value,omitempty"`
+	CurrentValue   *float64 `json:"current_value,omitempty"`
+	LinkedLogs     int      `json:"linked_logs"`
+	LinkedSessions int      `json:"linked_sessions"`
+	DaysRemaining  *int     `json:"days_remaining,omitempty"` // 距目标日期的天数，已过期为负数
+}
+
+// GoalWithProgress 附带进度的目标
+type GoalWithProgress struct {
+	model.TreatmentGoal
+	Progress GoalProgress `json:"progress"`
+}
+
+// GoalDetail 目标详情，包含关联记录
+type GoalDetail struct {
+	GoalWithProgress
+	Links []model.GoalLink `json:"links"`
+}
+
+// TreatmentPlanDetail 附带目标进度的治疗方案
+type TreatmentPlanDetail struct {
+	model.TreatmentPlan
+	Goals    []GoalWithProgress `json:"goals"`
+	Progress float64            `json:"progress"` // 未放弃目标的平均进度
+}
+
+// CreatePlan 为儿童创建治疗方案，可同时创建目标
+func (s *TreatmentPlanService) CreatePlan(userID string, req *request.TreatmentPlanRequest) (*TreatmentPlanDetail, error) {
+	if req.ChildArchiveID == "" {
+		return nil, invalidRequest("儿童档案ID不能为空")
+	}
+	if _, err := checkChildAccess(s.userDAO, userID, req.ChildArchiveID); err != nil {
+		return nil, err
+	}
+
+	plan := &model.TreatmentPlan{
+		ChildArchiveID: req.ChildArchiveID,
+		Title:          req.Title,
+		Description:    req.Description,
+		Status:         req.Status,
+		StartDate:      req.StartDate,
+		EndDate:        req.EndDate,
+		CreatedBy:      userID,
+	}
+	if plan.Status == "" {
+		plan.Status = "active"
+	}
+	for i := range req.Goals {
+		goal := goalFromRequest(&req.Goals[i])
+		goal.ChildArchiveID = req.ChildArchiveID
+		plan.Goals = append(plan.Goals, goal)
+	}
+
+	if err := s.treatmentPlanDAO.CreateTreatmentPlan(plan); err != nil {
+		return nil, fmt.Errorf("创建治疗方案失败: %v", err)
+	}
+	return s.buildPlanDetail(plan)
+}
+
+// GetPlan 获取治疗方案详情
+func (s *TreatmentPlanService) GetPlan(userID string, planID uint) (*TreatmentPlanDetail, error) {
+	plan, err := s.getAccessiblePlan(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildPlanDetail(plan)
+}
+
+// ListPlansForChild 获取儿童的所有治疗方案
+func (s *TreatmentPlanService) ListPlansForChild(userID, childArchiveID string) ([]TreatmentPlanDetail, error) {
+	if _, err := checkChildAccess(s.userDAO, userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	plans, err := s.treatmentPlanDAO.GetTreatmentPlansByChildID(childArchiveID)
+	if err != nil {
+		return nil, fmt.Errorf("获取治疗方案失败: %v", err)
+	}
+
+	details := make([]TreatmentPlanDetail, 0, len(plans))
+	for i := range plans {
+		detail, err := s.buildPlanDetail(&plans[i])
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, *detail)
+	}
+	return details, nil
+}
+
+// UpdatePlan 更新治疗方案基本信息，目标通过目标接口单独维护
+func (s *TreatmentPlanService) UpdatePlan(userID string, planID uint, req *request.TreatmentPlanRequest) (*TreatmentPlanDetail, error) {
+	plan, err := s.getAccessiblePlan(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Title = req.Title
+	plan.Description = req.Description
+	plan.StartDate = req.StartDate
+	plan.EndDate = req.EndDate
+	if req.Status != "" {
+		plan.Status = req.Status
+	}
+	if err := s.treatmentPlanDAO.UpdateTreatmentPlan(plan); err != nil {
+		return nil, fmt.Errorf("更新治疗方案失败: %v", err)
+	}
+	return s.buildPlanDetail(plan)
+}
+
+// DeletePlan 删除治疗方案及其目标
+func (s *TreatmentPlanService) DeletePlan(userID string, planID uint) error {
+	if _, err := s.getAccessiblePlan(userID, planID); err != nil {
+		return err
+	}
+	return s.treatmentPlanDAO.DeleteTreatmentPlan(planID)
+}
+
+// AddGoal 为治疗方案添加目标
+func (s *TreatmentPlanService) AddGoal(userID string, planID uint, req *request.TreatmentGoalRequest) (*GoalWithProgress, error) {
+	plan, err := s.getAccessiblePlan(userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	goal := goalFromRequest(req)
+	goal.PlanID = plan.ID
+	goal.ChildArchiveID = plan.ChildArchiveID
+	if err := s.treatmentPlanDAO.CreateGoal(&goal); err != nil {
+		return nil, fmt.Errorf("创建目标失败: %v", err)
+	}
+	return s.goalWithProgress(goal, nil)
+}
+
+// GetGoal 获取目标详情及关联的日志和游戏记录
+func (s *TreatmentPlanService) GetGoal(userID string, goalID uint) (*GoalDetail, error) {
+	goal, err := s.getAccessibleGoal(userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.treatmentPlanDAO.GetLinksByGoalID(goal.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取目标关联记录失败: %v", err)
+	}
+	withProgress, err := s.goalWithProgress(*goal, links)
+	if err != nil {
+		return nil, err
+	}
+	return &GoalDetail{GoalWithProgress: *withProgress, Links: links}, nil
+}
+
+// UpdateGoal 更新目标，标记为达成时记录达成时间
+func (s *TreatmentPlanService) UpdateGoal(userID string, goalID uint, req *request.TreatmentGoalRequest) (*GoalWithProgress, error) {
+	goal, err := s.getAccessibleGoal(userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := goalFromRequest(req)
+	updated.ID = goal.ID
+	updated.PlanID = goal.PlanID
+	updated.ChildArchiveID = goal.ChildArchiveID
+	updated.CreatedAt = goal.CreatedAt
+	if req.Status == "" {
+		updated.Status = goal.Status
+	}
+	if updated.Status == model.GoalStatusAchieved {
+		updated.AchievedAt = goal.AchievedAt
+		if updated.AchievedAt == nil {
+			now := time.Now()
+			updated.AchievedAt = &now
+		}
+	}
+
+	if err := s.treatmentPlanDAO.UpdateGoal(&updated); err != nil {
+		return nil, fmt.Errorf("更新目标失败: %v", err)
+	}
+	links, err := s.treatmentPlanDAO.GetLinksByGoalID(updated.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取目标关联记录失败: %v", err)
+	}
+	return s.goalWithProgress(updated, links)
+}
+
+// DeleteGoal 删除目标
+func (s *TreatmentPlanService) DeleteGoal(userID string, goalID uint) error {
+	if _, err := s.getAccessibleGoal(userID, goalID); err != nil {
+		return err
+	}
+	return s.treatmentPlanDAO.DeleteGoal(goalID)
+}
+
+// LinkGoal 将已有的疗愈日志或游戏记录关联到目标
+func (s *TreatmentPlanService) LinkGoal(userID string, goalID uint, req *request.GoalLinkRequest) error {
+	goal, err := s.getAccessibleGoal(userID, goalID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkRecordBelongsToChild(req.TargetType, req.TargetID, goal.ChildArchiveID); err != nil {
+		return err
+	}
+	return s.treatmentPlanDAO.CreateGoalLinks([]model.GoalLink{{GoalID: goal.ID, TargetType: req.TargetType, TargetID: req.TargetID}})
+}
+
+// UnlinkGoal 取消目标的某个关联
+func (s *TreatmentPlanService) UnlinkGoal(userID string, goalID, linkID uint) error {
+	if _, err := s.getAccessibleGoal(userID, goalID); err != nil {
+		return err
+	}
+	return s.treatmentPlanDAO.DeleteGoalLink(goalID, linkID)
+}
+
+// TagRecord 创建日志或游戏记录时关联目标，目标必须属于同一儿童
+func (s *TreatmentPlanService) TagRecord(childArchiveID, targetType string, targetID uint, goalIDs []uint) error {
+	if len(goalIDs) == 0 {
+		return nil
+	}
+	goals, err := s.treatmentPlanDAO.GetGoalsByIDs(goalIDs)
+	if err != nil {
+		return fmt.Errorf("获取目标失败: %v", err)
+	}
+
+	var links []model.GoalLink
+	for _, goal := range goals {
+		if goal.ChildArchiveID != childArchiveID {
+			return invalidRequest("目标不属于该儿童")
+		}
+		links = append(links, model.GoalLink{GoalID: goal.ID, TargetType: targetType, TargetID: targetID})
+	}
+	if len(links) != len(uniqueUints(goalIDs)) {
+		return invalidRequest("目标不存在")
+	}
+	return s.treatmentPlanDAO.CreateGoalLinks(links)
+}
+
+// RemoveRecordLinks 日志或游戏记录删除后移除其目标关联
+func (s *TreatmentPlanService) RemoveRecordLinks(targetType string, targetID uint) error {
+	return s.treatmentPlanDAO.DeleteLinksByTarget(targetType, targetID)
+}
+
+// GetGoalsWithProgress 获取儿童全部目标及进度，调用方需自行校验访问权限
+func (s *TreatmentPlanService) GetGoalsWithProgress(childArchiveID string) ([]GoalWithProgress, error) {
+	goals, err := s.treatmentPlanDAO.GetGoalsByChildID(childArchiveID)
+	if err != nil {
+		return nil, fmt.Errorf("获取治疗目标失败: %v", err)
+	}
+	return s.goalsWithProgress(goals)
+}
+
+// CreateGameSession 记录一次游戏训练，可同时关联目标
+func (s *TreatmentPlanService) CreateGameSession(userID string, req *request.GameSessionRequest) (*model.GameSession, error) {
+	if _, err := checkChildAccess(s.userDAO, userID, req.ChildArchiveID); err != nil {
+		return nil, err
+	}
+
+	session := &model.GameSession{
+		ChildArchiveID:  req.ChildArchiveID,
+		UserID:          userID,
+		GameID:          req.GameID,
+		Score:           req.Score,
+		DurationSeconds: req.DurationSeconds,
+		Notes:           req.Notes,
+		PlayedAt:        time.Now(),
+	}
+	if req.PlayedAt != nil {
+		session.PlayedAt = *req.PlayedAt
+	}
+	if err := s.gameSessionDAO.CreateGameSession(session); err != nil {
+		return nil, fmt.Errorf("记录游戏训练失败: %v", err)
+	}
+	if err := s.TagRecord(req.ChildArchiveID, model.GoalLinkGameSession, session.ID, req.GoalIDs); err != nil {
+		return session, fmt.Errorf("游戏训练已记录，但关联目标失败: %w", err)
+	}
+	return session, nil
+}
+
+// GetGameSessions 获取儿童的游戏训练记录
+func (s *TreatmentPlanService) GetGameSessions(userID, childArchiveID string) ([]model.GameSession, error) {
+	if _, err := checkChildAccess(s.userDAO, userID, childArchiveID); err != nil {
+		return nil, err
+	}
+	return s.gameSessionDAO.GetGameSessionsByChildID(childArchiveID)
+}
+
+func (s *TreatmentPlanService) getAccessiblePlan(userID string, planID uint) (*model.TreatmentPlan, error) {
+	plan, err := s.treatmentPlanDAO.GetTreatmentPlanByID(planID)
+	if err != nil {
+		return nil, notFound("治疗方案不存在")
+	}
+	if _, err := checkChildAccess(s.userDAO, userID, plan.ChildArchiveID); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (s *TreatmentPlanService) getAccessibleGoal(userID string, goalID uint) (*model.TreatmentGoal, error) {
+	goal, err := s.treatmentPlanDAO.GetGoalByID(goalID)
+	if err != nil {
+		return nil, notFound("目标不存在")
+	}
+	if _, err := checkChildAccess(s.userDAO, userID, goal.ChildArchiveID); err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
+
+// checkRecordBelongsToChild 校验日志或游戏记录属于目标所在的儿童
+func (s *TreatmentPlanService) checkRecordBelongsToChild(targetType string, targetID uint, childArchiveID string) error {
+	switch targetType {
+	case model.GoalLinkHealingLog:
+		healingLog, err := s.healingLogDAO.GetHealingLogByID(targetID)
+		if err != nil {
+			return invalidRequest("疗愈日志不存在")
+		}
+		if strconv.FormatUint(uint64(healingLog.ChildArchiveID), 10) != childArchiveID {
+			return invalidRequest("疗愈日志不属于该儿童")
+		}
+	case model.GoalLinkGameSession:
+		session, err := s.gameSessionDAO.GetGameSessionByID(targetID)
+		if err != nil {
+			return invalidRequest("游戏记录不存在")
+		}
+		if session.ChildArchiveID != childArchiveID {
+			return invalidRequest("游戏记录不属于该儿童")
+		}
+	default:
+		return invalidRequest("不支持的关联类型")
+	}
+	return nil
+}
+
+func (s *TreatmentPlanService) buildPlanDetail(plan *model.TreatmentPlan) (*TreatmentPlanDetail, error) {
+	goals, err := s.goalsWithProgress(plan.Goals)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &TreatmentPlanDetail{TreatmentPlan: *plan, Goals: goals}
+	detail.TreatmentPlan.Goals = nil
+	var total float64
+	var counted int
+	for _, goal := range goals {
+		if goal.Status == model.GoalStatusDropped {
+			continue
+		}
+		total += goal.Progress.Percent
+		counted++
+	}
+	if counted > 0 {
+		detail.Progress = roundPercent(total / float64(counted))
+	}
+	return detail, nil
+}
+
+func (s *TreatmentPlanService) goalsWithProgress(goals []model.TreatmentGoal) ([]GoalWithProgress, error) {
+	goalIDs := make([]uint, 0, len(goals))
+	for _, goal := range goals {
+		goalIDs = append(goalIDs, goal.ID)
+	}
+	links, err := s.treatmentPlanDAO.GetLinksByGoalIDs(goalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取目标关联记录失败: %v", err)
+	}
+	linksByGoal := make(map[uint][]model.GoalLink)
+	for _, link := range links {
+		linksByGoal[link.GoalID] = append(linksByGoal[link.GoalID], link)
+	}
+
+	result := make([]GoalWithProgress, 0, len(goals))
+	for _, goal := range goals {
+		withProgress, err := s.goalWithProgress(goal, linksByGoal[goal.ID])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *withProgress)
+	}
+	return result, nil
+}
+
+// goalWithProgress 计算目标进度：已达成为100%；设置了指标和目标值时按最新指标相对基线的完成比例；
+// 设置了目标次数时按关联记录次数；指标进度达到100%时自动标记为达成
+func (s *TreatmentPlanService) goalWithProgress(goal model.TreatmentGoal, links []model.GoalLink) (*GoalWithProgress, error) {
+	progress := GoalProgress{Method: GoalProgressNone}
+	for _, link := range links {
+		switch link.TargetType {
+		case model.GoalLinkHealingLog:
+			progress.LinkedLogs++
+		case model.GoalLinkGameSession:
+			progress.LinkedSessions++
+		}
+	}
+	if goal.TargetDate != nil {
+		days := int(math.Ceil(time.Until(*goal.TargetDate).Hours() / 24))
+		progress.DaysRemaining = &days
+	}
+
+	switch {
+	case goal.Status == model.GoalStatusAchieved:
+		progress.Method = GoalProgressByStatus
+		progress.Percent = 100
+	case goal.MetricName != "" && goal.TargetValue != nil:
+		progress.Method = GoalProgressByMetric
+		if err := s.fillMetricProgress(&goal, &progress); err != nil {
+			return nil, err
+		}
+	case goal.TargetCount > 0:
+		progress.Method = GoalProgressByActivity
+		progress.Percent = roundPercent(clampPercent(float64(progress.LinkedLogs+progress.LinkedSessions) / float64(goal.TargetCount) * 100))
+	}
+
+	if progress.Method == GoalProgressByMetric && progress.Percent >= 100 &&
+		(goal.Status == model.GoalStatusNotStarted || goal.Status == model.GoalStatusInProgress) {
+		now := time.Now()
+		goal.Status = model.GoalStatusAchieved
+		goal.AchievedAt = &now
+		if err := s.treatmentPlanDAO.UpdateGoal(&goal); err != nil {
+			return nil, fmt.Errorf("更新目标状态失败: %v", err)
+		}
+	}
+	return &GoalWithProgress{TreatmentGoal: goal, Progress: progress}, nil
+}
+
+// fillMetricProgress 根据目标开始日期之后的指标记录计算进度，目标值可以高于或低于基线
+func (s *TreatmentPlanService) fillMetricProgress(goal *model.TreatmentGoal, progress *GoalProgress) error {
+	childID, err := strconv.ParseUint(goal.ChildArchiveID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	metrics, err := s.healingLogDAO.GetMetricsByChildIDAndName(uint(childID), goal.MetricName)
+	if err != nil {
+		return fmt.Errorf("获取指标记录失败: %v", err)
+	}
+
+	var values []float64
+	for _, metric := range metrics {
+		if goal.StartDate != nil && metric.RecordedAt.Before(*goal.StartDate) {
+			continue
+		}
+		values = append(values, metric.Value)
+	}
+	if len(values) == 0 {
+		progress.BaselineValue = goal.BaselineValue
+		return nil
+	}
+
+	baseline := values[0]
+	if goal.BaselineValue != nil {
+		baseline = *goal.BaselineValue
+	}
+	current := values[len(values)-1]
+	progress.BaselineValue = &baseline
+	progress.CurrentValue = &current
+
+	target := *goal.TargetValue
+	if target == baseline {
+		if current == target {
+			progress.Percent = 100
+		}
+		return nil
+	}
+	progress.Percent = roundPercent(clampPercent((current - baseline) / (target - baseline) * 100))
+	return nil
+}
+
+func goalFromRequest(req *request.TreatmentGoalRequest) model.TreatmentGoal {
+	goal := model.TreatmentGoal{
+		Domain:         req.Domain,
+		Title:          req.Title,
+		Description:    req.Description,
+		TargetCriteria: req.TargetCriteria,
+		MetricName:     req.MetricName,
+		BaselineValue:  req.BaselineValue,
+		TargetValue:    req.TargetValue,
+		TargetCount:    req.TargetCount,
+		StartDate:      req.StartDate,
+		TargetDate:     req.TargetDate,
+		Status:         req.Status,
+	}
+	if goal.Status == "" {
+		goal.Status = model.GoalStatusNotStarted
+	}
+	return goal
+}
+
+func clampPercent(percent float64) float64 {
+	return math.Max(0, math.Min(100, percent))
+}
+
+func roundPercent(percent float64) float64 {
+	return math.Round(percent*10) / 10
+}
+
+func uniqueUints(values []uint) []uint {
+	seen := make(map[uint]bool, len(values))
+	var result []uint
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
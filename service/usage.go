@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"melody_cure/DAO"
+	"melody_cure/config"
+	"melody_cure/model"
+	"strings"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("AI用量已超出配额")
+
+// 配额周期和范围
+const (
+	QuotaPeriodDaily      = "daily"
+	QuotaPeriodMonthly    = "monthly"
+	QuotaScopeUser        = "user"
+	QuotaScopeInstitution = "institution"
+)
+
+// UsageService 记录大模型调用的 token 用量，按用户身份和机构校验配额，并按模型汇总用量和费用
+type UsageService struct {
+	usageDAO *DAO.AIUsageDAO
+	userDAO  *DAO.UserDAO
+}
+
+func NewUsageService(usageDAO *DAO.AIUsageDAO, userDAO *DAO.UserDAO) *UsageService {
+	return &UsageService{
+		usageDAO: usageDAO,
+		userDAO:  userDAO,
+	}
+}
+
+type usageScopeKey struct{}
+
+// usageScope 大模型调用的归属，随 context 传递给计量提供商
+type usageScope struct {
+	userID string
+	source string
+}
+
+// WithUsageScope 将后续大模型调用的用量记在指定用户名下
+func WithUsageScope(ctx context.Context, userID, source string) context.Context {
+	return context.WithValue(ctx, usageScopeKey{}, usageScope{userID: userID, source: source})
+}
+
+// QuotaWindow 一个配额周期的用量
+type QuotaWindow struct {
+	Period    string    `json:"period"` // daily, monthly
+	Scope     string    `json:"scope"`  // user, institution
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// QuotaStatus 用户当前的配额状态，同时受个人和机构配额限制时取剩余较少的一项，不限时为空
+type QuotaStatus struct {
+	Daily   *QuotaWindow `json:"daily,omitempty"`
+	Monthly *QuotaWindow `json:"monthly,omitempty"`
+}
+
+// Exceeded 返回已用尽的配额周期，都未用尽时返回空
+func (q *QuotaStatus) Exceeded() *QuotaWindow {
+	for _, window := range []*QuotaWindow{q.Monthly, q.Daily} {
+		if window != nil && window.Remaining <= 0 {
+			return window
+		}
+	}
+	return nil
+}
+
+// ModelUsageCost 按模型汇总的用量和估算费用
+type ModelUsageCost struct {
+	model.AIModelUsage
+	EstimatedCost float64 `json:"estimated_cost"`
+	Priced        bool    `json:"priced"` // 是否配置了该模型的单价
+}
+
+// UsageSummary 用量汇总
+type UsageSummary struct {
+	Start            *time.Time       `json:"start,omitempty"`
+	End              *time.Time       `json:"end,omitempty"`
+	Currency         string           `json:"currency"`
+	Requests         int64            `json:"requests"`
+	PromptTokens     int64            `json:"prompt_tokens"`
+	CompletionTokens int64            `json:"completion_tokens"`
+	TotalTokens      int64            `json:"total_tokens"`
+	EstimatedCost    float64          `json:"estimated_cost"`
+	Models           []ModelUsageCost `json:"models"`
+}
+
+// CheckQuota 获取用户的配额状态，任一配额用尽时返回 ErrQuotaExceeded
+func (s *UsageService) CheckQuota(userID string) (*QuotaStatus, error) {
+	status := &QuotaStatus{}
+	cfg := config.GetUsageConfig()
+	if !cfg.Enabled {
+		return status, nil
+	}
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil {
+		return nil, notFound("用户不存在")
+	}
+
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	periods := []struct {
+		name   string
+		start  time.Time
+		reset  time.Time
+		target **QuotaWindow
+		limit  func(config.QuotaConfig) int64
+	}{
+		{QuotaPeriodDaily, dayStart, dayStart.AddDate(0, 0, 1), &status.Daily, func(q config.QuotaConfig) int64 { return q.Daily }},
+		{QuotaPeriodMonthly, monthStart, monthStart.AddDate(0, 1, 0), &status.Monthly, func(q config.QuotaConfig) int64 { return q.Monthly }},
+	}
+
+	roleQuota := cfg.RoleQuotas[strings.ToLower(user.Identity)]
+	institutionID := institutionOf(user)
+	institutionQuota, ok := cfg.InstitutionQuotas[strings.ToLower(institutionID)]
+	if !ok {
+		institutionQuota = cfg.InstitutionQuota
+	}
+
+	for _, period := range periods {
+		if limit := period.limit(roleQuota); limit > 0 {
+			used, err := s.usageDAO.SumUserTokens(user.ID, period.start)
+			if err != nil {
+				return nil, fmt.Errorf("统计AI用量失败: %v", err)
+			}
+			keepTighter(period.target, newQuotaWindow(period.name, QuotaScopeUser, limit, used, period.reset))
+		}
+		if limit := period.limit(institutionQuota); limit > 0 && institutionID != "" {
+			used, err := s.usageDAO.SumInstitutionTokens(institutionID, period.start)
+			if err != nil {
+				return nil, fmt.Errorf("统计AI用量失败: %v", err)
+			}
+			keepTighter(period.target, newQuotaWindow(period.name, QuotaScopeInstitution, limit, used, period.reset))
+		}
+	}
+
+	if status.Exceeded() != nil {
+		return status, ErrQuotaExceeded
+	}
+	return status, nil
+}
+
+func newQuotaWindow(period, scope string, limit, used int64, resetAt time.Time) *QuotaWindow {
+	remaining := limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &QuotaWindow{Period: period, Scope: scope, Limit: limit, Used: used, Remaining: remaining, ResetAt: resetAt}
+}
+
+func keepTighter(target **QuotaWindow, window *QuotaWindow) {
+	if *target == nil || window.Remaining < (*target).Remaining {
+		*target = window
+	}
+}
+
+// institutionOf 用户所属的机构，机构账号本身即为机构
+func institutionOf(user *DAO.User) string {
+	if user.Identity == DAO.IdentityInstitution {
+		return user.ID
+	}
+	return user.InstitutionID
+}
+
+// RecordUsage 记录一次成功的大模型调用，提供商未返回用量时按文本长度估算
+func (s *UsageService) RecordUsage(ctx context.Context, req *LLMRequest, resp *LLMResponse) {
+	record := &model.AIUsageRecord{
+		ReportType:       req.ReportType,
+		Provider:         resp.Provider,
+		Model:            resp.Model,
+		PromptTokens:     resp.PromptTokens,
+		CompletionTokens: resp.CompletionTokens,
+	}
+	if record.PromptTokens == 0 && record.CompletionTokens == 0 {
+		for _, message := range req.Messages {
+			record.PromptTokens += estimateTokens(message.Content)
+		}
+		record.CompletionTokens = estimateTokens(resp.Content)
+		record.Estimated = true
+	}
+	record.TotalTokens = record.PromptTokens + record.CompletionTokens
+
+	if scope, ok := ctx.Value(usageScopeKey{}).(usageScope); ok {
+		record.UserID, record.Source = scope.userID, scope.source
+		if user, err := s.userDAO.GetUserByID(scope.userID); err == nil {
+			record.Identity = user.Identity
+			record.InstitutionID = institutionOf(user)
+		}
+	}
+	if err := s.usageDAO.CreateAIUsageRecord(record); err != nil {
+		log.Printf("记录AI用量失败: %v", err)
+	}
+}
+
+// GetUsageSummary 按模型汇总用量和估算费用（仅管理员）
+func (s *UsageService) GetUsageSummary(userID string, filter DAO.AIUsageFilter) (*UsageSummary, error) {
+	if err := s.checkAdmin(userID); err != nil {
+		return nil, err
+	}
+	usages, err := s.usageDAO.SummarizeAIUsageByModel(filter)
+	if err != nil {
+		return nil, fmt.Errorf("汇总AI用量失败: %v", err)
+	}
+
+	cfg := config.GetUsageConfig()
+	summary := &UsageSummary{
+		Start:    filter.Start,
+		End:      filter.End,
+		Currency: cfg.Currency,
+		Models:   make([]ModelUsageCost, 0, len(usages)),
+	}
+	for _, usage := range usages {
+		item := ModelUsageCost{AIModelUsage: usage}
+		if pricing, ok := cfg.Pricing[strings.ToLower(usage.Model)]; ok {
+			item.Priced = true
+			item.EstimatedCost = (float64(usage.PromptTokens)*pricing.Prompt + float64(usage.CompletionTokens)*pricing.Completion) / 1000
+		}
+		summary.Requests += usage.Requests
+		summary.PromptTokens += usage.PromptTokens
+		summary.CompletionTokens += usage.CompletionTokens
+		summary.TotalTokens += usage.TotalTokens
+		summary.EstimatedCost += item.EstimatedCost
+		summary.Models = append(summary.Models, item)
+	}
+	return summary, nil
+}
+
+// SetUserInstitution 设置用户所属的机构，institutionID 为空时解除关联（仅管理员）
+func (s *UsageService) SetUserInstitution(adminID, userID, institutionID string) error {
+	if err := s.checkAdmin(adminID); err != nil {
+		return err
+	}
+	if _, err := s.userDAO.GetUserByID(userID); err != nil {
+		return notFound("用户不存在")
+	}
+	if institutionID != "" {
+		institution, err := s.userDAO.GetUserByID(institutionID)
+		if err != nil || institution.Identity != DAO.IdentityInstitution {
+			return notFound("机构不存在")
+		}
+	}
+	return s.userDAO.UpdateUserInstitution(userID, institutionID)
+}
+
+func (s *UsageService) checkAdmin(userID string) error {
+	user, err := s.userDAO.GetUserByID(userID)
+	if err != nil || !isAdmin(user) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// MeteredLLMProvider 记录每次成功调用的 token 用量
+type MeteredLLMProvider struct {
+	provider     LLMProvider
+	usageService *UsageService
+}
+
+func (p *MeteredLLMProvider) Name() string {
+	return p.provider.Name()
+}
+
+func (p *MeteredLLMProvider) Chat(ctx context.Context, req *LLMRequest) (*LLMResponse, error) {
+	resp, err := p.provider.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	p.usageService.RecordUsage(ctx, req, resp)
+	return resp, nil
+}
+
+func (p *MeteredLLMProvider) ChatStream(ctx context.Context, req *LLMRequest, onDelta LLMStreamHandler) (*LLMResponse, error) {
+	resp, err := chatStream(ctx, p.provider, req, onDelta)
+	if err != nil {
+		return nil, err
+	}
+	p.usageService.RecordUsage(ctx, req, resp)
+	return resp, nil
+}
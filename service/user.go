@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"crypto/rand"
 	"encoding/hex"
-	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -244,12 +243,14 @@ func (u *User) GetCertificationStatus(userID string) (*DAO.Certification, error)
 func (u *User) CreateAICompanion(userID string, req *request.AICompanionRequest) (*DAO.AICompanion, error) {
 	// 构建AI陪伴数据
 	companion := &DAO.AICompanion{
+		ID:            generateUUID(),
 		UserID:        userID,
 		CompanionType: req.CompanionType,
 		Name:          req.Name,
 		Avatar:        req.Avatar,
 		Personality:   req.Personality,
 		VoiceType:     req.VoiceType,
+		IsActive:      true,
 	}
 	
 	// 调用DAO层创建AI陪伴
@@ -270,12 +271,14 @@ func (u *User) GetAICompanions(userID string) ([]DAO.AICompanion, error) {
 func (u *User) CreateVirtualTherapist(userID string, req *request.VirtualTherapistRequest) (*DAO.VirtualTherapist, error) {
 	// 构建虚拟疗愈导师数据
 	therapist := &DAO.VirtualTherapist{
+		ID:             generateUUID(),
 		UserID:         userID,
 		TherapistType:  req.TherapistType,
 		Name:           req.Name,
 		Avatar:         req.Avatar,
 		Specialization: req.Specialization,
 		Experience:     req.Experience,
+		IsActive:       true,
 	}
 	
 	// 调用DAO层创建虚拟疗愈导师
@@ -294,13 +297,7 @@ func (u *User) GetVirtualTherapists(userID string) ([]DAO.VirtualTherapist, erro
 
 // 创建儿童档案
 func (u *User) CreateChildArchive(userID string, req *request.ChildArchiveRequest) (*DAO.ChildArchive, error) {
-	// 计算已疗愈天数
-	healedDays := 0
-	if req.TreatmentStartDate != nil {
-		healedDays = int(time.Since(*req.TreatmentStartDate).Hours() / 24)
-	}
-	
-	// 构建儿童档案数据
+	// 构建儿童档案数据，已疗愈天数由疗愈进度统计根据日志记录计算
 	archive := &DAO.ChildArchive{
 		UserID:             userID,
 		ChildName:          req.ChildName,
@@ -313,7 +310,6 @@ func (u *User) CreateChildArchive(userID string, req *request.ChildArchiveReques
 		Progress:           req.Progress,
 		Notes:              req.Notes,
 		TreatmentStartDate: req.TreatmentStartDate,
-		HealedDays:         healedDays,
 	}
 	
 	// 调用DAO层创建儿童档案
@@ -332,12 +328,6 @@ func (u *User) GetChildArchives(userID string) ([]DAO.ChildArchive, error) {
 
 // 更新儿童档案
 func (u *User) UpdateChildArchive(userID string, archiveID string, req *request.ChildArchiveRequest) error {
-	// 计算已疗愈天数
-	healedDays := 0
-	if req.TreatmentStartDate != nil {
-		healedDays = int(time.Since(*req.TreatmentStartDate).Hours() / 24)
-	}
-	
 	// 构建更新数据
 	archive := &DAO.ChildArchive{
 		ID:                 archiveID,
@@ -352,7 +342,6 @@ func (u *User) UpdateChildArchive(userID string, archiveID string, req *request.
 		Progress:           req.Progress,
 		Notes:              req.Notes,
 		TreatmentStartDate: req.TreatmentStartDate,
-		HealedDays:         healedDays,
 	}
 	
 	return u.dao.UpdateChildArchive(archive)
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"melody_cure/DAO"
+	"melody_cure/api/request"
+	"melody_cure/config"
+	"melody_cure/model"
+	"strconv"
+	"strings"
+)
+
+// voiceLogAudioDir 语音日志录音在对象存储中的目录
+const voiceLogAudioDir = "healing-logs/audio"
+
+// VoiceLogResult 语音日志的创建结果和识别详情
+type VoiceLogResult struct {
+	Log        *model.HealingLog `json:"log"`
+	Transcript *SpeechTranscript `json:"transcript"`
+}
+
+// VoiceLogService 以录音创建疗愈日志：识别的文字保存为日志内容，录音作为音频媒体保留
+type VoiceLogService struct {
+	userDAO           *DAO.UserDAO
+	healingLogService *HealingLogService
+	speechService     *SpeechService
+}
+
+func NewVoiceLogService(userDAO *DAO.UserDAO, healingLogService *HealingLogService, speechService *SpeechService) *VoiceLogService {
+	return &VoiceLogService{
+		userDAO:           userDAO,
+		healingLogService: healingLogService,
+		speechService:     speechService,
+	}
+}
+
+// CreateVoiceLog 识别录音并创建疗愈日志。识别不出文字时日志内容只包含补充说明，录音仍会保存
+func (s *VoiceLogService) CreateVoiceLog(ctx context.Context, userID string, req *request.CreateVoiceLogRequest, filename string, audio []byte) (*VoiceLogResult, error) {
+	if len(audio) == 0 {
+		return nil, invalidRequest("录音不能为空")
+	}
+	if max := config.GetSpeechConfig().MaxAudioBytes; max > 0 && int64(len(audio)) > max {
+		return nil, invalidRequest("录音不能超过%dMB", max>>20)
+	}
+	format, err := audioFormat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := checkChildAccess(s.userDAO, userID, strconv.FormatUint(uint64(req.ChildArchiveID), 10)); err != nil {
+		return nil, err
+	}
+	language, err := NormalizeReportLanguage(req.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	transcript, err := s.speechService.Transcribe(ctx, audio, format, language)
+	if err != nil {
+		return nil, err
+	}
+	url, err := s.speechService.StoreRecording(ctx, voiceLogAudioDir, audio, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []string
+	for _, part := range []string{req.Note, transcript.Text} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	// 日志的 UserID 是数字类型，无法保存字符串用户ID，归属以儿童档案为准
+	log := &model.HealingLog{
+		ChildArchiveID: req.ChildArchiveID,
+		Content:        strings.Join(parts, "\n\n"),
+		Phase:          req.Phase,
+		Skill:          req.Skill,
+		Media:          []model.LogMedia{{MediaType: model.LogMediaAudio, URL: url}},
+	}
+	if err := s.healingLogService.CreateHealingLog(userID, log); err != nil {
+		return nil, fmt.Errorf("创建日志失败: %w", err)
+	}
+	return &VoiceLogResult{Log: log, Transcript: transcript}, nil
+}
@@ -4,19 +4,26 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"melody_cure/DAO"
 	"melody_cure/config"
 	"melody_cure/controller"
 	"melody_cure/middleware"
 	"melody_cure/routes"
+	"melody_cure/scheduler"
 	"melody_cure/service"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
 )
 
 type App struct {
-	Engine *gin.Engine
+	Engine     *gin.Engine
+	Scheduler  *scheduler.Scheduler
+	ReportJobs *service.ReportJobService
 }
 
 var ProviderSet = wire.NewSet(
@@ -24,18 +31,74 @@ var ProviderSet = wire.NewSet(
 	DAO.NewUserDAO,
 	DAO.NewHealingLogDAO,
 	DAO.NewGeneratedReportDAO,
+	DAO.NewLogTemplateDAO,
+	DAO.NewNotificationDAO,
+	DAO.NewChildMilestoneDAO,
+	DAO.NewTreatmentPlanDAO,
+	DAO.NewGameSessionDAO,
+	DAO.NewImageTokenDAO,
+	DAO.NewReportJobDAO,
+	DAO.NewReportTypeDAO,
+	DAO.NewReportCacheDAO,
+	DAO.NewAIUsageDAO,
+	DAO.NewInstitutionBrandingDAO,
+	DAO.NewShareLinkDAO,
+	DAO.NewReportFeedbackDAO,
+	DAO.NewReportSafetyDAO,
+	DAO.NewCompanionDAO,
+	DAO.NewConsultationDAO,
+	DAO.NewSemanticIndexDAO,
 	service.NewUser,
 	service.NewHealingLogService,
 	service.NewImageService,
 	service.NewOtherService,
 	service.NewAIReportService,
+	service.NewLogTemplateService,
+	service.NewNotificationService,
+	service.NewChildProgressService,
+	service.NewHealingComparisonService,
+	service.NewTreatmentPlanService,
+	service.NewScheduledJobService,
+	service.NewReportJobService,
+	service.NewReportTemplateService,
+	service.NewLLMProvider,
+	service.NewUsageService,
+	service.NewExportService,
+	service.NewShareService,
+	service.NewReportFeedbackService,
+	service.NewSafetyService,
+	service.NewCompanionService,
+	service.NewConsultationService,
+	service.NewObjectStorage,
+	service.NewSpeechProvider,
+	service.NewSpeechService,
+	service.NewVoiceLogService,
+	service.NewEmbeddingProvider,
+	service.NewSemanticIndexService,
+	service.NewTherapistGrantService,
 	controller.NewUserController,
 	controller.NewHealingLogController,
 	controller.NewChildArchiveController,
 	controller.NewAIReportController,
+	controller.NewLogTemplateController,
+	controller.NewNotificationController,
+	controller.NewTreatmentPlanController,
+	controller.NewReportTypeController,
+	controller.NewUsageController,
+	controller.NewExportController,
+	controller.NewShareController,
+	controller.NewReportFeedbackController,
+	controller.NewReportSafetyController,
+	controller.NewCompanionController,
+	controller.NewConsultationController,
+	controller.NewVoiceLogController,
+	controller.NewSemanticIndexController,
+	controller.NewTherapistGrantController,
 	NewJwtClient,
+	NewRedisClient,
+	NewScheduler,
 	NewEngine,
-	wire.Struct(new(App), "Engine"),
+	wire.Struct(new(App), "Engine", "Scheduler", "ReportJobs"),
 	wire.Bind(new(service.UserService), new(*service.User)),
 )
 
@@ -43,31 +106,149 @@ func NewJwtClient() *middleware.JwtClient {
 	return &middleware.JwtClient{SecretKey: config.GetJWTConfig().SecretKey}
 }
 
+// NewRedisClient 使用 main 中初始化的全局 Redis 连接
+func NewRedisClient() *redis.Client {
+	return DAO.RDB
+}
+
+// NewScheduler 创建定时任务调度器并注册任务
+func NewScheduler(rdb *redis.Client, jobService *service.ScheduledJobService) (*scheduler.Scheduler, error) {
+	cfg := config.GetSchedulerConfig()
+	s := scheduler.NewScheduler(rdb)
+
+	reminderHour, reminderMinute, err := scheduler.ParseClock(cfg.ReminderTime)
+	if err != nil {
+		return nil, fmt.Errorf("每日提醒时间配置错误: %v", err)
+	}
+	reportHour, reportMinute, err := scheduler.ParseClock(cfg.WeeklyReportTime)
+	if err != nil {
+		return nil, fmt.Errorf("周报生成时间配置错误: %v", err)
+	}
+	certHour, certMinute, err := scheduler.ParseClock(cfg.CertExpiryCheckTime)
+	if err != nil {
+		return nil, fmt.Errorf("认证到期检查时间配置错误: %v", err)
+	}
+	cleanupInterval := time.Duration(cfg.TokenCleanupInterval) * time.Minute
+	if cleanupInterval <= 0 {
+		cleanupInterval = time.Hour
+	}
+
+	// 每日记录提醒
+	s.Register(&scheduler.Job{
+		Name:     "logging_reminder",
+		Schedule: scheduler.Daily(reminderHour, reminderMinute),
+		Run:      jobService.SendLoggingReminders,
+	})
+	// 每周自动生成总结报告
+	s.Register(&scheduler.Job{
+		Name:     "weekly_report",
+		Schedule: scheduler.Weekly(time.Weekday(cfg.WeeklyReportWeekday%7), reportHour, reportMinute),
+		Run:      jobService.GenerateWeeklyReports,
+		LockTTL:  2 * time.Hour,
+	})
+	// 认证到期检查
+	s.Register(&scheduler.Job{
+		Name:     "certification_expiry",
+		Schedule: scheduler.Daily(certHour, certMinute),
+		Run: func(ctx context.Context) error {
+			return jobService.CheckCertificationExpiry(ctx, cfg.CertExpiryWarnDays)
+		},
+	})
+	// 清理过期图床token
+	s.Register(&scheduler.Job{
+		Name:     "image_token_cleanup",
+		Schedule: scheduler.Every(cleanupInterval),
+		Run:      jobService.CleanupExpiredImageTokens,
+		LockTTL:  cleanupInterval / 2,
+	})
+	return s, nil
+}
+
 func NewEngine(
 	userController *controller.User,
 	healingLogController *controller.HealingLogController,
 	childArchiveController *controller.ChildArchiveController,
 	aiReportController *controller.AIReportController,
+	logTemplateController *controller.LogTemplateController,
+	notificationController *controller.NotificationController,
+	treatmentPlanController *controller.TreatmentPlanController,
+	reportTypeController *controller.ReportTypeController,
+	usageController *controller.UsageController,
+	exportController *controller.ExportController,
+	shareController *controller.ShareController,
+	reportFeedbackController *controller.ReportFeedbackController,
+	reportSafetyController *controller.ReportSafetyController,
+	companionController *controller.CompanionController,
+	consultationController *controller.ConsultationController,
+	voiceLogController *controller.VoiceLogController,
+	semanticIndexController *controller.SemanticIndexController,
+	therapistGrantController *controller.TherapistGrantController,
+	objectStorage service.ObjectStorage,
 	jwtClient *middleware.JwtClient,
 ) *gin.Engine {
 	r := gin.Default()
-	
+
 	// 设置用户路由
 	routes.SetupUserRoutes(r, userController, jwtClient)
-	
+
 	// 设置疗愈日志路由
 	routes.SetupHealingLogRoutes(r, healingLogController, jwtClient)
-	
+
 	// 设置儿童档案路由
 	routes.SetupChildArchiveRoutes(r, childArchiveController, jwtClient)
-	
+
 	// 设置AI报告路由
 	routes.SetupAIReportRoutes(r, aiReportController, jwtClient)
-	
+
+	// 设置日志模板路由
+	routes.SetupLogTemplateRoutes(r, logTemplateController, jwtClient)
+
+	// 设置站内通知路由
+	routes.SetupNotificationRoutes(r, notificationController, jwtClient)
+
+	// 设置治疗方案路由
+	routes.SetupTreatmentPlanRoutes(r, treatmentPlanController, jwtClient)
+
+	// 设置报告类型路由
+	routes.SetupReportTypeRoutes(r, reportTypeController, jwtClient)
+
+	// 设置AI用量路由
+	routes.SetupUsageRoutes(r, usageController, jwtClient)
+
+	// 设置文档导出路由
+	routes.SetupExportRoutes(r, exportController, jwtClient)
+
+	// 设置分享链接路由
+	routes.SetupShareRoutes(r, shareController, jwtClient)
+
+	// 设置报告评价路由
+	routes.SetupReportFeedbackRoutes(r, reportFeedbackController, jwtClient)
+
+	// 设置报告安全审核路由
+	routes.SetupReportSafetyRoutes(r, reportSafetyController, jwtClient)
+
+	// 设置AI陪伴对话路由
+	routes.SetupCompanionRoutes(r, companionController, jwtClient)
+
+	// 设置虚拟疗愈导师咨询路由
+	routes.SetupConsultationRoutes(r, consultationController, jwtClient)
+
+	// 设置语音疗愈日志路由
+	routes.SetupVoiceLogRoutes(r, voiceLogController, jwtClient)
+
+	// 设置语义索引路由
+	routes.SetupSemanticIndexRoutes(r, semanticIndexController, jwtClient)
+
+	// 设置康复师授权路由
+	routes.SetupTherapistGrantRoutes(r, therapistGrantController, jwtClient)
+
+	// 设置本地对象存储的文件访问路由
+	routes.SetupMediaRoutes(r, objectStorage)
+
 	return r
 }
 
 func InitializeApp() (*App, error) {
 	wire.Build(ProviderSet)
 	return &App{}, nil
-}
\ No newline at end of file
+}
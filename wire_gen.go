@@ -7,14 +7,19 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
 	"melody_cure/DAO"
 	"melody_cure/config"
 	"melody_cure/controller"
 	"melody_cure/middleware"
 	"melody_cure/routes"
+	"melody_cure/scheduler"
 	"melody_cure/service"
+	"time"
 )
 
 // Injectors from wire.go:
@@ -29,15 +34,88 @@ func InitializeApp() (*App, error) {
 	user := service.NewUser(userDAO, jwtClient)
 	controllerUser := controller.NewUserController(user)
 	healingLogDAO := DAO.NewHealingLogDAO(db)
-	healingLogService := service.NewHealingLogService(healingLogDAO)
-	healingLogController := controller.NewHealingLogController(healingLogService)
-	childArchiveController := controller.NewChildArchiveController(user)
+	logTemplateDAO := DAO.NewLogTemplateDAO(db)
+	childMilestoneDAO := DAO.NewChildMilestoneDAO(db)
+	notificationDAO := DAO.NewNotificationDAO(db)
+	notificationService := service.NewNotificationService(notificationDAO)
+	childProgressService := service.NewChildProgressService(userDAO, healingLogDAO, childMilestoneDAO, notificationService)
+	treatmentPlanDAO := DAO.NewTreatmentPlanDAO(db)
+	gameSessionDAO := DAO.NewGameSessionDAO(db)
+	treatmentPlanService := service.NewTreatmentPlanService(treatmentPlanDAO, gameSessionDAO, healingLogDAO, userDAO)
+	semanticIndexDAO := DAO.NewSemanticIndexDAO(db)
 	generatedReportDAO := DAO.NewGeneratedReportDAO(db)
-	aiReportService := service.NewAIReportService(generatedReportDAO, healingLogDAO)
-	aiReportController := controller.NewAIReportController(aiReportService)
-	engine := NewEngine(controllerUser, healingLogController, childArchiveController, aiReportController, jwtClient)
+	reportTypeDAO := DAO.NewReportTypeDAO(db)
+	embeddingProvider := service.NewEmbeddingProvider()
+	semanticIndexService := service.NewSemanticIndexService(semanticIndexDAO, healingLogDAO, generatedReportDAO, reportTypeDAO, treatmentPlanDAO, userDAO, embeddingProvider)
+	healingLogService := service.NewHealingLogService(healingLogDAO, logTemplateDAO, childProgressService, treatmentPlanService, semanticIndexService, userDAO)
+	healingComparisonService := service.NewHealingComparisonService(healingLogDAO, userDAO)
+	healingLogController := controller.NewHealingLogController(healingLogService, healingComparisonService)
+	childArchiveController := controller.NewChildArchiveController(user, childProgressService, treatmentPlanService)
+	reportTemplateService, err := service.NewReportTemplateService(reportTypeDAO, userDAO)
+	if err != nil {
+		return nil, err
+	}
+	client := NewRedisClient()
+	reportCacheDAO := DAO.NewReportCacheDAO(client)
+	aiUsageDAO := DAO.NewAIUsageDAO(db)
+	usageService := service.NewUsageService(aiUsageDAO, userDAO)
+	llmProvider := service.NewLLMProvider(usageService)
+	reportSafetyDAO := DAO.NewReportSafetyDAO(db)
+	reportJobDAO := DAO.NewReportJobDAO(db, client)
+	safetyService, err := service.NewSafetyService(reportSafetyDAO, userDAO, reportJobDAO, notificationService, llmProvider, usageService)
+	if err != nil {
+		return nil, err
+	}
+	aiReportService := service.NewAIReportService(generatedReportDAO, healingLogDAO, userDAO, healingComparisonService, reportTemplateService, reportCacheDAO, llmProvider, safetyService, semanticIndexService)
+	reportJobService := service.NewReportJobService(reportJobDAO, userDAO, aiReportService, notificationService)
+	aiReportController := controller.NewAIReportController(aiReportService, reportJobService, usageService)
+	logTemplateService := service.NewLogTemplateService(logTemplateDAO, userDAO)
+	logTemplateController := controller.NewLogTemplateController(logTemplateService)
+	notificationController := controller.NewNotificationController(notificationService)
+	treatmentPlanController := controller.NewTreatmentPlanController(treatmentPlanService)
+	reportTypeController := controller.NewReportTypeController(reportTemplateService, aiReportService)
+	usageController := controller.NewUsageController(usageService)
+	institutionBrandingDAO := DAO.NewInstitutionBrandingDAO(db)
+	exportService := service.NewExportService(userDAO, healingLogDAO, generatedReportDAO, institutionBrandingDAO, aiReportService, treatmentPlanService)
+	exportController := controller.NewExportController(exportService)
+	shareLinkDAO := DAO.NewShareLinkDAO(db)
+	shareService := service.NewShareService(shareLinkDAO, generatedReportDAO, userDAO, aiReportService, exportService)
+	shareController := controller.NewShareController(shareService)
+	reportFeedbackDAO := DAO.NewReportFeedbackDAO(db)
+	reportFeedbackService := service.NewReportFeedbackService(reportFeedbackDAO, userDAO, aiReportService)
+	reportFeedbackController := controller.NewReportFeedbackController(reportFeedbackService)
+	reportSafetyController := controller.NewReportSafetyController(safetyService)
+	companionDAO := DAO.NewCompanionDAO(db)
+	speechProvider := service.NewSpeechProvider()
+	objectStorage, err := service.NewObjectStorage()
+	if err != nil {
+		return nil, err
+	}
+	speechService := service.NewSpeechService(speechProvider, objectStorage)
+	companionService, err := service.NewCompanionService(companionDAO, userDAO, notificationService, llmProvider, speechService)
+	if err != nil {
+		return nil, err
+	}
+	companionController := controller.NewCompanionController(companionService, usageService)
+	consultationDAO := DAO.NewConsultationDAO(db)
+	consultationService := service.NewConsultationService(consultationDAO, userDAO, healingLogDAO, generatedReportDAO, reportTypeDAO, notificationService, llmProvider)
+	consultationController := controller.NewConsultationController(consultationService, usageService)
+	voiceLogService := service.NewVoiceLogService(userDAO, healingLogService, speechService)
+	voiceLogController := controller.NewVoiceLogController(voiceLogService)
+	semanticIndexController := controller.NewSemanticIndexController(semanticIndexService)
+	therapistGrantService := service.NewTherapistGrantService(userDAO, notificationService)
+	therapistGrantController := controller.NewTherapistGrantController(therapistGrantService)
+	engine := NewEngine(controllerUser, healingLogController, childArchiveController, aiReportController, logTemplateController, notificationController, treatmentPlanController, reportTypeController, usageController, exportController, shareController, reportFeedbackController, reportSafetyController, companionController, consultationController, voiceLogController, semanticIndexController, therapistGrantController, objectStorage, jwtClient)
+	imageTokenDAO := DAO.NewImageTokenDAO(db)
+	scheduledJobService := service.NewScheduledJobService(userDAO, healingLogDAO, notificationDAO, imageTokenDAO, notificationService, reportJobService)
+	scheduler, err := NewScheduler(client, scheduledJobService)
+	if err != nil {
+		return nil, err
+	}
 	app := &App{
-		Engine: engine,
+		Engine:     engine,
+		Scheduler:  scheduler,
+		ReportJobs: reportJobService,
 	}
 	return app, nil
 }
@@ -45,22 +123,98 @@ func InitializeApp() (*App, error) {
 // wire.go:
 
 type App struct {
-	Engine *gin.Engine
+	Engine     *gin.Engine
+	Scheduler  *scheduler.Scheduler
+	ReportJobs *service.ReportJobService
 }
 
-var ProviderSet = wire.NewSet(DAO.NewDB, DAO.NewUserDAO, DAO.NewHealingLogDAO, DAO.NewGeneratedReportDAO, service.NewUser, service.NewHealingLogService, service.NewImageService, service.NewOtherService, service.NewAIReportService, controller.NewUserController, controller.NewHealingLogController, controller.NewChildArchiveController, controller.NewAIReportController, NewJwtClient,
-	NewEngine, wire.Struct(new(App), "Engine"), wire.Bind(new(service.UserService), new(*service.User)),
+var ProviderSet = wire.NewSet(DAO.NewDB, DAO.NewUserDAO, DAO.NewHealingLogDAO, DAO.NewGeneratedReportDAO, DAO.NewLogTemplateDAO, DAO.NewNotificationDAO, DAO.NewChildMilestoneDAO, DAO.NewTreatmentPlanDAO, DAO.NewGameSessionDAO, DAO.NewImageTokenDAO, DAO.NewReportJobDAO, DAO.NewReportTypeDAO, DAO.NewReportCacheDAO, DAO.NewAIUsageDAO, DAO.NewInstitutionBrandingDAO, DAO.NewShareLinkDAO, DAO.NewReportFeedbackDAO, DAO.NewReportSafetyDAO, DAO.NewCompanionDAO, DAO.NewConsultationDAO, DAO.NewSemanticIndexDAO, service.NewUser, service.NewHealingLogService, service.NewImageService, service.NewOtherService, service.NewAIReportService, service.NewLogTemplateService, service.NewNotificationService, service.NewChildProgressService, service.NewHealingComparisonService, service.NewTreatmentPlanService, service.NewScheduledJobService, service.NewReportJobService, service.NewReportTemplateService, service.NewLLMProvider, service.NewUsageService, service.NewExportService, service.NewShareService, service.NewReportFeedbackService, service.NewSafetyService, service.NewCompanionService, service.NewConsultationService, service.NewObjectStorage, service.NewSpeechProvider, service.NewSpeechService, service.NewVoiceLogService, service.NewEmbeddingProvider, service.NewSemanticIndexService, service.NewTherapistGrantService, controller.NewUserController, controller.NewHealingLogController, controller.NewChildArchiveController, controller.NewAIReportController, controller.NewLogTemplateController, controller.NewNotificationController, controller.NewTreatmentPlanController, controller.NewReportTypeController, controller.NewUsageController, controller.NewExportController, controller.NewShareController, controller.NewReportFeedbackController, controller.NewReportSafetyController, controller.NewCompanionController, controller.NewConsultationController, controller.NewVoiceLogController, controller.NewSemanticIndexController, controller.NewTherapistGrantController, NewJwtClient,
+	NewRedisClient,
+	NewScheduler,
+	NewEngine, wire.Struct(new(App), "Engine", "Scheduler", "ReportJobs"), wire.Bind(new(service.UserService), new(*service.User)),
 )
 
 func NewJwtClient() *middleware.JwtClient {
 	return &middleware.JwtClient{SecretKey: config.GetJWTConfig().SecretKey}
 }
 
+// NewRedisClient 使用 main 中初始化的全局 Redis 连接
+func NewRedisClient() *redis.Client {
+	return DAO.RDB
+}
+
+// NewScheduler 创建定时任务调度器并注册任务
+func NewScheduler(rdb *redis.Client, jobService *service.ScheduledJobService) (*scheduler.Scheduler, error) {
+	cfg := config.GetSchedulerConfig()
+	s := scheduler.NewScheduler(rdb)
+
+	reminderHour, reminderMinute, err := scheduler.ParseClock(cfg.ReminderTime)
+	if err != nil {
+		return nil, fmt.Errorf("每日提醒时间配置错误: %v", err)
+	}
+	reportHour, reportMinute, err := scheduler.ParseClock(cfg.WeeklyReportTime)
+	if err != nil {
+		return nil, fmt.Errorf("周报生成时间配置错误: %v", err)
+	}
+	certHour, certMinute, err := scheduler.ParseClock(cfg.CertExpiryCheckTime)
+	if err != nil {
+		return nil, fmt.Errorf("认证到期检查时间配置错误: %v", err)
+	}
+	cleanupInterval := time.Duration(cfg.TokenCleanupInterval) * time.Minute
+	if cleanupInterval <= 0 {
+		cleanupInterval = time.Hour
+	}
+
+	s.Register(&scheduler.Job{
+		Name:     "logging_reminder",
+		Schedule: scheduler.Daily(reminderHour, reminderMinute),
+		Run:      jobService.SendLoggingReminders,
+	})
+
+	s.Register(&scheduler.Job{
+		Name:     "weekly_report",
+		Schedule: scheduler.Weekly(time.Weekday(cfg.WeeklyReportWeekday%7), reportHour, reportMinute),
+		Run:      jobService.GenerateWeeklyReports,
+		LockTTL:  2 * time.Hour,
+	})
+
+	s.Register(&scheduler.Job{
+		Name:     "certification_expiry",
+		Schedule: scheduler.Daily(certHour, certMinute),
+		Run: func(ctx context.Context) error {
+			return jobService.CheckCertificationExpiry(ctx, cfg.CertExpiryWarnDays)
+		},
+	})
+
+	s.Register(&scheduler.Job{
+		Name:     "image_token_cleanup",
+		Schedule: scheduler.Every(cleanupInterval),
+		Run:      jobService.CleanupExpiredImageTokens,
+		LockTTL:  cleanupInterval / 2,
+	})
+	return s, nil
+}
+
 func NewEngine(
 	userController *controller.User,
 	healingLogController *controller.HealingLogController,
 	childArchiveController *controller.ChildArchiveController,
 	aiReportController *controller.AIReportController,
+	logTemplateController *controller.LogTemplateController,
+	notificationController *controller.NotificationController,
+	treatmentPlanController *controller.TreatmentPlanController,
+	reportTypeController *controller.ReportTypeController,
+	usageController *controller.UsageController,
+	exportController *controller.ExportController,
+	shareController *controller.ShareController,
+	reportFeedbackController *controller.ReportFeedbackController,
+	reportSafetyController *controller.ReportSafetyController,
+	companionController *controller.CompanionController,
+	consultationController *controller.ConsultationController,
+	voiceLogController *controller.VoiceLogController,
+	semanticIndexController *controller.SemanticIndexController,
+	therapistGrantController *controller.TherapistGrantController,
+	objectStorage service.ObjectStorage,
 	jwtClient *middleware.JwtClient,
 ) *gin.Engine {
 	r := gin.Default()
@@ -68,6 +222,21 @@ func NewEngine(
 	routes.SetupHealingLogRoutes(r, healingLogController, jwtClient)
 	routes.SetupChildArchiveRoutes(r, childArchiveController, jwtClient)
 	routes.SetupAIReportRoutes(r, aiReportController, jwtClient)
+	routes.SetupLogTemplateRoutes(r, logTemplateController, jwtClient)
+	routes.SetupNotificationRoutes(r, notificationController, jwtClient)
+	routes.SetupTreatmentPlanRoutes(r, treatmentPlanController, jwtClient)
+	routes.SetupReportTypeRoutes(r, reportTypeController, jwtClient)
+	routes.SetupUsageRoutes(r, usageController, jwtClient)
+	routes.SetupExportRoutes(r, exportController, jwtClient)
+	routes.SetupShareRoutes(r, shareController, jwtClient)
+	routes.SetupReportFeedbackRoutes(r, reportFeedbackController, jwtClient)
+	routes.SetupReportSafetyRoutes(r, reportSafetyController, jwtClient)
+	routes.SetupCompanionRoutes(r, companionController, jwtClient)
+	routes.SetupConsultationRoutes(r, consultationController, jwtClient)
+	routes.SetupVoiceLogRoutes(r, voiceLogController, jwtClient)
+	routes.SetupSemanticIndexRoutes(r, semanticIndexController, jwtClient)
+	routes.SetupTherapistGrantRoutes(r, therapistGrantController, jwtClient)
+	routes.SetupMediaRoutes(r, objectStorage)
 
 	return r
 }